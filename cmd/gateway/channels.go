@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openclaw/go-openclaw/discord"
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/openclaw/go-openclaw/pkg/gateway"
+	"github.com/openclaw/go-openclaw/slack"
+	telegram "github.com/openclaw/go-openclaw/telegram"
+	"github.com/openclaw/go-openclaw/whatsapp"
+)
+
+// registerChannels registers the messaging channels enabled in the
+// configuration, answered by the agent of a gateway
+func registerChannels(gw *gateway.Gateway, cfg *config.ChannelsConfig) error {
+	if cfg.Telegram.Enabled {
+		bot, err := telegram.NewBot(&telegram.Config{
+			BotToken:           cfg.Telegram.BotToken,
+			APIURL:             cfg.Telegram.APIURL,
+			MaxFileSize:        cfg.Telegram.MaxFileSize,
+			ParseMode:          cfg.Telegram.ParseMode,
+			EditInterval:       cfg.Telegram.EditInterval,
+			AllowedUsers:       cfg.Telegram.AllowedUsers,
+			AllowedGroups:      cfg.Telegram.AllowedGroups,
+			AdminUsers:         cfg.Telegram.AdminUsers,
+			GroupTrigger:       cfg.Telegram.GroupTrigger,
+			GroupKeywords:      cfg.Telegram.GroupKeywords,
+			GroupSession:       cfg.Telegram.GroupSession,
+			UseWebhook:         cfg.Telegram.UseWebhook,
+			WebhookURL:         cfg.Telegram.WebhookURL,
+			WebhookPath:        cfg.Telegram.WebhookPath,
+			WebhookSecret:      cfg.Telegram.WebhookSecret,
+			WebhookCertificate: cfg.Telegram.WebhookCertificate,
+			Debug:              cfg.Telegram.Debug,
+			Control:            gw,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create Telegram channel: %w", err)
+		}
+		gw.RegisterChannel(bot)
+	}
+	if cfg.Slack.Enabled {
+		bot, err := slack.NewBot(&slack.Config{
+			BotToken:        cfg.Slack.BotToken,
+			SigningSecret:   cfg.Slack.SigningSecret,
+			APIURL:          cfg.Slack.APIURL,
+			WebhookPath:     cfg.Slack.WebhookPath,
+			EditInterval:    cfg.Slack.EditInterval,
+			AllowedUsers:    cfg.Slack.AllowedUsers,
+			AllowedChannels: cfg.Slack.AllowedChannels,
+			Debug:           cfg.Slack.Debug,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create Slack channel: %w", err)
+		}
+		gw.RegisterChannel(bot)
+	}
+	if cfg.Discord.Enabled {
+		bot, err := discord.NewBot(&discord.Config{
+			BotToken:      cfg.Discord.BotToken,
+			GatewayURL:    cfg.Discord.GatewayURL,
+			APIURL:        cfg.Discord.APIURL,
+			Intents:       cfg.Discord.Intents,
+			EditInterval:  cfg.Discord.EditInterval,
+			AllowedUsers:  cfg.Discord.AllowedUsers,
+			AllowedGuilds: cfg.Discord.AllowedGuilds,
+			AdminUsers:    cfg.Discord.AdminUsers,
+			Debug:         cfg.Discord.Debug,
+			Control:       gw,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create Discord channel: %w", err)
+		}
+		gw.RegisterChannel(bot)
+	}
+	if cfg.WhatsApp.Enabled {
+		bot, err := whatsapp.NewBot(&whatsapp.Config{
+			AccessToken:      cfg.WhatsApp.AccessToken,
+			PhoneNumberID:    cfg.WhatsApp.PhoneNumberID,
+			AppSecret:        cfg.WhatsApp.AppSecret,
+			VerifyToken:      cfg.WhatsApp.VerifyToken,
+			APIURL:           cfg.WhatsApp.APIURL,
+			WebhookPath:      cfg.WhatsApp.WebhookPath,
+			Template:         cfg.WhatsApp.Template,
+			TemplateLanguage: cfg.WhatsApp.TemplateLanguage,
+			MaxFileSize:      cfg.WhatsApp.MaxFileSize,
+			AllowedUsers:     cfg.WhatsApp.AllowedUsers,
+			Debug:            cfg.WhatsApp.Debug,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create WhatsApp channel: %w", err)
+		}
+		gw.RegisterChannel(bot)
+	}
+	return nil
+}
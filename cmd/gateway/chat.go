@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/agent"
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/internal/storage"
+	"github.com/openclaw/go-openclaw/pkg/channels"
+	"github.com/openclaw/go-openclaw/pkg/channels/repl"
+	"github.com/openclaw/go-openclaw/pkg/client"
+	"github.com/openclaw/go-openclaw/pkg/gateway"
+	"github.com/spf13/cobra"
+)
+
+// chatTimeout is the default request timeout of remote chats, long enough
+// for an agent turn
+const chatTimeout = 2 * time.Minute
+
+// chatOptions are the flags of the chat command
+var chatOptions struct {
+	remote       bool
+	verbose      bool
+	history      string
+	provider     string
+	model        string
+	apiKey       string
+	systemPrompt string
+	tools        bool
+}
+
+// chatCmd chats with the agent in the terminal
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Chat with the agent in the terminal",
+	Long: "Chat with the agent in the terminal. By default the agent runs in " +
+		"this process with the workspaces of the configured storage; with " +
+		"--remote the chat goes through a running gateway.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !chatOptions.verbose {
+			log.SetOutput(io.Discard)
+		}
+		if chatOptions.remote {
+			if !cmd.Flags().Changed("timeout") {
+				clientOptions.timeout = chatTimeout
+			}
+			return chatRemote()
+		}
+		return chatLocal()
+	},
+}
+
+func init() {
+	home, _ := os.UserHomeDir()
+
+	flags := chatCmd.Flags()
+	flags.BoolVar(&chatOptions.remote, "remote", false, "chat through a running gateway")
+	flags.BoolVarP(&chatOptions.verbose, "verbose", "v", false, "show gateway logs")
+	flags.StringVar(&chatOptions.history, "history", filepath.Join(home, ".openclaw_history"), "history file, empty to keep none")
+	flags.StringVar(&chatOptions.provider, "provider", agent.DefaultConfig().LLMProvider, "LLM provider, in-process only")
+	flags.StringVar(&chatOptions.model, "model", agent.DefaultConfig().LLMModel, "LLM model, in-process only")
+	flags.StringVar(&chatOptions.apiKey, "api-key", os.Getenv("ANTHROPIC_API_KEY"), "LLM API key (env ANTHROPIC_API_KEY), in-process only")
+	flags.StringVar(&chatOptions.systemPrompt, "system-prompt", "", "system prompt, in-process only")
+	flags.BoolVar(&chatOptions.tools, "tools", false, "offer tools to the agent, in-process only")
+	addClientFlags(flags)
+
+	rootCmd.AddCommand(chatCmd)
+}
+
+// chatLocal runs the agent in process, answering a REPL channel
+func chatLocal() error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+	store, err := storage.Open(&cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+
+	gw = gateway.NewWithConfig(cfg)
+	if err := gw.SetStore(store); err != nil {
+		return fmt.Errorf("failed to load gateway state: %w", err)
+	}
+
+	agentCfg := agent.DefaultConfig()
+	agentCfg.LLMProvider = chatOptions.provider
+	agentCfg.LLMModel = chatOptions.model
+	agentCfg.APIKey = chatOptions.apiKey
+	agentCfg.ToolsEnabled = chatOptions.tools
+	if chatOptions.systemPrompt != "" {
+		agentCfg.SystemPrompt = chatOptions.systemPrompt
+	}
+
+	ctx := context.Background()
+	if err := gw.StartAgent(ctx, agentCfg); err != nil {
+		return err
+	}
+	defer gw.StopAgent(ctx)
+
+	r := repl.New(&repl.Config{HistoryFile: chatOptions.history, Control: gw})
+	gw.RegisterChannel(r)
+	if err := r.Start(ctx); err != nil {
+		return err
+	}
+	<-r.Done()
+	return nil
+}
+
+// chatRemote answers a REPL channel through a running gateway
+func chatRemote() error {
+	gc, err := dialGateway(true)
+	if err != nil {
+		return err
+	}
+	defer gc.Close()
+
+	remote := &remoteAgent{c: gc.c}
+	r := repl.New(&repl.Config{HistoryFile: chatOptions.history, Control: remote})
+	r.SetMessageHandler(func(ctx context.Context, msg *channels.Message) error {
+		return remote.chat(ctx, r, msg)
+	})
+
+	if err := r.Start(context.Background()); err != nil {
+		return err
+	}
+	<-r.Done()
+	return nil
+}
+
+// remoteAgent runs the turns and commands of a REPL through a gateway
+// connection
+type remoteAgent struct {
+	c *client.Client
+}
+
+// channelID returns the channel ID of a REPL target, named like the
+// session of the in-process chat. The gateway keeps it among the sessions
+// of the client's user.
+func (a *remoteAgent) channelID(channel, target string) string {
+	return channel + ":" + target
+}
+
+// chat sends a message and streams the reply into the REPL
+func (a *remoteAgent) chat(ctx context.Context, r *repl.REPL, msg *channels.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, clientOptions.timeout)
+	defer cancel()
+
+	reply, err := a.c.StreamMessage(ctx, a.channelID(msg.Channel, msg.To), msg.Content)
+	if err != nil {
+		return err
+	}
+	stream, err := r.SendStream(ctx, msg.To, nil)
+	if err != nil {
+		return err
+	}
+
+	for delta := range reply.Deltas(ctx) {
+		stream.Append(delta)
+	}
+	resp, err := reply.Response(ctx)
+	if err != nil {
+		stream.Close("")
+		return err
+	}
+	return stream.Close(resp.Message)
+}
+
+// ResetSession starts a new conversation for a target
+func (a *remoteAgent) ResetSession(ctx context.Context, channel, target string) error {
+	ctx, cancel := context.WithTimeout(ctx, clientOptions.timeout)
+	defer cancel()
+	return a.c.ResetSession(ctx, a.channelID(channel, target))
+}
+
+// SessionInfo returns the session of a target
+func (a *remoteAgent) SessionInfo(ctx context.Context, channel, target string) (*protocol.SessionState, error) {
+	ctx, cancel := context.WithTimeout(ctx, clientOptions.timeout)
+	defer cancel()
+	return a.c.SessionInfo(ctx, a.channelID(channel, target))
+}
+
+// ListTools returns the tools offered to the agent
+func (a *remoteAgent) ListTools(ctx context.Context, channel, target string) ([]protocol.ToolInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, clientOptions.timeout)
+	defer cancel()
+	return a.c.ListTools(ctx)
+}
+
+// Model returns the model of the agent, switching to model first if set
+func (a *remoteAgent) Model(ctx context.Context, model string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, clientOptions.timeout)
+	defer cancel()
+	return a.c.Model(ctx, model)
+}
+
+// AgentState returns the agent runtime as mirrored from the gateway
+func (a *remoteAgent) AgentState(ctx context.Context, channel, target string) (*protocol.AgentState, error) {
+	state := a.c.State()
+	if state == nil || state.Agent == nil {
+		return nil, fmt.Errorf("agent state not received yet")
+	}
+	return state.Agent, nil
+}
+
+// remoteAgent implements the REPL slash commands
+var _ channels.AgentControl = (*remoteAgent)(nil)
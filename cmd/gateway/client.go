@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/openclaw/go-openclaw/pkg/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// clientOptions are the connection flags of the client commands
+var clientOptions struct {
+	url       string
+	token     string
+	deviceID  string
+	workspace string
+	timeout   time.Duration
+}
+
+// gatewayConn is a handshaken connection to a gateway, with the request
+// timeout of the client flags
+type gatewayConn struct {
+	c *client.Client
+}
+
+// clientCmd groups the protocol client commands
+var clientCmd = &cobra.Command{
+	Use:   "client",
+	Short: "Talk to a running gateway over the WebSocket protocol",
+}
+
+// clientConnectCmd performs the handshake and prints the hello payload
+var clientConnectCmd = &cobra.Command{
+	Use:   "connect",
+	Short: "Connect and print the hello payload",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gc, err := dialGateway(false)
+		if err != nil {
+			return err
+		}
+		defer gc.Close()
+
+		hello, err := json.Marshal(gc.c.Hello())
+		if err != nil {
+			return err
+		}
+		return printJSON(hello)
+	},
+}
+
+// clientCallCmd sends a request and prints the response payload
+var clientCallCmd = &cobra.Command{
+	Use:   "call <method> [params-json]",
+	Short: "Send a request and print the response",
+	Example: `  openclaw client call state '{"include_clients":true}'
+  openclaw client call workspace.list`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var params json.RawMessage
+		if len(args) > 1 {
+			params = json.RawMessage(args[1])
+			if !json.Valid(params) {
+				return fmt.Errorf("params are not valid JSON")
+			}
+		}
+
+		gc, err := dialGateway(false)
+		if err != nil {
+			return err
+		}
+		defer gc.Close()
+
+		payload, err := gc.Call(args[0], params)
+		if err != nil {
+			return err
+		}
+		return printJSON(payload)
+	},
+}
+
+// clientSubscribeCmd prints events until interrupted
+var clientSubscribeCmd = &cobra.Command{
+	Use:   "subscribe [event...]",
+	Short: "Print events pushed by the gateway, all or those named",
+	Long: "Print events pushed by the gateway until interrupted. Names select " +
+		"events, and may end in * to match a prefix, like presence.*",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gc, err := dialGateway(true)
+		if err != nil {
+			return err
+		}
+		defer gc.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		sub := gc.c.Subscribe(args...)
+		defer sub.Close()
+		for event := range sub.All(ctx) {
+			fmt.Printf("%s seq=%d %s\n", event.Name, event.Seq, event.Data)
+		}
+		return nil
+	},
+}
+
+func init() {
+	addClientFlags(clientCmd.PersistentFlags())
+
+	clientCmd.AddCommand(clientConnectCmd, clientCallCmd, clientSubscribeCmd)
+	rootCmd.AddCommand(clientCmd)
+}
+
+// addClientFlags adds the connection flags of commands talking to a gateway
+func addClientFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&clientOptions.url, "url", "", "gateway WebSocket URL (default: from the config file)")
+	flags.StringVar(&clientOptions.token, "token", os.Getenv("OPENCLAW_TOKEN"), "auth token (env OPENCLAW_TOKEN)")
+	flags.StringVar(&clientOptions.deviceID, "device-id", "", "device ID (default: cli-<hostname>)")
+	flags.StringVar(&clientOptions.workspace, "workspace", "", "workspace to join")
+	flags.DurationVar(&clientOptions.timeout, "timeout", 10*time.Second, "timeout of the handshake and requests")
+}
+
+// dialGateway connects to the gateway and completes the handshake,
+// reconnecting after the connection is lost if asked to
+func dialGateway(reconnect bool) (*gatewayConn, error) {
+	url := clientOptions.url
+	if url == "" {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return nil, err
+		}
+		url = gatewayURL(cfg)
+	}
+
+	deviceID := clientOptions.deviceID
+	if deviceID == "" {
+		host, _ := os.Hostname()
+		deviceID = "cli-" + host
+	}
+
+	c, err := client.Dial(context.Background(), &client.Options{
+		URL:        url,
+		Token:      clientOptions.token,
+		DeviceID:   deviceID,
+		Version:    rootCmd.Version,
+		ClientType: "cli",
+		Workspace:  clientOptions.workspace,
+		Timeout:    clientOptions.timeout,
+		Reconnect:  reconnect,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &gatewayConn{c: c}, nil
+}
+
+// gatewayURL returns the WebSocket URL of the gateway of a configuration
+func gatewayURL(cfg *config.Config) string {
+	return "ws://" + dialAddr(cfg) + "/ws"
+}
+
+// dialAddr returns the address to reach the gateway of a configuration on,
+// loopback when it listens on every interface
+func dialAddr(cfg *config.Config) string {
+	host := cfg.Server.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(cfg.Server.Port))
+}
+
+// Call sends a request and waits for its response, returning the payload
+func (gc *gatewayConn) Call(method string, params interface{}) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clientOptions.timeout)
+	defer cancel()
+
+	var payload json.RawMessage
+	if err := gc.c.Call(ctx, method, params, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Close closes the connection
+func (gc *gatewayConn) Close() error {
+	return gc.c.Close()
+}
+
+// printJSON prints raw JSON indented
+func printJSON(data json.RawMessage) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+// configCmd groups the config file commands
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Validate, print or create configuration",
+}
+
+// configValidateCmd checks the configuration
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration for errors",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration:\n%w", err)
+		}
+		fmt.Println("✅ Configuration is valid")
+		return nil
+	},
+}
+
+// configPrintCmd prints the effective configuration
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the effective configuration, defaults and environment included",
+	Long: "Print the effective configuration, defaults and environment included. " +
+		"Tokens, secrets and passwords are masked unless --show-secrets is given.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		if show, _ := cmd.Flags().GetBool("show-secrets"); !show {
+			cfg = cfg.Redacted()
+		}
+		m, err := cfg.ToMap()
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		switch format, _ := cmd.Flags().GetString("format"); format {
+		case "yaml":
+			data, err = yaml.Marshal(m)
+		case "json":
+			data, err = json.MarshalIndent(m, "", "  ")
+			data = append(data, '\n')
+		default:
+			return fmt.Errorf("unsupported format: %s", format)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = os.Stdout.Write(data)
+		return err
+	},
+}
+
+// configInitCmd writes a config file with the default values
+var configInitCmd = &cobra.Command{
+	Use:   "init [path]",
+	Short: "Write a config file with the default values",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "config.yaml"
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists, use --force to overwrite", path)
+			}
+		}
+
+		if err := config.Default().Save(path); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("✅ Wrote %s\n", path)
+		return nil
+	},
+}
+
+func init() {
+	configPrintCmd.Flags().String("format", "yaml", "output format: yaml or json")
+	configPrintCmd.Flags().Bool("show-secrets", false, "print tokens, secrets and passwords unmasked")
+	configInitCmd.Flags().Bool("force", false, "overwrite an existing file")
+
+	configCmd.AddCommand(configValidateCmd, configPrintCmd, configInitCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/openclaw/go-openclaw/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// doctorOptions are the flags of the doctor command
+var doctorOptions struct {
+	providerURL string
+	timeout     time.Duration
+}
+
+// doctorCmd checks that the gateway can run
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check configuration, storage, provider reachability and port availability",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		failed := 0
+		report := func(name string, err error) {
+			if err != nil {
+				failed++
+				fmt.Printf("❌ %s: %v\n", name, err)
+				return
+			}
+			fmt.Printf("✅ %s\n", name)
+		}
+
+		cfg, err := config.Load(configPath)
+		if err == nil {
+			err = cfg.Validate()
+		}
+		report("Configuration", err)
+		if cfg == nil {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+
+		report(fmt.Sprintf("Storage (%s %s)", cfg.Database.Type, cfg.Database.Path), checkStorage(cfg))
+		report("Provider "+doctorOptions.providerURL, checkProvider(doctorOptions.providerURL, doctorOptions.timeout))
+
+		if err := checkPort(cfg); errors.Is(err, errGatewayRunning) {
+			fmt.Printf("⚠️  Port %s: %v\n", cfg.GetAddr(), err)
+		} else {
+			report("Port "+cfg.GetAddr(), err)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&doctorOptions.providerURL, "provider-url", "https://api.anthropic.com",
+		"LLM provider endpoint to check")
+	doctorCmd.Flags().DurationVar(&doctorOptions.timeout, "timeout", 5*time.Second, "timeout of network checks")
+
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// errGatewayRunning reports a port taken by a gateway answering health checks
+var errGatewayRunning = errors.New("in use by a running gateway")
+
+// checkStorage opens the store and writes, reads back and deletes a record
+func checkStorage(cfg *config.Config) error {
+	store, err := storage.Open(&cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	const collection, id = "doctor", "probe"
+	probe := map[string]int64{"time": time.Now().Unix()}
+	if err := store.Put(collection, id, probe); err != nil {
+		return fmt.Errorf("write failed: %w", err)
+	}
+	var read map[string]int64
+	if err := store.Get(collection, id, &read); err != nil {
+		return fmt.Errorf("read failed: %w", err)
+	}
+	if err := store.Delete(collection, id); err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+	return nil
+}
+
+// checkProvider checks that the provider endpoint answers HTTP. Any status
+// will do: without credentials most endpoints refuse the request.
+func checkProvider(url string, timeout time.Duration) error {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// checkPort checks that the gateway address is free to listen on
+func checkPort(cfg *config.Config) error {
+	ln, err := net.Listen("tcp", cfg.GetAddr())
+	if err == nil {
+		return ln.Close()
+	}
+
+	// Tell a running gateway apart from another program
+	client := &http.Client{Timeout: doctorOptions.timeout}
+	resp, herr := client.Get("http://" + dialAddr(cfg) + "/health")
+	if herr == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusServiceUnavailable {
+			return errGatewayRunning
+		}
+	}
+	return err
+}
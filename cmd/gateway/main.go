@@ -4,58 +4,39 @@ import (
 	"context"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
-	"github.com/openclaw/go-openclaw/internal/config"
 	"github.com/openclaw/go-openclaw/pkg/gateway"
+	"github.com/spf13/cobra"
 )
 
 var (
 	gw *gateway.Gateway
-)
-
-// Execute starts the OpenClaw gateway
-func Execute() {
-	// Load configuration
-	cfg, err := config.Load("")
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
-
-	// Create gateway
-	gw = gateway.New(cfg.GetAddr())
-
-	// Start gateway
-	log.Printf("🚀 Starting OpenClaw Gateway v0.0.1")
-	log.Printf("🌐 Listening on %s", cfg.GetAddr())
-
-	if err := gw.Start(context.Background()); err != nil {
-		log.Fatalf("Failed to start gateway: %v", err)
-	}
 
-	log.Printf("✅ Gateway started successfully")
-
-	// Wait for interrupt signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	<-sigChan
+	// configPath is the config file given with --config, empty to search
+	// the default locations
+	configPath string
+)
 
-	log.Println("🛑 Shutting down...")
+// rootCmd is the openclaw command
+var rootCmd = &cobra.Command{
+	Use:           "openclaw",
+	Short:         "OpenClaw gateway and tools",
+	Version:       "0.0.1",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
 
-	// Graceful shutdown
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer shutdownCancel()
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "",
+		"config file (default: config.yaml in ., ./config or /etc/openclaw)")
+}
 
-	if err := gw.Stop(shutdownCtx); err != nil {
-		log.Printf("⚠️  Gateway shutdown error: %v", err)
+// Execute runs the openclaw command line
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		log.Printf("❌ %v", err)
+		os.Exit(1)
 	}
-
-	log.Println("✅ Gateway stopped")
-
-	os.Exit(0)
 }
 
 // GetGateway returns the current gateway instance
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/openclaw/go-openclaw/internal/storage"
+	"github.com/openclaw/go-openclaw/pkg/cluster"
+	"github.com/openclaw/go-openclaw/pkg/gateway"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd runs the gateway until interrupted
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the gateway",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return serve(configPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
+
+// serve starts the gateway with a config file and serves until SIGINT or
+// SIGTERM
+func serve(path string) error {
+	// Load configuration
+	cfg, err := config.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	// Open persistent storage
+	store, err := storage.Open(&cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+
+	// Create gateway
+	gw = gateway.NewWithConfig(cfg)
+	if err := gw.SetStore(store); err != nil {
+		return fmt.Errorf("failed to load gateway state: %w", err)
+	}
+
+	// Join a gateway cluster if configured
+	if cfg.Cluster.Enabled {
+		bp, err := cluster.New(&cfg.Cluster)
+		if err != nil {
+			return fmt.Errorf("failed to create cluster backplane: %w", err)
+		}
+		gw.SetBackplane(bp)
+	}
+
+	// Register messaging channels, started with the gateway
+	if err := registerChannels(gw, &cfg.Channels); err != nil {
+		return err
+	}
+
+	// Start gateway
+	log.Printf("🚀 Starting OpenClaw Gateway v0.0.1")
+	log.Printf("🌐 Listening on %s", cfg.GetAddr())
+
+	if err := gw.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start gateway: %w", err)
+	}
+
+	log.Printf("✅ Gateway started successfully")
+
+	// Let the process that handed over its listeners stop accepting
+	if err := gw.Ready(); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+
+	// Wait for interrupt signal, or SIGHUP to hand the listener to a new process
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	reason := "shutdown"
+	for sig := range sigChan {
+		if sig == syscall.SIGHUP {
+			if _, err := gw.Restart(); err != nil {
+				log.Printf("⚠️  Restart failed, continuing to serve: %v", err)
+				continue
+			}
+			reason = "restart"
+		}
+		break
+	}
+
+	log.Println("🛑 Shutting down...")
+
+	// Graceful shutdown: drain in-flight work, then close what is left
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(),
+		time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	defer shutdownCancel()
+
+	if err := gw.Drain(shutdownCtx, reason); err != nil {
+		log.Printf("⚠️  Gateway drain incomplete: %v", err)
+	}
+
+	if err := gw.Stop(shutdownCtx); err != nil {
+		log.Printf("⚠️  Gateway shutdown error: %v", err)
+	}
+
+	if err := store.Close(); err != nil {
+		log.Printf("⚠️  Storage close error: %v", err)
+	}
+
+	log.Println("✅ Gateway stopped")
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/spf13/cobra"
+)
+
+// sessionsCmd groups the agent session commands
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List and export agent sessions of a running gateway",
+}
+
+// sessionsListCmd lists the agent sessions of a workspace
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the agent sessions of the workspace",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gc, err := dialGateway(false)
+		if err != nil {
+			return err
+		}
+		defer gc.Close()
+
+		payload, err := gc.Call("sessions.list", nil)
+		if err != nil {
+			return err
+		}
+		var list struct {
+			Sessions []*protocol.SessionState `json:"sessions"`
+		}
+		if err := json.Unmarshal(payload, &list); err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tCHANNEL\tSTATUS\tMESSAGES\tLAST ACTIVE")
+		for _, sess := range list.Sessions {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", sess.ID, sess.Channel, sess.Status,
+				sess.Metadata["messages"], time.Unix(sess.LastActive, 0).Format(time.DateTime))
+		}
+		return w.Flush()
+	},
+}
+
+// sessionsExportCmd writes an agent session with its messages as JSON
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "Export an agent session with its messages as JSON",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gc, err := dialGateway(false)
+		if err != nil {
+			return err
+		}
+		defer gc.Close()
+
+		payload, err := gc.Call("sessions.export", &protocol.SessionRequest{ID: args[0]})
+		if err != nil {
+			return err
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" || output == "-" {
+			return printJSON(payload)
+		}
+
+		var v interface{}
+		if err := json.Unmarshal(payload, &v); err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(output, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Exported %s to %s\n", args[0], output)
+		return nil
+	},
+}
+
+func init() {
+	sessionsExportCmd.Flags().StringP("output", "o", "", "file to write, stdout if empty")
+
+	// Sessions are read over the protocol
+	addClientFlags(sessionsCmd.PersistentFlags())
+
+	sessionsCmd.AddCommand(sessionsListCmd, sessionsExportCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
@@ -0,0 +1,203 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// Bot represents a Discord bot connected to the gateway
+type Bot struct {
+	config         *Config
+	rest           *restClient
+	handler        *Handler
+	messageHandler channels.MessageHandler
+	session        gatewaySession // resumed when the connection is lost
+	self           *user          // bot user, known once started
+	applicationID  string         // application of the bot, from READY
+	running        bool
+	mu             sync.RWMutex
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+// NewBot creates a new Discord bot
+func NewBot(config *Config) (*Bot, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	bot := &Bot{
+		config: config,
+		rest:   newRESTClient(config.apiURL(), config.BotToken),
+	}
+	bot.handler = NewHandler(bot)
+
+	return bot, nil
+}
+
+// Name returns channel name
+func (b *Bot) Name() string {
+	return "discord"
+}
+
+// Start checks the bot token and connects to the gateway
+func (b *Bot) Start(ctx context.Context) error {
+	b.mu.Lock()
+	if b.running {
+		b.mu.Unlock()
+		return fmt.Errorf("bot is already running")
+	}
+	b.mu.Unlock()
+
+	log.Printf("🤖 Starting Discord bot...")
+
+	self, err := b.rest.currentUser(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get bot info: %w", err)
+	}
+
+	log.Printf("✅ Discord Bot initialized: %s (ID: %s)", self.Username, self.ID)
+
+	b.mu.Lock()
+	b.self = self
+	b.ctx, b.cancel = context.WithCancel(ctx)
+	b.running = true
+	b.mu.Unlock()
+
+	b.handler.Start(b.ctx)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.runGateway(b.ctx)
+	}()
+
+	log.Printf("🚀 Discord bot started, gateway %s", b.config.gatewayURL())
+
+	return nil
+}
+
+// Stop disconnects from the gateway
+func (b *Bot) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.running {
+		b.mu.Unlock()
+		return nil
+	}
+	b.running = false
+	b.mu.Unlock()
+
+	log.Println("🛑 Stopping Discord bot...")
+
+	b.cancel()
+
+	// Wait for the connection and the replies being generated
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("✅ Discord bot stopped")
+		return nil
+	case <-time.After(10 * time.Second):
+		log.Println("⚠️  Discord bot stop timeout")
+		return fmt.Errorf("timeout waiting for bot to stop")
+	}
+}
+
+// Send sends a message to specified target, a channel ID
+func (b *Bot) Send(ctx context.Context, target string, content string, options map[string]interface{}) error {
+	if target == "" {
+		return fmt.Errorf("invalid target: channel ID is required")
+	}
+	replyTo, _ := options["reply_to"].(string)
+
+	_, err := b.SendMessage(ctx, target, content, replyTo)
+	return err
+}
+
+// SendMessage sends Markdown to a Discord channel, as a reply to the
+// message replyTo when set. Long text is split into several messages;
+// the ID of the last one is returned.
+func (b *Bot) SendMessage(ctx context.Context, channelID, text, replyTo string) (string, error) {
+	if text == "" {
+		return "", fmt.Errorf("message text cannot be empty")
+	}
+
+	messageID := ""
+	for i, part := range splitMessage(text, maxMessageLength) {
+		reference := ""
+		if i == 0 {
+			reference = replyTo
+		}
+		var err error
+		if messageID, err = b.rest.createMessage(ctx, channelID, part, reference); err != nil {
+			return "", fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+
+	if b.config.Debug {
+		log.Printf("📤 Sent message to channel %s: %s", channelID, truncateString(text, 50))
+	}
+
+	return messageID, nil
+}
+
+// SetMessageHandler sets handler for incoming messages
+func (b *Bot) SetMessageHandler(handler channels.MessageHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messageHandler = handler
+}
+
+// GetMessageHandler returns current message handler
+func (b *Bot) GetMessageHandler() channels.MessageHandler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.messageHandler
+}
+
+// IsRunning returns true if bot is running
+func (b *Bot) IsRunning() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.running
+}
+
+// Status returns current status of bot
+func (b *Bot) Status() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.running {
+		return "running"
+	}
+	return "stopped"
+}
+
+// botUserID returns the user ID of the bot, empty before it is started
+func (b *Bot) botUserID() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.self == nil {
+		return ""
+	}
+	return b.self.ID
+}
+
+// truncateString truncates a string to max characters
+func truncateString(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
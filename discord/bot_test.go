@@ -0,0 +1,78 @@
+package discord
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openclaw/go-openclaw/pkg/channels/channeltest"
+)
+
+// newRESTStandIn returns a REST API stand-in creating messages in channel
+// 42. It rate limits the first request while limited is set.
+func newRESTStandIn(t *testing.T, limited *atomic.Bool) *channeltest.StandIn {
+	api := channeltest.New(t)
+	var ids atomic.Int32
+	api.Handle("", func(r *channeltest.Request) (int, interface{}) {
+		switch {
+		case r.Header.Get("Authorization") != "Bot token" || r.Method != http.MethodPost || r.Path != "/channels/42/messages":
+			return http.StatusUnauthorized, map[string]interface{}{"code": 0, "message": "401: Unauthorized"}
+		case limited.Swap(false):
+			return http.StatusTooManyRequests, map[string]interface{}{"message": "You are being rate limited.", "retry_after": 0.01}
+		}
+		return http.StatusOK, map[string]string{"id": strings.Repeat("9", int(ids.Add(1)))}
+	})
+	return api
+}
+
+// created returns the messages created on a REST API stand-in, rate
+// limited ones included
+func created(t *testing.T, api *channeltest.StandIn) []messageCreate {
+	var messages []messageCreate
+	for _, r := range api.Requests("/channels/42/messages") {
+		var msg messageCreate
+		if err := r.JSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestSendMessage(t *testing.T) {
+	var limited atomic.Bool
+	limited.Store(true)
+	api := newRESTStandIn(t, &limited)
+	b, err := NewBot(&Config{BotToken: "token", APIURL: api.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := strings.Repeat("ö", maxMessageLength) + "\n@everyone done"
+	id, err := b.SendMessage(context.Background(), "42", text, "7")
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	// The first message is tried again after the rate limit
+	messages := created(t, api)
+	if len(messages) != 3 {
+		t.Fatalf("%d requests to create messages, want 2 and a retry", len(messages))
+	}
+	if ref := messages[1].MessageReference; ref == nil || ref.MessageID != "7" {
+		t.Errorf("first message references %+v, want message 7", ref)
+	}
+	if messages[2].MessageReference != nil {
+		t.Error("second message is a reply too")
+	}
+	for i, msg := range messages {
+		if msg.AllowedMentions == nil || len(msg.AllowedMentions.Parse) != 0 {
+			t.Errorf("message %d allows mentions: %+v", i, msg.AllowedMentions)
+		}
+	}
+	if id != "99" {
+		t.Errorf("id = %q, want the one of the last message", id)
+	}
+}
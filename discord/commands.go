@@ -0,0 +1,244 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// command is a slash command. Its reply is Markdown, shown only to the
+// user who ran it unless public.
+type command struct {
+	name        string
+	description string
+	option      *applicationCommandOption
+	control     bool // needs Config.Control
+	public      bool
+	run         func(h *Handler, ctx context.Context, in *interaction) (string, error)
+}
+
+// optionString is the type of string options of slash commands
+const optionString = 3
+
+// commands lists the slash commands, in the order they are offered
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "ask", description: "Ask the assistant",
+			option: &applicationCommandOption{Type: optionString, Name: "prompt", Description: "Your message", Required: true},
+			public: true, run: (*Handler).commandAsk},
+		{name: "reset", description: "Start a new conversation", control: true, run: (*Handler).commandReset},
+		{name: "status", description: "Show agent and session status", control: true, run: (*Handler).commandStatus},
+		{name: "model", description: "Show or switch the model",
+			option:  &applicationCommandOption{Type: optionString, Name: "name", Description: "Model to switch to"},
+			control: true, run: (*Handler).commandModel},
+		{name: "help", description: "Show the commands", run: (*Handler).commandHelp},
+	}
+}
+
+// availableCommands returns the commands the bot can run
+func (h *Handler) availableCommands() []*command {
+	var available []*command
+	for _, cmd := range commands {
+		if !cmd.control || h.bot.config.Control != nil {
+			available = append(available, cmd)
+		}
+	}
+	return available
+}
+
+// registerCommands offers the slash commands in every server of the bot
+// and in direct messages
+func (b *Bot) registerCommands(ctx context.Context) error {
+	b.mu.RLock()
+	applicationID := b.applicationID
+	b.mu.RUnlock()
+	if applicationID == "" {
+		return fmt.Errorf("application ID is unknown")
+	}
+
+	var appCommands []applicationCommand
+	for _, cmd := range b.handler.availableCommands() {
+		appCmd := applicationCommand{Name: cmd.name, Description: cmd.description, Type: 1}
+		if cmd.option != nil {
+			appCmd.Options = []applicationCommandOption{*cmd.option}
+		}
+		appCommands = append(appCommands, appCmd)
+	}
+	return b.rest.setCommands(ctx, applicationID, appCommands)
+}
+
+// HandleInteraction runs a slash command. Discord waits three seconds
+// for the response, so it is not queued behind messages.
+func (h *Handler) HandleInteraction(ctx context.Context, in *interaction) {
+	if in.Type != interactionTypeCommand {
+		return
+	}
+
+	h.bot.wg.Add(1)
+	go func() {
+		defer h.bot.wg.Done()
+		h.handleCommand(ctx, in)
+	}()
+}
+
+// handleCommand runs a slash command and responds with its result
+func (h *Handler) handleCommand(ctx context.Context, in *interaction) {
+	from := in.from()
+	reply, public := fmt.Sprintf("Unknown command /%s, see /help", in.Data.Name), false
+
+	switch {
+	case !isAllowed(h.bot.config.AllowedUsers, from.ID),
+		in.GuildID != "" && !isAllowed(h.bot.config.AllowedGuilds, in.GuildID):
+		if h.bot.config.Debug {
+			log.Printf("🚫 Command /%s from user %s (not in allowed list)", in.Data.Name, from.ID)
+		}
+		reply = "🚫 You are not allowed to use this bot"
+	default:
+		for _, cmd := range h.availableCommands() {
+			if cmd.name != in.Data.Name {
+				continue
+			}
+			if h.bot.config.Debug {
+				log.Printf("⌨️  Command /%s from %s in channel %s", cmd.name, from.String(), in.ChannelID)
+			}
+			var err error
+			if reply, err = cmd.run(h, ctx, in); err != nil {
+				reply = "⚠️ " + err.Error()
+			} else {
+				public = cmd.public
+			}
+			break
+		}
+	}
+
+	if err := h.bot.rest.respond(ctx, in, truncateString(reply, maxMessageLength-3), !public); err != nil {
+		log.Printf("❌ Error responding to /%s: %v", in.Data.Name, err)
+	}
+}
+
+// commandAsk shows the prompt and passes it to the agent like a message;
+// the reply is sent to the channel
+func (h *Handler) commandAsk(ctx context.Context, in *interaction) (string, error) {
+	prompt := strings.TrimSpace(in.option("prompt"))
+	if prompt == "" {
+		return "", fmt.Errorf("a prompt is required")
+	}
+	from := in.from()
+
+	metadata := map[string]interface{}{
+		"discord_interaction_id": in.ID,
+		"discord_channel_id":     in.ChannelID,
+	}
+	if in.GuildID != "" {
+		metadata["discord_guild_id"] = in.GuildID
+	}
+	groupID := ""
+	if in.GuildID != "" {
+		groupID = in.ChannelID
+	}
+	fromName := from.String()
+	if in.Member != nil && in.Member.Nick != "" {
+		fromName = in.Member.Nick
+	}
+
+	h.queue(&channels.Message{
+		ID:        in.ID,
+		Channel:   h.bot.Name(),
+		From:      from.ID,
+		FromName:  fromName,
+		To:        in.ChannelID,
+		IsGroup:   in.GuildID != "",
+		GroupID:   groupID,
+		Content:   prompt,
+		Type:      channels.MessageTypeText,
+		Timestamp: time.Now(),
+		Metadata:  metadata,
+	})
+	return fmt.Sprintf("💬 **%s:** %s", fromName, prompt), nil
+}
+
+// commandReset starts a new conversation for the channel
+func (h *Handler) commandReset(ctx context.Context, in *interaction) (string, error) {
+	if err := h.bot.config.Control.ResetSession(ctx, h.bot.Name(), in.ChannelID); err != nil {
+		return "", err
+	}
+	return "🧹 Started a new conversation", nil
+}
+
+// commandStatus shows the agent runtime and the session of the channel
+func (h *Handler) commandStatus(ctx context.Context, in *interaction) (string, error) {
+	control := h.bot.config.Control
+	agent, err := control.AgentState(ctx, h.bot.Name(), in.ChannelID)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Agent:** %s\n", agent.Status)
+	if agent.Model != "" {
+		fmt.Fprintf(&b, "**Model:** `%s` (%s)\n", agent.Model, agent.Provider)
+	}
+	fmt.Fprintf(&b, "**Sessions:** %d\n", agent.Sessions)
+
+	state, err := control.SessionInfo(ctx, h.bot.Name(), in.ChannelID)
+	if err != nil {
+		b.WriteString("**This channel:** no conversation yet")
+		return b.String(), nil
+	}
+	fmt.Fprintf(&b, "**This channel:** %s messages, last active <t:%d:R>",
+		state.Metadata["messages"], state.LastActive)
+	return b.String(), nil
+}
+
+// commandModel shows the model, or switches it for admin users
+func (h *Handler) commandModel(ctx context.Context, in *interaction) (string, error) {
+	model := strings.TrimSpace(in.option("name"))
+	if model != "" && !isAdmin(h.bot.config.AdminUsers, in.from().ID) {
+		return "", fmt.Errorf("only admins can switch the model")
+	}
+
+	current, err := h.bot.config.Control.Model(ctx, model)
+	if err != nil {
+		return "", err
+	}
+	if model != "" {
+		return fmt.Sprintf("🔁 Switched the model to `%s`", current), nil
+	}
+	return fmt.Sprintf("Model: `%s`", current), nil
+}
+
+// commandHelp lists the commands
+func (h *Handler) commandHelp(ctx context.Context, in *interaction) (string, error) {
+	var b strings.Builder
+	b.WriteString("🤖 **Discord Bot Help**\n\n")
+	b.WriteString("I'm an AI assistant connected to OpenClaw. Mention me or send me a direct message and I'll respond!\n\n")
+	b.WriteString("**Commands:**\n")
+	for _, cmd := range h.availableCommands() {
+		usage := "/" + cmd.name
+		if cmd.option != nil {
+			if cmd.option.Required {
+				usage += " " + cmd.option.Name
+			} else {
+				usage += " [" + cmd.option.Name + "]"
+			}
+		}
+		fmt.Fprintf(&b, "• %s - %s\n", usage, cmd.description)
+	}
+	return b.String(), nil
+}
+
+// isAdmin checks if a user may run admin commands
+func isAdmin(admins []string, userID string) bool {
+	for _, id := range admins {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
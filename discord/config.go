@@ -0,0 +1,197 @@
+package discord
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// Config holds the configuration for the Discord channel
+type Config struct {
+	// BotToken is the token of the bot user of the Discord application
+	BotToken string `json:"bot_token"`
+
+	// GatewayURL is the URL of the Discord gateway (default:
+	// wss://gateway.discord.gg), for a stand-in in tests
+	GatewayURL string `json:"gateway_url,omitempty"`
+
+	// APIURL is the base URL of the REST API (default:
+	// https://discord.com/api/v10), for a stand-in in tests
+	APIURL string `json:"api_url,omitempty"`
+
+	// Intents are the gateway intents identified with (default: guild
+	// messages and direct messages; the content of messages mentioning
+	// the bot is sent without the privileged message content intent)
+	Intents int `json:"intents,omitempty"`
+
+	// EditInterval is the minimum time between edits of a streamed reply,
+	// in milliseconds (default: 1000)
+	EditInterval int `json:"edit_interval_ms,omitempty"`
+
+	// AllowedUsers is a list of allowed user IDs (empty means all users are allowed)
+	AllowedUsers []string `json:"allowed_users,omitempty"`
+
+	// AllowedGuilds is a list of allowed server IDs (empty means all
+	// servers are allowed); direct messages are not restricted by it
+	AllowedGuilds []string `json:"allowed_guilds,omitempty"`
+
+	// AdminUsers is a list of user IDs allowed to switch the model with /model
+	AdminUsers []string `json:"admin_users,omitempty"`
+
+	// Debug enables debug logging
+	Debug bool `json:"debug,omitempty"`
+
+	// Control runs slash commands like /reset on the agent, nil to offer
+	// only /ask and /help
+	Control channels.AgentControl `json:"-"`
+}
+
+// Gateway intents, see https://discord.com/developers/docs/topics/gateway#gateway-intents
+const (
+	IntentGuilds         = 1 << 0
+	IntentGuildMessages  = 1 << 9
+	IntentDirectMessages = 1 << 12
+	IntentMessageContent = 1 << 15
+)
+
+const (
+	// defaultGatewayURL is the URL of the Discord gateway
+	defaultGatewayURL = "wss://gateway.discord.gg"
+
+	// defaultAPIURL is the base URL of the REST API
+	defaultAPIURL = "https://discord.com/api/v10"
+
+	// defaultIntents receive the messages the bot answers
+	defaultIntents = IntentGuilds | IntentGuildMessages | IntentDirectMessages
+
+	// defaultEditInterval is the default time between edits of a streamed
+	// reply, in milliseconds
+	defaultEditInterval = 1000
+)
+
+// LoadConfig loads configuration from environment variables or defaults
+func LoadConfig() (*Config, error) {
+	cfg := &Config{
+		BotToken:      os.Getenv("DISCORD_BOT_TOKEN"),
+		GatewayURL:    os.Getenv("DISCORD_GATEWAY_URL"),
+		APIURL:        os.Getenv("DISCORD_API_URL"),
+		Debug:         os.Getenv("DISCORD_DEBUG") == "true",
+		Intents:       defaultIntents,
+		EditInterval:  defaultEditInterval,
+		AllowedUsers:  make([]string, 0),
+		AllowedGuilds: make([]string, 0),
+	}
+
+	if cfg.BotToken == "" {
+		return nil, fmt.Errorf("DISCORD_BOT_TOKEN environment variable is required")
+	}
+
+	return cfg, nil
+}
+
+// LoadConfigFromMap loads configuration from a map
+func LoadConfigFromMap(configMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{
+		Intents:       defaultIntents,
+		EditInterval:  defaultEditInterval,
+		AllowedUsers:  make([]string, 0),
+		AllowedGuilds: make([]string, 0),
+	}
+
+	if botToken, ok := configMap["bot_token"].(string); ok {
+		cfg.BotToken = botToken
+	} else {
+		return nil, fmt.Errorf("bot_token is required in config")
+	}
+
+	if gatewayURL, ok := configMap["gateway_url"].(string); ok {
+		cfg.GatewayURL = gatewayURL
+	}
+
+	if apiURL, ok := configMap["api_url"].(string); ok {
+		cfg.APIURL = apiURL
+	}
+
+	if intents, ok := configMap["intents"].(float64); ok {
+		cfg.Intents = int(intents)
+	}
+
+	if editInterval, ok := configMap["edit_interval_ms"].(float64); ok {
+		cfg.EditInterval = int(editInterval)
+	}
+
+	cfg.AllowedUsers = append(cfg.AllowedUsers, stringList(configMap["allowed_users"])...)
+	cfg.AllowedGuilds = append(cfg.AllowedGuilds, stringList(configMap["allowed_guilds"])...)
+	cfg.AdminUsers = stringList(configMap["admin_users"])
+
+	if debug, ok := configMap["debug"].(bool); ok {
+		cfg.Debug = debug
+	}
+
+	return cfg, nil
+}
+
+// stringList returns the strings of a list of a config map
+func stringList(value interface{}) []string {
+	var list []string
+	if items, ok := value.([]interface{}); ok {
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	if c.BotToken == "" {
+		return fmt.Errorf("bot_token is required")
+	}
+
+	if c.GatewayURL != "" {
+		if u, err := url.Parse(c.GatewayURL); err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+			return fmt.Errorf("gateway_url must be a ws:// or wss:// URL, not %q", c.GatewayURL)
+		}
+	}
+
+	if c.APIURL != "" {
+		if u, err := url.Parse(c.APIURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("api_url must be an absolute URL, not %q", c.APIURL)
+		}
+	}
+
+	if c.Intents < 0 {
+		return fmt.Errorf("intents must not be negative")
+	}
+
+	return nil
+}
+
+// gatewayURL returns the URL of the gateway, without a trailing slash
+func (c *Config) gatewayURL() string {
+	if c.GatewayURL == "" {
+		return defaultGatewayURL
+	}
+	return strings.TrimRight(c.GatewayURL, "/")
+}
+
+// apiURL returns the base URL of the REST API, without a trailing slash
+func (c *Config) apiURL() string {
+	if c.APIURL == "" {
+		return defaultAPIURL
+	}
+	return strings.TrimRight(c.APIURL, "/")
+}
+
+// intents returns the gateway intents identified with
+func (c *Config) intents() int {
+	if c.Intents == 0 {
+		return defaultIntents
+	}
+	return c.Intents
+}
@@ -0,0 +1,113 @@
+package discord
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxMessageLength is the longest content Discord accepts in a message
+const maxMessageLength = 2000
+
+// splitMessage splits Markdown into messages of at most max characters,
+// between lines where possible. Discord renders Markdown as is; code
+// blocks split across messages are closed and opened again.
+func splitMessage(text string, max int) []string {
+	if runeLen(text) <= max {
+		return []string{text}
+	}
+
+	var parts []string
+	var lines []string
+	length := 0 // of lines joined
+	fence := "" // opening line of the code block the part ends in
+
+	// closing returns the length added to close the open code block
+	closing := func() int {
+		if fence != "" {
+			return len("\n```")
+		}
+		return 0
+	}
+	add := func(line string) {
+		if len(lines) > 0 {
+			length++
+		}
+		lines = append(lines, line)
+		length += runeLen(line)
+	}
+	flush := func() {
+		part := strings.Join(lines, "\n")
+		if fence != "" {
+			part += "\n```"
+		}
+		if strings.TrimSpace(part) != "" {
+			parts = append(parts, part)
+		}
+		lines, length = nil, 0
+		if fence != "" {
+			add(fence)
+		}
+	}
+	// empty reports whether the part holds nothing but a reopened fence
+	empty := func() bool {
+		return len(lines) == 0 || (fence != "" && len(lines) == 1)
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		for {
+			sep := 0
+			if len(lines) > 0 {
+				sep = 1
+			}
+			room := max - length - sep - closing()
+			if runeLen(line) <= room {
+				break
+			}
+			if !empty() {
+				flush()
+				continue
+			}
+			// A line too long for a message of its own is cut
+			head, rest := cutLine(line, room)
+			add(head)
+			flush()
+			line = rest
+		}
+		add(line)
+
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") {
+			if fence == "" {
+				fence = trimmed
+			} else {
+				fence = ""
+			}
+		}
+	}
+	flush()
+	return parts
+}
+
+// cutLine splits a line after at most n characters, after the last space
+// when there is one
+func cutLine(line string, n int) (head, rest string) {
+	if n < 1 {
+		n = 1
+	}
+	end := len(line)
+	for i := range line {
+		if n == 0 {
+			end = i
+			break
+		}
+		n--
+	}
+	if i := strings.LastIndexByte(line[:end], ' '); i > 0 {
+		end = i + 1
+	}
+	return line[:end], line[end:]
+}
+
+// runeLen returns the number of characters of s
+func runeLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,45 @@
+package discord
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitMessage(t *testing.T) {
+	code := "```go\n" + strings.Repeat("fmt.Println(\"ü\")\n", 150) + "```"
+	for _, tc := range []struct {
+		name  string
+		text  string
+		parts int
+	}{
+		{"short", "hello", 1},
+		{"exactly the limit", strings.Repeat("ä", maxMessageLength), 1},
+		{"multibyte over the limit", strings.Repeat("ä", maxMessageLength+1), 2},
+		{"long line of words", strings.Repeat("wörd ", maxMessageLength/2), 3},
+		{"lines", strings.Repeat(strings.Repeat("x", 99)+"\n", 30), 2},
+		{"code block", code, 2},
+	} {
+		parts := splitMessage(tc.text, maxMessageLength)
+		if len(parts) != tc.parts {
+			t.Errorf("%s: got %d parts, want %d", tc.name, len(parts), tc.parts)
+		}
+		for i, part := range parts {
+			if !utf8.ValidString(part) {
+				t.Errorf("%s: part %d cut in a character", tc.name, i)
+			}
+			if n := utf8.RuneCountInString(part); n > maxMessageLength {
+				t.Errorf("%s: part %d has %d characters, over %d", tc.name, i, n, maxMessageLength)
+			}
+			if strings.Count(part, "```")%2 != 0 {
+				t.Errorf("%s: part %d leaves a code block open", tc.name, i)
+			}
+		}
+	}
+
+	// Code blocks are opened again with their language
+	parts := splitMessage(code, maxMessageLength)
+	if !strings.HasPrefix(parts[1], "```go\n") {
+		t.Errorf("second part starts with %q, want the code block opened again", parts[1][:10])
+	}
+}
@@ -0,0 +1,371 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+const (
+	// gatewayVersion is the version of the gateway protocol spoken
+	gatewayVersion = "10"
+
+	// dialTimeout bounds connecting to the gateway and its hello
+	dialTimeout = 10 * time.Second
+
+	// writeTimeout bounds sending a payload
+	writeTimeout = 10 * time.Second
+
+	// minBackoff and maxBackoff bound the wait between reconnections
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+var (
+	// errReconnect is returned when Discord asks to reconnect and resume
+	errReconnect = errors.New("gateway asked to reconnect")
+
+	// errInvalidSession is returned when Discord rejects the session
+	errInvalidSession = errors.New("invalid session")
+)
+
+// gatewaySession is what is needed to resume a gateway session
+type gatewaySession struct {
+	mu        sync.Mutex
+	id        string
+	resumeURL string
+	seq       int64
+}
+
+// resumable returns the session to resume, empty to identify anew
+func (s *gatewaySession) resumable() (id, resumeURL string, seq int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id, s.resumeURL, s.seq
+}
+
+// sequence returns the sequence number of the last dispatch received
+func (s *gatewaySession) sequence() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq
+}
+
+// setSequence records the sequence number of a dispatch
+func (s *gatewaySession) setSequence(seq int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq = seq
+}
+
+// start records a new session
+func (s *gatewaySession) start(id, resumeURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.id, s.resumeURL = id, resumeURL
+}
+
+// reset forgets the session, so that the next connection identifies
+func (s *gatewaySession) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.id, s.resumeURL, s.seq = "", "", 0
+}
+
+// gatewayConn is a connection to the gateway, written by the reader and
+// the heartbeat
+type gatewayConn struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+// send writes a payload with op and data
+func (c *gatewayConn) send(op int, data interface{}) error {
+	d, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.conn.WriteJSON(&payload{Op: op, D: d})
+}
+
+// runGateway keeps a gateway connection up until ctx is done,
+// reconnecting with backoff and resuming the session when possible
+func (b *Bot) runGateway(ctx context.Context) {
+	backoff := minBackoff
+	for {
+		ready, err := b.connectGateway(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		code, fatal := fatalClose(err)
+		if fatal {
+			log.Printf("❌ Discord gateway closed the connection for good (%d): %v", code, err)
+			return
+		}
+		if code == 4007 || code == 4009 {
+			// invalid sequence or session timed out
+			b.session.reset()
+		}
+		if ready {
+			backoff = minBackoff
+		}
+		log.Printf("⚠️  Discord gateway connection lost: %v, reconnecting in %s", err, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// connectGateway runs one gateway connection: hello, identify or resume,
+// then dispatches until it is lost. ready reports whether the session
+// was established.
+func (b *Bot) connectGateway(ctx context.Context) (ready bool, err error) {
+	sessionID, resumeURL, seq := b.session.resumable()
+	url := b.config.gatewayURL()
+	if sessionID != "" && resumeURL != "" {
+		url = resumeURL
+	}
+
+	dialer := websocket.Dialer{HandshakeTimeout: dialTimeout}
+	ws, _, err := dialer.DialContext(ctx, url+"/?v="+gatewayVersion+"&encoding=json", nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to %s: %w", url, err)
+	}
+	conn := &gatewayConn{conn: ws}
+	defer ws.Close()
+
+	// Closing the connection ends the read below
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+		case <-done:
+		}
+	}()
+
+	ws.SetReadDeadline(time.Now().Add(dialTimeout))
+	var hello helloData
+	var p payload
+	if err := ws.ReadJSON(&p); err != nil {
+		return false, fmt.Errorf("failed to read hello: %w", err)
+	}
+	if p.Op != opHello || json.Unmarshal(p.D, &hello) != nil || hello.HeartbeatInterval <= 0 {
+		return false, fmt.Errorf("expected hello, got op %d", p.Op)
+	}
+	ws.SetReadDeadline(time.Time{})
+
+	heartbeat := newHeartbeat(conn, time.Duration(hello.HeartbeatInterval)*time.Millisecond, &b.session)
+	go heartbeat.run(done)
+
+	if sessionID != "" {
+		err = conn.send(opResume, map[string]interface{}{
+			"token":      b.config.BotToken,
+			"session_id": sessionID,
+			"seq":        seq,
+		})
+	} else {
+		err = conn.send(opIdentify, map[string]interface{}{
+			"token":   b.config.BotToken,
+			"intents": b.config.intents(),
+			"properties": map[string]string{
+				"os":      runtime.GOOS,
+				"browser": "openclaw",
+				"device":  "openclaw",
+			},
+		})
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to identify: %w", err)
+	}
+
+	for {
+		var p payload
+		if err := ws.ReadJSON(&p); err != nil {
+			if heartbeat.missed() {
+				return ready, fmt.Errorf("heartbeat not acknowledged")
+			}
+			return ready, err
+		}
+		if p.S != nil {
+			b.session.setSequence(*p.S)
+		}
+
+		switch p.Op {
+		case opDispatch:
+			if p.T == "READY" || p.T == "RESUMED" {
+				ready = true
+			}
+			b.dispatch(ctx, p.T, p.D)
+		case opHeartbeat:
+			heartbeat.beat()
+		case opHeartbeatACK:
+			heartbeat.ack()
+		case opReconnect:
+			return ready, errReconnect
+		case opInvalidSession:
+			var resumable bool
+			_ = json.Unmarshal(p.D, &resumable)
+			if !resumable {
+				b.session.reset()
+			}
+			// Discord asks to wait 1 to 5 seconds before identifying again
+			select {
+			case <-time.After(time.Second + time.Duration(rand.Int63n(int64(4*time.Second)))):
+			case <-ctx.Done():
+			}
+			return ready, errInvalidSession
+		}
+	}
+}
+
+// dispatch handles an event of the gateway
+func (b *Bot) dispatch(ctx context.Context, event string, data json.RawMessage) {
+	switch event {
+	case "READY":
+		var r readyData
+		if err := json.Unmarshal(data, &r); err != nil {
+			log.Printf("⚠️  Invalid Discord READY event: %v", err)
+			return
+		}
+		b.session.start(r.SessionID, r.ResumeGatewayURL)
+		b.mu.Lock()
+		b.self = &r.User
+		b.applicationID = r.Application.ID
+		b.mu.Unlock()
+		log.Printf("✅ Discord gateway session ready as %s (ID: %s)", r.User.Username, r.User.ID)
+
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			if err := b.registerCommands(ctx); err != nil {
+				log.Printf("⚠️  Failed to register slash commands: %v", err)
+			}
+		}()
+	case "RESUMED":
+		log.Printf("🔄 Discord gateway session resumed")
+	case "MESSAGE_CREATE":
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("⚠️  Invalid Discord message: %v", err)
+			return
+		}
+		b.handler.HandleMessage(&msg)
+	case "INTERACTION_CREATE":
+		var in interaction
+		if err := json.Unmarshal(data, &in); err != nil {
+			log.Printf("⚠️  Invalid Discord interaction: %v", err)
+			return
+		}
+		b.handler.HandleInteraction(ctx, &in)
+	default:
+		if b.config.Debug {
+			log.Printf("ℹ️  Received Discord event: %s", event)
+		}
+	}
+}
+
+// fatalClose returns the code the gateway closed the connection with,
+// and whether reconnecting cannot help after it, as for an invalid token
+func fatalClose(err error) (int, bool) {
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		return 0, false
+	}
+	switch closeErr.Code {
+	case 4004, 4010, 4011, 4012, 4013, 4014:
+		// authentication failed, invalid shard, sharding required,
+		// invalid API version, invalid or disallowed intents
+		return closeErr.Code, true
+	}
+	return closeErr.Code, false
+}
+
+// heartbeat keeps a gateway connection alive, closing it when Discord
+// stops acknowledging
+type heartbeat struct {
+	conn     *gatewayConn
+	interval time.Duration
+	session  *gatewaySession
+
+	mu     sync.Mutex
+	acked  bool
+	zombie bool
+}
+
+// newHeartbeat returns the heartbeat of a connection
+func newHeartbeat(conn *gatewayConn, interval time.Duration, session *gatewaySession) *heartbeat {
+	return &heartbeat{conn: conn, interval: interval, session: session, acked: true}
+}
+
+// run sends a heartbeat every interval until done, the first after a
+// random part of it as Discord asks
+func (h *heartbeat) run(done <-chan struct{}) {
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(h.interval))))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+			h.mu.Lock()
+			if !h.acked {
+				h.zombie = true
+				h.mu.Unlock()
+				h.conn.conn.Close()
+				return
+			}
+			h.acked = false
+			h.mu.Unlock()
+
+			h.beat()
+			timer.Reset(h.interval)
+		}
+	}
+}
+
+// beat sends a heartbeat with the last sequence number
+func (h *heartbeat) beat() {
+	var seq interface{}
+	if s := h.session.sequence(); s > 0 {
+		seq = s
+	}
+	if err := h.conn.send(opHeartbeat, seq); err != nil {
+		log.Printf("⚠️  Failed to send Discord heartbeat: %v", err)
+	}
+}
+
+// ack records that Discord acknowledged a heartbeat
+func (h *heartbeat) ack() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.acked = true
+}
+
+// missed reports whether the connection was closed for a heartbeat
+// never acknowledged
+func (h *heartbeat) missed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.zombie
+}
@@ -0,0 +1,211 @@
+package discord
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// Handler handles incoming Discord messages and slash commands
+type Handler struct {
+	bot      *Bot
+	incoming chan *channels.Message
+}
+
+// NewHandler creates a new message handler
+func NewHandler(bot *Bot) *Handler {
+	return &Handler{
+		bot:      bot,
+		incoming: make(chan *channels.Message, 100),
+	}
+}
+
+// Start starts the message handler
+func (h *Handler) Start(ctx context.Context) {
+	h.bot.wg.Add(1)
+	go func() {
+		defer h.bot.wg.Done()
+		h.processMessages(ctx)
+	}()
+}
+
+// HandleMessage queues a message of a channel the bot can read. Only
+// direct messages and messages mentioning the bot are answered; those
+// of bots, the bot itself included, are dropped.
+func (h *Handler) HandleMessage(msg *message) {
+	if msg.Author.Bot || msg.Author.ID == "" || msg.Author.ID == h.bot.botUserID() {
+		return
+	}
+	if msg.GuildID != "" && !h.mentionsBot(msg) {
+		return
+	}
+
+	// Check if user is allowed
+	if !isAllowed(h.bot.config.AllowedUsers, msg.Author.ID) {
+		if h.bot.config.Debug {
+			log.Printf("🚫 Message from user %s (not in allowed list)", msg.Author.ID)
+		}
+		return
+	}
+
+	// Check if server is allowed
+	if msg.GuildID != "" && !isAllowed(h.bot.config.AllowedGuilds, msg.GuildID) {
+		if h.bot.config.Debug {
+			log.Printf("🚫 Message from server %s (not in allowed list)", msg.GuildID)
+		}
+		return
+	}
+
+	channelMsg := h.toChannelMessage(msg)
+	if strings.TrimSpace(channelMsg.Content) == "" {
+		return
+	}
+	h.queue(channelMsg)
+}
+
+// queue passes a message to the worker answering messages in order
+func (h *Handler) queue(msg *channels.Message) {
+	select {
+	case h.incoming <- msg:
+	default:
+		log.Printf("⚠️  Incoming message queue full, dropping message from %s", msg.FromName)
+	}
+}
+
+// processMessages answers queued messages in order
+func (h *Handler) processMessages(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-h.incoming:
+			h.handleMessage(ctx, msg)
+		}
+	}
+}
+
+// handleMessage passes a message to the message handler
+func (h *Handler) handleMessage(ctx context.Context, msg *channels.Message) {
+	handler := h.bot.GetMessageHandler()
+	if handler == nil {
+		return
+	}
+
+	// Show the bot typing until the reply starts
+	if err := h.bot.rest.triggerTyping(ctx, msg.To); err != nil && h.bot.config.Debug {
+		log.Printf("⚠️  Failed to send typing indicator: %v", err)
+	}
+
+	if err := handler(ctx, msg); err != nil {
+		log.Printf("❌ Error in message handler: %v", err)
+		// Send error message to user
+		replyTo, _ := msg.Metadata["discord_message_id"].(string)
+		_, _ = h.bot.SendMessage(ctx, msg.To, "Sorry, I encountered an error processing your message.", replyTo)
+	}
+}
+
+// mentionsBot reports whether a message mentions the bot or replies to it
+func (h *Handler) mentionsBot(msg *message) bool {
+	botID := h.bot.botUserID()
+	for _, u := range msg.Mentions {
+		if u.ID == botID {
+			return true
+		}
+	}
+	if ref := msg.ReferencedMessage; ref != nil && ref.Author.ID == botID {
+		return true
+	}
+	return strings.Contains(msg.Content, "<@"+botID+">") || strings.Contains(msg.Content, "<@!"+botID+">")
+}
+
+// toChannelMessage converts a Discord message to a channel message. The
+// channel is the target, so that each channel and thread has its own
+// agent session.
+func (h *Handler) toChannelMessage(msg *message) *channels.Message {
+	replyTo := ""
+	if msg.MessageReference != nil {
+		replyTo = msg.MessageReference.MessageID
+	}
+
+	groupID := ""
+	if msg.GuildID != "" {
+		groupID = msg.ChannelID
+	}
+
+	fromName := msg.Author.String()
+	if msg.Member != nil && msg.Member.Nick != "" {
+		fromName = msg.Member.Nick
+	}
+
+	metadata := make(map[string]interface{})
+	metadata["discord_message_id"] = msg.ID
+	metadata["discord_channel_id"] = msg.ChannelID
+	if msg.GuildID != "" {
+		metadata["discord_guild_id"] = msg.GuildID
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, msg.Timestamp)
+	if err != nil {
+		timestamp = time.Now()
+	}
+
+	return &channels.Message{
+		ID:        msg.ID,
+		Channel:   h.bot.Name(),
+		From:      msg.Author.ID,
+		FromName:  fromName,
+		To:        msg.ChannelID,
+		IsGroup:   msg.GuildID != "",
+		GroupID:   groupID,
+		Content:   h.plainText(msg),
+		Type:      channels.MessageTypeText,
+		Timestamp: timestamp,
+		ReplyTo:   replyTo,
+		Metadata:  metadata,
+	}
+}
+
+// userMentionPattern matches mentions of users, as <@123> or <@!123>
+var userMentionPattern = regexp.MustCompile(`<@!?(\d+)>`)
+
+// removedMention matches a removed mention of the bot with the spaces
+// around it
+var removedMention = regexp.MustCompile(`[ \t]*\x00[ \t]*`)
+
+// plainText returns the content of a message with the mentions of the
+// bot removed and those of other users spelled out by name
+func (h *Handler) plainText(msg *message) string {
+	botID := h.bot.botUserID()
+	text := userMentionPattern.ReplaceAllStringFunc(msg.Content, func(mention string) string {
+		id := userMentionPattern.FindStringSubmatch(mention)[1]
+		if id == botID {
+			return "\x00"
+		}
+		for _, u := range msg.Mentions {
+			if u.ID == id {
+				return "@" + u.String()
+			}
+		}
+		return mention
+	})
+
+	// Removed mentions leave the words around them apart by one space
+	return strings.TrimSpace(removedMention.ReplaceAllString(text, " "))
+}
+
+// isAllowed reports whether id is in an allow list, empty to allow all
+func isAllowed(allowed []string, id string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == id {
+			return true
+		}
+	}
+	return false
+}
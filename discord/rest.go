@@ -0,0 +1,240 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// restTimeout bounds a single REST call
+	restTimeout = 30 * time.Second
+
+	// maxRateLimitWait is the longest wait for a rate limit before a call
+	// fails instead
+	maxRateLimitWait = 10 * time.Second
+
+	// maxRateLimitRetries is how many times a rate limited call is sent
+	maxRateLimitRetries = 3
+
+	// userAgent identifies the bot to Discord, in the required format
+	userAgent = "DiscordBot (https://github.com/openclaw/go-openclaw, 1.0)"
+)
+
+// restClient calls the Discord REST API with the bot token
+type restClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// newRESTClient returns a client of the REST API at baseURL
+func newRESTClient(baseURL, token string) *restClient {
+	return &restClient{
+		baseURL: baseURL,
+		token:   token,
+		http:    &http.Client{Timeout: restTimeout},
+	}
+}
+
+// apiError is an error returned by the REST API
+type apiError struct {
+	Status     int
+	Code       int     `json:"code"`
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after,omitempty"` // seconds, when rate limited
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("discord: status %d", e.Status)
+	}
+	return fmt.Sprintf("discord: %s (code %d)", e.Message, e.Code)
+}
+
+// do sends a request with body as JSON, if not nil, and decodes the
+// response into out, if not nil. Rate limited requests are retried after
+// the wait Discord asks for, when it is short.
+func (c *restClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("discord %s %s: %w", method, path, err)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.send(ctx, method, path, data, out)
+		var apiErr *apiError
+		if !errors.As(err, &apiErr) || apiErr.Status != http.StatusTooManyRequests {
+			return err
+		}
+		wait := time.Duration(apiErr.RetryAfter * float64(time.Second))
+		if wait <= 0 {
+			wait = time.Second
+		}
+		if wait > maxRateLimitWait || attempt == maxRateLimitRetries {
+			return err
+		}
+		log.Printf("⏳ Discord rate limit on %s %s, retrying in %s", method, path, wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// send sends a request once
+func (c *restClient) send(ctx context.Context, method, path string, data []byte, out interface{}) error {
+	var reader io.Reader
+	if data != nil {
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("discord %s %s: %w", method, path, err)
+	}
+	req.Header.Set("Authorization", "Bot "+c.token)
+	req.Header.Set("User-Agent", userAgent)
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("discord %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("discord %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := &apiError{Status: resp.StatusCode}
+		_ = json.Unmarshal(respBody, apiErr)
+		return apiErr
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("discord %s %s: invalid response: %w", method, path, err)
+		}
+	}
+	return nil
+}
+
+// currentUser returns the bot user of the token
+func (c *restClient) currentUser(ctx context.Context) (*user, error) {
+	var u user
+	if err := c.do(ctx, http.MethodGet, "/users/@me", nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// messageCreate is the body of a new message
+type messageCreate struct {
+	Content          string            `json:"content"`
+	MessageReference *messageReference `json:"message_reference,omitempty"`
+	AllowedMentions  *allowedMentions  `json:"allowed_mentions,omitempty"`
+}
+
+// allowedMentions selects the mentions of a message that notify
+type allowedMentions struct {
+	Parse       []string `json:"parse"`
+	RepliedUser bool     `json:"replied_user"`
+}
+
+// noMentions keeps replies from pinging users, roles or @everyone that
+// the model happens to write
+var noMentions = &allowedMentions{Parse: []string{}}
+
+// createMessage posts a message to a channel, as a reply when replyTo is
+// set, and returns its ID
+func (c *restClient) createMessage(ctx context.Context, channelID, content, replyTo string) (string, error) {
+	body := &messageCreate{Content: content, AllowedMentions: noMentions}
+	if replyTo != "" {
+		body.MessageReference = &messageReference{MessageID: replyTo}
+	}
+	var sent message
+	if err := c.do(ctx, http.MethodPost, "/channels/"+channelID+"/messages", body, &sent); err != nil {
+		return "", err
+	}
+	return sent.ID, nil
+}
+
+// editMessage replaces the content of a message of the bot
+func (c *restClient) editMessage(ctx context.Context, channelID, messageID, content string) error {
+	return c.do(ctx, http.MethodPatch, "/channels/"+channelID+"/messages/"+messageID,
+		&messageCreate{Content: content, AllowedMentions: noMentions}, nil)
+}
+
+// deleteMessage deletes a message of the bot
+func (c *restClient) deleteMessage(ctx context.Context, channelID, messageID string) error {
+	return c.do(ctx, http.MethodDelete, "/channels/"+channelID+"/messages/"+messageID, nil, nil)
+}
+
+// triggerTyping shows the bot typing in a channel for a few seconds
+func (c *restClient) triggerTyping(ctx context.Context, channelID string) error {
+	return c.do(ctx, http.MethodPost, "/channels/"+channelID+"/typing", nil, nil)
+}
+
+// applicationCommand is a slash command offered to users
+type applicationCommand struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Type        int                        `json:"type"` // 1 for slash commands
+	Options     []applicationCommandOption `json:"options,omitempty"`
+}
+
+// applicationCommandOption is an argument of a slash command
+type applicationCommandOption struct {
+	Type        int    `json:"type"` // 3 for strings
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// setCommands replaces the global slash commands of an application
+func (c *restClient) setCommands(ctx context.Context, applicationID string, commands []applicationCommand) error {
+	return c.do(ctx, http.MethodPut, "/applications/"+applicationID+"/commands", commands, nil)
+}
+
+// interactionResponse answers an interaction
+type interactionResponse struct {
+	Type int                      `json:"type"` // 4 to reply with a message
+	Data *interactionResponseData `json:"data,omitempty"`
+}
+
+// interactionResponseData is the message answering an interaction
+type interactionResponseData struct {
+	Content         string           `json:"content"`
+	Flags           int              `json:"flags,omitempty"` // 64 to show it to the user only
+	AllowedMentions *allowedMentions `json:"allowed_mentions,omitempty"`
+}
+
+const (
+	// responseChannelMessage replies to an interaction with a message
+	responseChannelMessage = 4
+
+	// flagEphemeral shows a reply to the user who ran the command only
+	flagEphemeral = 1 << 6
+)
+
+// respond replies to an interaction with a message
+func (c *restClient) respond(ctx context.Context, in *interaction, content string, ephemeral bool) error {
+	data := &interactionResponseData{Content: content, AllowedMentions: noMentions}
+	if ephemeral {
+		data.Flags = flagEphemeral
+	}
+	return c.do(ctx, http.MethodPost, "/interactions/"+in.ID+"/"+in.Token+"/callback",
+		&interactionResponse{Type: responseChannelMessage, Data: data}, nil)
+}
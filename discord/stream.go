@@ -0,0 +1,170 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+const (
+	// streamPlaceholder is shown until the first part of a reply arrives
+	streamPlaceholder = "…"
+
+	// streamCursor ends a reply while it is generated
+	streamCursor = " ▍"
+)
+
+// SendStream posts a placeholder message and edits it as the reply is
+// generated, at most once per EditInterval
+func (b *Bot) SendStream(ctx context.Context, target string, options map[string]interface{}) (channels.Stream, error) {
+	if target == "" {
+		return nil, fmt.Errorf("invalid target: channel ID is required")
+	}
+	replyTo, _ := options["reply_to"].(string)
+
+	messageID, err := b.rest.createMessage(ctx, target, streamPlaceholder, replyTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	interval := time.Duration(b.config.EditInterval) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultEditInterval * time.Millisecond
+	}
+
+	s := &stream{
+		bot:       b,
+		channelID: target,
+		messageID: messageID,
+		interval:  interval,
+		shown:     streamPlaceholder,
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go s.loop(ctx)
+	return s, nil
+}
+
+// stream is a reply edited in place while it is generated
+type stream struct {
+	bot       *Bot
+	channelID string
+	messageID string
+	interval  time.Duration
+
+	mu      sync.Mutex
+	text    strings.Builder
+	shown   string // content of the message as last edited
+	closed  bool
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// Append adds generated text, shown with the next edit
+func (s *stream) Append(delta string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("reply is complete")
+	}
+	s.text.WriteString(delta)
+	return nil
+}
+
+// Close shows the full reply, continued in new messages when it is too
+// long for one. Without text the placeholder is removed.
+func (s *stream) Close(text string) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.done)
+	<-s.stopped
+
+	ctx := context.Background()
+	if text == "" {
+		return s.bot.rest.deleteMessage(ctx, s.channelID, s.messageID)
+	}
+
+	parts := splitMessage(text, maxMessageLength)
+	if err := s.edit(ctx, parts[0]); err != nil {
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+	for _, part := range parts[1:] {
+		if _, err := s.bot.rest.createMessage(ctx, s.channelID, part, ""); err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+	return nil
+}
+
+// loop edits the message with the text generated so far, once per interval
+func (s *stream) loop(ctx context.Context) {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			text := s.text.String()
+			s.mu.Unlock()
+
+			if text == "" {
+				continue
+			}
+			if err := s.edit(ctx, preview(text)); err != nil {
+				log.Printf("⚠️  Failed to update streamed reply in channel %s: %v", s.channelID, err)
+			}
+		}
+	}
+}
+
+// edit replaces the content of the message, unless it is shown already.
+// Rate limits are waited out by the REST client.
+func (s *stream) edit(ctx context.Context, text string) error {
+	s.mu.Lock()
+	if text == s.shown {
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+
+	if err := s.bot.rest.editMessage(ctx, s.channelID, s.messageID, text); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.shown = text
+	s.mu.Unlock()
+	return nil
+}
+
+// preview returns the text of a reply being generated, its end when too
+// long for a message
+func preview(text string) string {
+	limit := maxMessageLength - utf8.RuneCountInString(streamCursor)
+	if n := utf8.RuneCountInString(text); n > limit {
+		text = string([]rune(text)[n-limit:])
+	}
+	return strings.TrimRight(text, " \n") + streamCursor
+}
+
+var _ channels.StreamSender = (*Bot)(nil)
@@ -0,0 +1,133 @@
+package discord
+
+import "encoding/json"
+
+// Gateway opcodes, see https://discord.com/developers/docs/topics/opcodes-and-status-codes
+const (
+	opDispatch       = 0
+	opHeartbeat      = 1
+	opIdentify       = 2
+	opResume         = 6
+	opReconnect      = 7
+	opInvalidSession = 9
+	opHello          = 10
+	opHeartbeatACK   = 11
+)
+
+// payload is a message of the gateway protocol
+type payload struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d"`
+	S  *int64          `json:"s,omitempty"`
+	T  string          `json:"t,omitempty"`
+}
+
+// helloData opens a gateway session
+type helloData struct {
+	HeartbeatInterval int64 `json:"heartbeat_interval"` // milliseconds
+}
+
+// readyData describes a new gateway session
+type readyData struct {
+	SessionID        string `json:"session_id"`
+	ResumeGatewayURL string `json:"resume_gateway_url"`
+	User             user   `json:"user"`
+	Application      struct {
+		ID string `json:"id"`
+	} `json:"application"`
+}
+
+// user is a Discord user
+type user struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name,omitempty"`
+	Bot        bool   `json:"bot,omitempty"`
+}
+
+// String returns the name the user goes by
+func (u *user) String() string {
+	if u.GlobalName != "" {
+		return u.GlobalName
+	}
+	if u.Username != "" {
+		return u.Username
+	}
+	return u.ID
+}
+
+// member is a user in a server
+type member struct {
+	User *user  `json:"user,omitempty"`
+	Nick string `json:"nick,omitempty"`
+}
+
+// message is a message of a channel
+type message struct {
+	ID                string            `json:"id"`
+	ChannelID         string            `json:"channel_id"`
+	GuildID           string            `json:"guild_id,omitempty"` // empty in direct messages
+	Author            user              `json:"author"`
+	Member            *member           `json:"member,omitempty"`
+	Content           string            `json:"content"`
+	Timestamp         string            `json:"timestamp"`
+	Mentions          []user            `json:"mentions,omitempty"`
+	MessageReference  *messageReference `json:"message_reference,omitempty"`
+	ReferencedMessage *message          `json:"referenced_message,omitempty"`
+}
+
+// messageReference points to the message a message replies to
+type messageReference struct {
+	MessageID string `json:"message_id,omitempty"`
+	ChannelID string `json:"channel_id,omitempty"`
+}
+
+// interaction is a slash command run by a user
+type interaction struct {
+	ID        string          `json:"id"`
+	Type      int             `json:"type"` // 2 for application commands
+	Token     string          `json:"token"`
+	GuildID   string          `json:"guild_id,omitempty"`
+	ChannelID string          `json:"channel_id"`
+	Member    *member         `json:"member,omitempty"` // in servers
+	User      *user           `json:"user,omitempty"`   // in direct messages
+	Data      interactionData `json:"data"`
+}
+
+// interactionTypeCommand is the type of slash command interactions
+const interactionTypeCommand = 2
+
+// interactionData is the command of an interaction
+type interactionData struct {
+	Name    string              `json:"name"`
+	Options []interactionOption `json:"options,omitempty"`
+}
+
+// interactionOption is an argument of a slash command
+type interactionOption struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// from returns the user who ran the command
+func (i *interaction) from() *user {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	if i.User != nil {
+		return i.User
+	}
+	return &user{}
+}
+
+// option returns the string value of an argument, empty if not given
+func (i *interaction) option(name string) string {
+	for _, option := range i.Data.Options {
+		if option.Name == name {
+			if s, ok := option.Value.(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
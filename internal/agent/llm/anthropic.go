@@ -75,7 +75,7 @@ func (c *AnthropicClient) SendMessages(ctx context.Context, req *MultiMessageReq
 // StreamMessage streams message responses
 // TODO: Implement streaming
 func (c *AnthropicClient) StreamMessage(ctx context.Context, req *Request, handler StreamHandler) error {
-	return fmt.Errorf("streaming not yet implemented")
+	return ErrStreamingUnsupported
 }
 
 // CallTool executes a tool call
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"errors"
 )
 
 // Provider represents an LLM provider
@@ -53,8 +54,24 @@ type Request struct {
 
 // Message represents a message in the conversation
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string     `json:"role"` // user, assistant or tool
+	Content    string     `json:"content"`
+	Images     []Image    `json:"images,omitempty"`       // image content blocks, before the text
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // tools called by an assistant message
+	ToolCallID string     `json:"tool_call_id,omitempty"` // call a tool message answers
+}
+
+// ToolCall is a call of a tool requested by the model
+type ToolCall struct {
+	ID     string                 `json:"id"`
+	Name   string                 `json:"name"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// Image is an image content block of a user message
+type Image struct {
+	MediaType string `json:"media_type"` // image/jpeg, image/png, image/gif or image/webp
+	Data      []byte `json:"data"`
 }
 
 // MultiMessageRequest represents a request with multiple messages
@@ -65,7 +82,8 @@ type MultiMessageRequest struct {
 
 // Response represents an LLM response
 type Response struct {
-	Text     string                 `json:"text"`
+	Text      string                 `json:"text"`
+	ToolCalls []ToolCall             `json:"tool_calls,omitempty"` // tools to call before the model answers
 	Usage    *Usage                  `json:"usage,omitempty"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -93,6 +111,10 @@ type ToolResponse struct {
 // StreamHandler handles streaming responses
 type StreamHandler func(chunk string, done bool) error
 
+// ErrStreamingUnsupported is returned by clients that cannot stream
+// responses; callers fall back to SendMessage
+var ErrStreamingUnsupported = errors.New("streaming not supported")
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Message string `json:"message"`
@@ -0,0 +1,155 @@
+// Package llmtest provides a scripted LLM client for tests
+package llmtest
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/openclaw/go-openclaw/internal/agent/llm"
+)
+
+// Provider is the provider name of the mock client
+const Provider llm.Provider = "mock"
+
+// Client is an LLM client that answers from a reply function and
+// records every request. By default it echoes the last user message.
+type Client struct {
+	// Reply returns the response to a request
+	Reply func(req *llm.Request) (string, error)
+
+	// Respond, when set, answers SendMessage instead of Reply, as to call
+	// tools
+	Respond func(req *llm.Request) (*llm.Response, error)
+
+	// NoStreaming makes StreamMessage fail with llm.ErrStreamingUnsupported
+	NoStreaming bool
+
+	model    string
+	requests []*llm.Request
+	mu       sync.Mutex
+}
+
+// New creates a mock client echoing the last user message
+func New() *Client {
+	return &Client{
+		Reply: Echo,
+		model: "mock-model",
+	}
+}
+
+// Echo replies "echo: " and the last user message
+func Echo(req *llm.Request) (string, error) {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			return "echo: " + req.Messages[i].Content, nil
+		}
+	}
+	return "echo:", nil
+}
+
+// Requests returns the requests received so far
+func (c *Client) Requests() []*llm.Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*llm.Request(nil), c.requests...)
+}
+
+// LastRequest returns the last request received, nil if none
+func (c *Client) LastRequest() *llm.Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.requests) == 0 {
+		return nil
+	}
+	return c.requests[len(c.requests)-1]
+}
+
+// Provider returns the mock provider name
+func (c *Client) Provider() llm.Provider {
+	return Provider
+}
+
+// Model returns the model name
+func (c *Client) Model() string {
+	return c.model
+}
+
+// SendMessage answers a request
+func (c *Client) SendMessage(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	c.mu.Lock()
+	respond := c.Respond
+	c.mu.Unlock()
+	if respond != nil {
+		if err := c.record(ctx, req); err != nil {
+			return nil, err
+		}
+		return respond(req)
+	}
+
+	text, err := c.reply(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &llm.Response{Text: text}, nil
+}
+
+// SendMessages answers a request without options
+func (c *Client) SendMessages(ctx context.Context, req *llm.MultiMessageRequest) (*llm.Response, error) {
+	return c.SendMessage(ctx, &llm.Request{Messages: req.Messages})
+}
+
+// StreamMessage answers a request word by word
+func (c *Client) StreamMessage(ctx context.Context, req *llm.Request, handler llm.StreamHandler) error {
+	if c.NoStreaming {
+		return llm.ErrStreamingUnsupported
+	}
+	text, err := c.reply(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for _, chunk := range strings.SplitAfter(text, " ") {
+		if err := handler(chunk, false); err != nil {
+			return err
+		}
+	}
+	return handler("", true)
+}
+
+// CallTool returns the call as its response
+func (c *Client) CallTool(ctx context.Context, tool string, params map[string]interface{}) (*llm.ToolResponse, error) {
+	return &llm.ToolResponse{Name: tool, Params: params}, nil
+}
+
+// Close does nothing
+func (c *Client) Close(ctx context.Context) error {
+	return nil
+}
+
+// reply records a request and computes its response
+func (c *Client) reply(ctx context.Context, req *llm.Request) (string, error) {
+	if err := c.record(ctx, req); err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	reply := c.Reply
+	c.mu.Unlock()
+
+	return reply(req)
+}
+
+// record records a request unless ctx is done
+func (c *Client) record(ctx context.Context, req *llm.Request) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	recorded := *req
+	recorded.Messages = append([]llm.Message(nil), req.Messages...)
+	c.mu.Lock()
+	c.requests = append(c.requests, &recorded)
+	c.mu.Unlock()
+	return nil
+}
@@ -2,24 +2,65 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/openclaw/go-openclaw/internal/agent/llm"
 	"github.com/openclaw/go-openclaw/internal/agent/session"
+	"github.com/openclaw/go-openclaw/internal/agent/tools"
 )
 
 // Runtime represents Agent runtime
 type Runtime struct {
 	llm        llm.Client
 	sessionMgr *session.Manager
+	tools      *tools.Registry
+	executor   *tools.Executor
+	config     *Config
 	running    bool
 	mu         sync.RWMutex
 	ctx        context.Context
 	cancel     context.CancelFunc
 	wg         sync.WaitGroup
+	inflight   sync.WaitGroup // in-flight agent turns
+	draining   bool           // refuses new turns, guarded by drainMu
+	drainMu    sync.Mutex
+	usage      map[string]*dailyUsage // workspace -> messages today
+	usageMu    sync.Mutex
+}
+
+// DefaultWorkspace is the workspace of turns processed without a scope
+const DefaultWorkspace = "default"
+
+// maxToolRounds bounds the rounds of tool calls in one turn
+const maxToolRounds = 8
+
+// ErrQuotaExceeded is returned when a turn would exceed a workspace quota
+var ErrQuotaExceeded = errors.New("workspace quota exceeded")
+
+// ErrDraining is returned for turns started once the runtime drains
+var ErrDraining = errors.New("agent runtime is draining")
+
+// Scope confines agent turns to a workspace: its sessions are kept apart
+// from other workspaces' and it may narrow the prompt and tools
+type Scope struct {
+	Workspace         string
+	SystemPrompt      string   // overrides the configured prompt when set
+	Tools             []string // tools offered to the model, empty for all unrestricted
+	MaxSessions       int      // 0 for unlimited
+	MaxMessagesPerDay int      // 0 for unlimited
+}
+
+// dailyUsage counts the messages of a workspace on one day
+type dailyUsage struct {
+	day   string
+	count int
 }
 
 // Config represents Agent runtime configuration
@@ -33,6 +74,10 @@ type Config struct {
 	SystemPrompt string        `mapstructure:"system_prompt"`
 	ToolsEnabled bool          `mapstructure:"tools_enabled"`
 	APIKey       string        `mapstructure:"api_key"`
+
+	// LLMClient is used instead of a client of LLMProvider when set, as by
+	// tests and programs embedding the runtime
+	LLMClient llm.Client `mapstructure:"-" json:"-"`
 }
 
 // DefaultConfig returns default Agent configuration
@@ -56,8 +101,12 @@ func NewRuntime(config *Config) (*Runtime, error) {
 	var llmClient llm.Client
 	var err error
 
-	switch config.LLMProvider {
-	case "anthropic":
+	switch {
+	case config.LLMClient != nil:
+		llmClient = config.LLMClient
+		config.LLMProvider = llmClient.Provider().String()
+		config.LLMModel = llmClient.Model()
+	case config.LLMProvider == "anthropic":
 		llmClient, err = llm.NewAnthropicClient(config.APIKey, config.LLMModel, config.Timeout)
 	default:
 		return nil, fmt.Errorf("unsupported LLM provider: %s", config.LLMProvider)
@@ -72,9 +121,15 @@ func NewRuntime(config *Config) (*Runtime, error) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	registry := tools.NewRegistry()
+
 	return &Runtime{
 		llm:        llmClient,
 		sessionMgr: sessionMgr,
+		tools:      registry,
+		executor:   tools.NewExecutor(llmClient, registry),
+		config:     config,
+		usage:      make(map[string]*dailyUsage),
 		running:    false,
 		ctx:        ctx,
 		cancel:     cancel,
@@ -112,48 +167,364 @@ func (r *Runtime) Stop(ctx context.Context) error {
 
 	r.wg.Wait()
 
+	// Wait for tool calls of turns still running
+	if err := r.executor.Stop(ctx); err != nil {
+		log.Printf("Failed to stop tool executor: %v", err)
+	}
+
 	log.Printf("✅ Agent runtime stopped")
 
 	return nil
 }
 
-// ProcessMessage processes a message and returns LLM response
+// Tools returns the registry of tools offered to the model
+func (r *Runtime) Tools() *tools.Registry {
+	return r.tools
+}
+
+// Drain refuses new turns and waits for in-flight agent turns to finish
+// or ctx to expire
+func (r *Runtime) Drain(ctx context.Context) error {
+	r.drainMu.Lock()
+	r.draining = true
+	r.drainMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("agent turns still in flight: %w", ctx.Err())
+	}
+}
+
+// Input is a user message with the images sent along with it, as from a
+// messaging channel
+type Input struct {
+	Text   string
+	Images []llm.Image
+}
+
+// ProcessMessage processes a message in the default workspace and returns
+// LLM response
 func (r *Runtime) ProcessMessage(ctx context.Context, channelID string, msg string) (string, error) {
+	return r.ProcessMessageIn(ctx, nil, channelID, msg)
+}
+
+// ProcessMessageIn processes a message in the session of channelID within
+// a workspace and returns LLM response
+func (r *Runtime) ProcessMessageIn(ctx context.Context, scope *Scope, channelID string, msg string) (string, error) {
+	return r.turn(ctx, scope, channelID, &Input{Text: msg}, nil)
+}
+
+// StreamMessageIn processes a message like ProcessMessageIn, passing the
+// response to onDelta as it is generated. With a client that cannot
+// stream, or when tools are offered, the whole response is one delta.
+func (r *Runtime) StreamMessageIn(ctx context.Context, scope *Scope, channelID string, msg string, onDelta func(delta string)) (string, error) {
+	return r.StreamInputIn(ctx, scope, channelID, &Input{Text: msg}, onDelta)
+}
+
+// ProcessInputIn processes a message with images like ProcessMessageIn
+func (r *Runtime) ProcessInputIn(ctx context.Context, scope *Scope, channelID string, input *Input) (string, error) {
+	return r.turn(ctx, scope, channelID, input, nil)
+}
+
+// StreamInputIn processes a message with images like StreamMessageIn
+func (r *Runtime) StreamInputIn(ctx context.Context, scope *Scope, channelID string, input *Input, onDelta func(delta string)) (string, error) {
+	if onDelta == nil {
+		onDelta = func(string) {}
+	}
+	return r.turn(ctx, scope, channelID, input, onDelta)
+}
+
+// turn runs one agent turn, streaming when onDelta is set
+func (r *Runtime) turn(ctx context.Context, scope *Scope, channelID string, input *Input, onDelta func(delta string)) (string, error) {
+	// Drain waits for the turns counted before it set draining
+	r.drainMu.Lock()
+	if r.draining {
+		r.drainMu.Unlock()
+		return "", ErrDraining
+	}
+	r.inflight.Add(1)
+	r.drainMu.Unlock()
+	defer r.inflight.Done()
+
+	if scope == nil {
+		scope = &Scope{Workspace: DefaultWorkspace}
+	}
+
 	// Get or create session for this channel
-	sess, err := r.sessionMgr.GetOrCreate(channelID)
+	sess, err := r.session(scope, channelID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get session: %w", err)
+		return "", err
+	}
+
+	if err := r.countMessage(scope); err != nil {
+		return "", err
 	}
 
 	// Build message history for context
 	history := r.buildMessageHistory(sess)
 
 	// Build user prompt
-	systemPrompt := r.buildSystemPrompt()
+	systemPrompt := r.buildSystemPrompt(scope)
 
 	// Prepare request
-	llmReq := r.buildLLMRequest(msg, history, systemPrompt)
+	llmReq := r.buildLLMRequest(input, history, systemPrompt, scope)
+
+	// Call LLM, running the tools it calls before it answers
+	var text string
+	switch {
+	case len(llmReq.Tools) > 0:
+		text, err = r.runTools(ctx, &llmReq, scope)
+		if err == nil && onDelta != nil {
+			onDelta(text)
+		}
+	case onDelta != nil:
+		text, err = r.stream(ctx, &llmReq, onDelta)
+	default:
+		text, err = r.send(ctx, &llmReq)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Update session history with both sides of the turn. Images are sent
+	// once; later turns only see that they were there.
+	content := input.Text
+	if n := len(input.Images); n > 0 {
+		content = strings.TrimSpace(fmt.Sprintf("[%d image(s) attached]\n%s", n, input.Text))
+	}
+	sess.Messages = append(sess.Messages, &session.Message{
+		Role:      "user",
+		Content:   content,
+		Timestamp: time.Now(),
+	}, &session.Message{
+		Role:      "assistant",
+		Content:   text,
+		Timestamp: time.Now(),
+	})
+
+	return text, nil
+}
 
-	// Call LLM
-	llmResp, err := r.llm.SendMessage(ctx, &llmReq)
+// send calls the LLM and returns the whole response
+func (r *Runtime) send(ctx context.Context, req *llm.Request) (string, error) {
+	llmResp, err := r.client().SendMessage(ctx, req)
 	if err != nil {
 		return "", fmt.Errorf("LLM call failed: %w", err)
 	}
 
-	// Extract and stream response
+	// Extract response
 	response, err := r.extractResponse(llmResp)
 	if err != nil {
 		return "", fmt.Errorf("response extraction failed: %w", err)
 	}
+	return response.Text, nil
+}
 
-	// Update session history
-	sess.Messages = append(sess.Messages, &session.Message{
-		Role:      "assistant",
-		Content:   response.Text,
-		Timestamp: time.Now(),
+// runTools calls the LLM until it answers without calling tools, running
+// the tools it calls in between and passing their results back
+func (r *Runtime) runTools(ctx context.Context, req *llm.Request, scope *Scope) (string, error) {
+	ctx = tools.WithWorkspace(ctx, scope.Workspace)
+
+	for round := 0; ; round++ {
+		llmResp, err := r.client().SendMessage(ctx, req)
+		if err != nil {
+			return "", fmt.Errorf("LLM call failed: %w", err)
+		}
+		response, err := r.extractResponse(llmResp)
+		if err != nil {
+			return "", fmt.Errorf("response extraction failed: %w", err)
+		}
+		if len(response.ToolCalls) == 0 {
+			return response.Text, nil
+		}
+		if round == maxToolRounds {
+			return "", fmt.Errorf("tool calls did not finish within %d rounds", maxToolRounds)
+		}
+
+		req.Messages = append(req.Messages, llm.Message{
+			Role:      "assistant",
+			Content:   response.Text,
+			ToolCalls: response.ToolCalls,
+		})
+		for _, call := range response.ToolCalls {
+			req.Messages = append(req.Messages, llm.Message{
+				Role:       "tool",
+				Content:    r.callTool(ctx, scope, call),
+				ToolCallID: call.ID,
+			})
+		}
+	}
+}
+
+// callTool runs a tool call and returns its result as JSON. Tools the
+// scope does not offer are refused whatever the model asks for.
+func (r *Runtime) callTool(ctx context.Context, scope *Scope, call llm.ToolCall) string {
+	result := &tools.ToolResult{Name: call.Name, Error: fmt.Sprintf("tool not available: %s", call.Name)}
+	if tool, ok := r.tools.Get(call.Name); ok && scope.allowsTool(tool) {
+		executed, err := r.executor.Execute(ctx, call.Name, call.Params)
+		if err != nil {
+			executed = &tools.ToolResult{Name: call.Name, Error: err.Error()}
+		}
+		result = executed
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf(`{"name":%q,"success":false,"error":"failed to encode result"}`, call.Name)
+	}
+	return string(data)
+}
+
+// stream calls the LLM in streaming mode, falling back to a single delta
+// for clients that cannot stream
+func (r *Runtime) stream(ctx context.Context, req *llm.Request, onDelta func(delta string)) (string, error) {
+	streamReq := *req
+	streamReq.Stream = true
+
+	var text strings.Builder
+	err := r.client().StreamMessage(ctx, &streamReq, func(chunk string, done bool) error {
+		if chunk != "" {
+			text.WriteString(chunk)
+			onDelta(chunk)
+		}
+		return nil
 	})
+	if errors.Is(err, llm.ErrStreamingUnsupported) {
+		full, err := r.send(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		onDelta(full)
+		return full, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("LLM call failed: %w", err)
+	}
+	return text.String(), nil
+}
 
-	return response.Text, nil
+// client returns the LLM client
+func (r *Runtime) client() llm.Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.llm
+}
+
+// SetModel switches the model of later turns, keeping the provider
+func (r *Runtime) SetModel(model string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var client llm.Client
+	var err error
+	switch r.config.LLMProvider {
+	case "anthropic":
+		client, err = llm.NewAnthropicClient(r.config.APIKey, model, r.config.Timeout)
+	default:
+		err = fmt.Errorf("unsupported LLM provider: %s", r.config.LLMProvider)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.llm = client
+	r.config.LLMModel = model
+	return nil
+}
+
+// Session returns the session of a channel in a workspace
+func (r *Runtime) Session(workspace, channelID string) (*session.Session, bool) {
+	return r.sessionMgr.Get(workspace + "/" + channelID)
+}
+
+// ResetSession forgets the session of a channel in a workspace, so the
+// next turn starts a new conversation. It reports whether there was one.
+func (r *Runtime) ResetSession(workspace, channelID string) bool {
+	key := workspace + "/" + channelID
+	if _, ok := r.sessionMgr.Get(key); !ok {
+		return false
+	}
+	r.sessionMgr.Delete(key)
+	return true
+}
+
+// ScopeTools returns the tools offered to the model within a scope
+func (r *Runtime) ScopeTools(scope *Scope) []*tools.Tool {
+	offered := make([]*tools.Tool, 0)
+	if !r.config.ToolsEnabled {
+		return offered
+	}
+	if scope == nil {
+		scope = &Scope{}
+	}
+	for _, tool := range r.tools.GetAll() {
+		if scope.allowsTool(tool) {
+			offered = append(offered, tool)
+		}
+	}
+	sort.Slice(offered, func(i, j int) bool { return offered[i].Name < offered[j].Name })
+	return offered
+}
+
+// Sessions returns the sessions of a workspace
+func (r *Runtime) Sessions(workspace string) []*session.Session {
+	sessions := make([]*session.Session, 0)
+	for _, sess := range r.sessionMgr.GetAll() {
+		if sess.Metadata["workspace"] == workspace {
+			sessions = append(sessions, sess)
+		}
+	}
+	return sessions
+}
+
+// session returns the session of a channel in a workspace, creating it
+// within the workspace session quota
+func (r *Runtime) session(scope *Scope, channelID string) (*session.Session, error) {
+	// Workspaces may use the same channel IDs without sharing history
+	key := scope.Workspace + "/" + channelID
+	if sess, ok := r.sessionMgr.Get(key); ok {
+		sess.LastActive = time.Now()
+		return sess, nil
+	}
+
+	if scope.MaxSessions > 0 && len(r.Sessions(scope.Workspace)) >= scope.MaxSessions {
+		return nil, fmt.Errorf("%w: max %d sessions", ErrQuotaExceeded, scope.MaxSessions)
+	}
+
+	sess, err := r.sessionMgr.GetOrCreate(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	sess.Metadata["workspace"] = scope.Workspace
+	sess.Metadata["channel_id"] = channelID
+	return sess, nil
+}
+
+// countMessage counts a message against the workspace daily quota
+func (r *Runtime) countMessage(scope *Scope) error {
+	r.usageMu.Lock()
+	defer r.usageMu.Unlock()
+
+	today := time.Now().Format("2006-01-02")
+	usage, ok := r.usage[scope.Workspace]
+	if !ok || usage.day != today {
+		usage = &dailyUsage{day: today}
+		r.usage[scope.Workspace] = usage
+	}
+
+	if scope.MaxMessagesPerDay > 0 && usage.count >= scope.MaxMessagesPerDay {
+		return fmt.Errorf("%w: max %d messages per day", ErrQuotaExceeded, scope.MaxMessagesPerDay)
+	}
+	usage.count++
+	return nil
 }
 
 // buildMessageHistory builds message history for LLM context
@@ -166,47 +537,76 @@ func (r *Runtime) buildMessageHistory(session *session.Session) []llm.Message {
 
 	// Convert to LLM message format
 	history := make([]llm.Message, 0, len(recent))
-	for i, msg := range recent {
+	for _, msg := range recent {
 		// LLM format: Role (user/assistant), Content
 		role := "user"
 		if msg.Role == "assistant" {
 			role = "assistant"
 		}
-		history[i] = llm.Message{
+		history = append(history, llm.Message{
 			Role:    role,
 			Content: msg.Content,
-		}
+		})
 	}
 
 	return history
 }
 
 // buildSystemPrompt builds system prompt with context
-func (r *Runtime) buildSystemPrompt() string {
-	// Build context from sessions
-	// TODO: Include session metadata, workspace info, etc.
-
-	// For now, use a simple system prompt
-	prompt := "You are OpenClaw, an AI agent platform built to help developers build intelligent applications.\n\n"
-	prompt += "Your role is to assist users with their development tasks, answer questions, and provide helpful suggestions.\n\n"
-	prompt += "Be concise, practical, and focus on technical accuracy."
-
-	return prompt
+func (r *Runtime) buildSystemPrompt(scope *Scope) string {
+	// Workspaces may bring their own prompt
+	if scope.SystemPrompt != "" {
+		return scope.SystemPrompt
+	}
+	if r.config.SystemPrompt != "" {
+		return r.config.SystemPrompt
+	}
+	return DefaultConfig().SystemPrompt
 }
 
 // buildLLMRequest builds LLM API request
-func (r *Runtime) buildLLMRequest(msg string, history []llm.Message, systemPrompt string) llm.Request {
+func (r *Runtime) buildLLMRequest(input *Input, history []llm.Message, systemPrompt string, scope *Scope) llm.Request {
+	var toolDefs []llm.Tool
+	if r.config.ToolsEnabled {
+		for _, tool := range r.tools.GetAll() {
+			if !scope.allowsTool(tool) {
+				continue
+			}
+			toolDefs = append(toolDefs, llm.Tool{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.InputSchema,
+			})
+		}
+	}
+
 	return llm.Request{
 		SystemPrompt: systemPrompt,
 		Messages: append(history, llm.Message{
 			Role:    "user",
-			Content: msg,
+			Content: input.Text,
+			Images:  input.Images,
 		}),
 		MaxTokens:   200000,
 		Temperature: 0.7,
 		TopP:        5,
 		Stream:      false,
+		Tools:       toolDefs,
+	}
+}
+
+// allowsTool reports whether a tool is offered within the scope.
+// Restricted tools are only offered to scopes naming them.
+func (s *Scope) allowsTool(tool *tools.Tool) bool {
+	if len(s.Tools) == 0 {
+		return !tool.Restricted
+	}
+	for _, t := range s.Tools {
+		if t == tool.Name {
+			return true
+		}
 	}
+	return false
 }
 
 // extractResponse extracts LLM response
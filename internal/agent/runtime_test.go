@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openclaw/go-openclaw/internal/agent/llm"
+	"github.com/openclaw/go-openclaw/internal/agent/llm/llmtest"
+	"github.com/openclaw/go-openclaw/internal/agent/tools"
+)
+
+// newToolRuntime returns a runtime with tools enabled whose model calls
+// each tool of calls once, in order, then answers with the tool results
+func newToolRuntime(t *testing.T, calls ...string) (*Runtime, *llmtest.Client) {
+	t.Helper()
+
+	mock := llmtest.New()
+	mock.Respond = func(req *llm.Request) (*llm.Response, error) {
+		var results []string
+		for _, m := range req.Messages {
+			if m.Role == "tool" {
+				results = append(results, m.Content)
+			}
+		}
+		if len(results) < len(calls) {
+			name := calls[len(results)]
+			return &llm.Response{ToolCalls: []llm.ToolCall{{ID: "call-" + name, Name: name, Params: map[string]interface{}{"n": 1}}}}, nil
+		}
+		return &llm.Response{Text: strings.Join(results, "\n")}, nil
+	}
+
+	config := DefaultConfig()
+	config.LLMClient = mock
+	config.ToolsEnabled = true
+	r, err := NewRuntime(config)
+	if err != nil {
+		t.Fatalf("NewRuntime: %v", err)
+	}
+	return r, mock
+}
+
+// registerTool registers a tool recording the workspace it is called in
+func registerTool(t *testing.T, r *Runtime, name string, restricted bool, workspaces *[]string) {
+	t.Helper()
+	err := r.Tools().Register(&tools.Tool{
+		Name:       name,
+		Restricted: restricted,
+		Handler: func(ctx context.Context, params map[string]interface{}) (*tools.ToolResult, error) {
+			*workspaces = append(*workspaces, tools.Workspace(ctx))
+			return &tools.ToolResult{Name: name, Success: true, Data: params}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+}
+
+func TestToolCalls(t *testing.T) {
+	r, mock := newToolRuntime(t, "open", "shell")
+
+	var called []string
+	registerTool(t, r, "open", false, &called)
+	registerTool(t, r, "shell", true, &called)
+
+	text, err := r.ProcessMessageIn(context.Background(), &Scope{Workspace: "team"}, "c1", "hi")
+	if err != nil {
+		t.Fatalf("ProcessMessageIn: %v", err)
+	}
+
+	// The restricted tool is neither offered nor run
+	if got := mock.Requests()[0].Tools; len(got) != 1 || got[0].Name != "open" {
+		t.Errorf("offered tools = %+v, want only open", got)
+	}
+	if len(called) != 1 || called[0] != "team" {
+		t.Errorf("tools ran in workspaces %v, want [team]", called)
+	}
+
+	results := strings.Split(text, "\n")
+	if len(results) != 2 {
+		t.Fatalf("answer = %q, want two tool results", text)
+	}
+	var open, shell tools.ToolResult
+	if err := json.Unmarshal([]byte(results[0]), &open); err != nil || !open.Success {
+		t.Errorf("open result = %s, want success", results[0])
+	}
+	if err := json.Unmarshal([]byte(results[1]), &shell); err != nil || shell.Success || shell.Error != "tool not available: shell" {
+		t.Errorf("shell result = %s, want tool not available", results[1])
+	}
+
+	// Only the question and the answer are kept
+	sess, _ := r.Session("team", "c1")
+	if n := len(sess.Messages); n != 2 {
+		t.Errorf("session has %d messages, want 2", n)
+	}
+}
+
+func TestRestrictedToolNamedByScope(t *testing.T) {
+	r, _ := newToolRuntime(t, "shell")
+
+	var called []string
+	registerTool(t, r, "shell", true, &called)
+
+	scope := &Scope{Workspace: "ops", Tools: []string{"shell"}}
+	if got := r.ScopeTools(scope); len(got) != 1 {
+		t.Errorf("ScopeTools = %d tools, want 1", len(got))
+	}
+	if got := r.ScopeTools(nil); len(got) != 0 {
+		t.Errorf("ScopeTools(nil) = %d tools, want 0", len(got))
+	}
+
+	var deltas []string
+	if _, err := r.StreamMessageIn(context.Background(), scope, "c1", "hi", func(delta string) {
+		deltas = append(deltas, delta)
+	}); err != nil {
+		t.Fatalf("StreamMessageIn: %v", err)
+	}
+	if len(called) != 1 || called[0] != "ops" {
+		t.Errorf("tools ran in workspaces %v, want [ops]", called)
+	}
+	if len(deltas) != 1 {
+		t.Errorf("got %d deltas, want the answer as one", len(deltas))
+	}
+}
+
+func TestToolRoundsBounded(t *testing.T) {
+	mock := llmtest.New()
+	mock.Respond = func(req *llm.Request) (*llm.Response, error) {
+		return &llm.Response{ToolCalls: []llm.ToolCall{{ID: "loop", Name: "loop"}}}, nil
+	}
+	config := DefaultConfig()
+	config.LLMClient = mock
+	config.ToolsEnabled = true
+	r, err := NewRuntime(config)
+	if err != nil {
+		t.Fatalf("NewRuntime: %v", err)
+	}
+	var called []string
+	registerTool(t, r, "loop", false, &called)
+
+	if _, err := r.ProcessMessage(context.Background(), "c1", "hi"); err == nil {
+		t.Fatal("ProcessMessage succeeded, want an error after too many rounds")
+	}
+	if len(called) != maxToolRounds {
+		t.Errorf("tool ran %d times, want %d", len(called), maxToolRounds)
+	}
+}
@@ -0,0 +1,17 @@
+package tools
+
+import "context"
+
+// workspaceKey is the context key of the workspace a tool is called in
+type workspaceKey struct{}
+
+// WithWorkspace returns a context for calling tools within a workspace
+func WithWorkspace(ctx context.Context, workspace string) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspace)
+}
+
+// Workspace returns the workspace a tool is called in, empty if unknown
+func Workspace(ctx context.Context) string {
+	workspace, _ := ctx.Value(workspaceKey{}).(string)
+	return workspace
+}
@@ -16,6 +16,7 @@ type Executor struct {
 	llm       llm.Client
 	registry  *Registry
 	running   bool
+	stopped   bool // refuses calls once Stop waits for them
 	mu        sync.RWMutex
 	ctx       context.Context
 	cancel    context.CancelFunc
@@ -45,6 +46,7 @@ func (e *Executor) Start(ctx context.Context) error {
 
 	e.ctx, e.cancel = context.WithCancel(ctx)
 	e.running = true
+	e.stopped = false
 	e.startTime = time.Now()
 	e.mu.Unlock()
 
@@ -64,6 +66,7 @@ func (e *Executor) Start(ctx context.Context) error {
 func (e *Executor) Stop(ctx context.Context) error {
 	e.mu.Lock()
 	e.running = false
+	e.stopped = true
 	e.mu.Unlock()
 
 	log.Printf("🛑 Stopping tool executor...")
@@ -82,12 +85,20 @@ func (e *Executor) Stop(ctx context.Context) error {
 
 // Execute executes a tool by name
 func (e *Executor) Execute(ctx context.Context, toolName string, params map[string]interface{}) (*ToolResult, error) {
+	// Track the call so Stop waits for it. Stop sets stopped under the
+	// lock before it waits, so no call is added while it does.
 	e.mu.RLock()
-	defer e.mu.RUnlock()
+	if e.stopped {
+		e.mu.RUnlock()
+		return nil, fmt.Errorf("executor is stopped")
+	}
+	e.wg.Add(1)
+	e.mu.RUnlock()
+	defer e.wg.Done()
 
 	// Get tool from registry
 	tool, ok := e.registry.Get(toolName)
-	if !ok {
+	if !ok || tool.Handler == nil {
 		return &ToolResult{
 			Name:    toolName,
 			Success: false,
@@ -99,6 +110,9 @@ func (e *Executor) Execute(ctx context.Context, toolName string, params map[stri
 
 	// Execute tool handler
 	result, err := tool.Handler(ctx, params)
+	if err == nil && result == nil {
+		err = fmt.Errorf("tool %s returned no result", toolName)
+	}
 	if err != nil {
 		return &ToolResult{
 			Name:    toolName,
@@ -126,7 +140,7 @@ func (e *Executor) Execute(ctx context.Context, toolName string, params map[stri
 		Success:  result.Success,
 		Data:     data,
 		ExecTime: execTime,
-		Error:    "",
+		Error:    result.Error,
 	}, nil
 }
 
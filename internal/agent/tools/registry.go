@@ -23,6 +23,7 @@ type Tool struct {
 	Description string                 `json:"description"`
 	InputSchema map[string]interface{} `json:"input_schema"`
 	Function    string                 `json:"function"`  // For LLM function calling
+	Restricted  bool                   `json:"restricted"` // offered only to scopes naming it
 	Handler     ToolHandler `json:"-"`
 }
 
@@ -57,6 +58,9 @@ func (r *Registry) Register(tool *Tool) error {
 	}
 
 	r.tools[tool.Name] = tool
+	if tool.Handler != nil {
+		r.handlers[tool.Name] = tool.Handler
+	}
 	log.Printf("🔧 Tool registered: %s", tool.Name)
 
 	return nil
@@ -72,6 +76,7 @@ func (r *Registry) Unregister(name string) error {
 	}
 
 	delete(r.tools, name)
+	delete(r.handlers, name)
 	log.Printf("📝 Tool unregistered: %s", name)
 
 	return nil
@@ -92,10 +97,8 @@ func (r *Registry) GetAll() []*Tool {
 	defer r.mu.RUnlock()
 
 	tools := make([]*Tool, 0, len(r.tools))
-	i := 0
 	for _, tool := range r.tools {
-		tools[i] = tool
-		i++
+		tools = append(tools, tool)
 	}
 
 	return tools
@@ -21,6 +21,20 @@ type CommandContext struct {
 	Gateway   interface{} // Avoid circular dependency
 }
 
+// NodeService is implemented by gateways that manage paired nodes
+type NodeService interface {
+	ListNodes() []protocol.NodeInfo
+	GetNode(nodeID string) (*protocol.NodeInfo, bool)
+	InvokeNode(ctx context.Context, nodeID, capability string, params json.RawMessage) (json.RawMessage, error)
+}
+
+// WorkspaceService is implemented by gateways that manage workspaces
+type WorkspaceService interface {
+	ListWorkspaces() []protocol.WorkspaceInfo
+	GetWorkspace(id string) (*protocol.WorkspaceInfo, bool)
+	SwitchWorkspace(clientID, workspaceID string) error
+}
+
 // CommandHandler handles specific commands
 type CommandHandler func(ctx context.Context, cmdCtx *CommandContext, params json.RawMessage) (interface{}, error)
 
@@ -167,26 +181,43 @@ func (r *Registry) handleWorkspace(cmdCtx *CommandContext) CommandHandler {
 			return nil, fmt.Errorf("invalid workspace request: %w", err)
 		}
 
+		wsSvc, ok := cmdCtx.Gateway.(WorkspaceService)
+		if !ok {
+			return nil, fmt.Errorf("workspace service unavailable")
+		}
+
 		switch req.Action {
 		case "list", "":
 			return &protocol.WorkspaceResponse{
 				Status:     "ok",
-				Workspaces: []protocol.WorkspaceInfo{},
+				Workspaces: wsSvc.ListWorkspaces(),
 			}, nil
 
 		case "get":
+			if req.Workspace == "" {
+				return nil, fmt.Errorf("workspace is required for get action")
+			}
+			workspace, ok := wsSvc.GetWorkspace(req.Workspace)
+			if !ok {
+				return nil, fmt.Errorf("unknown workspace: %s", req.Workspace)
+			}
 			return &protocol.WorkspaceResponse{
-				Status: "ok",
-				Workspace: &protocol.WorkspaceInfo{
-					ID:   "default",
-					Name: "Default Workspace",
-				},
+				Status:    "ok",
+				Workspace: workspace,
 			}, nil
 
 		case "switch":
+			if req.Workspace == "" {
+				return nil, fmt.Errorf("workspace is required for switch action")
+			}
+			if err := wsSvc.SwitchWorkspace(cc.ClientID, req.Workspace); err != nil {
+				return nil, err
+			}
+			workspace, _ := wsSvc.GetWorkspace(req.Workspace)
 			return &protocol.WorkspaceResponse{
-				Status:  "ok",
-				Message: fmt.Sprintf("Switched to workspace: %s", req.Workspace),
+				Status:    "ok",
+				Message:   fmt.Sprintf("Switched to workspace: %s", req.Workspace),
+				Workspace: workspace,
 			}, nil
 
 		default:
@@ -203,39 +234,60 @@ func (r *Registry) handleNode(cmdCtx *CommandContext) CommandHandler {
 			return nil, fmt.Errorf("invalid node request: %w", err)
 		}
 
+		nodeSvc, ok := cmdCtx.Gateway.(NodeService)
+		if !ok {
+			return nil, fmt.Errorf("node service unavailable")
+		}
+
+		nodeID := req.NodeID
+		if nodeID == "" {
+			nodeID = req.ChannelID
+		}
+
 		switch req.Action {
 		case "list", "":
 			return &protocol.NodeResponse{
 				Status: "ok",
-				Nodes:  []protocol.NodeInfo{},
+				Nodes:  nodeSvc.ListNodes(),
 			}, nil
 
 		case "get":
-			nodeID := ""
-			if req.ChannelID != "" {
-				nodeID = req.ChannelID
-			}
 			if nodeID == "" {
 				return nil, fmt.Errorf("node_id is required for get action")
 			}
+			node, ok := nodeSvc.GetNode(nodeID)
+			if !ok {
+				return nil, fmt.Errorf("unknown node: %s", nodeID)
+			}
 			return &protocol.NodeResponse{
 				Status: "ok",
-				Node:   &protocol.NodeInfo{ID: nodeID},
+				Node:   node,
 			}, nil
 
-		case "notify":
-			// Publish notification event
-			eventData := &events.AgentEventData{
-				Action:  "notify",
-				NodeID:  req.ChannelID,
-				Details: make(map[string]any),
+		case "notify", "invoke":
+			if nodeID == "" {
+				return nil, fmt.Errorf("node_id is required for %s action", req.Action)
+			}
+			capability := req.Capability
+			if req.Action == "notify" {
+				capability = "notify"
 			}
-			event, _ := events.NewEvent(string(events.EventNode), "notify", eventData, "gateway")
+
+			result, err := nodeSvc.InvokeNode(ctx, nodeID, capability, req.Params)
+			if err != nil {
+				return nil, err
+			}
+
+			eventData := &events.NodeEventData{
+				NodeID: nodeID,
+				Action: capability,
+			}
+			event, _ := events.NewEvent(string(events.EventNode), capability, eventData, "gateway")
 			cc.EventBus.PublishAsync(event)
 
 			return &protocol.NodeResponse{
-				Status:  "ok",
-				Message: "Notification sent",
+				Status: "ok",
+				Result: result,
 			}, nil
 
 		default:
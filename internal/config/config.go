@@ -1,10 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"strings"
 	"sync"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
@@ -20,25 +24,111 @@ type Config struct {
 	Logging  LoggingConfig  `mapstructure:"logging"`
 	Database DatabaseConfig `mapstructure:"database"`
 	Features FeaturesConfig `mapstructure:"features"`
+	Cluster  ClusterConfig  `mapstructure:"cluster"`
+	Nodes    NodesConfig    `mapstructure:"nodes"`
+	Presence PresenceConfig `mapstructure:"presence"`
+	Channels ChannelsConfig `mapstructure:"channels"`
 }
 
 // ServerConfig represents server configuration
 type ServerConfig struct {
-	Host            string `mapstructure:"host"`
-	Port            int    `mapstructure:"port"`
-	ReadTimeout     int    `mapstructure:"read_timeout"`
-	WriteTimeout    int    `mapstructure:"write_timeout"`
-	ShutdownTimeout int    `mapstructure:"shutdown_timeout"`
+	Host             string `mapstructure:"host"`
+	Port             int    `mapstructure:"port"`
+	ReadTimeout      int    `mapstructure:"read_timeout"`
+	WriteTimeout     int    `mapstructure:"write_timeout"`
+	ShutdownTimeout  int    `mapstructure:"shutdown_timeout"`
+	ReconnectDelay   int    `mapstructure:"reconnect_delay"`   // hint sent to clients when draining, in seconds
+	CallTimeout      int    `mapstructure:"call_timeout"`      // default timeout of requests sent to clients, in seconds
+	SnapshotInterval int    `mapstructure:"snapshot_interval"` // seconds between state.update diffs to clients, 0 to disable
+
+	Backpressure BackpressureConfig `mapstructure:"backpressure"`
+	WebSocket    WebSocketConfig    `mapstructure:"websocket"`
+	Heartbeat    HeartbeatConfig    `mapstructure:"heartbeat"`
+}
+
+// HeartbeatConfig represents client liveness checking. Intervals are in
+// seconds.
+type HeartbeatConfig struct {
+	PingInterval  int `mapstructure:"ping_interval"`  // between WebSocket pings
+	PongTimeout   int `mapstructure:"pong_timeout"`   // without pong or message before a client is dead
+	IdleTimeout   int `mapstructure:"idle_timeout"`   // without requests before a client is disconnected, 0 to keep idle clients
+	CheckInterval int `mapstructure:"check_interval"` // between liveness checks
+}
+
+// WebSocketConfig represents upgrade policy and limits for WebSocket
+// connections. Zero limits mean unlimited.
+type WebSocketConfig struct {
+	// AllowedOrigins lists the origins allowed to connect: exact origins
+	// ("https://app.example.com"), wildcard subdomains ("*.example.com",
+	// "https://*.example.com" or "*.example.com:8443") and regular
+	// expressions prefixed with "re:", which must match the whole origin.
+	// An empty list or "*" allows every origin.
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+
+	MaxConnections          int   `mapstructure:"max_connections"`
+	MaxConnectionsPerIP     int   `mapstructure:"max_connections_per_ip"`
+	MaxConnectionsPerDevice int   `mapstructure:"max_connections_per_device"`
+	MaxMessageSize          int64 `mapstructure:"max_message_size"` // bytes
+	ReadBufferSize          int   `mapstructure:"read_buffer_size"`
+	WriteBufferSize         int   `mapstructure:"write_buffer_size"`
+
+	// Compression enables permessage-deflate for clients that offer it
+	Compression      bool `mapstructure:"compression"`
+	CompressionLevel int  `mapstructure:"compression_level"` // 1 (fastest) to 9 (best)
+
+	// BinaryEncoding offers the openclaw.msgpack subprotocol
+	BinaryEncoding bool `mapstructure:"binary_encoding"`
+}
+
+// BackpressureConfig represents send/receive queue configuration for
+// WebSocket connections
+type BackpressureConfig struct {
+	SendBufferSize      int `mapstructure:"send_buffer_size"`
+	ReceiveBufferSize   int `mapstructure:"receive_buffer_size"`
+	BroadcastBufferSize int `mapstructure:"broadcast_buffer_size"`
+
+	// Default is the slow-consumer policy applied before a client
+	// identifies itself, and to client types without an override
+	Default SlowConsumerConfig `mapstructure:"default"`
+
+	// Classes overrides the policy per client type (agent, node, web, mobile)
+	Classes map[string]SlowConsumerConfig `mapstructure:"classes"`
+}
+
+// SlowConsumerConfig represents the policy for a full connection queue
+type SlowConsumerConfig struct {
+	Policy       string `mapstructure:"policy"`        // block, drop_oldest, drop_newest, disconnect
+	BlockTimeout int    `mapstructure:"block_timeout"` // milliseconds, for the block policy
+}
+
+// ForClass returns the slow-consumer policy for a client type
+func (b BackpressureConfig) ForClass(clientType string) SlowConsumerConfig {
+	if policy, ok := b.Classes[clientType]; ok && policy.Policy != "" {
+		if policy.BlockTimeout == 0 {
+			policy.BlockTimeout = b.Default.BlockTimeout
+		}
+		return policy
+	}
+	return b.Default
 }
 
 // AuthConfig represents authentication configuration
 type AuthConfig struct {
-	Enabled          bool     `mapstructure:"enabled"`
-	Secret           string   `mapstructure:"secret"`
-	Tokens           []string `mapstructure:"tokens"`
-	TokenRequired    bool     `mapstructure:"token_required"`
-	DeviceCheck      bool     `mapstructure:"device_check"`
-	AllowedDeviceIDs []string `mapstructure:"allowed_device_ids"`
+	Enabled          bool         `mapstructure:"enabled"`
+	Secret           string       `mapstructure:"secret"`
+	Tokens           []string     `mapstructure:"tokens"`
+	TokenRequired    bool         `mapstructure:"token_required"`
+	DeviceCheck      bool         `mapstructure:"device_check"`
+	AllowedDeviceIDs []string     `mapstructure:"allowed_device_ids"`
+	AdminTokens      []string     `mapstructure:"admin_tokens"` // tokens allowed to approve nodes and invoke them
+	Users            []UserConfig `mapstructure:"users"`        // users the presence of clients counts for, by token
+}
+
+// UserConfig names the user of tokens. Clients presenting one of them
+// count as that user; other clients count as their device.
+type UserConfig struct {
+	ID     string   `mapstructure:"id"`
+	Tokens []string `mapstructure:"tokens"` // also accepted by ValidateToken
 }
 
 // LoggingConfig represents logging configuration
@@ -53,8 +143,8 @@ type LoggingConfig struct {
 
 // DatabaseConfig represents database configuration
 type DatabaseConfig struct {
-	Type     string `mapstructure:"type"`     // sqlite, postgres, mysql
-	Path     string `mapstructure:"path"`     // for sqlite
+	Type     string `mapstructure:"type"`     // file or memory
+	Path     string `mapstructure:"path"`     // directory, for file
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
 	Database string `mapstructure:"database"`
@@ -78,6 +168,124 @@ func Load(configPath string) (*Config, error) {
 	return instance, err
 }
 
+// PresenceConfig represents presence tracking configuration, enabled by
+// features.presence
+type PresenceConfig struct {
+	AwayAfter     int `mapstructure:"away_after"`     // seconds without activity before a user is away, 0 to disable
+	StaleAfter    int `mapstructure:"stale_after"`    // seconds without a heartbeat before a connection counts as offline
+	CheckInterval int `mapstructure:"check_interval"` // seconds between presence sweeps
+}
+
+// NodesConfig represents device node pairing configuration
+type NodesConfig struct {
+	PairingTTL int `mapstructure:"pairing_ttl"` // lifetime of a pairing code, in seconds
+}
+
+// ChannelsConfig represents the messaging channels answered by the agent
+type ChannelsConfig struct {
+	Telegram TelegramConfig `mapstructure:"telegram"`
+	Slack    SlackConfig    `mapstructure:"slack"`
+	Discord  DiscordConfig  `mapstructure:"discord"`
+	WhatsApp WhatsAppConfig `mapstructure:"whatsapp"`
+}
+
+// TelegramConfig represents the Telegram bot channel
+type TelegramConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	BotToken      string  `mapstructure:"bot_token"`
+	APIURL        string  `mapstructure:"api_url"`       // Bot API base URL, for a local Bot API server
+	MaxFileSize   int64   `mapstructure:"max_file_size"` // largest photo, document or voice message read, in bytes
+	ParseMode     string  `mapstructure:"parse_mode"`    // html, markdownv2 or none
+	EditInterval  int     `mapstructure:"edit_interval"` // milliseconds between edits of a streamed reply
+	AllowedUsers  []int64 `mapstructure:"allowed_users"`
+	AllowedGroups []int64 `mapstructure:"allowed_groups"`
+	AdminUsers    []int64 `mapstructure:"admin_users"` // users allowed to switch the model
+	Debug         bool    `mapstructure:"debug"`
+
+	// Groups answer messages mentioning the bot, replying to it or starting
+	// with a keyword (mention), or every message (always)
+	GroupTrigger  string   `mapstructure:"group_trigger"`
+	GroupKeywords []string `mapstructure:"group_keywords"`
+	GroupSession  string   `mapstructure:"group_session"` // group or user, one agent session per group or per user in it
+
+	// Webhook mode receives updates on the gateway HTTP server instead of
+	// long polling
+	UseWebhook         bool   `mapstructure:"use_webhook"`
+	WebhookURL         string `mapstructure:"webhook_url"`         // public URL Telegram posts updates to
+	WebhookPath        string `mapstructure:"webhook_path"`        // path on the gateway, the path of webhook_url by default
+	WebhookSecret      string `mapstructure:"webhook_secret"`      // checked in every update, random by default
+	WebhookCertificate string `mapstructure:"webhook_certificate"` // PEM certificate to upload for a self-signed webhook_url
+}
+
+// SlackConfig represents the Slack app channel, receiving events on the
+// gateway HTTP server
+type SlackConfig struct {
+	Enabled         bool     `mapstructure:"enabled"`
+	BotToken        string   `mapstructure:"bot_token"`      // bot user OAuth token, xoxb-...
+	SigningSecret   string   `mapstructure:"signing_secret"` // verifies that event requests come from Slack
+	APIURL          string   `mapstructure:"api_url"`        // Web API base URL
+	WebhookPath     string   `mapstructure:"webhook_path"`   // path of the Events API request URL on the gateway
+	EditInterval    int      `mapstructure:"edit_interval"`  // milliseconds between edits of a streamed reply
+	AllowedUsers    []string `mapstructure:"allowed_users"`
+	AllowedChannels []string `mapstructure:"allowed_channels"`
+	Debug           bool     `mapstructure:"debug"`
+}
+
+// DiscordConfig represents the Discord bot channel, connected to the
+// Discord gateway
+type DiscordConfig struct {
+	Enabled       bool     `mapstructure:"enabled"`
+	BotToken      string   `mapstructure:"bot_token"`
+	GatewayURL    string   `mapstructure:"gateway_url"`   // gateway WebSocket URL
+	APIURL        string   `mapstructure:"api_url"`       // REST API base URL
+	Intents       int      `mapstructure:"intents"`       // gateway intents, guild and direct messages by default
+	EditInterval  int      `mapstructure:"edit_interval"` // milliseconds between edits of a streamed reply
+	AllowedUsers  []string `mapstructure:"allowed_users"`
+	AllowedGuilds []string `mapstructure:"allowed_guilds"`
+	AdminUsers    []string `mapstructure:"admin_users"` // users allowed to switch the model
+	Debug         bool     `mapstructure:"debug"`
+}
+
+// WhatsAppConfig represents the WhatsApp Cloud API channel, receiving
+// webhook notifications on the gateway HTTP server
+type WhatsAppConfig struct {
+	Enabled          bool     `mapstructure:"enabled"`
+	AccessToken      string   `mapstructure:"access_token"`      // system user access token
+	PhoneNumberID    string   `mapstructure:"phone_number_id"`   // business phone number replies are sent from
+	AppSecret        string   `mapstructure:"app_secret"`        // verifies X-Hub-Signature-256 of notifications
+	VerifyToken      string   `mapstructure:"verify_token"`      // echoed when Meta checks the callback URL
+	APIURL           string   `mapstructure:"api_url"`           // Graph API base URL with its version
+	WebhookPath      string   `mapstructure:"webhook_path"`      // path of the callback URL on the gateway
+	Template         string   `mapstructure:"template"`          // sent once the 24-hour window has closed, with the reply as its variable
+	TemplateLanguage string   `mapstructure:"template_language"` // language code of the template
+	MaxFileSize      int64    `mapstructure:"max_file_size"`     // largest image or audio message read, in bytes
+	AllowedUsers     []string `mapstructure:"allowed_users"`     // phone numbers, as 8613800000000
+	Debug            bool     `mapstructure:"debug"`
+}
+
+// ClusterConfig represents multi-gateway clustering configuration
+type ClusterConfig struct {
+	Enabled           bool     `mapstructure:"enabled"`
+	NodeID            string   `mapstructure:"node_id"`   // defaults to the hostname
+	Backplane         string   `mapstructure:"backplane"` // gossip or memory
+	BindAddr          string   `mapstructure:"bind_addr"` // gossip peer address
+	Peers             []string `mapstructure:"peers"`
+	Secret            string   `mapstructure:"secret"`             // shared secret between peers
+	HeartbeatInterval int      `mapstructure:"heartbeat_interval"` // seconds
+}
+
+// Default returns a configuration populated with default values only
+func Default() *Config {
+	v := viper.New()
+	setDefaults(v)
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		panic(fmt.Sprintf("failed to unmarshal default config: %v", err))
+	}
+	return &cfg
+}
+
 // loadConfig loads configuration from file
 func loadConfig(configPath string) (*Config, error) {
 	v := viper.New()
@@ -129,6 +337,29 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.read_timeout", 30)
 	v.SetDefault("server.write_timeout", 30)
 	v.SetDefault("server.shutdown_timeout", 10)
+	v.SetDefault("server.reconnect_delay", 2)
+	v.SetDefault("server.call_timeout", 30)
+	v.SetDefault("server.snapshot_interval", 2)
+	v.SetDefault("server.heartbeat.ping_interval", 30)
+	v.SetDefault("server.heartbeat.pong_timeout", 75)
+	v.SetDefault("server.heartbeat.idle_timeout", 0)
+	v.SetDefault("server.heartbeat.check_interval", 15)
+	v.SetDefault("server.backpressure.send_buffer_size", 256)
+	v.SetDefault("server.backpressure.receive_buffer_size", 64)
+	v.SetDefault("server.backpressure.broadcast_buffer_size", 256)
+	v.SetDefault("server.backpressure.default.policy", "drop_newest")
+	v.SetDefault("server.backpressure.default.block_timeout", 500)
+	v.SetDefault("server.backpressure.classes", map[string]interface{}{})
+	v.SetDefault("server.websocket.allowed_origins", []string{})
+	v.SetDefault("server.websocket.max_connections", 0)
+	v.SetDefault("server.websocket.max_connections_per_ip", 0)
+	v.SetDefault("server.websocket.max_connections_per_device", 0)
+	v.SetDefault("server.websocket.max_message_size", 8192)
+	v.SetDefault("server.websocket.read_buffer_size", 1024)
+	v.SetDefault("server.websocket.write_buffer_size", 1024)
+	v.SetDefault("server.websocket.compression", true)
+	v.SetDefault("server.websocket.compression_level", 1)
+	v.SetDefault("server.websocket.binary_encoding", true)
 
 	// Auth defaults
 	v.SetDefault("auth.enabled", false)
@@ -137,6 +368,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("auth.token_required", false)
 	v.SetDefault("auth.device_check", false)
 	v.SetDefault("auth.allowed_device_ids", []string{})
+	v.SetDefault("auth.admin_tokens", []string{})
+	v.SetDefault("auth.users", []interface{}{})
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
@@ -147,8 +380,56 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("logging.max_age", 28)
 
 	// Database defaults
-	v.SetDefault("database.type", "sqlite")
-	v.SetDefault("database.path", "./data/openclaw.db")
+	v.SetDefault("database.type", "file")
+	v.SetDefault("database.path", "./data")
+
+	// Nodes defaults
+	v.SetDefault("nodes.pairing_ttl", 300)
+
+	// Presence defaults
+	v.SetDefault("presence.away_after", 300)
+	v.SetDefault("presence.stale_after", 75)
+	v.SetDefault("presence.check_interval", 15)
+
+	// Cluster defaults
+	v.SetDefault("cluster.enabled", false)
+	v.SetDefault("cluster.backplane", "gossip")
+	v.SetDefault("cluster.bind_addr", "0.0.0.0:18791")
+	v.SetDefault("cluster.peers", []string{})
+	v.SetDefault("cluster.heartbeat_interval", 5)
+
+	// Channels defaults
+	v.SetDefault("channels.telegram.enabled", false)
+	v.SetDefault("channels.telegram.api_url", "https://api.telegram.org")
+	v.SetDefault("channels.telegram.max_file_size", 20<<20)
+	v.SetDefault("channels.telegram.parse_mode", "html")
+	v.SetDefault("channels.telegram.edit_interval", 1000)
+	v.SetDefault("channels.telegram.allowed_users", []int64{})
+	v.SetDefault("channels.telegram.allowed_groups", []int64{})
+	v.SetDefault("channels.telegram.admin_users", []int64{})
+	v.SetDefault("channels.telegram.group_trigger", "mention")
+	v.SetDefault("channels.telegram.group_keywords", []string{})
+	v.SetDefault("channels.telegram.group_session", "group")
+	v.SetDefault("channels.telegram.use_webhook", false)
+	v.SetDefault("channels.slack.enabled", false)
+	v.SetDefault("channels.slack.api_url", "https://slack.com/api")
+	v.SetDefault("channels.slack.webhook_path", "/slack/events")
+	v.SetDefault("channels.slack.edit_interval", 1000)
+	v.SetDefault("channels.slack.allowed_users", []string{})
+	v.SetDefault("channels.slack.allowed_channels", []string{})
+	v.SetDefault("channels.discord.enabled", false)
+	v.SetDefault("channels.discord.gateway_url", "wss://gateway.discord.gg")
+	v.SetDefault("channels.discord.api_url", "https://discord.com/api/v10")
+	v.SetDefault("channels.discord.edit_interval", 1000)
+	v.SetDefault("channels.discord.allowed_users", []string{})
+	v.SetDefault("channels.discord.allowed_guilds", []string{})
+	v.SetDefault("channels.discord.admin_users", []string{})
+	v.SetDefault("channels.whatsapp.enabled", false)
+	v.SetDefault("channels.whatsapp.api_url", "https://graph.facebook.com/v21.0")
+	v.SetDefault("channels.whatsapp.webhook_path", "/whatsapp/webhook")
+	v.SetDefault("channels.whatsapp.template_language", "en_US")
+	v.SetDefault("channels.whatsapp.max_file_size", 16<<20)
+	v.SetDefault("channels.whatsapp.allowed_users", []string{})
 
 	// Features defaults
 	v.SetDefault("features.events", true)
@@ -185,7 +466,22 @@ func (c *Config) ValidateToken(token string) bool {
 			return true
 		}
 	}
-	return false
+	return c.UserForToken(token) != ""
+}
+
+// UserForToken returns the user a token belongs to, empty if none
+func (c *Config) UserForToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	for _, user := range c.Auth.Users {
+		for _, t := range user.Tokens {
+			if t == token {
+				return user.ID
+			}
+		}
+	}
+	return ""
 }
 
 // IsDeviceAllowed checks if a device ID is allowed
@@ -202,12 +498,206 @@ func (c *Config) IsDeviceAllowed(deviceID string) bool {
 	return false
 }
 
-// Save saves the current configuration to a file
+// IsAdminToken checks if a token grants admin rights. Only tokens listed
+// in auth.admin_tokens do, and none without auth, where no token is checked.
+func (c *Config) IsAdminToken(token string) bool {
+	if !c.Auth.Enabled || token == "" {
+		return false
+	}
+
+	for _, t := range c.Auth.AdminTokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the configuration for values the gateway cannot run
+// with, reporting every problem found
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server.port: %d is not a valid port", c.Server.Port)
+	check(c.Server.ShutdownTimeout >= 0, "server.shutdown_timeout: must not be negative")
+	check(c.Server.SnapshotInterval >= 0, "server.snapshot_interval: must not be negative")
+	check(c.Server.Heartbeat.PingInterval > 0, "server.heartbeat.ping_interval: must be positive")
+	check(c.Server.Heartbeat.PongTimeout > c.Server.Heartbeat.PingInterval,
+		"server.heartbeat.pong_timeout: must be longer than ping_interval")
+	check(c.Server.WebSocket.CompressionLevel >= 1 && c.Server.WebSocket.CompressionLevel <= 9,
+		"server.websocket.compression_level: %d is not between 1 and 9", c.Server.WebSocket.CompressionLevel)
+
+	switch c.Database.Type {
+	case "file", "":
+		check(c.Database.Path != "", "database.path: required for file storage")
+	case "memory":
+	default:
+		check(false, "database.type: unsupported type %q", c.Database.Type)
+	}
+
+	switch c.Logging.Format {
+	case "json", "console", "":
+	default:
+		check(false, "logging.format: unsupported format %q", c.Logging.Format)
+	}
+
+	if c.Auth.Enabled && c.Auth.TokenRequired {
+		check(len(c.Auth.Tokens) > 0 || len(c.Auth.Users) > 0, "auth.tokens: required when auth.token_required is set")
+	}
+	owners := make(map[string]string)
+	for i, user := range c.Auth.Users {
+		check(user.ID != "", "auth.users[%d].id: required", i)
+		check(len(user.Tokens) > 0, "auth.users[%d].tokens: required", i)
+		for _, t := range user.Tokens {
+			owner, taken := owners[t]
+			check(!taken, "auth.users[%d].tokens: token already belongs to %s", i, owner)
+			owners[t] = user.ID
+		}
+	}
+	if c.Auth.Enabled && c.Auth.DeviceCheck {
+		check(len(c.Auth.AllowedDeviceIDs) > 0, "auth.allowed_device_ids: required when auth.device_check is set")
+	}
+
+	if c.Cluster.Enabled {
+		switch c.Cluster.Backplane {
+		case "gossip", "": // gossip is the default
+			check(c.Cluster.BindAddr != "", "cluster.bind_addr: required for the gossip backplane")
+			check(c.Cluster.Secret != "" || isLoopback(c.Cluster.BindAddr), "cluster.secret: required when cluster.bind_addr is not a loopback address")
+		case "memory":
+		default:
+			check(false, "cluster.backplane: unsupported backplane %q", c.Cluster.Backplane)
+		}
+	}
+
+	if c.Channels.Telegram.Enabled {
+		check(c.Channels.Telegram.BotToken != "", "channels.telegram.bot_token: required when the channel is enabled")
+		switch c.Channels.Telegram.GroupTrigger {
+		case "mention", "always", "":
+		default:
+			check(false, "channels.telegram.group_trigger: unsupported trigger %q", c.Channels.Telegram.GroupTrigger)
+		}
+		switch c.Channels.Telegram.GroupSession {
+		case "group", "user", "":
+		default:
+			check(false, "channels.telegram.group_session: unsupported session key %q", c.Channels.Telegram.GroupSession)
+		}
+		if c.Channels.Telegram.UseWebhook {
+			check(c.Channels.Telegram.WebhookURL != "", "channels.telegram.webhook_url: required in webhook mode")
+		}
+	}
+
+	if c.Channels.Slack.Enabled {
+		check(c.Channels.Slack.BotToken != "", "channels.slack.bot_token: required when the channel is enabled")
+		check(c.Channels.Slack.SigningSecret != "", "channels.slack.signing_secret: required when the channel is enabled")
+		if path := c.Channels.Slack.WebhookPath; path != "" {
+			check(strings.HasPrefix(path, "/"), "channels.slack.webhook_path: must start with /, not %q", path)
+		}
+	}
+
+	if c.Channels.Discord.Enabled {
+		check(c.Channels.Discord.BotToken != "", "channels.discord.bot_token: required when the channel is enabled")
+		check(c.Channels.Discord.Intents >= 0, "channels.discord.intents: must not be negative")
+	}
+
+	if c.Channels.WhatsApp.Enabled {
+		check(c.Channels.WhatsApp.AccessToken != "", "channels.whatsapp.access_token: required when the channel is enabled")
+		check(c.Channels.WhatsApp.PhoneNumberID != "", "channels.whatsapp.phone_number_id: required when the channel is enabled")
+		check(c.Channels.WhatsApp.AppSecret != "", "channels.whatsapp.app_secret: required when the channel is enabled")
+		check(c.Channels.WhatsApp.VerifyToken != "", "channels.whatsapp.verify_token: required when the channel is enabled")
+		if path := c.Channels.WhatsApp.WebhookPath; path != "" {
+			check(strings.HasPrefix(path, "/"), "channels.whatsapp.webhook_path: must start with /, not %q", path)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// isLoopback reports whether a host:port address only listens on loopback
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// ToMap returns the configuration as nested maps keyed like the config file
+func (c *Config) ToMap() (map[string]interface{}, error) {
+	var m map[string]interface{}
+	if err := mapstructure.Decode(c, &m); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return m, nil
+}
+
+// redactedValue replaces secrets in a redacted configuration
+const redactedValue = "***"
+
+// Redacted returns a copy of the configuration with its tokens, secrets
+// and passwords masked, for printing. Unset secrets stay empty.
+func (c *Config) Redacted() *Config {
+	r := *c
+	r.Auth.Secret = redact(c.Auth.Secret)
+	r.Auth.Tokens = redactAll(c.Auth.Tokens)
+	r.Auth.AdminTokens = redactAll(c.Auth.AdminTokens)
+	r.Auth.Users = make([]UserConfig, len(c.Auth.Users))
+	for i, user := range c.Auth.Users {
+		r.Auth.Users[i] = UserConfig{ID: user.ID, Tokens: redactAll(user.Tokens)}
+	}
+	r.Database.Password = redact(c.Database.Password)
+	r.Cluster.Secret = redact(c.Cluster.Secret)
+
+	r.Channels.Telegram.BotToken = redact(c.Channels.Telegram.BotToken)
+	r.Channels.Telegram.WebhookSecret = redact(c.Channels.Telegram.WebhookSecret)
+	r.Channels.Slack.BotToken = redact(c.Channels.Slack.BotToken)
+	r.Channels.Slack.SigningSecret = redact(c.Channels.Slack.SigningSecret)
+	r.Channels.Discord.BotToken = redact(c.Channels.Discord.BotToken)
+	r.Channels.WhatsApp.AccessToken = redact(c.Channels.WhatsApp.AccessToken)
+	r.Channels.WhatsApp.AppSecret = redact(c.Channels.WhatsApp.AppSecret)
+	r.Channels.WhatsApp.VerifyToken = redact(c.Channels.WhatsApp.VerifyToken)
+	return &r
+}
+
+// redact masks a secret if it is set
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// redactAll masks a list of secrets
+func redactAll(secrets []string) []string {
+	if secrets == nil {
+		return nil
+	}
+	masked := make([]string, len(secrets))
+	for i, secret := range secrets {
+		masked[i] = redact(secret)
+	}
+	return masked
+}
+
+// Save saves the current configuration to a file, in the format given by
+// its extension
 func (c *Config) Save(path string) error {
+	m, err := c.ToMap()
+	if err != nil {
+		return err
+	}
+
 	v := viper.New()
-	// 直接设置配置值
-	if err := v.Unmarshal(c); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+	if err := v.MergeConfigMap(m); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
 	}
 	return v.WriteConfigAs(path)
 }
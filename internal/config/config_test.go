@@ -0,0 +1,79 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestRedacted(t *testing.T) {
+	c := Default()
+	c.Auth.Secret = "auth-secret"
+	c.Auth.Tokens = []string{"device-token"}
+	c.Auth.AdminTokens = []string{"admin-token"}
+	c.Auth.Users = []UserConfig{{ID: "alice", Tokens: []string{"alice-token"}}}
+	c.Database.Password = "db-password"
+	c.Cluster.Secret = "cluster-secret"
+	c.Channels.Telegram.BotToken = "telegram-token"
+	c.Channels.Telegram.WebhookSecret = "telegram-secret"
+	c.Channels.Slack.BotToken = "slack-token"
+	c.Channels.Slack.SigningSecret = "slack-secret"
+	c.Channels.Discord.BotToken = "discord-token"
+	c.Channels.WhatsApp.AccessToken = "whatsapp-token"
+	c.Channels.WhatsApp.AppSecret = "whatsapp-secret"
+	c.Channels.WhatsApp.VerifyToken = "whatsapp-verify"
+
+	m, err := c.Redacted().ToMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{
+		"auth-secret", "device-token", "admin-token", "alice-token", "db-password", "cluster-secret",
+		"telegram-token", "telegram-secret", "slack-token", "slack-secret", "discord-token",
+		"whatsapp-token", "whatsapp-secret", "whatsapp-verify",
+	} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("%s printed unmasked", secret)
+		}
+	}
+	if !strings.Contains(string(data), "id: alice") {
+		t.Error("user IDs are masked too")
+	}
+
+	// The configuration itself keeps its secrets
+	if c.Auth.Tokens[0] != "device-token" || c.Auth.Users[0].Tokens[0] != "alice-token" {
+		t.Error("Redacted changed the configuration")
+	}
+}
+
+func TestValidateCluster(t *testing.T) {
+	for _, tc := range []struct {
+		backplane, bindAddr, secret string
+		wantErr                     string
+	}{
+		{"gossip", "0.0.0.0:18791", "", "cluster.secret"},
+		{"", "0.0.0.0:18791", "", "cluster.secret"},
+		{"", "", "s3cret", "cluster.bind_addr"},
+		{"", "0.0.0.0:18791", "s3cret", ""},
+		{"gossip", "127.0.0.1:18791", "", ""},
+		{"memory", "", "", ""},
+		{"redis", "", "", "cluster.backplane"},
+	} {
+		c := Default()
+		c.Cluster.Enabled = true
+		c.Cluster.Backplane, c.Cluster.BindAddr, c.Cluster.Secret = tc.backplane, tc.bindAddr, tc.secret
+
+		err := c.Validate()
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("backplane %q on %q: %v", tc.backplane, tc.bindAddr, err)
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("backplane %q on %q: error %v, want one about %s", tc.backplane, tc.bindAddr, err, tc.wantErr)
+		}
+	}
+}
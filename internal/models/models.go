@@ -21,6 +21,7 @@ type Client struct {
 // Node represents a paired device node
 type Node struct {
 	ID           string            `json:"id"`
+	DeviceID     string            `json:"device_id"`
 	Name         string            `json:"name"`
 	Type         string            `json:"type"` // mobile, desktop, server
 	Status       string            `json:"status"` // online, offline, error
@@ -30,6 +31,8 @@ type Node struct {
 	LastSeen     time.Time         `json:"last_seen"`
 	Capabilities []string          `json:"capabilities"`
 	Metadata     map[string]string `json:"metadata"`
+	TokenHash    string            `json:"token_hash"` // SHA-256 of the node token
+	Workspace    string            `json:"workspace,omitempty"` // workspace the node was paired in
 }
 
 // Agent represents an agent instance
@@ -47,13 +50,25 @@ type Agent struct {
 
 // Workspace represents a workspace
 type Workspace struct {
-	ID          string            `json:"id"`
-	Name        string            `json:"name"`
-	Description string            `json:"description"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
-	Settings    map[string]any    `json:"settings"`
-	Metadata    map[string]string `json:"metadata"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	SystemPrompt string            `json:"system_prompt,omitempty"` // overrides the agent system prompt
+	Tools        []string          `json:"tools,omitempty"`         // tools offered to the agent, empty for all
+	Channels     []string          `json:"channels,omitempty"`      // channel bindings: "telegram" or "telegram:<chat id>"
+	Members      []string          `json:"members,omitempty"`       // users allowed to join, empty for all
+	Quotas       WorkspaceQuotas   `json:"quotas"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	Settings     map[string]any    `json:"settings"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+// WorkspaceQuotas limits the resources of a workspace. Zero means unlimited.
+type WorkspaceQuotas struct {
+	MaxClients        int `json:"max_clients"`
+	MaxSessions       int `json:"max_sessions"`
+	MaxMessagesPerDay int `json:"max_messages_per_day"`
 }
 
 // Session represents a user session
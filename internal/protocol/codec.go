@@ -0,0 +1,123 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// WebSocket subprotocols used to negotiate the wire encoding
+const (
+	SubprotocolJSON    = "openclaw.json"
+	SubprotocolMsgpack = "openclaw.msgpack"
+)
+
+// Codec converts messages between their canonical JSON form, used inside
+// the gateway, and the encoding used on the wire
+type Codec interface {
+	// Name returns the encoding name reported to clients
+	Name() string
+
+	// Binary returns true if frames must be sent as binary messages
+	Binary() bool
+
+	// Encode converts a JSON message to a wire frame
+	Encode(data []byte) ([]byte, error)
+
+	// Decode converts a wire frame to a JSON message
+	Decode(frame []byte) ([]byte, error)
+}
+
+// JSONCodec sends JSON as text frames
+type JSONCodec struct{}
+
+// Name returns the encoding name
+func (JSONCodec) Name() string { return "json" }
+
+// Binary returns false, JSON goes in text frames
+func (JSONCodec) Binary() bool { return false }
+
+// Encode returns the JSON message unchanged
+func (JSONCodec) Encode(data []byte) ([]byte, error) { return data, nil }
+
+// Decode returns the frame unchanged
+func (JSONCodec) Decode(frame []byte) ([]byte, error) { return frame, nil }
+
+// MsgpackCodec sends MessagePack as binary frames. It carries the JSON
+// data model, so field names and omitted fields match the JSON encoding.
+type MsgpackCodec struct{}
+
+// Name returns the encoding name
+func (MsgpackCodec) Name() string { return "msgpack" }
+
+// Binary returns true, MessagePack goes in binary frames
+func (MsgpackCodec) Binary() bool { return true }
+
+// Encode converts a JSON message to MessagePack
+func (MsgpackCodec) Encode(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+
+	return msgpack.Marshal(fromJSONNumbers(value))
+}
+
+// Decode converts a MessagePack frame to JSON
+func (MsgpackCodec) Decode(frame []byte) ([]byte, error) {
+	var value interface{}
+	if err := msgpack.Unmarshal(frame, &value); err != nil {
+		return nil, fmt.Errorf("invalid msgpack message: %w", err)
+	}
+
+	return json.Marshal(value)
+}
+
+// fromJSONNumbers replaces json.Number values with integers where they
+// fit and floats otherwise, so numbers are not encoded as strings
+func fromJSONNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = fromJSONNumbers(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = fromJSONNumbers(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Subprotocols returns the supported subprotocols in order of preference
+func Subprotocols(binary bool) []string {
+	if binary {
+		return []string{SubprotocolMsgpack, SubprotocolJSON}
+	}
+	return []string{SubprotocolJSON}
+}
+
+// CodecForSubprotocol returns the codec of a negotiated subprotocol. Clients
+// that did not request a subprotocol use JSON.
+func CodecForSubprotocol(subprotocol string) Codec {
+	switch subprotocol {
+	case SubprotocolMsgpack:
+		return MsgpackCodec{}
+	default:
+		return JSONCodec{}
+	}
+}
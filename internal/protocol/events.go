@@ -28,6 +28,28 @@ const (
 	// State events
 	EventStateUpdate EventType = "state.update"
 
+	// Stream events
+	EventStreamGap EventType = "stream.gap"
+
+	// Gateway events
+	EventGatewayDraining EventType = "gateway.draining"
+
+	// Node events
+	EventNodePairing EventType = "node.pairing" // sent to a node when its pairing is decided
+	EventNodeStatus  EventType = "node.status"  // a node was paired, unpaired, came online or went offline
+
+	// Call events
+	EventCallCancelled EventType = "call.cancelled" // the gateway gave up on a request it sent
+
+	// Presence events
+	EventPresenceUpdate EventType = "presence.update" // the aggregated status of a user changed
+
+	// Workspace events
+	EventWorkspaceUpdate EventType = "workspace.update" // a workspace was created, updated or deleted
+
+	// Channel events
+	EventChannelAction EventType = "channel.action" // a user pressed a button attached to a channel message
+
 	// Custom events
 	EventCustom EventType = "custom"
 )
@@ -39,6 +61,7 @@ type Event struct {
 	Data    json.RawMessage `json:"data"`
 	Seq     int             `json:"seq"`
 	Time    int64           `json:"timestamp"`
+	Origin  string          `json:"origin,omitempty"` // Gateway node the event came from (empty = local)
 }
 
 // EventBus manages event broadcasting
@@ -172,6 +195,33 @@ func (eb *EventBus) PublishData(eventType EventType, channel string, data json.R
 	}
 }
 
+// PublishEvent publishes an event received from another gateway node,
+// keeping its origin and assigning a local sequence number
+func (eb *EventBus) PublishEvent(event *Event) {
+	eb.mu.Lock()
+	eb.seq++
+	e := *event
+	e.Seq = eb.seq
+	eb.mu.Unlock()
+
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+
+	for sub := range eb.subscribers {
+		if !eb.matchFilter(sub, e.Type, e.Channel) {
+			continue
+		}
+
+		select {
+		case sub.send <- &e:
+		default:
+			if sub.Callback != nil && !sub.Callback(&e) {
+				delete(eb.subscribers, sub)
+			}
+		}
+	}
+}
+
 // matchFilter checks if a subscriber matches the event
 func (eb *EventBus) matchFilter(sub *EventSubscriber, eventType EventType, channel string) bool {
 	// Check channel filter
@@ -28,10 +28,18 @@ type ProtocolMessage struct {
 
 // ConnectRequest represents the connect handshake request
 type ConnectRequest struct {
-	Token    string `json:"token"`              // Authentication token
-	DeviceID string `json:"device_id"`          // Device identifier
-	ClientID string `json:"client_id,omitempty"` // Client identifier (optional)
-	Version  string `json:"version,omitempty"`  // Client version
+	Token      string `json:"token"`                 // Authentication token
+	DeviceID   string `json:"device_id"`             // Device identifier
+	ClientID   string `json:"client_id,omitempty"`   // Client identifier (optional)
+	Version    string `json:"version,omitempty"`     // Client version
+	ClientType string `json:"client_type,omitempty"` // Client type: agent, node, web, mobile
+	Workspace  string `json:"workspace,omitempty"`   // Workspace to join, default if empty
+	UserID     string `json:"user_id,omitempty"`     // User the presence of the client counts for; with auth, that of the token
+
+	// Paired nodes authenticate with the identity issued at pairing
+	NodeID       string   `json:"node_id,omitempty"`
+	NodeToken    string   `json:"node_token,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"`
 }
 
 // HelloResponse represents the hello response after successful connect
@@ -48,6 +56,7 @@ type HelloPayload struct {
 	DeviceID  string        `json:"device_id"`
 	SessionID string        `json:"session_id"`
 	Workspace string        `json:"workspace"`
+	Encoding  string        `json:"encoding,omitempty"` // wire encoding: json or msgpack
 	State     *StateSnapshot `json:"state"`
 }
 
@@ -60,8 +69,43 @@ type StateSnapshot struct {
 	Workspace  string            `json:"workspace,omitempty"`
 	Clients    []*ClientState    `json:"clients,omitempty"`
 	Sessions   []*SessionState   `json:"sessions,omitempty"`
+	Channels   []*ChannelState   `json:"channels,omitempty"`
+	Agent      *AgentState       `json:"agent,omitempty"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
 	Timestamp  int64             `json:"timestamp"`
+	Seq        uint64            `json:"seq"` // state.update diffs with a higher seq apply on top
+}
+
+// ChannelState represents the state of a messaging channel
+type ChannelState struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Running bool   `json:"running"`
+}
+
+// AgentState represents the state of the agent runtime
+type AgentState struct {
+	Status   string `json:"status"` // not_started, running, stopped
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Sessions int    `json:"sessions"` // sessions in the workspace
+}
+
+// StateDiff is the payload of a state.update event. Seq increases by one
+// per diff within a workspace; a client that sees a gap re-requests state.
+type StateDiff struct {
+	Seq       uint64         `json:"seq"`
+	Workspace string         `json:"workspace"`
+	Changes   []*StateChange `json:"changes"`
+	Timestamp int64          `json:"timestamp"`
+}
+
+// StateChange is one change in a state diff
+type StateChange struct {
+	Op    string      `json:"op"`   // add, update, remove
+	Kind  string      `json:"kind"` // client, session, channel, agent
+	ID    string      `json:"id"`
+	Value interface{} `json:"value,omitempty"` // the new state, for add and update
 }
 
 // ClientState represents a client state
@@ -70,10 +114,32 @@ type ClientState struct {
 	DeviceID    string            `json:"device_id"`
 	Type        string            `json:"type"`
 	Status      string            `json:"status"`
+	Workspace   string            `json:"workspace,omitempty"`
 	ConnectedAt int64             `json:"connected_at"`
 	LastSeen    int64             `json:"last_seen"`
 	Capabilities []string         `json:"capabilities,omitempty"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
+	Queue       *QueueStats       `json:"queue,omitempty"`
+}
+
+// QueueStats represents the send/receive queue state of a connection
+type QueueStats struct {
+	Policy          string `json:"policy"`
+	Status          string `json:"status"` // ok, lagging, slow_consumer
+	Queued          int    `json:"queued"`
+	Capacity        int    `json:"capacity"`
+	Sent            uint64 `json:"sent"`
+	Received        uint64 `json:"received"`
+	DroppedOutgoing uint64 `json:"dropped_outgoing"`
+	DroppedIncoming uint64 `json:"dropped_incoming"`
+	Gaps            uint64 `json:"gaps"`
+}
+
+// GapNotice is the payload of a stream.gap event, sent to a client after
+// messages addressed to it were dropped
+type GapNotice struct {
+	Dropped uint64 `json:"dropped"`
+	Reason  string `json:"reason"`
 }
 
 // SessionState represents a session state
@@ -88,6 +154,14 @@ type SessionState struct {
 	Metadata   map[string]string `json:"metadata,omitempty"`
 }
 
+// DrainNotice is the payload of a gateway.draining event
+type DrainNotice struct {
+	GatewayID      string `json:"gateway_id"`
+	Reason         string `json:"reason,omitempty"`
+	ReconnectAfter int    `json:"reconnect_after"` // seconds the client should wait before reconnecting
+	Deadline       int64  `json:"deadline,omitempty"` // unix time when remaining connections are closed
+}
+
 // PingMessage represents a ping message
 type PingMessage struct {
 	Seq int `json:"seq"`
@@ -110,9 +184,26 @@ type AgentRequest struct {
 	ChannelID string                 `json:"channel_id,omitempty"`
 	Message   string                 `json:"message,omitempty"`
 	Action    string                 `json:"action,omitempty"` // start, stop, query
+	Stream    bool                   `json:"stream,omitempty"` // send the reply as agent.message events while generated
+	Model     string                 `json:"model,omitempty"`  // for agent.model, empty to query
 	Options   map[string]interface{} `json:"options,omitempty"`
 }
 
+// AgentDelta is the payload of an agent.message event: a piece of a reply
+// being generated for a request
+type AgentDelta struct {
+	RequestID string `json:"request_id"`
+	ChannelID string `json:"channel_id"`
+	Delta     string `json:"delta,omitempty"`
+	Done      bool   `json:"done,omitempty"`
+}
+
+// ToolInfo describes a tool offered to the agent
+type ToolInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
 // AgentResponse represents an agent response
 type AgentResponse struct {
 	SessionID string                 `json:"session_id"`
@@ -152,8 +243,28 @@ type CheckResult struct {
 
 // WorkspaceRequest represents a workspace request
 type WorkspaceRequest struct {
-	Action   string `json:"action,omitempty"`   // list, get, switch
-	Workspace string `json:"workspace,omitempty"`
+	Action    string          `json:"action,omitempty"` // list, get, switch
+	Workspace string          `json:"workspace,omitempty"`
+	Spec      json.RawMessage `json:"spec,omitempty"` // WorkspaceSpec fields to set, for create and update
+}
+
+// WorkspaceSpec holds the editable fields of a workspace
+type WorkspaceSpec struct {
+	ID           string          `json:"id,omitempty"` // for create, derived from the name if empty
+	Name         string          `json:"name"`
+	Description  string          `json:"description,omitempty"`
+	SystemPrompt string          `json:"system_prompt,omitempty"`
+	Tools        []string        `json:"tools,omitempty"`
+	Channels     []string        `json:"channels,omitempty"`
+	Members      []string        `json:"members,omitempty"`
+	Quotas       WorkspaceQuotas `json:"quotas"`
+}
+
+// WorkspaceQuotas limits the resources of a workspace. Zero means unlimited.
+type WorkspaceQuotas struct {
+	MaxClients        int `json:"max_clients"`
+	MaxSessions       int `json:"max_sessions"`
+	MaxMessagesPerDay int `json:"max_messages_per_day"`
 }
 
 // WorkspaceResponse represents a workspace response
@@ -162,33 +273,83 @@ type WorkspaceResponse struct {
 	Message    string          `json:"message,omitempty"`
 	Workspaces []WorkspaceInfo `json:"workspaces,omitempty"`
 	Workspace  *WorkspaceInfo  `json:"workspace,omitempty"`
+
+	// State is the snapshot of the workspace switched to, the baseline
+	// for its state.update diffs
+	State *StateSnapshot `json:"state,omitempty"`
 }
 
 // WorkspaceInfo represents workspace information
 type WorkspaceInfo struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+
+	Description  string          `json:"description,omitempty"`
+	SystemPrompt string          `json:"system_prompt,omitempty"`
+	Tools        []string        `json:"tools,omitempty"`
+	Channels     []string        `json:"channels,omitempty"`
+	Members      []string        `json:"members,omitempty"`
+	Quotas       WorkspaceQuotas `json:"quotas"`
+	Clients      int             `json:"clients"` // connected clients
+	CreatedAt    int64           `json:"created_at,omitempty"`
+	UpdatedAt    int64           `json:"updated_at,omitempty"`
 }
 
 // NodeRequest represents a node request
 type NodeRequest struct {
-	Action    string `json:"action,omitempty"` // list, get, notify
-	NodeID    string `json:"node_id,omitempty"`
-	ChannelID string `json:"channel_id,omitempty"`
+	Action     string          `json:"action,omitempty"` // list, get, notify, invoke
+	NodeID     string          `json:"node_id,omitempty"`
+	ChannelID  string          `json:"channel_id,omitempty"`
+	Code       string          `json:"code,omitempty"`       // pairing code, for approve and reject
+	Capability string          `json:"capability,omitempty"` // for invoke
+	Params     json.RawMessage `json:"params,omitempty"`     // for invoke
 }
 
 // NodeResponse represents a node response
 type NodeResponse struct {
-	Status  string     `json:"status"`
-	Message string     `json:"message,omitempty"`
+	Status  string          `json:"status"`
+	Message string          `json:"message,omitempty"`
 	Nodes  []NodeInfo `json:"nodes,omitempty"`
-	Node    *NodeInfo  `json:"node,omitempty"`
+	Node    *NodeInfo       `json:"node,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"` // for invoke
 }
 
 // NodeInfo represents node information
 type NodeInfo struct {
-	ID     string `json:"id"`
-	Status string `json:"status,omitempty"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name,omitempty"`
+	Type         string   `json:"type,omitempty"`
+	Status       string   `json:"status,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"`
+	Workspace    string   `json:"workspace,omitempty"`
+	PairedAt     int64    `json:"paired_at,omitempty"`
+	LastSeen     int64    `json:"last_seen,omitempty"`
+}
+
+// PairRequest is sent by an unpaired node to request pairing
+type PairRequest struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type,omitempty"` // mobile, desktop, server
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// PairingInfo describes a pending pairing request. The code is shown on
+// the node and entered by an admin to approve it.
+type PairingInfo struct {
+	Code         string   `json:"code"`
+	DeviceID     string   `json:"device_id"`
+	Name         string   `json:"name"`
+	Type         string   `json:"type,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"`
+	ExpiresAt    int64    `json:"expires_at"`
+}
+
+// PairingResult is the payload of a node.pairing event sent to the node
+// once an admin has decided on its request
+type PairingResult struct {
+	Status string `json:"status"` // approved, rejected
+	NodeID string `json:"node_id,omitempty"`
+	Token  string `json:"token,omitempty"` // present once, on approval
 }
 
 // Validate validates the connect request
@@ -265,3 +426,23 @@ func NewEvent(eventType string, channel string, data interface{}, seq int) *Even
 		Seq:     seq,
 	}
 }
+
+// SessionRequest represents sessions.export parameters
+type SessionRequest struct {
+	ID string `json:"id"` // agent session ID
+}
+
+// PresenceRequest represents presence.set and presence.get parameters
+type PresenceRequest struct {
+	Status string `json:"status,omitempty"`  // online, away, busy, or empty for automatic
+	UserID string `json:"user_id,omitempty"` // for get
+}
+
+// PresenceInfo represents the aggregated presence of a user
+type PresenceInfo struct {
+	UserID      string   `json:"user_id"`
+	Status      string   `json:"status"` // online, away, busy, offline
+	Devices     []string `json:"devices,omitempty"`
+	Connections int      `json:"connections"`
+	LastActive  int64    `json:"last_active,omitempty"`
+}
@@ -6,20 +6,57 @@ import (
 	"sync"
 )
 
-// Serializer handles message serialization and deserialization
+// Serializer handles message serialization and deserialization. Messages
+// are built as JSON; the codec converts them to and from the wire encoding.
 type Serializer struct {
 	bufferPool sync.Pool
+	codec      Codec
 }
 
-// NewSerializer creates a new serializer
+// NewSerializer creates a new serializer using the JSON wire encoding
 func NewSerializer() *Serializer {
+	return NewSerializerWithCodec(JSONCodec{})
+}
+
+// NewSerializerWithCodec creates a new serializer using the given wire encoding
+func NewSerializerWithCodec(codec Codec) *Serializer {
 	return &Serializer{
 		bufferPool: sync.Pool{
 			New: func() interface{} {
 				return new(bytes.Buffer)
 			},
 		},
+		codec: codec,
+	}
+}
+
+// Codec returns the wire encoding
+func (s *Serializer) Codec() Codec {
+	return s.codec
+}
+
+// Encode converts a JSON message to a wire frame
+func (s *Serializer) Encode(data []byte) ([]byte, error) {
+	return s.codec.Encode(data)
+}
+
+// Decode converts a wire frame to a protocol message. Binary frames are
+// MessagePack regardless of the negotiated encoding.
+func (s *Serializer) Decode(frame []byte, binary bool) (*ProtocolMessage, error) {
+	codec := s.codec
+	if binary != codec.Binary() {
+		if binary {
+			codec = MsgpackCodec{}
+		} else {
+			codec = JSONCodec{}
+		}
+	}
+
+	data, err := codec.Decode(frame)
+	if err != nil {
+		return nil, err
 	}
+	return s.Unmarshal(data)
 }
 
 // Marshal serializes a protocol message to JSON
@@ -56,7 +93,8 @@ func (s *Serializer) Marshal(msg *ProtocolMessage) ([]byte, error) {
 		data = data[:len(data)-1]
 	}
 
-	return data, nil
+	// Copy out of the pooled buffer, which is reused once returned
+	return append([]byte(nil), data...), nil
 }
 
 // Unmarshal deserializes JSON to a protocol message
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileStore is a Store persisting each collection as a JSON file in a
+// directory. Collections are loaded on open and rewritten atomically on
+// every change.
+type FileStore struct {
+	dir  string
+	data collections
+	mu   sync.RWMutex
+}
+
+// NewFileStore opens or creates a file store in dir
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("storage path is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	s := &FileStore{
+		dir:  dir,
+		data: make(collections),
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		var records map[string]json.RawMessage
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		s.data[strings.TrimSuffix(filepath.Base(file), ".json")] = records
+	}
+
+	return s, nil
+}
+
+// Get decodes a record into v
+func (s *FileStore) Get(collection, id string, v interface{}) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.get(collection, id, v)
+}
+
+// Put stores a record and rewrites its collection file
+func (s *FileStore) Put(collection, id string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.data.put(collection, id, v); err != nil {
+		return err
+	}
+	return s.flush(collection)
+}
+
+// Delete removes a record and rewrites its collection file
+func (s *FileStore) Delete(collection, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.data.remove(collection, id); err != nil {
+		return err
+	}
+	return s.flush(collection)
+}
+
+// List returns the records of a collection
+func (s *FileStore) List(collection string) (map[string]json.RawMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.list(collection), nil
+}
+
+// Close does nothing, every change is already on disk
+func (s *FileStore) Close() error {
+	return nil
+}
+
+// flush writes a collection to a temporary file and renames it into place
+func (s *FileStore) flush(collection string) error {
+	data, err := json.MarshalIndent(s.data[collection], "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.dir, collection+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", collection, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", collection, err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// MemoryStore is a Store kept in memory, for tests and ephemeral gateways
+type MemoryStore struct {
+	data collections
+	mu   sync.RWMutex
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data: make(collections),
+	}
+}
+
+// Get decodes a record into v
+func (s *MemoryStore) Get(collection, id string, v interface{}) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.get(collection, id, v)
+}
+
+// Put stores a record
+func (s *MemoryStore) Put(collection, id string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.put(collection, id, v)
+}
+
+// Delete removes a record
+func (s *MemoryStore) Delete(collection, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.remove(collection, id)
+}
+
+// List returns the records of a collection
+func (s *MemoryStore) List(collection string) (map[string]json.RawMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.list(collection), nil
+}
+
+// Close does nothing
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -1,4 +1,84 @@
 package storage
 
-// Storage backend placeholder
-// TODO: Implement SQLite storage backend
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/openclaw/go-openclaw/internal/config"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// Store is a document store of JSON records grouped in collections
+type Store interface {
+	// Get decodes the record id of a collection into v
+	Get(collection, id string, v interface{}) error
+
+	// Put stores v as the record id of a collection
+	Put(collection, id string, v interface{}) error
+
+	// Delete removes a record, returning ErrNotFound if it does not exist
+	Delete(collection, id string) error
+
+	// List returns the records of a collection keyed by ID
+	List(collection string) (map[string]json.RawMessage, error)
+
+	// Close flushes and releases the store
+	Close() error
+}
+
+// Open opens the store selected by the database configuration
+func Open(cfg *config.DatabaseConfig) (Store, error) {
+	switch cfg.Type {
+	case "file", "":
+		return NewFileStore(cfg.Path)
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", cfg.Type)
+	}
+}
+
+// collections holds the records of a store in memory
+type collections map[string]map[string]json.RawMessage
+
+// get decodes a record
+func (c collections) get(collection, id string, v interface{}) error {
+	data, ok := c[collection][id]
+	if !ok {
+		return ErrNotFound
+	}
+	return json.Unmarshal(data, v)
+}
+
+// put encodes and stores a record
+func (c collections) put(collection, id string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s/%s: %w", collection, id, err)
+	}
+	if c[collection] == nil {
+		c[collection] = make(map[string]json.RawMessage)
+	}
+	c[collection][id] = data
+	return nil
+}
+
+// remove deletes a record
+func (c collections) remove(collection, id string) error {
+	if _, ok := c[collection][id]; !ok {
+		return ErrNotFound
+	}
+	delete(c[collection], id)
+	return nil
+}
+
+// list copies the records of a collection
+func (c collections) list(collection string) map[string]json.RawMessage {
+	records := make(map[string]json.RawMessage, len(c[collection]))
+	for id, data := range c[collection] {
+		records[id] = data
+	}
+	return records
+}
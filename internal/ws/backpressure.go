@@ -0,0 +1,282 @@
+package ws
+
+import (
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// SlowConsumerPolicy decides what happens when a connection queue is full
+type SlowConsumerPolicy string
+
+const (
+	// PolicyBlock waits up to BlockTimeout for room in the queue
+	PolicyBlock SlowConsumerPolicy = "block"
+	// PolicyDropOldest discards the oldest queued message to make room
+	PolicyDropOldest SlowConsumerPolicy = "drop_oldest"
+	// PolicyDropNewest discards the new message and notifies the peer of the gap
+	PolicyDropNewest SlowConsumerPolicy = "drop_newest"
+	// PolicyDisconnect closes the connection
+	PolicyDisconnect SlowConsumerPolicy = "disconnect"
+)
+
+// Queue status values reported in QueueStats
+const (
+	StatusOK           = "ok"
+	StatusLagging      = "lagging"
+	StatusSlowConsumer = "slow_consumer"
+)
+
+var (
+	ErrConnClosed     = errors.New("connection closed")
+	ErrSendBufferFull = errors.New("send buffer full")
+	ErrSendTimeout    = errors.New("send timed out waiting for buffer space")
+	ErrSlowConsumer   = errors.New("slow consumer disconnected")
+)
+
+// BackpressureConfig contains queue sizes and the slow-consumer policy
+type BackpressureConfig struct {
+	SendBufferSize    int
+	ReceiveBufferSize int
+	Policy            SlowConsumerPolicy
+	BlockTimeout      time.Duration
+}
+
+// DefaultBackpressureConfig returns the default backpressure configuration
+func DefaultBackpressureConfig() *BackpressureConfig {
+	return &BackpressureConfig{
+		SendBufferSize:    256,
+		ReceiveBufferSize: 64,
+		Policy:            PolicyDropNewest,
+		BlockTimeout:      500 * time.Millisecond,
+	}
+}
+
+// ParsePolicy converts a policy name to a SlowConsumerPolicy, falling back
+// to PolicyDropNewest for unknown names
+func ParsePolicy(name string) SlowConsumerPolicy {
+	switch p := SlowConsumerPolicy(name); p {
+	case PolicyBlock, PolicyDropOldest, PolicyDropNewest, PolicyDisconnect:
+		return p
+	default:
+		return PolicyDropNewest
+	}
+}
+
+// connStats holds the per-connection counters
+type connStats struct {
+	sent            atomic.Uint64
+	received        atomic.Uint64
+	droppedOutgoing atomic.Uint64
+	droppedIncoming atomic.Uint64
+	gaps            atomic.Uint64
+	pendingGap      atomic.Uint64
+	slow            atomic.Bool
+}
+
+// SetPolicy changes the slow-consumer policy of the connection
+func (c *Conn) SetPolicy(policy SlowConsumerPolicy, blockTimeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policy = policy
+	if blockTimeout > 0 {
+		c.blockTimeout = blockTimeout
+	}
+}
+
+// Policy returns the slow-consumer policy of the connection
+func (c *Conn) Policy() SlowConsumerPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.policy
+}
+
+// Stats returns a snapshot of the connection queue counters
+func (c *Conn) Stats() *protocol.QueueStats {
+	return &protocol.QueueStats{
+		Policy:          string(c.Policy()),
+		Status:          c.QueueStatus(),
+		Queued:          len(c.send),
+		Capacity:        cap(c.send),
+		Sent:            c.stats.sent.Load(),
+		Received:        c.stats.received.Load(),
+		DroppedOutgoing: c.stats.droppedOutgoing.Load(),
+		DroppedIncoming: c.stats.droppedIncoming.Load(),
+		Gaps:            c.stats.gaps.Load(),
+	}
+}
+
+// QueueStatus returns ok, lagging or slow_consumer
+func (c *Conn) QueueStatus() string {
+	if c.stats.slow.Load() {
+		return StatusSlowConsumer
+	}
+	if c.stats.pendingGap.Load() > 0 || len(c.send)*4 >= cap(c.send)*3 {
+		return StatusLagging
+	}
+	return StatusOK
+}
+
+// enqueue puts an outgoing message on the send queue, applying the
+// slow-consumer policy when the queue is full
+func (c *Conn) enqueue(data []byte) error {
+	if !c.IsAlive() {
+		return ErrConnClosed
+	}
+
+	c.flushGap()
+
+	select {
+	case c.send <- data:
+		return nil
+	case <-c.ctx.Done():
+		return ErrConnClosed
+	default:
+	}
+
+	c.mu.RLock()
+	policy, timeout := c.policy, c.blockTimeout
+	c.mu.RUnlock()
+
+	switch policy {
+	case PolicyBlock:
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case c.send <- data:
+			return nil
+		case <-c.ctx.Done():
+			return ErrConnClosed
+		case <-timer.C:
+			c.stats.droppedOutgoing.Add(1)
+			return ErrSendTimeout
+		}
+
+	case PolicyDropOldest:
+		// Other writers race for the freed slot, so retry a few times
+		for i := 0; i < 4; i++ {
+			select {
+			case <-c.send:
+				c.stats.droppedOutgoing.Add(1)
+			default:
+			}
+			select {
+			case c.send <- data:
+				return nil
+			default:
+			}
+		}
+		c.stats.droppedOutgoing.Add(1)
+		return ErrSendBufferFull
+
+	case PolicyDisconnect:
+		c.stats.droppedOutgoing.Add(1)
+		c.disconnectSlow()
+		return ErrSlowConsumer
+
+	default:
+		c.stats.droppedOutgoing.Add(1)
+		c.stats.pendingGap.Add(1)
+		return ErrSendBufferFull
+	}
+}
+
+// flushGap queues a stream.gap event if messages were dropped since the
+// last one, so the peer learns where its stream has holes
+func (c *Conn) flushGap() {
+	dropped := c.stats.pendingGap.Swap(0)
+	if dropped == 0 {
+		return
+	}
+
+	data, err := c.serializer.MarshalEvent(string(protocol.EventStreamGap), &protocol.GapNotice{
+		Dropped: dropped,
+		Reason:  StatusSlowConsumer,
+	}, 0)
+	if err != nil {
+		return
+	}
+
+	select {
+	case c.send <- data:
+		c.stats.gaps.Add(1)
+	default:
+		c.stats.pendingGap.Add(dropped)
+	}
+}
+
+// deliver hands an incoming message to the receive channel, applying the
+// slow-consumer policy when the gateway is not keeping up. It returns false
+// when the read pump should stop.
+func (c *Conn) deliver(msg *protocol.ProtocolMessage) bool {
+	select {
+	case c.receive <- msg:
+		return true
+	case <-c.ctx.Done():
+		return false
+	default:
+	}
+
+	c.mu.RLock()
+	policy, timeout := c.policy, c.blockTimeout
+	c.mu.RUnlock()
+
+	switch policy {
+	case PolicyBlock:
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case c.receive <- msg:
+			return true
+		case <-c.ctx.Done():
+			return false
+		case <-timer.C:
+		}
+
+	case PolicyDropOldest:
+		select {
+		case old := <-c.receive:
+			c.rejectIncoming(old)
+		default:
+		}
+		select {
+		case c.receive <- msg:
+			return true
+		default:
+		}
+
+	case PolicyDisconnect:
+		c.stats.droppedIncoming.Add(1)
+		c.disconnectSlow()
+		return false
+	}
+
+	c.rejectIncoming(msg)
+	return true
+}
+
+// rejectIncoming counts a dropped incoming message and, for requests,
+// tells the peer so it is not left waiting for a response
+func (c *Conn) rejectIncoming(msg *protocol.ProtocolMessage) {
+	c.stats.droppedIncoming.Add(1)
+	log.Printf("Receive buffer full on %s, dropping %s %s", c.ID(), msg.Type, msg.Method)
+
+	if msg.Type == protocol.TypeReq && msg.ID != "" {
+		_ = c.WriteResponse(msg.ID, false, nil, "server busy: request dropped")
+	}
+}
+
+// disconnectSlow closes the connection because the peer cannot keep up
+func (c *Conn) disconnectSlow() {
+	if c.stats.slow.Swap(true) {
+		return
+	}
+
+	log.Printf("Slow consumer %s, disconnecting (queued=%d)", c.ID(), len(c.send))
+
+	c.CloseWithReason(websocket.CloseTryAgainLater, "slow consumer")
+}
@@ -0,0 +1,168 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// newTestConn returns the server side of a WebSocket, with a send queue
+// of two messages and its pumps not started, and the client side
+func newTestConn(t *testing.T, policy SlowConsumerPolicy) (*Conn, *websocket.Conn) {
+	t.Helper()
+
+	conns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		conns <- ws
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	conn := NewConnWithConfig(<-conns, &BackpressureConfig{
+		SendBufferSize:    2,
+		ReceiveBufferSize: 2,
+		Policy:            policy,
+		BlockTimeout:      50 * time.Millisecond,
+	})
+	t.Cleanup(func() { conn.Close() })
+	return conn, client
+}
+
+// events reads n events from the client side and returns their names
+func events(t *testing.T, client *websocket.Conn, n int) []string {
+	t.Helper()
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var names []string
+	for i := 0; i < n; i++ {
+		_, data, err := client.ReadMessage()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		var msg protocol.ProtocolMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("invalid message %s: %v", data, err)
+		}
+		names = append(names, msg.Event)
+	}
+	return names
+}
+
+func TestDropNewest(t *testing.T) {
+	conn, client := newTestConn(t, PolicyDropNewest)
+	conn.WriteEvent("a", nil, 0)
+	conn.WriteEvent("b", nil, 0)
+	if err := conn.WriteEvent("c", nil, 0); !errors.Is(err, ErrSendBufferFull) {
+		t.Errorf("write to a full queue: %v, want ErrSendBufferFull", err)
+	}
+	if stats := conn.Stats(); stats.DroppedOutgoing != 1 || stats.Status != StatusLagging {
+		t.Errorf("stats = %+v, want one drop and lagging", stats)
+	}
+
+	// The peer learns of the drop once there is room
+	conn.Start()
+	if got := strings.Join(events(t, client, 3), ","); got != "a,b,stream.gap" {
+		t.Errorf("received %s, want a,b,stream.gap", got)
+	}
+	if stats := conn.Stats(); stats.Gaps != 1 {
+		t.Errorf("stats = %+v, want one gap notice", stats)
+	}
+}
+
+func TestDropOldest(t *testing.T) {
+	conn, client := newTestConn(t, PolicyDropOldest)
+	for _, name := range []string{"a", "b", "c"} {
+		if err := conn.WriteEvent(name, nil, 0); err != nil {
+			t.Errorf("write %s: %v", name, err)
+		}
+	}
+
+	conn.Start()
+	if got := strings.Join(events(t, client, 2), ","); got != "b,c" {
+		t.Errorf("received %s, want b,c", got)
+	}
+	if stats := conn.Stats(); stats.DroppedOutgoing != 1 {
+		t.Errorf("stats = %+v, want one drop", stats)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	conn, client := newTestConn(t, PolicyBlock)
+	conn.WriteEvent("a", nil, 0)
+	conn.WriteEvent("b", nil, 0)
+
+	start := time.Now()
+	if err := conn.WriteEvent("c", nil, 0); !errors.Is(err, ErrSendTimeout) {
+		t.Errorf("write to a full queue: %v, want ErrSendTimeout", err)
+	}
+	if waited := time.Since(start); waited < 50*time.Millisecond {
+		t.Errorf("gave up after %s, before the block timeout", waited)
+	}
+
+	// A writer waiting for room gets it once the queue drains
+	done := make(chan error, 1)
+	conn.SetPolicy(PolicyBlock, 5*time.Second)
+	go func() { done <- conn.WriteEvent("d", nil, 0) }()
+	time.Sleep(20 * time.Millisecond)
+	conn.Start()
+	if err := <-done; err != nil {
+		t.Errorf("blocked write: %v", err)
+	}
+	if got := strings.Join(events(t, client, 3), ","); got != "a,b,d" {
+		t.Errorf("received %s, want a,b,d", got)
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	conn, client := newTestConn(t, PolicyDisconnect)
+	conn.WriteEvent("a", nil, 0)
+	conn.WriteEvent("b", nil, 0)
+	if err := conn.WriteEvent("c", nil, 0); !errors.Is(err, ErrSlowConsumer) {
+		t.Errorf("write to a full queue: %v, want ErrSlowConsumer", err)
+	}
+	if status := conn.QueueStatus(); status != StatusSlowConsumer {
+		t.Errorf("status = %s, want slow_consumer", status)
+	}
+	if err := conn.WriteEvent("d", nil, 0); !errors.Is(err, ErrConnClosed) {
+		t.Errorf("write after the disconnect: %v, want ErrConnClosed", err)
+	}
+
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := client.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseTryAgainLater {
+		t.Errorf("client read %v, want close 1013", err)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for name, want := range map[string]SlowConsumerPolicy{
+		"block":       PolicyBlock,
+		"drop_oldest": PolicyDropOldest,
+		"drop_newest": PolicyDropNewest,
+		"disconnect":  PolicyDisconnect,
+		"":            PolicyDropNewest,
+		"nope":        PolicyDropNewest,
+	} {
+		if got := ParsePolicy(name); got != want {
+			t.Errorf("ParsePolicy(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
@@ -22,39 +22,77 @@ const (
 	// Send pings to peer with this period. Must be less than pongWait.
 	pingPeriod = (pongWait * 9) / 10
 
-	// Maximum message size allowed from peer.
-	maxMessageSize = 8192
+	// Default maximum message size allowed from peer.
+	defaultMaxMessageSize = 8192
 )
 
 // Conn represents a WebSocket connection
 type Conn struct {
-	conn        *websocket.Conn
-	send        chan []byte
-	receive     chan *protocol.ProtocolMessage
-	connectedAt time.Time
-	lastSeen    time.Time
-	mu          sync.RWMutex
-	ctx         context.Context
-	cancel      context.CancelFunc
-	serializer  *protocol.Serializer
+	conn         *websocket.Conn
+	send         chan []byte
+	receive      chan *protocol.ProtocolMessage
+	connectedAt  time.Time
+	lastSeen     time.Time
+	mu           sync.RWMutex
+	ctx          context.Context
+	cancel       context.CancelFunc
+	serializer   *protocol.Serializer
+	policy       SlowConsumerPolicy
+	blockTimeout time.Duration
+	readLimit    int64
+	onResponse   func(msg *protocol.ProtocolMessage)
+	stats        connStats
 }
 
 // NewConn creates a new WebSocket connection wrapper
 func NewConn(wsConn *websocket.Conn) *Conn {
+	return NewConnWithConfig(wsConn, nil)
+}
+
+// NewConnWithConfig creates a new WebSocket connection wrapper with the
+// given queue sizes and slow-consumer policy
+func NewConnWithConfig(wsConn *websocket.Conn, config *BackpressureConfig) *Conn {
+	if config == nil {
+		config = DefaultBackpressureConfig()
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Conn{
-		conn:        wsConn,
-		send:        make(chan []byte, 256),
-		receive:     make(chan *protocol.ProtocolMessage, 64),
-		connectedAt: time.Now(),
-		lastSeen:    time.Now(),
-		ctx:         ctx,
-		cancel:      cancel,
-		serializer:  protocol.NewSerializer(),
+		conn:         wsConn,
+		send:         make(chan []byte, config.SendBufferSize),
+		receive:      make(chan *protocol.ProtocolMessage, config.ReceiveBufferSize),
+		connectedAt:  time.Now(),
+		lastSeen:     time.Now(),
+		ctx:          ctx,
+		cancel:       cancel,
+		serializer:   protocol.NewSerializerWithCodec(protocol.CodecForSubprotocol(wsConn.Subprotocol())),
+		policy:       config.Policy,
+		blockTimeout: config.BlockTimeout,
+		readLimit:    defaultMaxMessageSize,
+	}
+}
+
+// SetReadLimit sets the maximum size of a message read from the peer.
+// It must be called before Start.
+func (c *Conn) SetReadLimit(limit int64) {
+	if limit > 0 {
+		c.readLimit = limit
 	}
 }
 
+// SetResponseHandler routes incoming responses to handler instead of the
+// receive queue, so they are not held up behind the request being handled.
+// It must be called before Start.
+func (c *Conn) SetResponseHandler(handler func(msg *protocol.ProtocolMessage)) {
+	c.onResponse = handler
+}
+
+// Encoding returns the wire encoding negotiated during the upgrade
+func (c *Conn) Encoding() string {
+	return c.serializer.Codec().Name()
+}
+
 // SetReadDeadline sets read deadline
 func (c *Conn) SetReadDeadline(deadline time.Time) error {
 	return c.conn.SetReadDeadline(deadline)
@@ -65,16 +103,10 @@ func (c *Conn) SetWriteDeadline(deadline time.Time) error {
 	return c.conn.SetWriteDeadline(deadline)
 }
 
-// Write writes a message to the WebSocket connection
+// Write writes a message to the WebSocket connection, applying the
+// slow-consumer policy if the send queue is full
 func (c *Conn) Write(data []byte) error {
-	select {
-	case c.send <- data:
-		return nil
-	case <-c.ctx.Done():
-		return fmt.Errorf("connection closed")
-	default:
-		return fmt.Errorf("send buffer full")
-	}
+	return c.enqueue(data)
 }
 
 // WriteMessage writes a protocol message
@@ -119,14 +151,7 @@ func (c *Conn) WriteEvent(event string, data interface{}, seq int) error {
 
 // WriteRaw writes raw data to the WebSocket connection
 func (c *Conn) WriteRaw(data []byte) error {
-	select {
-	case c.send <- data:
-		return nil
-	case <-c.ctx.Done():
-		return fmt.Errorf("connection closed")
-	default:
-		return fmt.Errorf("send buffer full")
-	}
+	return c.enqueue(data)
 }
 
 // Receive returns a channel for incoming messages
@@ -146,12 +171,38 @@ func (c *Conn) Stop() {
 	c.conn.Close()
 }
 
-// Close closes the connection
+// Close closes the connection. Once the pumps have stopped the write pump
+// owns closing, and the underlying connection may already be released.
 func (c *Conn) Close() error {
+	if !c.IsAlive() {
+		return nil
+	}
 	c.cancel()
+	// fasthttp closes hijacked connections only after the handler returns,
+	// so expire the pending read to stop the read pump and the handler
+	c.conn.SetReadDeadline(time.Now())
 	return c.conn.Close()
 }
 
+// CloseWithReason sends a close frame with the given code and reason, then
+// closes the connection
+func (c *Conn) CloseWithReason(code int, reason string) error {
+	if !c.IsAlive() {
+		return nil
+	}
+	msg := websocket.FormatCloseMessage(code, reason)
+	_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+	return c.Close()
+}
+
+// Ping sends a WebSocket ping frame. The pong updates LastSeen.
+func (c *Conn) Ping() error {
+	if !c.IsAlive() {
+		return ErrConnClosed
+	}
+	return c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+}
+
 // ID returns a unique ID for this connection
 func (c *Conn) ID() string {
 	return fmt.Sprintf("conn-%d", c.connectedAt.UnixNano())
@@ -193,7 +244,7 @@ func (c *Conn) readPump() {
 		c.cancel()
 	}()
 
-	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadLimit(c.readLimit)
 	c.SetReadDeadline(time.Now().Add(pongWait))
 
 	// Setup pong handler to reset read deadline
@@ -204,7 +255,7 @@ func (c *Conn) readPump() {
 	})
 
 	for {
-		_, message, err := c.conn.ReadMessage()
+		messageType, message, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket read error: %v", err)
@@ -212,22 +263,24 @@ func (c *Conn) readPump() {
 			break
 		}
 
-		// Unmarshal message
-		msg, err := c.serializer.Unmarshal(message)
+		// Decode message
+		msg, err := c.serializer.Decode(message, messageType == websocket.BinaryMessage)
 		if err != nil {
 			log.Printf("Message unmarshal error: %v", err)
 			continue
 		}
 
 		c.UpdateLastSeen()
+		c.stats.received.Add(1)
+
+		if msg.Type == protocol.TypeRes && c.onResponse != nil {
+			c.onResponse(msg)
+			continue
+		}
 
 		// Send to receive channel
-		select {
-		case c.receive <- msg:
-		case <-c.ctx.Done():
+		if !c.deliver(msg) {
 			return
-		default:
-			log.Printf("Receive buffer full, dropping message")
 		}
 	}
 }
@@ -251,23 +304,24 @@ func (c *Conn) writePump() {
 				return
 			}
 
-			// Write message
-			w, err := c.conn.NextWriter(websocket.TextMessage)
+			// Write each message in its own frame
+			frame, err := c.serializer.Encode(message)
 			if err != nil {
-				return
+				log.Printf("Message encode error: %v", err)
+				continue
 			}
-			w.Write(message)
 
-			// Add queued messages
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
+			frameType := websocket.TextMessage
+			if c.serializer.Codec().Binary() {
+				frameType = websocket.BinaryMessage
 			}
-
-			if err := w.Close(); err != nil {
+			if err := c.conn.WriteMessage(frameType, frame); err != nil {
 				return
 			}
+			c.stats.sent.Add(1)
+
+			// Report drops now that there is room in the queue
+			c.flushGap()
 
 		case <-ticker.C:
 			// Send ping
@@ -0,0 +1,143 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/agent/tools"
+)
+
+// Button is an action offered with a message, as an inline keyboard button
+type Button struct {
+	Text string `json:"text"`
+	Data string `json:"data"` // reported when pressed, at most 64 bytes
+}
+
+// Action is a button pressed by a user
+type Action struct {
+	Channel   string `json:"channel"`
+	Target    string `json:"target"`     // as messages from the user are addressed
+	MessageID string `json:"message_id"` // message the button was attached to
+	From      string `json:"from"`       // user ID
+	FromName  string `json:"from_name,omitempty"`
+	Data      string `json:"data"`
+	Text      string `json:"text,omitempty"` // label of the button
+}
+
+// ActionHandler handles pressed buttons
+type ActionHandler func(ctx context.Context, action *Action) error
+
+// ErrActionExpired is returned by action handlers for buttons of a
+// question no longer waiting for an answer
+var ErrActionExpired = errors.New("this question has expired")
+
+// Interactive is implemented by channels that can attach buttons to their
+// messages and report presses. Send also takes buttons, as the "buttons"
+// option holding [][]Button.
+type Interactive interface {
+	// SendButtons sends a message with rows of buttons and returns its ID
+	SendButtons(ctx context.Context, target, text string, buttons [][]Button) (string, error)
+
+	// SetActionHandler sets the handler for pressed buttons
+	SetActionHandler(handler ActionHandler)
+}
+
+// Asker asks a user on a channel to choose one of options, waiting until
+// a button is pressed or the timeout
+type Asker func(ctx context.Context, channel, target, question string, options []string, timeout time.Duration) (*Action, error)
+
+// defaultAskTimeout is how long channel_ask waits for an answer by default
+const defaultAskTimeout = 5 * time.Minute
+
+type targetKey struct{}
+
+// targetValue is the channel target of an agent turn
+type targetValue struct {
+	channel, target string
+}
+
+// WithTarget returns a context for an agent turn answering a channel
+// target, for tools replying on the same chat
+func WithTarget(ctx context.Context, channel, target string) context.Context {
+	return context.WithValue(ctx, targetKey{}, targetValue{channel, target})
+}
+
+// TargetFromContext returns the channel target of an agent turn
+func TargetFromContext(ctx context.Context) (channel, target string, ok bool) {
+	v, ok := ctx.Value(targetKey{}).(targetValue)
+	return v.channel, v.target, ok
+}
+
+// RegisterTools registers an agent tool asking the user of a channel to
+// pick an answer with buttons, as to confirm an action
+func RegisterTools(registry *tools.Registry, ask Asker) error {
+	return registry.Register(&tools.Tool{
+		Name: "channel_ask",
+		Description: "Ask the user a question with buttons for the answers, such as Yes and No to confirm an action, " +
+			"and wait for their choice. Asks on the chat of the conversation unless channel and target are given.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"question": map[string]interface{}{"type": "string", "description": "Question shown above the buttons"},
+				"options": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Answers, one button each",
+				},
+				"timeout": map[string]interface{}{"type": "integer", "description": "Seconds to wait for an answer, 300 by default"},
+				"channel": map[string]interface{}{"type": "string", "description": "Channel to ask on, as telegram"},
+				"target":  map[string]interface{}{"type": "string", "description": "Chat to ask in"},
+			},
+			"required": []string{"question", "options"},
+		},
+		Handler: func(ctx context.Context, params map[string]interface{}) (*tools.ToolResult, error) {
+			question, _ := params["question"].(string)
+			if question == "" {
+				return nil, fmt.Errorf("question is required")
+			}
+			var options []string
+			if list, ok := params["options"].([]interface{}); ok {
+				for _, item := range list {
+					if option, ok := item.(string); ok && option != "" {
+						options = append(options, option)
+					}
+				}
+			}
+			if len(options) == 0 {
+				return nil, fmt.Errorf("options are required")
+			}
+
+			channel, target, ok := TargetFromContext(ctx)
+			if c, _ := params["channel"].(string); c != "" {
+				channel, target, ok = c, "", true
+			}
+			if t, _ := params["target"].(string); t != "" {
+				target = t
+			}
+			if !ok || channel == "" || target == "" {
+				return nil, fmt.Errorf("channel and target are required outside a channel conversation")
+			}
+
+			timeout := defaultAskTimeout
+			if seconds, ok := params["timeout"].(float64); ok && seconds > 0 {
+				timeout = time.Duration(seconds) * time.Second
+			}
+
+			action, err := ask(ctx, channel, target, question, options, timeout)
+			if err != nil {
+				return nil, err
+			}
+			return &tools.ToolResult{
+				Name:    "channel_ask",
+				Success: true,
+				Data: map[string]interface{}{
+					"answer":    action.Text,
+					"user_id":   action.From,
+					"user_name": action.FromName,
+				},
+			}, nil
+		},
+	})
+}
@@ -2,7 +2,12 @@ package channels
 
 import (
 	"context"
+	"sort"
+	"sync"
 	"time"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/valyala/fasthttp"
 )
 
 // MessageType represents the type of message
@@ -31,6 +36,33 @@ type Message struct {
 	Timestamp time.Time              `json:"timestamp"`
 	ReplyTo   string                 `json:"reply_to,omitempty"` // message ID being replied to
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+
+	// Attachments are the files sent with the message, downloaded by the
+	// channel
+	Attachments []*Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a file sent with a message
+type Attachment struct {
+	Type     MessageType `json:"type"` // image or file
+	Name     string      `json:"name,omitempty"`
+	MimeType string      `json:"mime_type,omitempty"`
+	Data     []byte      `json:"-"`
+}
+
+// Transcriber turns voice messages into text
+type Transcriber interface {
+	// Transcribe returns the text spoken in audio, empty if it has none
+	Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error)
+}
+
+// NopTranscriber is the Transcriber of channels without speech to text:
+// it hears nothing in any voice message
+type NopTranscriber struct{}
+
+// Transcribe returns no text
+func (NopTranscriber) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	return "", nil
 }
 
 // MessageHandler handles incoming messages
@@ -60,6 +92,52 @@ type Channel interface {
 	Status() string
 }
 
+// Stream is a reply shown while it is generated
+type Stream interface {
+	// Append adds generated text to the reply
+	Append(delta string) error
+
+	// Close completes the reply with its full text
+	Close(text string) error
+}
+
+// StreamSender is implemented by channels that can show replies as they
+// are generated; others receive the whole reply through Send
+type StreamSender interface {
+	// SendStream starts a reply to the specified target
+	SendStream(ctx context.Context, target string, options map[string]interface{}) (Stream, error)
+}
+
+// Webhook is implemented by channels receiving updates as HTTP requests
+// to the gateway, so that no other port needs to be exposed
+type Webhook interface {
+	// WebhookPath returns the path of the webhook on the gateway HTTP
+	// server, empty when the channel does not use one
+	WebhookPath() string
+
+	// ServeWebhook handles a request to the webhook path
+	ServeWebhook(ctx *fasthttp.RequestCtx)
+}
+
+// AgentControl acts on the agent session behind a channel target, for
+// commands like /reset typed in a chat
+type AgentControl interface {
+	// ResetSession starts a new conversation for the target
+	ResetSession(ctx context.Context, channel, target string) error
+
+	// SessionInfo returns the session of the target
+	SessionInfo(ctx context.Context, channel, target string) (*protocol.SessionState, error)
+
+	// ListTools returns the tools offered to the agent for the target
+	ListTools(ctx context.Context, channel, target string) ([]protocol.ToolInfo, error)
+
+	// Model returns the model of the agent, switching to model first if set
+	Model(ctx context.Context, model string) (string, error)
+
+	// AgentState returns the agent runtime as seen from the target
+	AgentState(ctx context.Context, channel, target string) (*protocol.AgentState, error)
+}
+
 // ChannelConfig represents configuration for a channel
 type ChannelConfig struct {
 	Enabled bool                   `json:"enabled"`
@@ -69,6 +147,7 @@ type ChannelConfig struct {
 // ChannelManager manages multiple channels
 type ChannelManager struct {
 	channels map[string]Channel
+	mu       sync.RWMutex
 }
 
 // NewChannelManager creates a new channel manager
@@ -80,27 +159,35 @@ func NewChannelManager() *ChannelManager {
 
 // Register registers a channel
 func (cm *ChannelManager) Register(channel Channel) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
 	cm.channels[channel.Name()] = channel
 }
 
 // Get returns a channel by name
 func (cm *ChannelManager) Get(name string) (Channel, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
 	ch, ok := cm.channels[name]
 	return ch, ok
 }
 
-// GetAll returns all registered channels
+// GetAll returns all registered channels ordered by name
 func (cm *ChannelManager) GetAll() []Channel {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
 	channels := make([]Channel, 0, len(cm.channels))
 	for _, ch := range cm.channels {
 		channels = append(channels, ch)
 	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name() < channels[j].Name() })
 	return channels
 }
 
 // StartAll starts all channels
 func (cm *ChannelManager) StartAll(ctx context.Context) error {
-	for _, ch := range cm.channels {
+	for _, ch := range cm.GetAll() {
 		if err := ch.Start(ctx); err != nil {
 			return err
 		}
@@ -110,7 +197,7 @@ func (cm *ChannelManager) StartAll(ctx context.Context) error {
 
 // StopAll stops all channels
 func (cm *ChannelManager) StopAll(ctx context.Context) error {
-	for _, ch := range cm.channels {
+	for _, ch := range cm.GetAll() {
 		if err := ch.Stop(ctx); err != nil {
 			return err
 		}
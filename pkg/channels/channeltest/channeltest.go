@@ -0,0 +1,134 @@
+// Package channeltest provides an HTTP stand-in for the API of a chat
+// platform, for testing channels without reaching the platform.
+package channeltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Request is a request made to a stand-in
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+	Form   url.Values // query, URL-encoded and multipart form values
+}
+
+// JSON decodes the body of the request into v
+func (r *Request) JSON(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Handler answers a request with a status and a body, encoded as JSON
+// unless it is a []byte
+type Handler func(r *Request) (status int, body interface{})
+
+// route is a handler for the paths ending in a suffix
+type route struct {
+	suffix  string
+	handler Handler
+}
+
+// StandIn is an HTTP server answering requests with handlers and
+// recording them. Requests no handler matches get a 404.
+type StandIn struct {
+	// URL is the base URL of the stand-in
+	URL string
+
+	mu       sync.Mutex
+	routes   []route
+	requests []*Request
+}
+
+// New starts a stand-in, closed when the test ends
+func New(t *testing.T) *StandIn {
+	t.Helper()
+	s := &StandIn{}
+	server := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(server.Close)
+	s.URL = server.URL
+	return s
+}
+
+// Handle answers the requests to paths ending in suffix with handler,
+// replacing the handler of the same suffix; an empty suffix matches every
+// path. Handlers are tried in the order they were first set.
+func (s *StandIn) Handle(suffix string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.routes {
+		if s.routes[i].suffix == suffix {
+			s.routes[i].handler = handler
+			return
+		}
+	}
+	s.routes = append(s.routes, route{suffix: suffix, handler: handler})
+}
+
+// Requests returns the requests made to paths ending in suffix, all of
+// them for an empty suffix
+func (s *StandIn) Requests(suffix string) []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var requests []*Request
+	for _, r := range s.requests {
+		if strings.HasSuffix(r.Path, suffix) {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+// Reset forgets the requests made so far
+func (s *StandIn) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+func (s *StandIn) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ParseMultipartForm(32 << 20)
+	req := &Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header,
+		Body:   body,
+		Form:   r.Form,
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	var handler Handler
+	for _, route := range s.routes {
+		if strings.HasSuffix(req.Path, route.suffix) {
+			handler = route.handler
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if handler == nil {
+		http.NotFound(w, r)
+		return
+	}
+	status, response := handler(req)
+	if raw, ok := response.([]byte); ok {
+		w.WriteHeader(status)
+		w.Write(raw)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
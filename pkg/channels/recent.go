@@ -0,0 +1,40 @@
+package channels
+
+import "sync"
+
+// recentIDCount is how many IDs RecentIDs remembers
+const recentIDCount = 1000
+
+// RecentIDs remembers the IDs of the last updates a webhook received, to
+// drop those the platform delivers again when it missed the response. The
+// zero value is ready to use.
+type RecentIDs struct {
+	mu    sync.Mutex
+	seen  map[string]struct{}
+	order []string
+}
+
+// Add records an ID, returning false if it was seen already. Updates
+// without an ID are never dropped.
+func (r *RecentIDs) Add(id string) bool {
+	if id == "" {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.seen == nil {
+		r.seen = make(map[string]struct{}, recentIDCount)
+	}
+	if _, ok := r.seen[id]; ok {
+		return false
+	}
+	if len(r.order) == recentIDCount {
+		delete(r.seen, r.order[0])
+		r.order = r.order[1:]
+	}
+	r.seen[id] = struct{}{}
+	r.order = append(r.order, id)
+	return true
+}
@@ -0,0 +1,27 @@
+package channels
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRecentIDs(t *testing.T) {
+	var r RecentIDs
+	if !r.Add("1") || r.Add("1") {
+		t.Error("update delivered twice not dropped")
+	}
+	if !r.Add("") || !r.Add("") {
+		t.Error("updates without an ID dropped")
+	}
+
+	// The oldest IDs are forgotten
+	for i := 2; i < recentIDCount+2; i++ {
+		r.Add(strconv.Itoa(i))
+	}
+	if !r.Add("1") {
+		t.Error("update forgotten long ago dropped")
+	}
+	if r.Add(strconv.Itoa(recentIDCount + 1)) {
+		t.Error("recent update not dropped")
+	}
+}
@@ -0,0 +1,413 @@
+package repl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// Config represents REPL channel configuration
+type Config struct {
+	In          io.Reader             // input, stdin if nil
+	Out         io.Writer             // output, stdout if nil
+	Target      string                // chat the REPL talks in, "local" if empty
+	User        string                // user messages come from, "user" if empty
+	Prompt      string                // input prompt, "you> " if empty
+	HistoryFile string                // file keeping input across runs, none if empty
+	Control     channels.AgentControl // runs slash commands, nil to disable them
+}
+
+// REPL is a channel reading messages from a terminal and printing replies,
+// streamed as they are generated
+type REPL struct {
+	config         *Config
+	in             *bufio.Scanner
+	out            io.Writer
+	history        []string
+	messageHandler channels.MessageHandler
+	running        bool
+	done           chan struct{}
+	outMu          sync.Mutex
+	mu             sync.RWMutex
+	cancel         context.CancelFunc
+}
+
+// Slash commands
+const helpText = `Commands:
+  /reset          start a new conversation
+  /session        show the current session
+  /tools          list the tools offered to the agent
+  /model [name]   show or switch the model
+  /history        list previous input; !! repeats the last, !N entry N
+  /help           show this help
+  /quit           leave
+End a line with \ to continue it, or enclose several lines in """.`
+
+// New creates a REPL channel
+func New(config *Config) *REPL {
+	cfg := *config
+	if cfg.In == nil {
+		cfg.In = os.Stdin
+	}
+	if cfg.Out == nil {
+		cfg.Out = os.Stdout
+	}
+	if cfg.Target == "" {
+		cfg.Target = "local"
+	}
+	if cfg.User == "" {
+		cfg.User = "user"
+	}
+	if cfg.Prompt == "" {
+		cfg.Prompt = "you> "
+	}
+
+	in := bufio.NewScanner(cfg.In)
+	in.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	return &REPL{
+		config: &cfg,
+		in:     in,
+		out:    cfg.Out,
+		done:   make(chan struct{}),
+	}
+}
+
+// Name returns channel name
+func (r *REPL) Name() string {
+	return "repl"
+}
+
+// Start starts reading input
+func (r *REPL) Start(ctx context.Context) error {
+	r.mu.Lock()
+	if r.running {
+		r.mu.Unlock()
+		return fmt.Errorf("repl is already running")
+	}
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.running = true
+	r.mu.Unlock()
+
+	r.loadHistory()
+	r.printf("OpenClaw chat, /help for commands\n")
+
+	go r.loop(ctx)
+	return nil
+}
+
+// Stop stops reading input. A read in progress ends with the input.
+func (r *REPL) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.running = false
+	return nil
+}
+
+// Done is closed when the input ends or the user quits
+func (r *REPL) Done() <-chan struct{} {
+	return r.done
+}
+
+// Send prints a message
+func (r *REPL) Send(ctx context.Context, target string, content string, options map[string]interface{}) error {
+	r.printf("agent> %s\n", content)
+	return nil
+}
+
+// SendStream prints a reply as it is generated
+func (r *REPL) SendStream(ctx context.Context, target string, options map[string]interface{}) (channels.Stream, error) {
+	r.printf("agent> ")
+	return &stream{repl: r}, nil
+}
+
+// SetMessageHandler sets handler for incoming messages
+func (r *REPL) SetMessageHandler(handler channels.MessageHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messageHandler = handler
+}
+
+// IsRunning returns true if the REPL is reading input
+func (r *REPL) IsRunning() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.running
+}
+
+// Status returns current status of the REPL
+func (r *REPL) Status() string {
+	if r.IsRunning() {
+		return "running"
+	}
+	return "stopped"
+}
+
+// loop reads input until it ends, the user quits or ctx is done. Each
+// message is answered before the next prompt.
+func (r *REPL) loop(ctx context.Context) {
+	defer close(r.done)
+	defer r.Stop(context.Background())
+
+	for ctx.Err() == nil {
+		input, ok := r.read()
+		if !ok {
+			r.printf("\n")
+			return
+		}
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+
+		// Recall history
+		if strings.HasPrefix(input, "!") {
+			recalled, err := r.recall(input)
+			if err != nil {
+				r.printf("%v\n", err)
+				continue
+			}
+			input = recalled
+			r.printf("%s%s\n", r.config.Prompt, input)
+		}
+		r.remember(input)
+
+		if strings.HasPrefix(input, "/") {
+			if quit := r.command(ctx, input); quit {
+				return
+			}
+			continue
+		}
+
+		r.mu.RLock()
+		handler := r.messageHandler
+		r.mu.RUnlock()
+		if handler == nil {
+			r.printf("no agent attached\n")
+			continue
+		}
+
+		msg := &channels.Message{
+			ID:        strconv.FormatInt(time.Now().UnixNano(), 10),
+			Channel:   r.Name(),
+			From:      r.config.User,
+			To:        r.config.Target,
+			Content:   input,
+			Type:      channels.MessageTypeText,
+			Timestamp: time.Now(),
+		}
+		if err := handler(ctx, msg); err != nil {
+			r.printf("error: %v\n", err)
+		}
+	}
+}
+
+// read reads one message, joining continued lines and """ blocks
+func (r *REPL) read() (string, bool) {
+	r.printf("%s", r.config.Prompt)
+
+	var lines []string
+	block := false
+	for r.in.Scan() {
+		line := r.in.Text()
+
+		switch {
+		case !block && strings.TrimSpace(line) == `"""`:
+			block = true
+		case block && strings.TrimSpace(line) == `"""`:
+			return strings.Join(lines, "\n"), true
+		case block:
+			lines = append(lines, line)
+		case strings.HasSuffix(line, `\`):
+			lines = append(lines, strings.TrimSuffix(line, `\`))
+		default:
+			return strings.Join(append(lines, line), "\n"), true
+		}
+		r.printf("... ")
+	}
+
+	if len(lines) > 0 {
+		return strings.Join(lines, "\n"), true
+	}
+	return "", false
+}
+
+// command runs a slash command, reporting whether the user quits
+func (r *REPL) command(ctx context.Context, input string) bool {
+	fields := strings.Fields(input)
+	name, args := fields[0], fields[1:]
+
+	control := r.config.Control
+	needsControl := func() bool {
+		if control == nil {
+			r.printf("%s is not available\n", name)
+			return false
+		}
+		return true
+	}
+
+	switch name {
+	case "/quit", "/exit":
+		return true
+
+	case "/help":
+		r.printf("%s\n", helpText)
+
+	case "/history":
+		for i, entry := range r.history {
+			r.printf("%4d  %s\n", i+1, strings.ReplaceAll(entry, "\n", "\n      "))
+		}
+
+	case "/reset":
+		if !needsControl() {
+			break
+		}
+		if err := control.ResetSession(ctx, r.Name(), r.config.Target); err != nil {
+			r.printf("error: %v\n", err)
+			break
+		}
+		r.printf("Started a new conversation\n")
+
+	case "/session":
+		if !needsControl() {
+			break
+		}
+		state, err := control.SessionInfo(ctx, r.Name(), r.config.Target)
+		if err != nil {
+			r.printf("error: %v\n", err)
+			break
+		}
+		r.printf("Session %s: %s, %s messages, started %s, last active %s\n",
+			state.ID, state.Status, state.Metadata["messages"],
+			time.Unix(state.CreatedAt, 0).Format(time.DateTime),
+			time.Unix(state.LastActive, 0).Format(time.DateTime))
+
+	case "/tools":
+		if !needsControl() {
+			break
+		}
+		tools, err := control.ListTools(ctx, r.Name(), r.config.Target)
+		if err != nil {
+			r.printf("error: %v\n", err)
+			break
+		}
+		if len(tools) == 0 {
+			r.printf("No tools offered\n")
+		}
+		for _, tool := range tools {
+			r.printf("  %-20s %s\n", tool.Name, tool.Description)
+		}
+
+	case "/model":
+		if !needsControl() {
+			break
+		}
+		model := ""
+		if len(args) > 0 {
+			model = args[0]
+		}
+		current, err := control.Model(ctx, model)
+		if err != nil {
+			r.printf("error: %v\n", err)
+			break
+		}
+		r.printf("Model: %s\n", current)
+
+	default:
+		r.printf("unknown command %s, /help for commands\n", name)
+	}
+	return false
+}
+
+// recall returns the history entry of !! or !N
+func (r *REPL) recall(input string) (string, error) {
+	if len(r.history) == 0 {
+		return "", fmt.Errorf("history is empty")
+	}
+	if input == "!!" {
+		return r.history[len(r.history)-1], nil
+	}
+
+	n, err := strconv.Atoi(input[1:])
+	if err != nil || n < 1 || n > len(r.history) {
+		return "", fmt.Errorf("no history entry %s", input[1:])
+	}
+	return r.history[n-1], nil
+}
+
+// remember adds input to the history, and to the history file if any
+func (r *REPL) remember(input string) {
+	r.history = append(r.history, input)
+	if r.config.HistoryFile == "" {
+		return
+	}
+
+	f, err := os.OpenFile(r.config.HistoryFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		log.Printf("⚠️  Failed to save history: %v", err)
+		return
+	}
+	defer f.Close()
+
+	// Entries are one per line, with newlines of multi-line input escaped
+	fmt.Fprintln(f, strconv.Quote(input))
+}
+
+// loadHistory reads the history file if any
+func (r *REPL) loadHistory() {
+	if r.config.HistoryFile == "" {
+		return
+	}
+	data, err := os.ReadFile(r.config.HistoryFile)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if entry, err := strconv.Unquote(line); err == nil {
+			r.history = append(r.history, entry)
+		}
+	}
+}
+
+// printf writes to the output
+func (r *REPL) printf(format string, args ...interface{}) {
+	r.outMu.Lock()
+	defer r.outMu.Unlock()
+	fmt.Fprintf(r.out, format, args...)
+}
+
+// stream prints a reply as deltas arrive
+type stream struct {
+	repl    *REPL
+	written bool
+}
+
+// Append prints generated text
+func (s *stream) Append(delta string) error {
+	s.written = true
+	s.repl.printf("%s", delta)
+	return nil
+}
+
+// Close ends the reply, printing it whole if nothing was streamed
+func (s *stream) Close(text string) error {
+	if !s.written {
+		s.repl.printf("%s", text)
+	}
+	s.repl.printf("\n")
+	return nil
+}
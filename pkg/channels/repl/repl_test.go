@@ -0,0 +1,173 @@
+package repl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// fakeControl is an agent control recording the sessions reset
+type fakeControl struct {
+	resets []string
+	model  string
+}
+
+func (c *fakeControl) ResetSession(ctx context.Context, channel, target string) error {
+	c.resets = append(c.resets, channel+":"+target)
+	return nil
+}
+
+func (c *fakeControl) SessionInfo(ctx context.Context, channel, target string) (*protocol.SessionState, error) {
+	return &protocol.SessionState{ID: channel + ":" + target, Status: "active", Metadata: map[string]string{"messages": "4"}}, nil
+}
+
+func (c *fakeControl) ListTools(ctx context.Context, channel, target string) ([]protocol.ToolInfo, error) {
+	return []protocol.ToolInfo{{Name: "clock", Description: "Tells the time"}}, nil
+}
+
+func (c *fakeControl) Model(ctx context.Context, model string) (string, error) {
+	if model != "" {
+		c.model = model
+	}
+	return c.model, nil
+}
+
+func (c *fakeControl) AgentState(ctx context.Context, channel, target string) (*protocol.AgentState, error) {
+	return &protocol.AgentState{Status: "running"}, nil
+}
+
+// run runs a REPL on input until it ends or the user quits, with messages
+// answered by streaming reply, and returns the output and the messages
+func run(t *testing.T, config *Config, input string, reply ...string) (string, []string) {
+	t.Helper()
+	var out strings.Builder
+	config.In = strings.NewReader(input)
+	config.Out = &out
+	r := New(config)
+
+	var messages []string
+	r.SetMessageHandler(func(ctx context.Context, msg *channels.Message) error {
+		if msg.Channel != "repl" || msg.To != r.config.Target || msg.From != r.config.User {
+			t.Errorf("message from %s to %s on %s", msg.From, msg.To, msg.Channel)
+		}
+		messages = append(messages, msg.Content)
+		stream, err := r.SendStream(ctx, msg.To, nil)
+		if err != nil {
+			return err
+		}
+		for _, delta := range reply {
+			stream.Append(delta)
+		}
+		return stream.Close(strings.Join(reply, ""))
+	})
+
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	select {
+	case <-r.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("REPL still running")
+	}
+	if r.IsRunning() {
+		t.Error("REPL running after it is done")
+	}
+	return out.String(), messages
+}
+
+func TestMessages(t *testing.T) {
+	input := "hello\n" +
+		"line one\\\nline two\n" +
+		"\"\"\"\nfirst\n\n  indented\n\"\"\"\n" +
+		"   \n" +
+		"/quit\n" +
+		"not read\n"
+	out, messages := run(t, &Config{}, input, "Hi", " there")
+
+	want := []string{"hello", "line one\nline two", "first\n\n  indented"}
+	if strings.Join(messages, "|") != strings.Join(want, "|") {
+		t.Errorf("messages = %q, want %q", messages, want)
+	}
+	if n := strings.Count(out, "agent> Hi there\n"); n != 3 {
+		t.Errorf("%d streamed replies in output:\n%s", n, out)
+	}
+	if !strings.Contains(out, "you> ... agent>") {
+		t.Errorf("continued line not prompted for:\n%s", out)
+	}
+}
+
+func TestReplyNotStreamed(t *testing.T) {
+	// Replies without deltas are printed whole when complete
+	var b strings.Builder
+	r := New(&Config{In: strings.NewReader(""), Out: &b})
+	stream, _ := r.SendStream(context.Background(), "local", nil)
+	stream.Close("whole reply")
+	r.Send(context.Background(), "local", "sent", nil)
+	if b.String() != "agent> whole reply\nagent> sent\n" {
+		t.Errorf("output = %q", b.String())
+	}
+}
+
+func TestCommands(t *testing.T) {
+	control := &fakeControl{model: "small"}
+	input := "/reset\n/model\n/model large\n/tools\n/session\n/deploy\n/help\n"
+	out, messages := run(t, &Config{Control: control, Target: "desk"}, input)
+
+	if len(messages) != 0 {
+		t.Errorf("commands sent to the agent: %q", messages)
+	}
+	if strings.Join(control.resets, ",") != "repl:desk" || control.model != "large" {
+		t.Errorf("resets %v, model %s", control.resets, control.model)
+	}
+	for _, want := range []string{
+		"Started a new conversation\n",
+		"Model: small\n",
+		"Model: large\n",
+		"clock",
+		"Session repl:desk: active, 4 messages",
+		"unknown command /deploy, /help for commands\n",
+		"/model [name]",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	// Without a control only the local commands work
+	out, _ = run(t, &Config{}, "/reset\n/help\n")
+	if !strings.Contains(out, "/reset is not available\n") || !strings.Contains(out, "/quit") {
+		t.Errorf("output without a control:\n%s", out)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+	out, messages := run(t, &Config{HistoryFile: file}, "hello\n\"\"\"\ntwo\nlines\n\"\"\"\n!!\n!1\n!9\n/history\n")
+
+	want := []string{"hello", "two\nlines", "two\nlines", "hello"}
+	if strings.Join(messages, "|") != strings.Join(want, "|") {
+		t.Errorf("messages = %q, want %q", messages, want)
+	}
+	if !strings.Contains(out, "no history entry 9\n") || !strings.Contains(out, "   2  two\n      lines\n") {
+		t.Errorf("output:\n%s", out)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 5 {
+		t.Errorf("history file has %d entries, want 5:\n%s", lines, data)
+	}
+
+	// History is kept across runs
+	_, messages = run(t, &Config{HistoryFile: file}, "!2\n")
+	if len(messages) != 1 || messages[0] != "two\nlines" {
+		t.Errorf("messages after restart = %q, want the second entry", messages)
+	}
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+	"sync"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// SendMessage sends a message to the agent and waits for the whole
+// reply. An empty channel ID uses the session of the device.
+func (c *Client) SendMessage(ctx context.Context, channelID, message string) (*protocol.AgentResponse, error) {
+	var resp protocol.AgentResponse
+	req := &protocol.AgentRequest{ChannelID: channelID, Message: message}
+	if err := c.Call(ctx, "agent.message", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StreamMessage sends a message to the agent and returns the reply as it
+// is generated. The turn is bound to ctx.
+func (c *Client) StreamMessage(ctx context.Context, channelID, message string) (*AgentStream, error) {
+	stream := &AgentStream{
+		deltas: newQueue[string](0),
+		done:   make(chan struct{}),
+	}
+	req := &protocol.AgentRequest{ChannelID: channelID, Message: message, Stream: true}
+
+	registered := make(chan struct{})
+	go func() {
+		payload, err := c.call(ctx, "agent.message", req, func(id string) {
+			c.mu.Lock()
+			c.streams[id] = stream
+			c.mu.Unlock()
+			stream.id = id
+			close(registered)
+		})
+		if stream.id != "" {
+			c.mu.Lock()
+			delete(c.streams, stream.id)
+			c.mu.Unlock()
+		}
+
+		var resp protocol.AgentResponse
+		if err == nil {
+			err = decode("agent.message", payload, &resp)
+		}
+		stream.finish(&resp, err)
+	}()
+
+	// Wait for the request to be sent, or to fail before that
+	select {
+	case <-registered:
+		return stream, nil
+	case <-stream.done:
+		return nil, stream.err
+	}
+}
+
+// ResetSession starts a new agent conversation for a channel
+func (c *Client) ResetSession(ctx context.Context, channelID string) error {
+	return c.Call(ctx, "agent.reset", &protocol.AgentRequest{ChannelID: channelID}, nil)
+}
+
+// SessionInfo returns the agent session of a channel
+func (c *Client) SessionInfo(ctx context.Context, channelID string) (*protocol.SessionState, error) {
+	var state protocol.SessionState
+	if err := c.Call(ctx, "agent.session", &protocol.AgentRequest{ChannelID: channelID}, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// ListTools returns the tools offered to the agent in the client's
+// workspace
+func (c *Client) ListTools(ctx context.Context) ([]protocol.ToolInfo, error) {
+	var resp struct {
+		Tools []protocol.ToolInfo `json:"tools"`
+	}
+	if err := c.Call(ctx, "agent.tools", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tools, nil
+}
+
+// Model returns the model of the agent, switching to model first if set,
+// which takes an admin token
+func (c *Client) Model(ctx context.Context, model string) (string, error) {
+	var resp struct {
+		Model string `json:"model"`
+	}
+	if err := c.Call(ctx, "agent.model", &protocol.AgentRequest{Model: model}, &resp); err != nil {
+		return "", err
+	}
+	return resp.Model, nil
+}
+
+// AgentStatus returns the status of the agent runtime: not_started,
+// running or stopped
+func (c *Client) AgentStatus(ctx context.Context) (string, error) {
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if err := c.Call(ctx, "agent.status", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Status, nil
+}
+
+// AgentStream is an agent reply being generated
+type AgentStream struct {
+	id     string
+	deltas *queue[string]
+	resp   *protocol.AgentResponse
+	err    error
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Next waits for the next piece of the reply. It returns io.EOF once the
+// reply is complete, or the error that ended the turn.
+func (s *AgentStream) Next(ctx context.Context) (string, error) {
+	return s.deltas.pop(ctx)
+}
+
+// Deltas iterates over the pieces of the reply until it is complete or
+// ctx is done. Err tells how it ended.
+func (s *AgentStream) Deltas(ctx context.Context) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for {
+			delta, err := s.Next(ctx)
+			if err != nil || !yield(delta) {
+				return
+			}
+		}
+	}
+}
+
+// Response waits for the turn to end and returns the whole reply
+func (s *AgentStream) Response(ctx context.Context) (*protocol.AgentResponse, error) {
+	select {
+	case <-s.done:
+		return s.resp, s.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Err returns the error that ended the turn, nil while it runs or if it
+// succeeded
+func (s *AgentStream) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// push queues a piece of the reply
+func (s *AgentStream) push(delta *protocol.AgentDelta) {
+	if delta.Delta != "" {
+		s.deltas.push(delta.Delta)
+	}
+}
+
+// fail ends the turn with an error
+func (s *AgentStream) fail(err error) {
+	s.finish(nil, err)
+}
+
+// finish ends the turn, once
+func (s *AgentStream) finish(resp *protocol.AgentResponse, err error) {
+	s.once.Do(func() {
+		s.resp, s.err = resp, err
+		if err != nil {
+			s.resp = nil
+			s.deltas.close(err)
+		} else {
+			s.deltas.close(io.EOF)
+		}
+		close(s.done)
+	})
+}
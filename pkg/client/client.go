@@ -0,0 +1,609 @@
+// Package client is a Go client for the gateway WebSocket protocol. It
+// performs the connect handshake, correlates requests with responses,
+// answers gateway requests, fans events out to subscriptions and keeps the
+// connection up, reconnecting with backoff and resuming state by seq.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// Version is the protocol version the client announces
+const Version = "0.0.1"
+
+var (
+	ErrClosed       = errors.New("client closed")
+	ErrDisconnected = errors.New("disconnected from gateway")
+)
+
+// CallError is an error returned by the gateway in response to a call
+type CallError struct {
+	Method  string
+	Message string
+}
+
+// Error implements the error interface
+func (e *CallError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Method, e.Message)
+}
+
+// Handler answers a request sent by the gateway. The result is sent as
+// the response payload; an error fails the request.
+type Handler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// Options configures a client
+type Options struct {
+	URL        string // gateway WebSocket URL, like ws://127.0.0.1:18789/ws
+	Token      string // auth token, "anonymous" if empty
+	DeviceID   string // device identifier, <hostname> if empty
+	ClientID   string
+	ClientType string // agent, node, web, mobile, cli
+	Version    string // client version, Version if empty
+	Workspace  string // workspace to join, default if empty
+	UserID     string // user presence counts for, the device if empty
+
+	// Paired nodes authenticate with the identity issued at pairing
+	NodeID       string
+	NodeToken    string
+	Capabilities []string
+
+	Timeout time.Duration // dial, handshake and write timeout, 10s if zero
+
+	// Reconnect keeps the client connected after the connection is lost,
+	// waiting MinBackoff, doubled per failed attempt up to MaxBackoff.
+	// MaxAttempts bounds the attempts per outage, 0 for no bound.
+	Reconnect   bool
+	MinBackoff  time.Duration // 500ms if zero
+	MaxBackoff  time.Duration // 30s if zero
+	MaxAttempts int
+}
+
+// frame is a protocol message as received, with raw payloads
+type frame struct {
+	Type    protocol.MessageType `json:"type"`
+	ID      string               `json:"id"`
+	Method  string               `json:"method,omitempty"`
+	Params  json.RawMessage      `json:"params,omitempty"`
+	Ok      bool                 `json:"ok,omitempty"`
+	Payload json.RawMessage      `json:"payload,omitempty"`
+	Error   string               `json:"error,omitempty"`
+	Event   string               `json:"event,omitempty"`
+	Data    json.RawMessage      `json:"data,omitempty"`
+	Seq     int                  `json:"seq,omitempty"`
+}
+
+// Client is a connection to a gateway. It is safe for concurrent use.
+type Client struct {
+	opts *Options
+
+	conn    *websocket.Conn
+	hello   *protocol.HelloPayload
+	ready   chan struct{} // closed while connected
+	writeMu sync.Mutex
+
+	nextID  atomic.Uint64
+	pending map[string]chan *frame
+	streams map[string]*AgentStream
+
+	subs     map[*Subscription]struct{}
+	handlers map[string]Handler
+	state    *stateMirror
+
+	reconnectAfter time.Duration // wait asked for by a draining gateway
+	err            error         // why the client stopped, once done is closed
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+}
+
+// Dial connects to a gateway and completes the handshake
+func Dial(ctx context.Context, opts *Options) (*Client, error) {
+	o := *opts
+	if o.Token == "" {
+		// The handshake requires a token even when auth is disabled
+		o.Token = "anonymous"
+	}
+	if o.DeviceID == "" {
+		o.DeviceID, _ = os.Hostname()
+	}
+	if o.Version == "" {
+		o.Version = Version
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = 500 * time.Millisecond
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 30 * time.Second
+	}
+
+	c := &Client{
+		opts:     &o,
+		ready:    make(chan struct{}),
+		pending:  make(map[string]chan *frame),
+		streams:  make(map[string]*AgentStream),
+		subs:     make(map[*Subscription]struct{}),
+		handlers: make(map[string]Handler),
+		state:    &stateMirror{},
+		done:     make(chan struct{}),
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+
+	// Heartbeats from the gateway are answered by default
+	c.handlers["ping"] = func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var ping protocol.PingMessage
+		json.Unmarshal(params, &ping)
+		return &protocol.PongMessage{Seq: ping.Seq}, nil
+	}
+
+	conn, err := c.connect(ctx)
+	if err != nil {
+		c.cancel()
+		return nil, err
+	}
+	c.online(conn, false)
+	go c.run(conn)
+	return c, nil
+}
+
+// Hello returns the payload of the last handshake
+func (c *Client) Hello() *protocol.HelloPayload {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hello
+}
+
+// Connected reports whether the client is connected
+func (c *Client) Connected() bool {
+	c.mu.Lock()
+	ready := c.ready
+	c.mu.Unlock()
+
+	select {
+	case <-ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// Done is closed when the client stops: closed, or disconnected without
+// reconnecting
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the client stopped, nil while it runs
+func (c *Client) Err() error {
+	select {
+	case <-c.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.err
+	default:
+		return nil
+	}
+}
+
+// Handle sets the handler of requests sent by the gateway for a method,
+// replacing any previous one. Requests without a handler are refused.
+func (c *Client) Handle(method string, handler Handler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[method] = handler
+}
+
+// Close closes the connection and stops reconnecting
+func (c *Client) Close() error {
+	c.cancel()
+
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+
+	<-c.done
+	return nil
+}
+
+// Call sends a request and waits for its response, decoding the payload
+// into result unless it is nil. While reconnecting, calls wait for the
+// connection to come back.
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	payload, err := c.call(ctx, method, params, nil)
+	if err != nil {
+		return err
+	}
+	return decode(method, payload, result)
+}
+
+// decode decodes the response payload of a method into result, unless
+// either is empty
+func decode(method string, payload json.RawMessage, result interface{}) error {
+	if result == nil || len(payload) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(payload, result); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", method, err)
+	}
+	return nil
+}
+
+// call sends a request and waits for its raw response payload. The
+// request ID is passed to register before the request is sent, so events
+// about it are not missed.
+func (c *Client) call(ctx context.Context, method string, params interface{}, register func(id string)) (json.RawMessage, error) {
+	conn, err := c.waitReady(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id := fmt.Sprintf("req-%d", c.nextID.Add(1))
+	done := make(chan *frame, 1)
+	c.mu.Lock()
+	c.pending[id] = done
+	c.mu.Unlock()
+	if register != nil {
+		register(id)
+	}
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	req := map[string]interface{}{"type": protocol.TypeReq, "id": id, "method": method}
+	if params != nil {
+		req["params"] = params
+	}
+	if err := c.write(conn, req); err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+
+	select {
+	case f, ok := <-done:
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", method, ErrDisconnected)
+		}
+		if !f.Ok {
+			return nil, &CallError{Method: method, Message: f.Error}
+		}
+		return f.Payload, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		return nil, ErrClosed
+	}
+}
+
+// waitReady waits until the client is connected and returns the
+// connection
+func (c *Client) waitReady(ctx context.Context) (*websocket.Conn, error) {
+	for {
+		c.mu.Lock()
+		ready, conn := c.ready, c.conn
+		c.mu.Unlock()
+
+		select {
+		case <-ready:
+			return conn, nil
+		default:
+		}
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.done:
+			return nil, ErrClosed
+		}
+	}
+}
+
+// write sends a message on a connection
+func (c *Client) write(conn *websocket.Conn, msg interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(c.opts.Timeout))
+	return conn.WriteJSON(msg)
+}
+
+// connect dials the gateway and performs the handshake, without starting
+// to read
+func (c *Client) connect(ctx context.Context) (*websocket.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	dialer := websocket.Dialer{HandshakeTimeout: c.opts.Timeout}
+	conn, _, err := dialer.DialContext(ctx, c.opts.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", c.opts.URL, err)
+	}
+
+	c.mu.Lock()
+	workspace := c.opts.Workspace
+	c.mu.Unlock()
+
+	req := map[string]interface{}{
+		"type":   protocol.TypeReq,
+		"id":     "connect",
+		"method": "connect",
+		"params": &protocol.ConnectRequest{
+			Token:        c.opts.Token,
+			DeviceID:     c.opts.DeviceID,
+			ClientID:     c.opts.ClientID,
+			Version:      c.opts.Version,
+			ClientType:   c.opts.ClientType,
+			Workspace:    workspace,
+			UserID:       c.opts.UserID,
+			NodeID:       c.opts.NodeID,
+			NodeToken:    c.opts.NodeToken,
+			Capabilities: c.opts.Capabilities,
+		},
+	}
+	if err := c.write(conn, req); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("handshake failed: %w", err)
+	}
+
+	deadline, _ := ctx.Deadline()
+	conn.SetReadDeadline(deadline)
+	for {
+		var f frame
+		if err := conn.ReadJSON(&f); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("handshake failed: %w", err)
+		}
+		if f.Type != protocol.TypeRes || f.ID != "connect" {
+			continue
+		}
+		if !f.Ok {
+			conn.Close()
+			return nil, fmt.Errorf("handshake failed: %s", f.Error)
+		}
+
+		var hello protocol.HelloPayload
+		if err := json.Unmarshal(f.Payload, &hello); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("handshake failed: invalid hello: %w", err)
+		}
+		conn.SetReadDeadline(time.Time{})
+
+		c.mu.Lock()
+		c.conn = conn
+		c.hello = &hello
+		c.mu.Unlock()
+		return conn, nil
+	}
+}
+
+// run reads the connection, reconnecting when it is lost until the
+// client is closed or gives up
+func (c *Client) run(conn *websocket.Conn) {
+	defer close(c.done)
+
+	for {
+		err := c.readLoop(conn)
+		c.offline(conn)
+
+		if c.ctx.Err() != nil {
+			c.stop(ErrClosed)
+			return
+		}
+		if !c.opts.Reconnect {
+			c.stop(fmt.Errorf("%w: %v", ErrDisconnected, err))
+			return
+		}
+		log.Printf("⚠️  Connection to %s lost: %v", c.opts.URL, err)
+		c.emit(&Event{Name: EventDisconnected})
+
+		conn, err = c.reconnect()
+		if err != nil {
+			c.stop(err)
+			return
+		}
+		c.online(conn, true)
+	}
+}
+
+// reconnect dials until connected, backing off between attempts
+func (c *Client) reconnect() (*websocket.Conn, error) {
+	c.mu.Lock()
+	wait := c.reconnectAfter
+	c.reconnectAfter = 0
+	c.mu.Unlock()
+
+	backoff := c.opts.MinBackoff
+	if wait <= 0 {
+		wait = backoff
+	}
+	for attempt := 1; ; attempt++ {
+		// Jitter spreads clients reconnecting to a restarted gateway
+		wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+
+		select {
+		case <-time.After(wait):
+		case <-c.ctx.Done():
+			return nil, ErrClosed
+		}
+
+		conn, err := c.connect(c.ctx)
+		if err == nil {
+			log.Printf("🔌 Reconnected to %s after %d attempt(s)", c.opts.URL, attempt)
+			return conn, nil
+		}
+		if c.ctx.Err() != nil {
+			return nil, ErrClosed
+		}
+		if c.opts.MaxAttempts > 0 && attempt >= c.opts.MaxAttempts {
+			return nil, fmt.Errorf("%w: gave up after %d attempts: %v", ErrDisconnected, attempt, err)
+		}
+
+		backoff *= 2
+		if backoff > c.opts.MaxBackoff {
+			backoff = c.opts.MaxBackoff
+		}
+		wait = backoff
+	}
+}
+
+// online marks a handshaken connection ready and resumes the mirrored
+// state from its hello
+func (c *Client) online(conn *websocket.Conn, reconnected bool) {
+	c.mu.Lock()
+	hello := c.hello
+	close(c.ready)
+	c.mu.Unlock()
+
+	resumed, missed := c.state.resume(hello.State)
+	if reconnected {
+		c.emit(&Event{Name: EventReconnected, Data: mustMarshal(&Resume{Hello: hello, Resumed: resumed, Missed: missed})})
+	}
+}
+
+// offline marks the client disconnected, failing the calls and streams
+// that waited on the lost connection
+func (c *Client) offline(conn *websocket.Conn) {
+	conn.Close()
+
+	c.mu.Lock()
+	c.ready = make(chan struct{})
+	pending := c.pending
+	c.pending = make(map[string]chan *frame)
+	streams := c.streams
+	c.streams = make(map[string]*AgentStream)
+	c.mu.Unlock()
+
+	for _, done := range pending {
+		close(done)
+	}
+	for _, stream := range streams {
+		stream.fail(ErrDisconnected)
+	}
+}
+
+// stop records why the client stopped and ends its subscriptions
+func (c *Client) stop(err error) {
+	c.cancel()
+
+	c.mu.Lock()
+	c.err = err
+	subs := c.subs
+	c.subs = make(map[*Subscription]struct{})
+	c.mu.Unlock()
+
+	for sub := range subs {
+		sub.queue.close(err)
+	}
+}
+
+// readLoop dispatches the messages of a connection until it fails
+func (c *Client) readLoop(conn *websocket.Conn) error {
+	for {
+		var f frame
+		if err := conn.ReadJSON(&f); err != nil {
+			return err
+		}
+
+		switch f.Type {
+		case protocol.TypeRes:
+			c.mu.Lock()
+			done, ok := c.pending[f.ID]
+			delete(c.pending, f.ID)
+			c.mu.Unlock()
+			if ok {
+				done <- &f
+			}
+
+		case protocol.TypeReq:
+			go c.serve(conn, &f)
+
+		case protocol.TypeEvent:
+			c.dispatch(&f)
+		}
+	}
+}
+
+// serve answers a request from the gateway
+func (c *Client) serve(conn *websocket.Conn, f *frame) {
+	c.mu.Lock()
+	handler, ok := c.handlers[f.Method]
+	c.mu.Unlock()
+
+	res := map[string]interface{}{"type": protocol.TypeRes, "id": f.ID}
+	if !ok {
+		res["ok"] = false
+		res["error"] = "method not supported: " + f.Method
+	} else if result, err := handler(c.ctx, f.Params); err != nil {
+		res["ok"] = false
+		res["error"] = err.Error()
+	} else {
+		res["ok"] = true
+		if result != nil {
+			res["payload"] = result
+		}
+	}
+
+	if err := c.write(conn, res); err != nil {
+		log.Printf("⚠️  Failed to answer %s: %v", f.Method, err)
+	}
+}
+
+// dispatch routes an event to the streams and subscriptions it concerns
+func (c *Client) dispatch(f *frame) {
+	switch protocol.EventType(f.Event) {
+	case protocol.EventAgentMessage:
+		var delta protocol.AgentDelta
+		if json.Unmarshal(f.Data, &delta) == nil && delta.RequestID != "" {
+			c.mu.Lock()
+			stream, ok := c.streams[delta.RequestID]
+			c.mu.Unlock()
+			if ok {
+				stream.push(&delta)
+			}
+		}
+
+	case protocol.EventStateUpdate:
+		if gap := c.state.apply(f.Data); gap {
+			go c.resync()
+		}
+
+	case protocol.EventGatewayDraining:
+		var notice protocol.DrainNotice
+		if json.Unmarshal(f.Data, &notice) == nil && notice.ReconnectAfter > 0 {
+			c.mu.Lock()
+			c.reconnectAfter = time.Duration(notice.ReconnectAfter) * time.Second
+			c.mu.Unlock()
+		}
+	}
+
+	c.emit(&Event{Name: f.Event, Data: f.Data, Seq: f.Seq})
+}
+
+// mustMarshal encodes a value the client builds itself
+func mustMarshal(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
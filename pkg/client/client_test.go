@@ -0,0 +1,269 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/agent/llm"
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/openclaw/go-openclaw/pkg/gateway/gatewaytest"
+)
+
+// proxy forwards TCP connections to a gateway, so tests can cut them and
+// refuse new ones as if the network were down
+type proxy struct {
+	ln       net.Listener
+	target   string
+	refuse   bool
+	conns    []net.Conn
+	accepted []time.Time
+	mu       sync.Mutex
+}
+
+// newProxy starts a proxy to a gateway, closed when the test ends
+func newProxy(t *testing.T, s *gatewaytest.Server) *proxy {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &proxy{ln: ln, target: s.Gateway.Addr().String()}
+	t.Cleanup(func() {
+		ln.Close()
+		p.cut(true)
+	})
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			p.mu.Lock()
+			p.accepted = append(p.accepted, time.Now())
+			refuse, target := p.refuse, p.target
+			p.mu.Unlock()
+			if refuse {
+				conn.Close()
+				continue
+			}
+
+			up, err := net.Dial("tcp", target)
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			p.mu.Lock()
+			p.conns = append(p.conns, conn, up)
+			p.mu.Unlock()
+			go func() {
+				io.Copy(up, conn)
+				up.Close()
+			}()
+			go func() {
+				io.Copy(conn, up)
+				conn.Close()
+			}()
+		}
+	}()
+	return p
+}
+
+// url returns the WebSocket URL of the gateway through the proxy
+func (p *proxy) url() string {
+	return "ws://" + p.ln.Addr().String() + "/ws"
+}
+
+// cut closes the forwarded connections, and refuses new ones if refuse
+// is set until cut again without
+func (p *proxy) cut(refuse bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+	p.conns = nil
+	p.refuse = refuse
+}
+
+// retarget forwards new connections to another gateway
+func (p *proxy) retarget(s *gatewaytest.Server) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.target = s.Gateway.Addr().String()
+}
+
+// attempts returns when connections were accepted
+func (p *proxy) attempts() []time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]time.Time(nil), p.accepted...)
+}
+
+// dial connects a client through a proxy, closed when the test ends
+func dial(t *testing.T, p *proxy, opts *Options) *Client {
+	t.Helper()
+	opts.URL = p.url()
+	opts.Token = "test"
+	if opts.DeviceID == "" {
+		opts.DeviceID = "sdk"
+	}
+	c, err := Dial(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// next waits for the next event of a subscription
+func next(t *testing.T, sub *Subscription) *Event {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), gatewaytest.Timeout)
+	defer cancel()
+	event, err := sub.Next(ctx)
+	if err != nil {
+		t.Fatalf("no event: %v", err)
+	}
+	return event
+}
+
+func TestReconnectResumes(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Server.SnapshotInterval = 1
+	})
+	p := newProxy(t, s)
+	c := dial(t, p, &Options{Reconnect: true, MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond})
+	sub := c.Subscribe(EventDisconnected, EventReconnected)
+
+	// While the client is away another device connects, and the gateway
+	// sends the diffs the client misses
+	watch, _ := s.Connect(t, "watch")
+	p.cut(true)
+	if event := next(t, sub); event.Name != EventDisconnected {
+		t.Fatalf("event = %s, want %s", event.Name, EventDisconnected)
+	}
+	s.Connect(t, "other")
+	watch.Expect("diff adding other", func(f *gatewaytest.Frame) bool {
+		return f.Event == "state.update" && strings.Contains(string(f.Data), `"device_id":"other"`)
+	})
+
+	p.cut(false)
+	event := next(t, sub)
+	if event.Name != EventReconnected {
+		t.Fatalf("event = %s, want %s", event.Name, EventReconnected)
+	}
+	var resume Resume
+	if err := event.Decode(&resume); err != nil {
+		t.Fatal(err)
+	}
+	if !resume.Resumed || resume.Missed == 0 {
+		t.Errorf("resume = resumed:%v missed:%d, want resumed with missed diffs", resume.Resumed, resume.Missed)
+	}
+	found := false
+	for _, client := range c.State().Clients {
+		found = found || client.DeviceID == "other"
+	}
+	if !found {
+		t.Error("state after resuming lacks the device connected meanwhile")
+	}
+	if _, err := c.AgentStatus(context.Background()); err != nil {
+		t.Errorf("call after reconnecting: %v", err)
+	}
+
+	// Coming back to another gateway does not resume
+	other := gatewaytest.NewServer(t)
+	p.retarget(other)
+	p.cut(false)
+	next(t, sub)
+	if err := next(t, sub).Decode(&resume); err != nil {
+		t.Fatal(err)
+	}
+	if resume.Resumed {
+		t.Error("state of another gateway resumed")
+	}
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	p := newProxy(t, s)
+	c := dial(t, p, &Options{
+		Reconnect:   true,
+		MinBackoff:  5 * time.Millisecond,
+		MaxBackoff:  20 * time.Millisecond,
+		MaxAttempts: 8,
+	})
+
+	p.cut(true)
+	select {
+	case <-c.Done():
+	case <-time.After(gatewaytest.Timeout):
+		t.Fatal("client still reconnecting")
+	}
+	if err := c.Err(); !errors.Is(err, ErrDisconnected) {
+		t.Errorf("Err = %v, want ErrDisconnected", err)
+	}
+
+	// The dial of Dial, then 8 attempts. Doubling without a cap would wait
+	// 320ms or more before the last.
+	attempts := p.attempts()
+	if len(attempts) != 9 {
+		t.Fatalf("got %d connections, want 9", len(attempts))
+	}
+	for i := 2; i < len(attempts); i++ {
+		if gap := attempts[i].Sub(attempts[i-1]); gap > 150*time.Millisecond {
+			t.Errorf("attempt %d after %v, over the 20ms cap", i, gap)
+		}
+	}
+}
+
+func TestPendingCallsFailOnDisconnect(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	release := make(chan struct{})
+	s.LLM.Reply = func(req *llm.Request) (string, error) {
+		<-release
+		return "late", nil
+	}
+	t.Cleanup(func() { close(release) })
+
+	p := newProxy(t, s)
+	c := dial(t, p, &Options{Reconnect: true, MinBackoff: 10 * time.Millisecond})
+	sub := c.Subscribe(EventReconnected)
+
+	ctx := context.Background()
+	called := make(chan error, 1)
+	go func() {
+		_, err := c.SendMessage(ctx, "", "hello")
+		called <- err
+	}()
+	stream, err := c.StreamMessage(ctx, "streamed", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	p.cut(false)
+
+	select {
+	case err := <-called:
+		if !errors.Is(err, ErrDisconnected) {
+			t.Errorf("pending call failed with %v, want ErrDisconnected", err)
+		}
+	case <-time.After(gatewaytest.Timeout):
+		t.Fatal("pending call still waiting")
+	}
+	if _, err := stream.Response(ctx); !errors.Is(err, ErrDisconnected) {
+		t.Errorf("stream failed with %v, want ErrDisconnected", err)
+	}
+
+	// The client itself carries on
+	next(t, sub)
+	if _, err := c.AgentStatus(ctx); err != nil {
+		t.Errorf("call after reconnecting: %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"strings"
+	"sync"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// Events the client emits itself, alongside those of the gateway
+const (
+	EventDisconnected = "client.disconnected" // the connection was lost, reconnecting
+	EventReconnected  = "client.reconnected"  // reconnected; data is a Resume
+)
+
+// subscriptionBuffer is how many events a subscription holds before it
+// drops the oldest
+const subscriptionBuffer = 1024
+
+// Event is an event pushed by the gateway, or emitted by the client
+type Event struct {
+	Name string
+	Data json.RawMessage
+	Seq  int
+}
+
+// Decode decodes the event data into v
+func (e *Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// Resume is the data of a client.reconnected event
+type Resume struct {
+	Hello *protocol.HelloPayload `json:"hello"`
+
+	// Resumed is true when the state of the new connection continues the
+	// seq of the previous one: same gateway, same workspace. Missed counts
+	// the state.update diffs sent while disconnected, which the state of
+	// the hello already includes. When false, the gateway restarted or
+	// moved the client and anything derived from earlier state is stale.
+	Resumed bool   `json:"resumed"`
+	Missed  uint64 `json:"missed"`
+}
+
+// Subscription receives the events matching its names, in order
+type Subscription struct {
+	client *Client
+	names  []string
+	queue  *queue[*Event]
+}
+
+// Subscribe returns a subscription to events. Names select events and
+// may end in * to match a prefix, like presence.*; no names select every
+// event. Slow subscriptions drop their oldest events.
+func (c *Client) Subscribe(names ...string) *Subscription {
+	sub := &Subscription{
+		client: c,
+		names:  names,
+		queue:  newQueue[*Event](subscriptionBuffer),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		sub.queue.close(c.err)
+	} else {
+		c.subs[sub] = struct{}{}
+	}
+	return sub
+}
+
+// Next waits for the next event. It fails once the subscription is
+// closed, or the client stopped.
+func (s *Subscription) Next(ctx context.Context) (*Event, error) {
+	return s.queue.pop(ctx)
+}
+
+// All iterates over events until ctx is done or the subscription ends
+func (s *Subscription) All(ctx context.Context) iter.Seq[*Event] {
+	return func(yield func(*Event) bool) {
+		for {
+			event, err := s.Next(ctx)
+			if err != nil || !yield(event) {
+				return
+			}
+		}
+	}
+}
+
+// Dropped returns how many events were dropped because the subscription
+// fell behind
+func (s *Subscription) Dropped() uint64 {
+	return s.queue.droppedCount()
+}
+
+// Close ends the subscription
+func (s *Subscription) Close() {
+	s.client.mu.Lock()
+	delete(s.client.subs, s)
+	s.client.mu.Unlock()
+
+	s.queue.close(ErrClosed)
+}
+
+// matches reports whether a subscription selects an event
+func (s *Subscription) matches(name string) bool {
+	if len(s.names) == 0 {
+		return true
+	}
+	for _, n := range s.names {
+		if prefix, ok := strings.CutSuffix(n, "*"); ok && strings.HasPrefix(name, prefix) || n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// emit delivers an event to the matching subscriptions
+func (c *Client) emit(event *Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for sub := range c.subs {
+		if sub.matches(event.Name) {
+			sub.queue.push(event)
+		}
+	}
+}
+
+// queue is an unblocking FIFO between the read loop and a consumer. Past
+// its limit it drops the oldest items; a limit of 0 keeps everything.
+type queue[T any] struct {
+	items   []T
+	limit   int
+	dropped uint64
+	err     error // set once closed
+	notify  chan struct{}
+	mu      sync.Mutex
+}
+
+// newQueue creates an empty queue
+func newQueue[T any](limit int) *queue[T] {
+	return &queue[T]{
+		limit:  limit,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// push appends an item, unless the queue is closed
+func (q *queue[T]) push(item T) {
+	q.mu.Lock()
+	if q.err != nil {
+		q.mu.Unlock()
+		return
+	}
+	q.items = append(q.items, item)
+	if q.limit > 0 && len(q.items) > q.limit {
+		q.items = q.items[1:]
+		q.dropped++
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pop waits for the next item. Items pushed before the queue was closed
+// are still returned, then its error.
+func (q *queue[T]) pop(ctx context.Context) (T, error) {
+	for {
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			item := q.items[0]
+			q.items = q.items[1:]
+			q.mu.Unlock()
+			return item, nil
+		}
+		err := q.err
+		q.mu.Unlock()
+
+		var zero T
+		if err != nil {
+			return zero, err
+		}
+		select {
+		case <-q.notify:
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// close stops the queue, failing pops with err once it is drained
+func (q *queue[T]) close(err error) {
+	if err == nil {
+		err = ErrClosed
+	}
+
+	q.mu.Lock()
+	if q.err == nil {
+		q.err = err
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// droppedCount returns how many items were dropped
+func (q *queue[T]) droppedCount() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
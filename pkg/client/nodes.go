@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// Nodes lists the paired nodes
+func (c *Client) Nodes(ctx context.Context) ([]protocol.NodeInfo, error) {
+	var resp protocol.NodeResponse
+	if err := c.Call(ctx, "node.list", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Nodes, nil
+}
+
+// Node returns a paired node
+func (c *Client) Node(ctx context.Context, id string) (*protocol.NodeInfo, error) {
+	var resp protocol.NodeResponse
+	if err := c.Call(ctx, "node.get", &protocol.NodeRequest{NodeID: id}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Node, nil
+}
+
+// RequestPairing asks to pair the client, which must connect with client
+// type node. The returned code is entered by an admin to approve it; the
+// decision arrives as a node.pairing event.
+func (c *Client) RequestPairing(ctx context.Context, req *protocol.PairRequest) (*protocol.PairingInfo, error) {
+	var info protocol.PairingInfo
+	if err := c.Call(ctx, "node.pair", req, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// PendingPairings lists the pairing requests awaiting a decision. Takes
+// an admin token.
+func (c *Client) PendingPairings(ctx context.Context) ([]*protocol.PairingInfo, error) {
+	var resp struct {
+		Pending []*protocol.PairingInfo `json:"pending"`
+	}
+	if err := c.Call(ctx, "node.pending", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Pending, nil
+}
+
+// ApprovePairing approves the pairing request with a code and returns the
+// new node. Takes an admin token.
+func (c *Client) ApprovePairing(ctx context.Context, code string) (*protocol.NodeInfo, error) {
+	var resp protocol.NodeResponse
+	if err := c.Call(ctx, "node.approve", &protocol.NodeRequest{Code: code}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Node, nil
+}
+
+// RejectPairing rejects the pairing request with a code. Takes an admin
+// token.
+func (c *Client) RejectPairing(ctx context.Context, code string) error {
+	return c.Call(ctx, "node.reject", &protocol.NodeRequest{Code: code}, nil)
+}
+
+// Unpair removes a paired node, disconnecting it. Takes an admin token.
+func (c *Client) Unpair(ctx context.Context, id string) error {
+	return c.Call(ctx, "node.unpair", &protocol.NodeRequest{NodeID: id}, nil)
+}
+
+// InvokeNode calls a capability of a node and returns its result. Takes
+// an admin token.
+func (c *Client) InvokeNode(ctx context.Context, id, capability string, params interface{}) (json.RawMessage, error) {
+	req := &protocol.NodeRequest{NodeID: id, Capability: capability}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		req.Params = data
+	}
+
+	var resp protocol.NodeResponse
+	if err := c.Call(ctx, "node.invoke", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// stateMirror keeps a copy of the workspace state, kept current by the
+// state.update diffs. Diffs carry a seq one above the previous one, so a
+// gap means diffs were missed and the state must be fetched again.
+type stateMirror struct {
+	snapshot *protocol.StateSnapshot
+	mu       sync.Mutex
+}
+
+// rawStateDiff is a state.update diff with the values still encoded
+type rawStateDiff struct {
+	Seq       uint64 `json:"seq"`
+	Workspace string `json:"workspace"`
+	Changes   []struct {
+		Op    string          `json:"op"`
+		Kind  string          `json:"kind"`
+		ID    string          `json:"id"`
+		Value json.RawMessage `json:"value,omitempty"`
+	} `json:"changes"`
+}
+
+// State returns a copy of the state of the client's workspace as of the
+// last state.update, nil before the first handshake
+func (c *Client) State() *protocol.StateSnapshot {
+	return c.state.copy()
+}
+
+// RefreshState fetches the state of the client's workspace and mirrors it
+func (c *Client) RefreshState(ctx context.Context) (*protocol.StateSnapshot, error) {
+	var snapshot protocol.StateSnapshot
+	req := &protocol.StateRequest{IncludeClients: true, IncludeSessions: true}
+	if err := c.Call(ctx, "state", req, &snapshot); err != nil {
+		return nil, err
+	}
+	c.state.reset(&snapshot)
+	return c.State(), nil
+}
+
+// resync fetches the state after a gap in the diffs
+func (c *Client) resync() {
+	ctx, cancel := context.WithTimeout(c.ctx, c.opts.Timeout)
+	defer cancel()
+
+	if _, err := c.RefreshState(ctx); err != nil {
+		log.Printf("⚠️  Failed to resync state: %v", err)
+	}
+}
+
+// reset replaces the mirrored state
+func (m *stateMirror) reset(snapshot *protocol.StateSnapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// A diff may have overtaken the response
+	if m.snapshot != nil && m.snapshot.Workspace == snapshot.Workspace && m.snapshot.Seq > snapshot.Seq {
+		return
+	}
+	m.snapshot = snapshot
+}
+
+// resume replaces the mirrored state with that of a new handshake. It
+// reports whether the new state continues the seq of the previous one,
+// on the same gateway and workspace, and how many diffs it covers that
+// were missed while disconnected.
+func (m *stateMirror) resume(snapshot *protocol.StateSnapshot) (resumed bool, missed uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prev := m.snapshot
+	m.snapshot = snapshot
+	if prev == nil || snapshot == nil ||
+		prev.GatewayID != snapshot.GatewayID ||
+		prev.Workspace != snapshot.Workspace ||
+		prev.Seq > snapshot.Seq {
+		return false, 0
+	}
+	return true, snapshot.Seq - prev.Seq
+}
+
+// apply applies a state.update diff, reporting a gap in the seq
+func (m *stateMirror) apply(data json.RawMessage) (gap bool) {
+	var diff rawStateDiff
+	if err := json.Unmarshal(data, &diff); err != nil {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.snapshot
+	switch {
+	case s == nil || diff.Workspace != s.Workspace || diff.Seq <= s.Seq:
+		// Not ours, or already in the state
+		return false
+	case diff.Seq != s.Seq+1:
+		return true
+	}
+
+	for _, change := range diff.Changes {
+		remove := change.Op == "remove"
+		switch change.Kind {
+		case "client":
+			s.Clients = applyChange(s.Clients, change.ID, change.Value, remove, func(v *protocol.ClientState) string { return v.ID })
+		case "session":
+			s.Sessions = applyChange(s.Sessions, change.ID, change.Value, remove, func(v *protocol.SessionState) string { return v.ID })
+		case "channel":
+			s.Channels = applyChange(s.Channels, change.ID, change.Value, remove, func(v *protocol.ChannelState) string { return v.Name })
+		case "agent":
+			var agent protocol.AgentState
+			if remove {
+				s.Agent = nil
+			} else if json.Unmarshal(change.Value, &agent) == nil {
+				s.Agent = &agent
+			}
+		}
+	}
+	s.Seq = diff.Seq
+	return false
+}
+
+// copy returns a copy of the mirrored state
+func (m *stateMirror) copy() *protocol.StateSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.snapshot == nil {
+		return nil
+	}
+	s := *m.snapshot
+	s.Clients = append([]*protocol.ClientState(nil), s.Clients...)
+	s.Sessions = append([]*protocol.SessionState(nil), s.Sessions...)
+	s.Channels = append([]*protocol.ChannelState(nil), s.Channels...)
+	return &s
+}
+
+// applyChange adds, replaces or removes the entry with an ID in a list.
+// Entries are replaced, never changed in place, so copies stay intact.
+func applyChange[T any](list []*T, id string, value json.RawMessage, remove bool, key func(*T) string) []*T {
+	var entry *T
+	if !remove {
+		entry = new(T)
+		if err := json.Unmarshal(value, entry); err != nil {
+			return list
+		}
+	}
+
+	out := make([]*T, 0, len(list)+1)
+	found := false
+	for _, e := range list {
+		if key(e) != id {
+			out = append(out, e)
+			continue
+		}
+		found = true
+		if entry != nil {
+			out = append(out, entry)
+		}
+	}
+	if !found && entry != nil {
+		out = append(out, entry)
+	}
+	return out
+}
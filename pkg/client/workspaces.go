@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// Workspaces lists the workspaces of the gateway
+func (c *Client) Workspaces(ctx context.Context) ([]protocol.WorkspaceInfo, error) {
+	var resp protocol.WorkspaceResponse
+	if err := c.Call(ctx, "workspace.list", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Workspaces, nil
+}
+
+// Workspace returns a workspace, the client's own if id is empty
+func (c *Client) Workspace(ctx context.Context, id string) (*protocol.WorkspaceInfo, error) {
+	return c.workspaceCall(ctx, "workspace.get", &protocol.WorkspaceRequest{Workspace: id})
+}
+
+// CreateWorkspace creates a workspace. Takes an admin token.
+func (c *Client) CreateWorkspace(ctx context.Context, spec *protocol.WorkspaceSpec) (*protocol.WorkspaceInfo, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	return c.workspaceCall(ctx, "workspace.create", &protocol.WorkspaceRequest{Spec: data})
+}
+
+// UpdateWorkspace sets fields of a workspace. Fields holds the
+// WorkspaceSpec fields to set, as a map or a struct with the same JSON
+// names; fields left out keep their value. Takes an admin token.
+func (c *Client) UpdateWorkspace(ctx context.Context, id string, fields interface{}) (*protocol.WorkspaceInfo, error) {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return c.workspaceCall(ctx, "workspace.update", &protocol.WorkspaceRequest{Workspace: id, Spec: data})
+}
+
+// DeleteWorkspace deletes a workspace without connected clients. Takes an
+// admin token.
+func (c *Client) DeleteWorkspace(ctx context.Context, id string) error {
+	return c.Call(ctx, "workspace.delete", &protocol.WorkspaceRequest{Workspace: id}, nil)
+}
+
+// SwitchWorkspace moves the client to another workspace, which it also
+// joins when reconnecting. The mirrored state is replaced by the snapshot
+// of the new workspace.
+func (c *Client) SwitchWorkspace(ctx context.Context, id string) (*protocol.WorkspaceInfo, error) {
+	var resp protocol.WorkspaceResponse
+	if err := c.Call(ctx, "workspace.switch", &protocol.WorkspaceRequest{Workspace: id}, &resp); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.opts.Workspace = id
+	c.mu.Unlock()
+
+	if resp.State == nil {
+		if _, err := c.RefreshState(ctx); err != nil {
+			return resp.Workspace, err
+		}
+		return resp.Workspace, nil
+	}
+	c.state.reset(resp.State)
+	return resp.Workspace, nil
+}
+
+// workspaceCall sends a workspace request answered with one workspace
+func (c *Client) workspaceCall(ctx context.Context, method string, req *protocol.WorkspaceRequest) (*protocol.WorkspaceInfo, error) {
+	var resp protocol.WorkspaceResponse
+	if err := c.Call(ctx, method, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Workspace, nil
+}
@@ -0,0 +1,316 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/config"
+)
+
+var (
+	ErrNotStarted = errors.New("backplane not started")
+	ErrStopped    = errors.New("backplane stopped")
+)
+
+// Message is a message delivered through the backplane
+type Message struct {
+	Topic string `json:"topic"`
+	From  string `json:"from"` // publishing node ID
+	Data  []byte `json:"data"`
+}
+
+// Handler handles a message received from another node
+type Handler func(msg *Message)
+
+// Backplane connects gateway nodes for publish/subscribe messaging
+type Backplane interface {
+	// NodeID returns the ID of the local node
+	NodeID() string
+
+	// Start joins the cluster
+	Start(ctx context.Context) error
+
+	// Stop leaves the cluster
+	Stop(ctx context.Context) error
+
+	// Publish sends data to subscribers of topic on every other node.
+	// The publishing node does not receive its own messages.
+	Publish(ctx context.Context, topic string, data []byte) error
+
+	// Subscribe registers a handler for a topic and returns a function
+	// that removes it
+	Subscribe(topic string, handler Handler) func()
+
+	// Registry returns the cluster-wide presence registry
+	Registry() Registry
+}
+
+// PresenceEntry records which node a client is connected to
+type PresenceEntry struct {
+	ClientID    string    `json:"client_id"`
+	DeviceID    string    `json:"device_id,omitempty"`
+	SessionID   string    `json:"session_id,omitempty"`
+	ClientType  string    `json:"client_type,omitempty"`
+	NodeID      string    `json:"node_id"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// SessionClaim records which node owns an agent session
+type SessionClaim struct {
+	SessionID string    `json:"session_id"`
+	NodeID    string    `json:"node_id"`
+	ClaimedAt time.Time `json:"claimed_at"`
+}
+
+// Registry tracks clients and agent session ownership across nodes
+type Registry interface {
+	// Register records a client connected to the local node
+	Register(ctx context.Context, entry *PresenceEntry) error
+
+	// Unregister removes a client of the local node
+	Unregister(ctx context.Context, clientID string) error
+
+	// Lookup returns the entry for a client
+	Lookup(clientID string) (*PresenceEntry, bool)
+
+	// LookupDevice returns the entries for all connections of a device
+	LookupDevice(deviceID string) []*PresenceEntry
+
+	// List returns all entries
+	List() []*PresenceEntry
+
+	// ClaimSession claims an agent session for the local node and returns
+	// the owning node, which differs from the local node if the session
+	// was already claimed elsewhere
+	ClaimSession(ctx context.Context, sessionID string) (string, error)
+
+	// ReleaseSession releases a session owned by the local node
+	ReleaseSession(ctx context.Context, sessionID string) error
+
+	// SessionOwner returns the node owning a session
+	SessionOwner(sessionID string) (string, bool)
+}
+
+// registry is the replicated state shared by the backplane implementations
+type registry struct {
+	entries map[string]*PresenceEntry // client ID -> entry
+	claims  map[string]*SessionClaim  // session ID -> claim
+	mu      sync.RWMutex
+}
+
+// newRegistry creates an empty registry
+func newRegistry() *registry {
+	return &registry{
+		entries: make(map[string]*PresenceEntry),
+		claims:  make(map[string]*SessionClaim),
+	}
+}
+
+// put adds or replaces an entry
+func (r *registry) put(entry *PresenceEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[entry.ClientID] = entry
+}
+
+// remove removes an entry if it belongs to nodeID
+func (r *registry) remove(clientID, nodeID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry, ok := r.entries[clientID]; ok && entry.NodeID == nodeID {
+		delete(r.entries, clientID)
+	}
+}
+
+// lookup returns an entry by client ID
+func (r *registry) lookup(clientID string) (*PresenceEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.entries[clientID]
+	return entry, ok
+}
+
+// lookupDevice returns the entries of a device
+func (r *registry) lookupDevice(deviceID string) []*PresenceEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []*PresenceEntry
+	for _, entry := range r.entries {
+		if entry.DeviceID == deviceID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// list returns all entries ordered by client ID
+func (r *registry) list() []*PresenceEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]*PresenceEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ClientID < entries[j].ClientID })
+	return entries
+}
+
+// claim applies a session claim and returns the winning claim. The
+// earliest claim wins, ties go to the lower node ID, so every node
+// converges on the same owner regardless of delivery order.
+func (r *registry) claim(c *SessionClaim) *SessionClaim {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.claims[c.SessionID]
+	if !ok || claimWins(c, current) {
+		r.claims[c.SessionID] = c
+		return c
+	}
+	return current
+}
+
+// claimWins reports whether a beats b. A node re-claiming its own
+// session keeps the original claim.
+func claimWins(a, b *SessionClaim) bool {
+	if a.NodeID == b.NodeID {
+		return false
+	}
+	if !a.ClaimedAt.Equal(b.ClaimedAt) {
+		return a.ClaimedAt.Before(b.ClaimedAt)
+	}
+	return a.NodeID < b.NodeID
+}
+
+// claimList returns all claims
+func (r *registry) claimList() []*SessionClaim {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	claims := make([]*SessionClaim, 0, len(r.claims))
+	for _, c := range r.claims {
+		claims = append(claims, c)
+	}
+	return claims
+}
+
+// release removes a claim if it belongs to nodeID
+func (r *registry) release(sessionID, nodeID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok := r.claims[sessionID]; ok && c.NodeID == nodeID {
+		delete(r.claims, sessionID)
+	}
+}
+
+// owner returns the node owning a session
+func (r *registry) owner(sessionID string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.claims[sessionID]
+	if !ok {
+		return "", false
+	}
+	return c.NodeID, true
+}
+
+// dropNode removes every entry and claim of a node
+func (r *registry) dropNode(nodeID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := 0
+	for id, entry := range r.entries {
+		if entry.NodeID == nodeID {
+			delete(r.entries, id)
+			removed++
+		}
+	}
+	for id, c := range r.claims {
+		if c.NodeID == nodeID {
+			delete(r.claims, id)
+		}
+	}
+	return removed
+}
+
+// subscriptions dispatches messages to topic handlers
+type subscriptions struct {
+	handlers map[string]map[int]Handler
+	next     int
+	mu       sync.RWMutex
+}
+
+// newSubscriptions creates an empty subscription table
+func newSubscriptions() *subscriptions {
+	return &subscriptions{
+		handlers: make(map[string]map[int]Handler),
+	}
+}
+
+// add registers a handler and returns its removal function
+func (s *subscriptions) add(topic string, handler Handler) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.next
+	s.next++
+	if s.handlers[topic] == nil {
+		s.handlers[topic] = make(map[int]Handler)
+	}
+	s.handlers[topic][id] = handler
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.handlers[topic], id)
+	}
+}
+
+// dispatch calls the handlers of the message topic
+func (s *subscriptions) dispatch(msg *Message) {
+	s.mu.RLock()
+	handlers := make([]Handler, 0, len(s.handlers[msg.Topic]))
+	for _, h := range s.handlers[msg.Topic] {
+		handlers = append(handlers, h)
+	}
+	s.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(msg)
+	}
+}
+
+// New creates the backplane selected by the cluster configuration
+func New(cfg *config.ClusterConfig) (Backplane, error) {
+	nodeID := cfg.NodeID
+	if nodeID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("node_id is required: %w", err)
+		}
+		nodeID = hostname
+	}
+
+	switch cfg.Backplane {
+	case "memory":
+		return NewMemoryHub().Join(nodeID), nil
+	case "gossip", "":
+		return NewGossipBackplane(&GossipConfig{
+			NodeID:            nodeID,
+			BindAddr:          cfg.BindAddr,
+			Peers:             cfg.Peers,
+			Secret:            cfg.Secret,
+			HeartbeatInterval: time.Duration(cfg.HeartbeatInterval) * time.Second,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported backplane: %s", cfg.Backplane)
+	}
+}
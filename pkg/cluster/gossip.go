@@ -0,0 +1,815 @@
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Envelope kinds exchanged between gossip peers
+const (
+	kindHello     = "hello"
+	kindAuth      = "auth"
+	kindSync      = "sync"
+	kindPublish   = "publish"
+	kindJoin      = "join"
+	kindLeave     = "leave"
+	kindClaim     = "claim"
+	kindRelease   = "release"
+	kindHeartbeat = "heartbeat"
+	kindBye       = "bye"
+)
+
+// maxEnvelopeSize limits a single envelope read from a peer
+const maxEnvelopeSize = 4 << 20
+
+// ListenFDEnv names the environment variable carrying an inherited peer
+// listener from a parent gateway process
+const ListenFDEnv = "OPENCLAW_GOSSIP_FD"
+
+// GossipConfig contains gossip backplane configuration
+type GossipConfig struct {
+	NodeID            string        // Unique node ID
+	BindAddr          string        // Address to accept peer connections on
+	Peers             []string      // Addresses of other nodes
+	Secret            string        // Shared secret peers must prove they know
+	HeartbeatInterval time.Duration // Interval between liveness heartbeats
+	DialTimeout       time.Duration // Timeout for connecting to a peer
+}
+
+// DefaultGossipConfig returns the default gossip configuration
+func DefaultGossipConfig() *GossipConfig {
+	return &GossipConfig{
+		BindAddr:          "0.0.0.0:18791",
+		HeartbeatInterval: 5 * time.Second,
+		DialTimeout:       5 * time.Second,
+	}
+}
+
+// envelope is the wire format between gossip peers, one JSON object per line
+type envelope struct {
+	Kind      string           `json:"kind"`
+	ID        string           `json:"id,omitempty"` // origin node ID, epoch and sequence, for de-duplication
+	From      string           `json:"from"`
+	Epoch     string           `json:"epoch,omitempty"` // random per process of the origin node
+	Nonce     string           `json:"nonce,omitempty"` // handshake challenge
+	Proof     string           `json:"proof,omitempty"` // HMAC of the challenge with the secret
+	Topic     string           `json:"topic,omitempty"`
+	Data      []byte           `json:"data,omitempty"`
+	Entry     *PresenceEntry   `json:"entry,omitempty"`
+	Claim     *SessionClaim    `json:"claim,omitempty"`
+	ClientID  string           `json:"client_id,omitempty"`
+	SessionID string           `json:"session_id,omitempty"`
+	Entries   []*PresenceEntry `json:"entries,omitempty"`
+	Claims    []*SessionClaim  `json:"claims,omitempty"`
+}
+
+// departure records a node that said goodbye or went silent, so that its
+// stale state is not taken back from other peers
+type departure struct {
+	epoch string
+	bye   bool // the process stopped; a silent one may come back
+	at    time.Time
+}
+
+// peer is a TCP connection to another node
+type peer struct {
+	conn net.Conn
+	send chan *envelope
+	done chan struct{}
+	once sync.Once
+}
+
+// close closes the peer connection
+func (p *peer) close() {
+	p.once.Do(func() {
+		close(p.done)
+		p.conn.Close()
+	})
+}
+
+// GossipBackplane is a Backplane that floods messages between gateway
+// nodes over TCP. Every node forwards what it has not seen before to its
+// other peers, so nodes only need a connected peer graph, not a full mesh.
+// The peer port carries no encryption and should stay on a private network.
+//
+// A node restarted with the same ID starts a new epoch, so its envelopes
+// are not taken for ones already seen and the state of its previous
+// process is dropped.
+type GossipBackplane struct {
+	config    *GossipConfig
+	subs      *subscriptions
+	registry  *registry
+	listener  net.Listener
+	peers     map[*peer]bool
+	epoch     string
+	seen      map[string]time.Time // envelope ID -> first seen
+	lastHeard map[string]time.Time // node ID -> last envelope
+	epochs    map[string]string    // node ID -> epoch last heard from
+	departed  map[string]departure // node ID -> how it left
+	seq       atomic.Uint64
+	mu        sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewGossipBackplane creates a new gossip backplane
+func NewGossipBackplane(config *GossipConfig) (*GossipBackplane, error) {
+	if config == nil {
+		config = DefaultGossipConfig()
+	}
+	if config.NodeID == "" {
+		return nil, fmt.Errorf("node ID is required")
+	}
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = DefaultGossipConfig().HeartbeatInterval
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = DefaultGossipConfig().DialTimeout
+	}
+
+	epoch, err := newNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &GossipBackplane{
+		config:    config,
+		subs:      newSubscriptions(),
+		registry:  newRegistry(),
+		peers:     make(map[*peer]bool),
+		epoch:     epoch[:16],
+		seen:      make(map[string]time.Time),
+		lastHeard: make(map[string]time.Time),
+		epochs:    make(map[string]string),
+		departed:  make(map[string]departure),
+		ctx:       ctx,
+		cancel:    cancel,
+	}, nil
+}
+
+// NodeID returns the ID of the local node
+func (b *GossipBackplane) NodeID() string {
+	return b.config.NodeID
+}
+
+// Addr returns the address the backplane accepts peers on
+func (b *GossipBackplane) Addr() net.Addr {
+	if b.listener == nil {
+		return nil
+	}
+	return b.listener.Addr()
+}
+
+// Start listens for peers and connects to the configured ones
+func (b *GossipBackplane) Start(ctx context.Context) error {
+	ln, err := b.listen()
+	if err != nil {
+		return err
+	}
+	b.listener = ln
+
+	log.Printf("🕸️  Gossip backplane listening on %s (node=%s)", ln.Addr(), b.config.NodeID)
+
+	b.wg.Add(1)
+	go b.acceptLoop()
+
+	for _, addr := range b.config.Peers {
+		b.wg.Add(1)
+		go b.dialLoop(addr)
+	}
+
+	b.wg.Add(1)
+	go b.heartbeatLoop()
+
+	return nil
+}
+
+// listen opens the peer listener, reusing an inherited socket if the
+// process was started by a gateway restart
+func (b *GossipBackplane) listen() (net.Listener, error) {
+	if fdStr := os.Getenv(ListenFDEnv); fdStr != "" {
+		fd, err := strconv.Atoi(fdStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", ListenFDEnv, err)
+		}
+		os.Unsetenv(ListenFDEnv)
+
+		file := os.NewFile(uintptr(fd), "openclaw-gossip")
+		defer file.Close()
+
+		ln, err := net.FileListener(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inherit gossip listener (fd=%d): %w", fd, err)
+		}
+		return ln, nil
+	}
+
+	ln, err := net.Listen("tcp", b.config.BindAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", b.config.BindAddr, err)
+	}
+	return ln, nil
+}
+
+// Listener returns the peer listener, for a restart to hand it to a new
+// gateway process
+func (b *GossipBackplane) Listener() net.Listener {
+	return b.listener
+}
+
+// Stop tells peers the node is leaving and closes all connections
+func (b *GossipBackplane) Stop(ctx context.Context) error {
+	b.broadcast(&envelope{Kind: kindBye})
+
+	// Give writers a moment to flush the goodbye
+	select {
+	case <-time.After(100 * time.Millisecond):
+	case <-ctx.Done():
+	}
+	return b.Handoff(ctx)
+}
+
+// Handoff leaves the cluster without a goodbye, for a new process that
+// took over the listener and the node ID: peers keep the node and take
+// the new process's epoch as a restart
+func (b *GossipBackplane) Handoff(ctx context.Context) error {
+	b.cancel()
+	if b.listener != nil {
+		b.listener.Close()
+	}
+
+	b.mu.Lock()
+	for p := range b.peers {
+		p.close()
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	return nil
+}
+
+// Publish sends data to subscribers of topic on every other node
+func (b *GossipBackplane) Publish(ctx context.Context, topic string, data []byte) error {
+	if b.ctx.Err() != nil {
+		return ErrStopped
+	}
+	b.broadcast(&envelope{Kind: kindPublish, Topic: topic, Data: data})
+	return nil
+}
+
+// Subscribe registers a handler for a topic
+func (b *GossipBackplane) Subscribe(topic string, handler Handler) func() {
+	return b.subs.add(topic, handler)
+}
+
+// Registry returns the replicated presence registry
+func (b *GossipBackplane) Registry() Registry {
+	return &gossipRegistry{backplane: b}
+}
+
+// PeerCount returns the number of connected peers
+func (b *GossipBackplane) PeerCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.peers)
+}
+
+// acceptLoop accepts incoming peer connections
+func (b *GossipBackplane) acceptLoop() {
+	defer b.wg.Done()
+
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			if b.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Printf("Gossip accept error: %v", err)
+			continue
+		}
+
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			b.servePeer(conn, false)
+		}()
+	}
+}
+
+// dialLoop keeps a connection to a configured peer, reconnecting with backoff
+func (b *GossipBackplane) dialLoop(addr string) {
+	defer b.wg.Done()
+
+	backoff := time.Second
+	for {
+		dialer := net.Dialer{Timeout: b.config.DialTimeout}
+		conn, err := dialer.DialContext(b.ctx, "tcp", addr)
+		if err == nil {
+			backoff = time.Second
+			b.servePeer(conn, true)
+		} else if b.ctx.Err() == nil {
+			log.Printf("Gossip dial %s failed: %v", addr, err)
+		}
+
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// servePeer runs a peer connection until it closes. dialed tells
+// whether the local node opened the connection.
+func (b *GossipBackplane) servePeer(conn net.Conn, dialed bool) {
+	p := &peer{
+		conn: conn,
+		send: make(chan *envelope, 256),
+		done: make(chan struct{}),
+	}
+	defer p.close()
+
+	reader := bufio.NewReaderSize(conn, 64*1024)
+	conn.SetDeadline(time.Now().Add(b.config.DialTimeout))
+	from, err := b.handshake(conn, reader, dialed)
+	if err != nil {
+		log.Printf("Gossip handshake with %s failed: %v", conn.RemoteAddr(), err)
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	if from == b.config.NodeID {
+		return // connected to ourselves
+	}
+
+	go b.writeLoop(p)
+
+	b.mu.Lock()
+	if b.ctx.Err() != nil {
+		b.mu.Unlock()
+		return
+	}
+	b.peers[p] = true
+	b.mu.Unlock()
+
+	log.Printf("🕸️  Gossip peer connected: %s (%s)", from, conn.RemoteAddr())
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.peers, p)
+		b.mu.Unlock()
+		log.Printf("🕸️  Gossip peer disconnected: %s", from)
+	}()
+
+	// Share everything we know so the peer catches up
+	b.sendTo(p, &envelope{
+		Kind:    kindSync,
+		From:    b.config.NodeID,
+		Epoch:   b.epoch,
+		Entries: b.registry.list(),
+		Claims:  b.registry.claimList(),
+	})
+
+	for {
+		env, err := readEnvelope(reader)
+		if err != nil {
+			return
+		}
+		b.receive(p, env)
+	}
+}
+
+// handshake authenticates a peer and returns its node ID. The secret never
+// goes over the wire: each side sends a nonce and proves it knows the
+// secret by an HMAC over the other side's nonce. The dialer speaks first,
+// so the acceptor answers nothing to connections that do not say hello.
+func (b *GossipBackplane) handshake(conn net.Conn, reader *bufio.Reader, dialed bool) (string, error) {
+	nonce, err := newNonce()
+	if err != nil {
+		return "", err
+	}
+
+	write := func(env *envelope) error {
+		data, err := json.Marshal(env)
+		if err != nil {
+			return err
+		}
+		_, err = conn.Write(append(data, '\n'))
+		return err
+	}
+	read := func(kind string) (*envelope, error) {
+		env, err := readEnvelope(reader)
+		if err != nil {
+			return nil, err
+		}
+		if env.Kind != kind {
+			return nil, fmt.Errorf("expected %s, got %s", kind, env.Kind)
+		}
+		return env, nil
+	}
+
+	if dialed {
+		if err := write(&envelope{Kind: kindHello, From: b.config.NodeID, Nonce: nonce}); err != nil {
+			return "", err
+		}
+		hello, err := read(kindHello)
+		if err != nil {
+			return "", err
+		}
+		if hello.Nonce == "" || !b.verifyProof(hello.Proof, "accept", nonce, hello.Nonce, hello.From) {
+			return "", errBadProof
+		}
+		return hello.From, write(&envelope{Kind: kindAuth, From: b.config.NodeID, Proof: b.proof("dial", hello.Nonce, nonce, b.config.NodeID)})
+	}
+
+	hello, err := read(kindHello)
+	if err != nil {
+		return "", err
+	}
+	if hello.Nonce == "" {
+		return "", errBadProof
+	}
+	if err := write(&envelope{Kind: kindHello, From: b.config.NodeID, Nonce: nonce, Proof: b.proof("accept", hello.Nonce, nonce, b.config.NodeID)}); err != nil {
+		return "", err
+	}
+	auth, err := read(kindAuth)
+	if err != nil {
+		return "", err
+	}
+	if !b.verifyProof(auth.Proof, "dial", nonce, hello.Nonce, hello.From) {
+		return "", errBadProof
+	}
+	return hello.From, nil
+}
+
+// errBadProof is returned when a peer does not prove it knows the secret
+var errBadProof = errors.New("peer did not prove the shared secret")
+
+// proof returns the HMAC by which node from, in role dial or accept,
+// answers challenge with its own nonce. The role keeps a proof from being
+// replayed in the other direction.
+func (b *GossipBackplane) proof(role, challenge, nonce, from string) string {
+	mac := hmac.New(sha256.New, []byte(b.config.Secret))
+	mac.Write([]byte(role + "\n" + challenge + "\n" + nonce + "\n" + from))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyProof checks a proof in constant time
+func (b *GossipBackplane) verifyProof(got, role, challenge, nonce, from string) bool {
+	want := b.proof(role, challenge, nonce, from)
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// newNonce returns a random handshake challenge
+func newNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// readEnvelope reads one newline-delimited envelope
+func readEnvelope(reader *bufio.Reader) (*envelope, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, chunk...)
+		if len(line) > maxEnvelopeSize {
+			return nil, fmt.Errorf("envelope exceeds %d bytes", maxEnvelopeSize)
+		}
+		if !isPrefix {
+			break
+		}
+	}
+
+	var env envelope
+	if err := json.Unmarshal(line, &env); err != nil {
+		return nil, err
+	}
+	return &env, nil
+}
+
+// writeLoop writes queued envelopes to a peer
+func (b *GossipBackplane) writeLoop(p *peer) {
+	writer := bufio.NewWriter(p.conn)
+	encoder := json.NewEncoder(writer)
+
+	for {
+		select {
+		case env := <-p.send:
+			p.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := encoder.Encode(env); err != nil {
+				p.close()
+				return
+			}
+			// Flush once the queue is empty to batch bursts
+			if len(p.send) == 0 {
+				if err := writer.Flush(); err != nil {
+					p.close()
+					return
+				}
+			}
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// sendTo queues an envelope for one peer
+func (b *GossipBackplane) sendTo(p *peer, env *envelope) {
+	select {
+	case p.send <- env:
+	case <-p.done:
+	default:
+		log.Printf("Gossip peer %s queue full, dropping %s", p.conn.RemoteAddr(), env.Kind)
+	}
+}
+
+// broadcast stamps a locally originated envelope and sends it to all peers
+func (b *GossipBackplane) broadcast(env *envelope) {
+	if b.ctx.Err() != nil {
+		return // stopped or handed off
+	}
+	env.From = b.config.NodeID
+	env.Epoch = b.epoch
+	env.ID = fmt.Sprintf("%s-%s-%d", b.config.NodeID, b.epoch, b.seq.Add(1))
+
+	b.mu.Lock()
+	b.seen[env.ID] = time.Now()
+	peers := make([]*peer, 0, len(b.peers))
+	for p := range b.peers {
+		peers = append(peers, p)
+	}
+	b.mu.Unlock()
+
+	for _, p := range peers {
+		b.sendTo(p, env)
+	}
+}
+
+// receive applies an envelope from a peer and forwards it to the others
+func (b *GossipBackplane) receive(from *peer, env *envelope) {
+	if env.From == b.config.NodeID {
+		return
+	}
+
+	// A peer may still hold state of nodes that have since left, so only
+	// the state of nodes that are live here is taken from its sync
+	if env.Kind == kindSync {
+		b.heard(env.From, env.Epoch)
+		for _, entry := range env.Entries {
+			if b.touch(entry.NodeID) {
+				b.registry.put(entry)
+			}
+		}
+		for _, c := range env.Claims {
+			if b.touch(c.NodeID) {
+				b.registry.claim(c)
+			}
+		}
+		return
+	}
+
+	b.mu.Lock()
+	if _, ok := b.seen[env.ID]; ok || env.ID == "" {
+		b.mu.Unlock()
+		return
+	}
+	b.seen[env.ID] = time.Now()
+	peers := make([]*peer, 0, len(b.peers))
+	for p := range b.peers {
+		if p != from {
+			peers = append(peers, p)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, p := range peers {
+		b.sendTo(p, env)
+	}
+
+	if env.Kind == kindBye {
+		b.forgetNode(env.From, env.Epoch, true)
+		return
+	}
+	if !b.heard(env.From, env.Epoch) {
+		return // from a process that already stopped
+	}
+
+	switch env.Kind {
+	case kindPublish:
+		b.subs.dispatch(&Message{Topic: env.Topic, From: env.From, Data: env.Data})
+	case kindJoin:
+		if env.Entry != nil {
+			b.registry.put(env.Entry)
+		}
+	case kindLeave:
+		b.registry.remove(env.ClientID, env.From)
+	case kindClaim:
+		if env.Claim != nil {
+			b.registry.claim(env.Claim)
+		}
+	case kindRelease:
+		b.registry.release(env.SessionID, env.From)
+	}
+}
+
+// heard records an envelope from a node and reports whether the node is
+// live. Envelopes of a stopped process do not bring the node back; a new
+// epoch means the node restarted, and the state of its previous process
+// is dropped.
+func (b *GossipBackplane) heard(nodeID, epoch string) bool {
+	b.mu.Lock()
+	if d, ok := b.departed[nodeID]; ok {
+		if d.bye && d.epoch == epoch {
+			b.mu.Unlock()
+			return false
+		}
+		delete(b.departed, nodeID)
+	}
+	previous, known := b.epochs[nodeID]
+	b.epochs[nodeID] = epoch
+	b.lastHeard[nodeID] = time.Now()
+	b.mu.Unlock()
+
+	if known && previous != epoch {
+		if removed := b.registry.dropNode(nodeID); removed > 0 {
+			log.Printf("🕸️  Node %s restarted, dropped %d clients of its previous process", nodeID, removed)
+		}
+	}
+	return true
+}
+
+// touch reports whether a node whose state a peer passes on may be live:
+// any node but the local one and those that departed. Nodes not heard
+// from yet are taken as alive until they go silent.
+func (b *GossipBackplane) touch(nodeID string) bool {
+	if nodeID == "" || nodeID == b.config.NodeID {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.departed[nodeID]; ok {
+		return false
+	}
+	if _, ok := b.lastHeard[nodeID]; !ok {
+		b.lastHeard[nodeID] = time.Now()
+	}
+	return true
+}
+
+// forgetNode drops the presence state of a node epoch that left or went
+// silent. A goodbye from an epoch the node has since replaced is ignored.
+func (b *GossipBackplane) forgetNode(nodeID, epoch string, bye bool) {
+	b.mu.Lock()
+	if current, ok := b.epochs[nodeID]; ok && current != epoch {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.lastHeard, nodeID)
+	delete(b.epochs, nodeID)
+	b.departed[nodeID] = departure{epoch: epoch, bye: bye, at: time.Now()}
+	b.mu.Unlock()
+
+	if removed := b.registry.dropNode(nodeID); removed > 0 {
+		log.Printf("🕸️  Dropped %d clients of node %s", removed, nodeID)
+	}
+}
+
+// heartbeatLoop announces liveness and expires silent nodes
+func (b *GossipBackplane) heartbeatLoop() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.broadcast(&envelope{Kind: kindHeartbeat})
+			b.expire()
+		case <-b.ctx.Done():
+			return
+		}
+	}
+}
+
+// expire forgets nodes not heard from in three heartbeats and prunes the
+// de-duplication cache
+func (b *GossipBackplane) expire() {
+	now := time.Now()
+	deadline := 3 * b.config.HeartbeatInterval
+
+	b.mu.Lock()
+	silent := make(map[string]string)
+	for node, heard := range b.lastHeard {
+		if now.Sub(heard) > deadline {
+			silent[node] = b.epochs[node]
+		}
+	}
+	for id, seen := range b.seen {
+		if now.Sub(seen) > 10*deadline {
+			delete(b.seen, id)
+		}
+	}
+	for node, d := range b.departed {
+		if now.Sub(d.at) > 10*deadline {
+			delete(b.departed, node)
+		}
+	}
+	b.mu.Unlock()
+
+	for node, epoch := range silent {
+		b.forgetNode(node, epoch, false)
+	}
+}
+
+// gossipRegistry is the Registry view of a GossipBackplane
+type gossipRegistry struct {
+	backplane *GossipBackplane
+}
+
+// Register records a client connected to the local node
+func (r *gossipRegistry) Register(ctx context.Context, entry *PresenceEntry) error {
+	e := *entry
+	e.NodeID = r.backplane.config.NodeID
+	r.backplane.registry.put(&e)
+	r.backplane.broadcast(&envelope{Kind: kindJoin, Entry: &e})
+	return nil
+}
+
+// Unregister removes a client of the local node
+func (r *gossipRegistry) Unregister(ctx context.Context, clientID string) error {
+	r.backplane.registry.remove(clientID, r.backplane.config.NodeID)
+	r.backplane.broadcast(&envelope{Kind: kindLeave, ClientID: clientID})
+	return nil
+}
+
+// Lookup returns the entry for a client
+func (r *gossipRegistry) Lookup(clientID string) (*PresenceEntry, bool) {
+	return r.backplane.registry.lookup(clientID)
+}
+
+// LookupDevice returns the entries for all connections of a device
+func (r *gossipRegistry) LookupDevice(deviceID string) []*PresenceEntry {
+	return r.backplane.registry.lookupDevice(deviceID)
+}
+
+// List returns all entries
+func (r *gossipRegistry) List() []*PresenceEntry {
+	return r.backplane.registry.list()
+}
+
+// ClaimSession claims an agent session for the local node. Ownership is
+// eventually consistent: a concurrent claim on another node may win once
+// both claims have propagated.
+func (r *gossipRegistry) ClaimSession(ctx context.Context, sessionID string) (string, error) {
+	claim := &SessionClaim{
+		SessionID: sessionID,
+		NodeID:    r.backplane.config.NodeID,
+		ClaimedAt: time.Now(),
+	}
+
+	winner := r.backplane.registry.claim(claim)
+	if winner == claim {
+		r.backplane.broadcast(&envelope{Kind: kindClaim, Claim: claim})
+	}
+	return winner.NodeID, nil
+}
+
+// ReleaseSession releases a session owned by the local node
+func (r *gossipRegistry) ReleaseSession(ctx context.Context, sessionID string) error {
+	r.backplane.registry.release(sessionID, r.backplane.config.NodeID)
+	r.backplane.broadcast(&envelope{Kind: kindRelease, SessionID: sessionID})
+	return nil
+}
+
+// SessionOwner returns the node owning a session
+func (r *gossipRegistry) SessionOwner(sessionID string) (string, bool) {
+	return r.backplane.registry.owner(sessionID)
+}
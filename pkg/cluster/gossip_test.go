@@ -0,0 +1,189 @@
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startGossip starts a gossip backplane on a loopback port
+func startGossip(t *testing.T, nodeID, secret string, peers ...string) *GossipBackplane {
+	t.Helper()
+	b, err := NewGossipBackplane(&GossipConfig{
+		NodeID:            nodeID,
+		BindAddr:          "127.0.0.1:0",
+		Peers:             peers,
+		Secret:            secret,
+		HeartbeatInterval: time.Second,
+		DialTimeout:       time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewGossipBackplane: %v", err)
+	}
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { b.Stop(context.Background()) })
+	return b
+}
+
+// waitPeers waits until a backplane has n peers
+func waitPeers(t *testing.T, b *GossipBackplane, n int) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for b.PeerCount() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s has %d peers, want %d", b.NodeID(), b.PeerCount(), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGossipHandshake(t *testing.T) {
+	a := startGossip(t, "a", "s3cret")
+	b := startGossip(t, "b", "s3cret", a.Addr().String())
+	waitPeers(t, a, 1)
+	waitPeers(t, b, 1)
+
+	// A node with another secret is refused both ways
+	c := startGossip(t, "c", "other", a.Addr().String())
+	time.Sleep(200 * time.Millisecond)
+	if n := c.PeerCount(); n != 0 {
+		t.Errorf("node with wrong secret has %d peers", n)
+	}
+	waitPeers(t, a, 1)
+}
+
+func TestGossipAcceptorWaitsForHello(t *testing.T) {
+	a := startGossip(t, "a", "s3cret")
+
+	conn, err := net.Dial("tcp", a.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	// Nothing is sent before the dialer says hello
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if line, err := reader.ReadString('\n'); err == nil {
+		t.Fatalf("acceptor sent %q before hello", line)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	conn.Write([]byte(`{"kind":"hello","from":"x","nonce":"00"}` + "\n"))
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("no hello from acceptor: %v", err)
+	}
+	if strings.Contains(line, "s3cret") {
+		t.Fatalf("acceptor hello carries the secret: %s", line)
+	}
+	var hello envelope
+	if err := json.Unmarshal([]byte(line), &hello); err != nil || hello.Proof == "" {
+		t.Fatalf("acceptor hello = %s, want a proof", line)
+	}
+
+	// A wrong proof closes the connection without joining
+	conn.Write([]byte(`{"kind":"auth","from":"x","proof":"00"}` + "\n"))
+	if _, err := reader.ReadString('\n'); err == nil {
+		t.Error("connection still open after a wrong proof")
+	}
+	if n := a.PeerCount(); n != 0 {
+		t.Errorf("acceptor has %d peers after a wrong proof", n)
+	}
+}
+
+// waitFor waits until cond holds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGossipRestart(t *testing.T) {
+	ctx := context.Background()
+	a := startGossip(t, "a", "s3cret")
+	received := make(chan string, 10)
+	a.Subscribe("topic", func(msg *Message) { received <- string(msg.Data) })
+
+	// publish publishes from b until a receives it
+	publish := func(b *GossipBackplane, data string) {
+		t.Helper()
+		b.Publish(ctx, "topic", []byte(data))
+		select {
+		case got := <-received:
+			if got != data {
+				t.Fatalf("a received %q, want %q", got, data)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("a did not receive %q", data)
+		}
+	}
+
+	b := startGossip(t, "b", "s3cret", a.Addr().String())
+	waitPeers(t, a, 1)
+	b.Registry().Register(ctx, &PresenceEntry{ClientID: "b/1"})
+	waitFor(t, "the client of b", func() bool { _, ok := a.Registry().Lookup("b/1"); return ok })
+	publish(b, "before")
+	b.Stop(ctx)
+	waitFor(t, "b to leave", func() bool { _, ok := a.Registry().Lookup("b/1"); return !ok })
+
+	// The restarted node numbers its envelopes from the start again, in a
+	// new epoch
+	b = startGossip(t, "b", "s3cret", a.Addr().String())
+	waitPeers(t, b, 1)
+	publish(b, "after")
+	b.Registry().Register(ctx, &PresenceEntry{ClientID: "b/2"})
+	waitFor(t, "the client of the restarted b", func() bool { _, ok := a.Registry().Lookup("b/2"); return ok })
+	if owner, _ := b.Registry().ClaimSession(ctx, "chat"); owner != "b" {
+		t.Fatalf("session claimed by %s, want b", owner)
+	}
+	waitFor(t, "the claim of the restarted b", func() bool { owner, _ := a.Registry().SessionOwner("chat"); return owner == "b" })
+}
+
+func TestGossipSyncSkipsDepartedNodes(t *testing.T) {
+	a := startGossip(t, "a", "s3cret")
+	a.forgetNode("x", "", true)
+
+	// b still holds state of x, which a has seen leave
+	b, err := NewGossipBackplane(&GossipConfig{
+		NodeID:            "b",
+		BindAddr:          "127.0.0.1:0",
+		Peers:             []string{a.Addr().String()},
+		Secret:            "s3cret",
+		HeartbeatInterval: time.Second,
+		DialTimeout:       time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.registry.put(&PresenceEntry{ClientID: "x/1", NodeID: "x"})
+	b.registry.put(&PresenceEntry{ClientID: "b/1", NodeID: "b"})
+	b.registry.claim(&SessionClaim{SessionID: "chat", NodeID: "x", ClaimedAt: time.Now()})
+	b.registry.put(&PresenceEntry{ClientID: "a/1", NodeID: "a"})
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Stop(context.Background()) })
+
+	waitFor(t, "the sync of b", func() bool { _, ok := a.Registry().Lookup("b/1"); return ok })
+	if _, ok := a.Registry().Lookup("x/1"); ok {
+		t.Error("client of a departed node taken from a sync")
+	}
+	if owner, ok := a.Registry().SessionOwner("chat"); ok {
+		t.Errorf("session claim of a departed node taken from a sync, owner %s", owner)
+	}
+	if _, ok := a.Registry().Lookup("a/1"); ok {
+		t.Error("stale client of the local node taken from a sync")
+	}
+}
@@ -0,0 +1,165 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryHub connects in-process backplanes, for tests and single-binary
+// setups running several gateways
+type MemoryHub struct {
+	nodes    map[string]*MemoryBackplane
+	registry *registry
+	mu       sync.RWMutex
+}
+
+// NewMemoryHub creates a new in-memory hub
+func NewMemoryHub() *MemoryHub {
+	return &MemoryHub{
+		nodes:    make(map[string]*MemoryBackplane),
+		registry: newRegistry(),
+	}
+}
+
+// Join creates a backplane for a node attached to the hub
+func (h *MemoryHub) Join(nodeID string) *MemoryBackplane {
+	return &MemoryBackplane{
+		hub:    h,
+		nodeID: nodeID,
+		subs:   newSubscriptions(),
+	}
+}
+
+// MemoryBackplane is an in-memory Backplane
+type MemoryBackplane struct {
+	hub     *MemoryHub
+	nodeID  string
+	subs    *subscriptions
+	running bool
+	mu      sync.RWMutex
+}
+
+// NodeID returns the ID of the local node
+func (b *MemoryBackplane) NodeID() string {
+	return b.nodeID
+}
+
+// Start attaches the node to the hub
+func (b *MemoryBackplane) Start(ctx context.Context) error {
+	b.mu.Lock()
+	b.running = true
+	b.mu.Unlock()
+
+	b.hub.mu.Lock()
+	b.hub.nodes[b.nodeID] = b
+	b.hub.mu.Unlock()
+	return nil
+}
+
+// Stop detaches the node from the hub and drops its presence state
+func (b *MemoryBackplane) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	b.running = false
+	b.mu.Unlock()
+
+	b.hub.mu.Lock()
+	delete(b.hub.nodes, b.nodeID)
+	b.hub.mu.Unlock()
+
+	b.hub.registry.dropNode(b.nodeID)
+	return nil
+}
+
+// Publish delivers data to subscribers on the other attached nodes
+func (b *MemoryBackplane) Publish(ctx context.Context, topic string, data []byte) error {
+	if !b.isRunning() {
+		return ErrNotStarted
+	}
+
+	b.hub.mu.RLock()
+	nodes := make([]*MemoryBackplane, 0, len(b.hub.nodes))
+	for id, node := range b.hub.nodes {
+		if id != b.nodeID {
+			nodes = append(nodes, node)
+		}
+	}
+	b.hub.mu.RUnlock()
+
+	for _, node := range nodes {
+		node.subs.dispatch(&Message{Topic: topic, From: b.nodeID, Data: data})
+	}
+	return nil
+}
+
+// Subscribe registers a handler for a topic
+func (b *MemoryBackplane) Subscribe(topic string, handler Handler) func() {
+	return b.subs.add(topic, handler)
+}
+
+// Registry returns the hub-wide presence registry
+func (b *MemoryBackplane) Registry() Registry {
+	return &memoryRegistry{backplane: b}
+}
+
+// isRunning returns true between Start and Stop
+func (b *MemoryBackplane) isRunning() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.running
+}
+
+// memoryRegistry is the Registry view of a MemoryBackplane node
+type memoryRegistry struct {
+	backplane *MemoryBackplane
+}
+
+// Register records a client connected to the local node
+func (r *memoryRegistry) Register(ctx context.Context, entry *PresenceEntry) error {
+	e := *entry
+	e.NodeID = r.backplane.nodeID
+	r.backplane.hub.registry.put(&e)
+	return nil
+}
+
+// Unregister removes a client of the local node
+func (r *memoryRegistry) Unregister(ctx context.Context, clientID string) error {
+	r.backplane.hub.registry.remove(clientID, r.backplane.nodeID)
+	return nil
+}
+
+// Lookup returns the entry for a client
+func (r *memoryRegistry) Lookup(clientID string) (*PresenceEntry, bool) {
+	return r.backplane.hub.registry.lookup(clientID)
+}
+
+// LookupDevice returns the entries for all connections of a device
+func (r *memoryRegistry) LookupDevice(deviceID string) []*PresenceEntry {
+	return r.backplane.hub.registry.lookupDevice(deviceID)
+}
+
+// List returns all entries
+func (r *memoryRegistry) List() []*PresenceEntry {
+	return r.backplane.hub.registry.list()
+}
+
+// ClaimSession claims an agent session for the local node
+func (r *memoryRegistry) ClaimSession(ctx context.Context, sessionID string) (string, error) {
+	winner := r.backplane.hub.registry.claim(&SessionClaim{
+		SessionID: sessionID,
+		NodeID:    r.backplane.nodeID,
+		ClaimedAt: time.Now(),
+	})
+	return winner.NodeID, nil
+}
+
+// ReleaseSession releases a session owned by the local node
+func (r *memoryRegistry) ReleaseSession(ctx context.Context, sessionID string) error {
+	r.backplane.hub.registry.release(sessionID, r.backplane.nodeID)
+	return nil
+}
+
+// SessionOwner returns the node owning a session
+func (r *memoryRegistry) SessionOwner(sessionID string) (string, bool) {
+	return r.backplane.hub.registry.owner(sessionID)
+}
@@ -0,0 +1,208 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/pkg/workspaces"
+)
+
+// ResetSession starts a new agent conversation for a channel target
+func (g *Gateway) ResetSession(ctx context.Context, channel, target string) error {
+	return g.resetSession(g.WorkspaceForChannel(channel, target), channelSession(channel, target))
+}
+
+// SessionInfo returns the agent session of a channel target
+func (g *Gateway) SessionInfo(ctx context.Context, channel, target string) (*protocol.SessionState, error) {
+	return g.sessionInfo(g.WorkspaceForChannel(channel, target), channelSession(channel, target))
+}
+
+// ListTools returns the tools offered to the agent for a channel target
+func (g *Gateway) ListTools(ctx context.Context, channel, target string) ([]protocol.ToolInfo, error) {
+	return g.listTools(g.WorkspaceForChannel(channel, target))
+}
+
+// Model returns the model of the agent, switching to model first if set
+func (g *Gateway) Model(ctx context.Context, model string) (string, error) {
+	if g.agentRuntime == nil {
+		return "", fmt.Errorf("agent runtime is not running")
+	}
+	if model != "" {
+		if err := g.agentRuntime.SetModel(model); err != nil {
+			return "", err
+		}
+	}
+	return g.agentRuntime.GetStats().LLMModel, nil
+}
+
+// AgentState returns the agent runtime as seen from the workspace of a
+// channel target
+func (g *Gateway) AgentState(ctx context.Context, channel, target string) (*protocol.AgentState, error) {
+	return g.agentState(g.WorkspaceForChannel(channel, target)), nil
+}
+
+// resetSession forgets the agent session of a channel in a workspace
+func (g *Gateway) resetSession(workspaceID, channelID string) error {
+	if g.agentRuntime == nil {
+		return fmt.Errorf("agent runtime is not running")
+	}
+	g.agentRuntime.ResetSession(workspaceID, channelID)
+	return nil
+}
+
+// sessionInfo returns the agent session of a channel in a workspace
+func (g *Gateway) sessionInfo(workspaceID, channelID string) (*protocol.SessionState, error) {
+	if g.agentRuntime == nil {
+		return nil, fmt.Errorf("agent runtime is not running")
+	}
+	sess, ok := g.agentRuntime.Session(workspaceID, channelID)
+	if !ok {
+		return nil, fmt.Errorf("no session yet for %s", channelID)
+	}
+	return sessionState(sess), nil
+}
+
+// listTools returns the tools offered to the agent in a workspace
+func (g *Gateway) listTools(workspaceID string) ([]protocol.ToolInfo, error) {
+	if g.agentRuntime == nil {
+		return nil, fmt.Errorf("agent runtime is not running")
+	}
+	ws, ok := g.workspaces.Get(workspaceID)
+	if !ok {
+		return nil, workspaces.ErrUnknownWorkspace
+	}
+
+	infos := make([]protocol.ToolInfo, 0)
+	for _, tool := range g.agentRuntime.ScopeTools(workspaces.Scope(ws)) {
+		infos = append(infos, protocol.ToolInfo{Name: tool.Name, Description: tool.Description})
+	}
+	return infos, nil
+}
+
+// handleAgentRequest handles agent.* requests acting on the agent session
+// of a client: chatting, resetting and inspecting it
+func (g *Gateway) handleAgentRequest(client *Client, msg *protocol.ProtocolMessage) error {
+	var req protocol.AgentRequest
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &req); err != nil {
+			return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("invalid params: %v", err))
+		}
+	}
+	if !client.handshaken() {
+		return client.SendResponse(msg.ID, false, nil, "connect first")
+	}
+
+	// Sessions default to one per device, within the client's workspace.
+	// Clients name their sessions freely, but the names are kept under the
+	// client's user, so they never reach a channel's sessions or another
+	// user's.
+	client.mu.RLock()
+	workspaceID, deviceID, userID := client.workspace, client.deviceID, client.userID
+	client.mu.RUnlock()
+	channelID := req.ChannelID
+	if channelID == "" {
+		channelID = deviceID
+	}
+	sessionID := clientSession(userID, channelID)
+
+	switch msg.Method {
+	case "agent.message":
+		if req.Message == "" {
+			return client.SendResponse(msg.ID, false, nil, "message is required")
+		}
+		// Turns take long; run them off the connection's read loop
+		if !g.beginRequest() {
+			return client.SendResponse(msg.ID, false, nil, errDraining.Error())
+		}
+		go func() {
+			defer g.inflight.Done()
+			g.runAgentTurn(client, msg.ID, workspaceID, sessionID, channelID, &req)
+		}()
+		return nil
+
+	case "agent.reset":
+		if err := g.resetSession(workspaceID, sessionID); err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		return client.SendResponse(msg.ID, true, &protocol.AgentResponse{ChannelID: channelID, Status: "reset"}, "")
+
+	case "agent.session":
+		state, err := g.sessionInfo(workspaceID, sessionID)
+		if err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		return client.SendResponse(msg.ID, true, state, "")
+
+	case "agent.tools":
+		tools, err := g.listTools(workspaceID)
+		if err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		return client.SendResponse(msg.ID, true, map[string]interface{}{"tools": tools}, "")
+
+	case "agent.model":
+		if req.Model != "" && !client.IsAdmin() {
+			return client.SendResponse(msg.ID, false, nil, "admin token required")
+		}
+		model, err := g.Model(g.ctx, req.Model)
+		if err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		return client.SendResponse(msg.ID, true, map[string]interface{}{"model": model}, "")
+
+	default:
+		return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("unknown method: %s", msg.Method))
+	}
+}
+
+// clientSession returns the session a client of a user names channelID.
+// The user is escaped so that no user's sessions prefix another's.
+func clientSession(userID, channelID string) string {
+	return "client:" + url.PathEscape(userID) + "/" + channelID
+}
+
+// ownsSession reports whether a client may read the session of a channel:
+// admins may read all, other clients those of their user
+func ownsSession(client *Client, channelID string) bool {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	return client.admin || strings.HasPrefix(channelID, clientSession(client.userID, ""))
+}
+
+// runAgentTurn answers an agent.message request, streaming the reply as
+// agent.message events when asked to
+func (g *Gateway) runAgentTurn(client *Client, requestID, workspaceID, sessionID, channelID string, req *protocol.AgentRequest) {
+	var reply string
+	var err error
+	if req.Stream {
+		reply, err = g.StreamMessage(g.ctx, workspaceID, sessionID, req.Message, func(delta string) {
+			client.SendEvent(string(protocol.EventAgentMessage), &protocol.AgentDelta{
+				RequestID: requestID,
+				ChannelID: channelID,
+				Delta:     delta,
+			}, 0)
+		})
+		client.SendEvent(string(protocol.EventAgentMessage), &protocol.AgentDelta{
+			RequestID: requestID,
+			ChannelID: channelID,
+			Done:      true,
+		}, 0)
+	} else {
+		reply, err = g.ProcessMessage(g.ctx, workspaceID, sessionID, req.Message)
+	}
+
+	if err != nil {
+		client.SendResponse(requestID, false, nil, err.Error())
+		return
+	}
+	client.SendResponse(requestID, true, &protocol.AgentResponse{
+		SessionID: workspaceID + "/" + sessionID,
+		ChannelID: channelID,
+		Status:    "ok",
+		Message:   reply,
+	}, "")
+}
@@ -69,6 +69,9 @@ func (b *Broadcaster) eventLoop() {
 		protocol.EventSessionCreated,
 		protocol.EventSessionClosed,
 		protocol.EventStateUpdate,
+		protocol.EventPresenceUpdate,
+		protocol.EventNodeStatus,
+		protocol.EventWorkspaceUpdate,
 	}, nil)
 
 	defer eventBus.Unsubscribe(sub)
@@ -111,13 +114,13 @@ func (b *Broadcaster) broadcastEvent(event *protocol.Event) {
 
 	// Filter and send
 	for _, client := range clients {
-		// Check channel filter
-		if event.Channel != "" && client.deviceID != event.Channel {
+		// Check channel filter: a device, session or workspace
+		if event.Channel != "" && !client.onChannel(event.Channel) {
 			continue
 		}
 
-		// Check if client is connected
-		if !client.IsConnected() {
+		// Check if client is connected and past the handshake
+		if !client.IsConnected() || !client.handshaken() {
 			continue
 		}
 
@@ -128,17 +131,22 @@ func (b *Broadcaster) broadcastEvent(event *protocol.Event) {
 	}
 }
 
-// snapshotBroadcastLoop periodically broadcasts state snapshots
+// snapshotBroadcastLoop periodically pushes state.update diffs
 func (b *Broadcaster) snapshotBroadcastLoop() {
 	defer b.wg.Done()
 
-	ticker := time.NewTicker(30 * time.Second)
+	interval := time.Duration(b.gateway.config.Server.SnapshotInterval) * time.Second
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			b.broadcastStateSnapshot()
+			b.gateway.pushStateUpdates()
 
 		case <-b.ctx.Done():
 			return
@@ -146,28 +154,6 @@ func (b *Broadcaster) snapshotBroadcastLoop() {
 	}
 }
 
-// broadcastStateSnapshot broadcasts the current state snapshot
-func (b *Broadcaster) broadcastStateSnapshot() {
-	state := b.gateway.GetState()
-	if state == nil {
-		return
-	}
-
-	msg := &protocol.ProtocolMessage{
-		Type:  protocol.TypeEvent,
-		Event: string(protocol.EventStateUpdate),
-		Data:  state,
-	}
-
-	data, err := json.Marshal(msg)
-	if err != nil {
-		log.Printf("Failed to marshal state snapshot: %v", err)
-		return
-	}
-
-	b.gateway.Broadcast(data)
-}
-
 // Broadcast broadcasts a message to all clients
 func (b *Broadcaster) Broadcast(msg *protocol.ProtocolMessage) error {
 	data, err := json.Marshal(msg)
@@ -205,7 +191,7 @@ func (b *Broadcaster) BroadcastToChannel(channel string, msg *protocol.ProtocolM
 
 	clients := b.gateway.GetClients()
 	for _, client := range clients {
-		if client.deviceID == channel || client.sessionID == channel {
+		if client.onChannel(channel) {
 			client.Conn.Write(data)
 		}
 	}
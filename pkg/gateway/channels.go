@@ -0,0 +1,259 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openclaw/go-openclaw/internal/agent"
+	"github.com/openclaw/go-openclaw/internal/agent/llm"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// maxInlineFile is the most text of an attached file put in a message, in
+// bytes
+const maxInlineFile = 64 * 1024
+
+// Channels returns the messaging channel manager
+func (g *Gateway) Channels() *channels.ChannelManager {
+	return g.channels
+}
+
+// RegisterChannel adds a messaging channel whose messages are answered by
+// the agent, in the workspace the channel target is bound to. Channels
+// registered before Start are started with the gateway.
+func (g *Gateway) RegisterChannel(ch channels.Channel) {
+	ch.SetMessageHandler(func(ctx context.Context, msg *channels.Message) error {
+		return g.handleChannelMessage(ctx, ch, msg)
+	})
+	if interactive, ok := ch.(channels.Interactive); ok {
+		interactive.SetActionHandler(func(ctx context.Context, action *channels.Action) error {
+			return g.handleChannelAction(ctx, ch, action)
+		})
+	}
+	if webhook, ok := ch.(channels.Webhook); ok && webhook.WebhookPath() != "" {
+		g.webhooksLock.Lock()
+		g.webhooks[webhook.WebhookPath()] = webhook
+		g.webhooksLock.Unlock()
+		log.Printf("🪝 Webhook of %s at %s", ch.Name(), webhook.WebhookPath())
+	}
+	g.channels.Register(ch)
+	log.Printf("📡 Channel registered: %s", ch.Name())
+}
+
+// webhook returns the channel webhook served at a path
+func (g *Gateway) webhook(path string) (channels.Webhook, bool) {
+	g.webhooksLock.RLock()
+	defer g.webhooksLock.RUnlock()
+	webhook, ok := g.webhooks[path]
+	return webhook, ok
+}
+
+// channelSession returns the agent session key of a channel target
+func channelSession(channel, target string) string {
+	return channel + ":" + target
+}
+
+// handleChannelMessage runs an agent turn for a channel message and sends
+// the reply back, streamed when the channel supports it
+func (g *Gateway) handleChannelMessage(ctx context.Context, ch channels.Channel, msg *channels.Message) error {
+	target := msg.To
+	workspaceID := g.WorkspaceForChannel(ch.Name(), target)
+	channelID := channelSession(ch.Name(), target)
+	ctx = channels.WithTarget(ctx, ch.Name(), target)
+
+	streamer, ok := ch.(channels.StreamSender)
+	if !ok {
+		reply, err := g.ProcessInput(ctx, workspaceID, channelID, channelInput(msg))
+		if err != nil {
+			return err
+		}
+		return ch.Send(ctx, target, reply, nil)
+	}
+
+	stream, err := streamer.SendStream(ctx, target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start reply: %w", err)
+	}
+	reply, err := g.StreamInput(ctx, workspaceID, channelID, channelInput(msg), func(delta string) {
+		if err := stream.Append(delta); err != nil {
+			log.Printf("⚠️  Failed to stream reply on %s: %v", ch.Name(), err)
+		}
+	})
+	if err != nil {
+		stream.Close("")
+		return err
+	}
+	return stream.Close(reply)
+}
+
+// AskChannel asks a user on a channel to choose one of options with
+// buttons, and waits until one is pressed or the timeout
+func (g *Gateway) AskChannel(ctx context.Context, channel, target, question string, options []string, timeout time.Duration) (*channels.Action, error) {
+	ch, ok := g.channels.Get(channel)
+	if !ok {
+		return nil, fmt.Errorf("unknown channel: %s", channel)
+	}
+	interactive, ok := ch.(channels.Interactive)
+	if !ok {
+		return nil, fmt.Errorf("channel %s cannot show buttons", channel)
+	}
+
+	id := strconv.FormatUint(g.askSeq.Add(1), 36)
+	answer := make(chan *channels.Action, 1)
+	g.asksLock.Lock()
+	g.asks[id] = answer
+	g.asksLock.Unlock()
+	defer func() {
+		g.asksLock.Lock()
+		delete(g.asks, id)
+		g.asksLock.Unlock()
+	}()
+
+	if _, err := interactive.SendButtons(ctx, target, question, askButtons(id, options)); err != nil {
+		return nil, fmt.Errorf("failed to ask: %w", err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case action := <-answer:
+		// The answer is the option, whatever the channel shows
+		_, index, _ := strings.Cut(strings.TrimPrefix(action.Data, "ask:"+id), ":")
+		if i, err := strconv.Atoi(index); err == nil && i >= 0 && i < len(options) {
+			action.Text = options[i]
+		}
+		return action, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("no answer within %s", timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-g.ctx.Done():
+		return nil, fmt.Errorf("gateway is shutting down")
+	}
+}
+
+// askButtons returns the buttons of a question: short answers side by
+// side, longer ones a row each
+func askButtons(id string, options []string) [][]channels.Button {
+	short := len(options) <= 3
+	for _, option := range options {
+		if utf8.RuneCountInString(option) > 12 {
+			short = false
+		}
+	}
+
+	var rows [][]channels.Button
+	for i, option := range options {
+		button := channels.Button{Text: option, Data: fmt.Sprintf("ask:%s:%d", id, i)}
+		if short && len(rows) > 0 {
+			rows[0] = append(rows[0], button)
+			continue
+		}
+		rows = append(rows, []channels.Button{button})
+	}
+	return rows
+}
+
+// handleChannelAction answers a question waiting for a pressed button.
+// Other buttons, as those attached to replies, are the next message of
+// the user to the agent.
+func (g *Gateway) handleChannelAction(ctx context.Context, ch channels.Channel, action *channels.Action) error {
+	g.eventBus.Publish(protocol.EventChannelAction, "", action)
+
+	if rest, ok := strings.CutPrefix(action.Data, "ask:"); ok {
+		id, _, _ := strings.Cut(rest, ":")
+		g.asksLock.Lock()
+		answer, waiting := g.asks[id]
+		g.asksLock.Unlock()
+		if !waiting {
+			return channels.ErrActionExpired
+		}
+		select {
+		case answer <- action:
+		default:
+			// Answered already
+		}
+		return nil
+	}
+
+	content := action.Text
+	if content == "" {
+		content = action.Data
+	}
+	return g.handleChannelMessage(ctx, ch, &channels.Message{
+		Channel:   ch.Name(),
+		From:      action.From,
+		FromName:  action.FromName,
+		To:        action.Target,
+		Content:   content,
+		Type:      channels.MessageTypeText,
+		Timestamp: time.Now(),
+		ReplyTo:   action.MessageID,
+		Metadata:  map[string]interface{}{"action_data": action.Data},
+	})
+}
+
+// channelInput returns the agent input of a channel message: images are
+// passed to the model as such, text files are put in the message and other
+// files are only named
+func channelInput(msg *channels.Message) *agent.Input {
+	input := &agent.Input{}
+	var text strings.Builder
+	text.WriteString(msg.Content)
+
+	for _, att := range msg.Attachments {
+		name := att.Name
+		if name == "" {
+			name = "file"
+		}
+		switch {
+		case att.Type == channels.MessageTypeImage && isImageType(att.MimeType):
+			input.Images = append(input.Images, llm.Image{MediaType: att.MimeType, Data: att.Data})
+		case isTextFile(att):
+			content := att.Data
+			note := ""
+			if len(content) > maxInlineFile {
+				content, note = content[:maxInlineFile], fmt.Sprintf("\n[truncated, %d bytes in all]", len(att.Data))
+			}
+			fmt.Fprintf(&text, "\n\n📎 %s:\n```\n%s\n```%s", name, strings.ToValidUTF8(string(content), ""), note)
+		default:
+			fmt.Fprintf(&text, "\n\n📎 %s (%s, %d bytes), which cannot be read", name, att.MimeType, len(att.Data))
+		}
+	}
+
+	input.Text = strings.TrimSpace(text.String())
+	return input
+}
+
+// isImageType reports whether models accept images of a MIME type
+func isImageType(mimeType string) bool {
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// isTextFile reports whether an attachment is text, by its MIME type or,
+// when that says nothing, its content
+func isTextFile(att *channels.Attachment) bool {
+	mimeType, _, _ := strings.Cut(att.MimeType, ";")
+	switch {
+	case strings.HasPrefix(mimeType, "text/"):
+		return true
+	case mimeType == "application/json", mimeType == "application/xml",
+		mimeType == "application/x-yaml", mimeType == "application/yaml",
+		mimeType == "application/javascript", mimeType == "application/x-sh":
+		return true
+	case mimeType == "", mimeType == "application/octet-stream":
+		return utf8.Valid(att.Data) && !bytes.ContainsRune(att.Data, 0)
+	}
+	return false
+}
@@ -2,7 +2,6 @@ package gateway
 
 import (
 	"encoding/json"
-	"fmt"
 	"sync"
 	"time"
 
@@ -17,9 +16,18 @@ type Client struct {
 	gateway      *Gateway          // Parent gateway
 	deviceID     string            // Device identifier
 	clientID     string            // Client identifier
+	userID       string            // User the client's presence counts for
+	authUser     string            // User of the client's token, for workspace membership
 	sessionID    string            // Session identifier
 	clientType   string            // Client type: agent, node, web, mobile
+	remoteIP     string            // Remote address the connection came from
+	limitDevice  string            // Device counted against the connection limit
+	pending      *pendingCalls     // Requests sent to the client awaiting a response
+	nodeID       string            // Paired node identity, for node clients
+	workspace    string            // Workspace the client works in
+	admin        bool              // Whether the client may administer nodes
 	status       string            // Status: connected, disconnected, idle
+	closeReason  string            // Why the gateway closed the connection
 	connectedAt  time.Time         // Connection time
 	lastSeen     time.Time         // Last activity time
 	capabilities []string          // Client capabilities
@@ -169,18 +177,19 @@ func (m *Manager) BroadcastToType(clientType string, msg *protocol.ProtocolMessa
 	return nil
 }
 
-// SendTo sends a message to a specific client
+// SendTo sends a message to a specific client, routing through the
+// cluster when the client is connected to another gateway node
 func (m *Manager) SendTo(clientID string, msg *protocol.ProtocolMessage) error {
-	client, ok := m.Get(clientID)
-	if !ok {
-		return fmt.Errorf("client not found: %s", clientID)
-	}
-
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return err
 	}
 
+	client, ok := m.Get(clientID)
+	if !ok {
+		return m.gateway.SendTo(clientID, data)
+	}
+
 	return client.Conn.Write(data)
 }
 
@@ -208,15 +217,23 @@ func (c *Client) GetState() *protocol.ClientState {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	queue := c.Conn.Stats()
+	status := c.status
+	if status == "connected" && queue.Status != ws.StatusOK {
+		status = queue.Status
+	}
+
 	return &protocol.ClientState{
 		ID:           c.ID,
 		DeviceID:     c.deviceID,
 		Type:         c.clientType,
-		Status:       c.status,
+		Status:       status,
+		Workspace:    c.workspace,
 		ConnectedAt:  c.connectedAt.Unix(),
 		LastSeen:     c.lastSeen.Unix(),
 		Capabilities: c.capabilities,
 		Metadata:     c.metadata,
+		Queue:        queue,
 	}
 }
 
@@ -236,6 +253,34 @@ func (c *Client) Update(deviceID, clientID, clientType string) {
 	}
 }
 
+// IsAdmin returns true if the client connected with an admin token
+func (c *Client) IsAdmin() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.admin
+}
+
+// NodeID returns the paired node identity of the client, if any
+func (c *Client) NodeID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nodeID
+}
+
+// Workspace returns the workspace the client works in
+func (c *Client) Workspace() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.workspace
+}
+
+// limitedDevice returns the device counted against the connection limit
+func (c *Client) limitedDevice() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.limitDevice
+}
+
 // SetStatus sets the client status
 func (c *Client) SetStatus(status string) {
 	c.mu.Lock()
@@ -274,6 +319,18 @@ func (c *Client) Close() error {
 	return c.Conn.Close()
 }
 
+// CloseWithReason closes the client connection with a WebSocket close code
+func (c *Client) CloseWithReason(code int, reason string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.status = "disconnected"
+	if c.closeReason == "" {
+		c.closeReason = reason
+	}
+	return c.Conn.CloseWithReason(code, reason)
+}
+
 // Send sends a message to the client
 func (c *Client) Send(msg *protocol.ProtocolMessage) error {
 	data, err := json.Marshal(msg)
@@ -299,6 +356,35 @@ func (c *Client) SendEvent(event string, data interface{}, seq int) error {
 	return c.Conn.WriteEvent(event, data, seq)
 }
 
+// LastSeen returns when the client last sent a request
+func (c *Client) LastSeen() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastSeen
+}
+
+// touch records a request from the client
+func (c *Client) touch() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastSeen = time.Now()
+}
+
+// handshaken reports whether the client completed the connect handshake
+func (c *Client) handshaken() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sessionID != ""
+}
+
+// onChannel reports whether events on a channel reach the client: its
+// device, session or workspace
+func (c *Client) onChannel(channel string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return channel == c.deviceID || channel == c.sessionID || channel == c.workspace
+}
+
 // IsActive checks if the client is active (seen within last 5 minutes)
 func (c *Client) IsActive() bool {
 	c.mu.RLock()
@@ -0,0 +1,341 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/agent"
+	"github.com/openclaw/go-openclaw/internal/agent/llm"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/pkg/cluster"
+)
+
+// Backplane topics used between gateway nodes
+const (
+	topicBroadcast  = "gateway.broadcast"
+	topicEvents     = "gateway.events"
+	topicSendPrefix = "gateway.send."
+)
+
+// remoteTurnTimeout bounds the wait for a turn forwarded to another node
+const remoteTurnTimeout = 5 * time.Minute
+
+// remoteSend is a message for a client connected to another node, or an
+// agent turn forwarded to the node owning its session and its reply
+type remoteSend struct {
+	ClientID  string           `json:"client_id,omitempty"`
+	Data      []byte           `json:"data,omitempty"`
+	Turn      *remoteTurn      `json:"turn,omitempty"`
+	TurnReply *remoteTurnReply `json:"turn_reply,omitempty"`
+}
+
+// remoteTurn is an agent turn run by the node owning its session
+type remoteTurn struct {
+	ID        string      `json:"id"`
+	From      string      `json:"from"` // node waiting for the reply
+	Workspace string      `json:"workspace"`
+	ChannelID string      `json:"channel_id"`
+	Text      string      `json:"text"`
+	Images    []llm.Image `json:"images,omitempty"`
+	Stream    bool        `json:"stream,omitempty"`
+}
+
+// remoteTurnReply is a streamed delta or the outcome of a remote turn
+type remoteTurnReply struct {
+	ID    string `json:"id"`
+	Delta string `json:"delta,omitempty"`
+	Done  bool   `json:"done,omitempty"`
+	Text  string `json:"text,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// pendingTurn is a forwarded turn waiting for its replies
+type pendingTurn struct {
+	replies chan *remoteTurnReply
+	done    chan struct{}
+}
+
+// SetBackplane joins the gateway to a cluster. It must be called before
+// Start; the gateway ID becomes the backplane node ID.
+func (g *Gateway) SetBackplane(bp cluster.Backplane) {
+	g.backplane = bp
+	g.id = bp.NodeID()
+}
+
+// Backplane returns the cluster backplane, or nil when running standalone
+func (g *Gateway) Backplane() cluster.Backplane {
+	return g.backplane
+}
+
+// startCluster joins the backplane and bridges broadcasts, targeted sends
+// and events between nodes
+func (g *Gateway) startCluster(ctx context.Context) error {
+	if g.backplane == nil {
+		return nil
+	}
+
+	if err := g.backplane.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start backplane: %w", err)
+	}
+
+	g.backplane.Subscribe(topicBroadcast, func(msg *cluster.Message) {
+		g.broadcastLocal(msg.Data)
+	})
+
+	g.backplane.Subscribe(topicSendPrefix+g.id, func(msg *cluster.Message) {
+		var send remoteSend
+		if err := json.Unmarshal(msg.Data, &send); err != nil {
+			log.Printf("Invalid remote send from %s: %v", msg.From, err)
+			return
+		}
+		switch {
+		case send.Turn != nil:
+			g.serveRemoteTurn(send.Turn)
+			return
+		case send.TurnReply != nil:
+			g.deliverTurnReply(send.TurnReply)
+			return
+		}
+		client, ok := g.GetClient(send.ClientID)
+		if !ok {
+			return
+		}
+		if err := client.Conn.Write(send.Data); err != nil {
+			log.Printf("Remote send to %s failed: %v", send.ClientID, err)
+		}
+	})
+
+	g.backplane.Subscribe(topicEvents, func(msg *cluster.Message) {
+		var event protocol.Event
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			log.Printf("Invalid remote event from %s: %v", msg.From, err)
+			return
+		}
+		event.Origin = msg.From
+		g.eventBus.PublishEvent(&event)
+	})
+
+	// Forward locally published events to the other nodes
+	sub := g.eventBus.Subscribe("cluster", "", nil, nil)
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer g.eventBus.Unsubscribe(sub)
+
+		events := g.eventBus.GetSubscriberChannel(sub)
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if event.Origin != "" {
+					continue // already came through the backplane
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				if err := g.backplane.Publish(g.ctx, topicEvents, data); err != nil {
+					log.Printf("Failed to forward event %s: %v", event.Type, err)
+				}
+			case <-g.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	log.Printf("🕸️  Gateway joined cluster as %s", g.id)
+	return nil
+}
+
+// stopCluster leaves the backplane
+func (g *Gateway) stopCluster(ctx context.Context) {
+	if g.backplane == nil {
+		return
+	}
+	if err := g.backplane.Stop(ctx); err != nil {
+		log.Printf("Failed to stop backplane: %v", err)
+	}
+}
+
+// SendTo sends a message to a client connected to this or another node
+func (g *Gateway) SendTo(clientID string, data []byte) error {
+	if client, ok := g.GetClient(clientID); ok {
+		return client.Conn.Write(data)
+	}
+
+	if g.backplane == nil {
+		return fmt.Errorf("client not found: %s", clientID)
+	}
+
+	entry, ok := g.backplane.Registry().Lookup(clientID)
+	if !ok {
+		return fmt.Errorf("client not found: %s", clientID)
+	}
+
+	payload, err := json.Marshal(&remoteSend{ClientID: clientID, Data: data})
+	if err != nil {
+		return err
+	}
+	return g.backplane.Publish(g.ctx, topicSendPrefix+entry.NodeID, payload)
+}
+
+// ClaimSession claims an agent session for this node. It returns the
+// owning node and whether that is this node.
+func (g *Gateway) ClaimSession(ctx context.Context, sessionID string) (string, bool, error) {
+	if g.backplane == nil {
+		return g.id, true, nil
+	}
+
+	owner, err := g.backplane.Registry().ClaimSession(ctx, sessionID)
+	if err != nil {
+		return "", false, err
+	}
+	return owner, owner == g.id, nil
+}
+
+// forwardTurn runs an agent turn on the node owning its session and
+// waits for the reply, passing streamed deltas to onDelta
+func (g *Gateway) forwardTurn(ctx context.Context, owner string, turn *remoteTurn, onDelta func(delta string)) (string, error) {
+	turn.ID = g.id + "-" + strconv.FormatUint(g.turnSeq.Add(1), 36)
+	turn.From = g.id
+
+	pending := &pendingTurn{
+		replies: make(chan *remoteTurnReply, 64),
+		done:    make(chan struct{}),
+	}
+	g.turnsLock.Lock()
+	g.turns[turn.ID] = pending
+	g.turnsLock.Unlock()
+	defer func() {
+		g.turnsLock.Lock()
+		delete(g.turns, turn.ID)
+		g.turnsLock.Unlock()
+		close(pending.done)
+	}()
+
+	payload, err := json.Marshal(&remoteSend{Turn: turn})
+	if err != nil {
+		return "", err
+	}
+	if err := g.backplane.Publish(ctx, topicSendPrefix+owner, payload); err != nil {
+		return "", fmt.Errorf("failed to forward turn to %s: %w", owner, err)
+	}
+
+	timer := time.NewTimer(remoteTurnTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case reply := <-pending.replies:
+			if !reply.Done {
+				if onDelta != nil {
+					onDelta(reply.Delta)
+				}
+				continue
+			}
+			if reply.Error != "" {
+				return "", errors.New(reply.Error)
+			}
+			return reply.Text, nil
+		case <-timer.C:
+			return "", fmt.Errorf("no reply from %s, the owner of the session", owner)
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// serveRemoteTurn runs a turn forwarded by another node and sends the
+// replies back to it
+func (g *Gateway) serveRemoteTurn(turn *remoteTurn) {
+	reply := func(r *remoteTurnReply) {
+		r.ID = turn.ID
+		payload, err := json.Marshal(&remoteSend{TurnReply: r})
+		if err == nil {
+			err = g.backplane.Publish(g.ctx, topicSendPrefix+turn.From, payload)
+		}
+		if err != nil {
+			log.Printf("Failed to reply to turn %s: %v", turn.ID, err)
+		}
+	}
+
+	var onDelta func(string)
+	if turn.Stream {
+		onDelta = func(delta string) { reply(&remoteTurnReply{Delta: delta}) }
+	}
+
+	if !g.beginRequest() {
+		reply(&remoteTurnReply{Done: true, Error: errDraining.Error()})
+		return
+	}
+	go func() {
+		defer g.inflight.Done()
+		text, err := g.runLocalTurn(g.ctx, turn.Workspace, turn.ChannelID, &agent.Input{Text: turn.Text, Images: turn.Images}, onDelta)
+		if err != nil {
+			reply(&remoteTurnReply{Done: true, Error: err.Error()})
+			return
+		}
+		reply(&remoteTurnReply{Done: true, Text: text})
+	}()
+}
+
+// deliverTurnReply passes a reply to the forwarded turn waiting for it
+func (g *Gateway) deliverTurnReply(reply *remoteTurnReply) {
+	g.turnsLock.Lock()
+	pending, ok := g.turns[reply.ID]
+	g.turnsLock.Unlock()
+	if !ok {
+		return // gave up waiting
+	}
+	select {
+	case pending.replies <- reply:
+	case <-pending.done:
+	}
+}
+
+// ReleaseSession releases an agent session owned by this node
+func (g *Gateway) ReleaseSession(ctx context.Context, sessionID string) error {
+	if g.backplane == nil {
+		return nil
+	}
+	return g.backplane.Registry().ReleaseSession(ctx, sessionID)
+}
+
+// registerPresence records a handshaken client in the cluster registry
+func (g *Gateway) registerPresence(client *Client) {
+	if g.backplane == nil {
+		return
+	}
+
+	client.mu.RLock()
+	entry := &cluster.PresenceEntry{
+		ClientID:    client.ID,
+		DeviceID:    client.deviceID,
+		SessionID:   client.sessionID,
+		ClientType:  client.clientType,
+		ConnectedAt: client.connectedAt,
+	}
+	client.mu.RUnlock()
+
+	if err := g.backplane.Registry().Register(g.ctx, entry); err != nil {
+		log.Printf("Failed to register %s in cluster: %v", client.ID, err)
+	}
+}
+
+// unregisterPresence removes a client from the cluster registry
+func (g *Gateway) unregisterPresence(client *Client) {
+	if g.backplane == nil {
+		return
+	}
+
+	ctx := context.Background()
+	if err := g.backplane.Registry().Unregister(ctx, client.ID); err != nil {
+		log.Printf("Failed to unregister %s from cluster: %v", client.ID, err)
+	}
+}
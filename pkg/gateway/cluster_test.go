@@ -0,0 +1,80 @@
+package gateway_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openclaw/go-openclaw/internal/agent"
+	"github.com/openclaw/go-openclaw/internal/agent/llm/llmtest"
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/openclaw/go-openclaw/pkg/cluster"
+	"github.com/openclaw/go-openclaw/pkg/gateway"
+)
+
+// startNode starts a gateway joined to a hub, with its agent on a mock LLM
+func startNode(t *testing.T, hub *cluster.MemoryHub, id string) (*gateway.Gateway, *llmtest.Client) {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = 0
+	g := gateway.NewWithConfig(cfg)
+	g.SetBackplane(hub.Join(id))
+	if err := g.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start %s: %v", id, err)
+	}
+	t.Cleanup(func() { g.Stop(context.Background()) })
+
+	mock := llmtest.New()
+	agentCfg := agent.DefaultConfig()
+	agentCfg.LLMClient = mock
+	if err := g.StartAgent(context.Background(), agentCfg); err != nil {
+		t.Fatalf("failed to start agent of %s: %v", id, err)
+	}
+	return g, mock
+}
+
+func TestTurnsRunOnSessionOwner(t *testing.T) {
+	hub := cluster.NewMemoryHub()
+	a, llmA := startNode(t, hub, "a")
+	b, llmB := startNode(t, hub, "b")
+	ctx := context.Background()
+
+	// The first turn claims the session for a
+	if reply, err := a.ProcessMessage(ctx, "default", "chat", "one"); err != nil || reply != "echo: one" {
+		t.Fatalf("turn on a = %q, %v", reply, err)
+	}
+
+	// Later turns arriving at b run on a, with the history kept there
+	reply, err := b.ProcessMessage(ctx, "default", "chat", "two")
+	if err != nil || reply != "echo: two" {
+		t.Fatalf("turn via b = %q, %v", reply, err)
+	}
+	var deltas []string
+	reply, err = b.StreamMessage(ctx, "default", "chat", "three four", func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil || reply != "echo: three four" {
+		t.Fatalf("streamed turn via b = %q, %v", reply, err)
+	}
+	if got := strings.Join(deltas, ""); got != reply {
+		t.Errorf("deltas = %q, want %q", got, reply)
+	}
+
+	if n := len(llmB.Requests()); n != 0 {
+		t.Errorf("b ran %d turns of a session owned by a", n)
+	}
+	last := llmA.LastRequest()
+	if n := len(llmA.Requests()); n != 3 || len(last.Messages) != 5 {
+		t.Errorf("a ran %d turns, the last with %d messages; want 3 turns and 5 messages", n, len(last.Messages))
+	}
+
+	// Other sessions are claimed by the node that sees them first
+	if _, err := b.ProcessMessage(ctx, "default", "other", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(llmB.Requests()); n != 1 {
+		t.Errorf("b ran %d turns of its own session, want 1", n)
+	}
+}
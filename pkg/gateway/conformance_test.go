@@ -0,0 +1,1177 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/openclaw/go-openclaw/internal/agent/llm"
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/openclaw/go-openclaw/internal/events"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/pkg/gateway"
+	"github.com/openclaw/go-openclaw/pkg/gateway/gatewaytest"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Protocol conformance: what any client may rely on, checked over real
+// connections to an in-process gateway
+
+func TestHandshake(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	_, hello := s.Connect(t, "device-a")
+
+	if hello.DeviceID != "device-a" {
+		t.Errorf("device_id = %q, want device-a", hello.DeviceID)
+	}
+	if hello.SessionID == "" {
+		t.Error("session_id is empty")
+	}
+	if hello.Workspace != "default" {
+		t.Errorf("workspace = %q, want default", hello.Workspace)
+	}
+	if hello.Encoding != "json" {
+		t.Errorf("encoding = %q, want json", hello.Encoding)
+	}
+	if hello.State == nil {
+		t.Fatal("hello has no state")
+	}
+	if hello.State.Agent == nil || hello.State.Agent.Status != "running" {
+		t.Errorf("agent state = %+v, want running", hello.State.Agent)
+	}
+	if len(hello.State.Clients) != 1 || hello.State.Clients[0].DeviceID != "device-a" {
+		t.Errorf("clients = %+v, want only device-a", hello.State.Clients)
+	}
+}
+
+func TestHandshakeRejectsInvalidRequests(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+
+	tests := []struct {
+		name   string
+		params interface{}
+		want   string
+	}{
+		{"missing token", map[string]string{"device_id": "a"}, protocol.ErrMissingToken.Error()},
+		{"missing device", map[string]string{"token": "t"}, protocol.ErrMissingDeviceID.Error()},
+		{"token too long", map[string]string{"token": strings.Repeat("t", 257), "device_id": "a"}, protocol.ErrTokenTooLong.Error()},
+		{"invalid params", "not an object", "invalid params"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := s.Dial(t)
+			res := c.Call("connect", tt.params)
+			if res.Ok || !strings.Contains(res.Error, tt.want) {
+				t.Errorf("response = ok:%v error:%q, want error %q", res.Ok, res.Error, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthFailures(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Auth.Enabled = true
+		cfg.Auth.TokenRequired = true
+		cfg.Auth.Tokens = []string{"user-token"}
+		cfg.Auth.AdminTokens = []string{"admin-token"}
+		cfg.Auth.DeviceCheck = true
+		cfg.Auth.AllowedDeviceIDs = []string{"known"}
+	})
+	connect := func(token, device string) *gatewaytest.Frame {
+		return s.Dial(t).Call("connect", &protocol.ConnectRequest{Token: token, DeviceID: device})
+	}
+
+	if res := connect("wrong", "known"); res.Ok || res.Error != protocol.ErrUnauthorized.Error() {
+		t.Errorf("wrong token: ok:%v error:%q, want unauthorized", res.Ok, res.Error)
+	}
+	if res := connect("user-token", "stranger"); res.Ok {
+		t.Error("unknown device was let in")
+	}
+	if res := connect("user-token", "known"); !res.Ok {
+		t.Errorf("user token refused: %s", res.Error)
+	}
+	if res := connect("admin-token", "known"); !res.Ok {
+		t.Errorf("admin token refused: %s", res.Error)
+	}
+
+	// Refused clients stay outside until they connect properly
+	c := s.Dial(t)
+	c.Call("connect", &protocol.ConnectRequest{Token: "wrong", DeviceID: "known"})
+	if res := c.Call("state", nil); res.Ok {
+		t.Error("state answered after a refused handshake")
+	}
+
+	// Admin methods need the admin token
+	c = s.Dial(t)
+	c.Call("connect", &protocol.ConnectRequest{Token: "user-token", DeviceID: "known"})
+	res := c.Call("workspace.create", map[string]interface{}{"spec": map[string]string{"name": "Team"}})
+	if res.Ok || res.Error != protocol.ErrUnauthorized.Error() {
+		t.Errorf("workspace.create as user: ok:%v error:%q, want unauthorized", res.Ok, res.Error)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	c, _ := s.Connect(t, "a")
+
+	for _, method := range []string{"no.such.method", "agent.nope", "workspace.nope", "node.nope"} {
+		res := c.Call(method, nil)
+		if res.Ok || res.Error != "unknown method: "+method {
+			t.Errorf("%s: ok:%v error:%q, want unknown method", method, res.Ok, res.Error)
+		}
+	}
+}
+
+func TestMalformedJSON(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	c, _ := s.Connect(t, "a")
+
+	c.SendRaw("{not json")
+	c.SendRaw(`{"type":"req","id":"x","method":`)
+	c.SendRaw(`[]`)
+
+	// Garbage is dropped without closing the connection
+	if res := c.Call("state", nil); !res.Ok {
+		t.Fatalf("state after malformed frames: %s", res.Error)
+	}
+}
+
+func TestEventDelivery(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Server.SnapshotInterval = 1
+		cfg.Auth.Enabled = true
+		cfg.Auth.AdminTokens = []string{"test"}
+	})
+	a, hello := s.Connect(t, "a")
+	b, _ := s.Connect(t, "b")
+
+	a.Expect("client.connected of b", isEventFor("client.connected", "b"))
+
+	// State diffs follow the seq of the hello snapshot
+	var diff protocol.StateDiff
+	if err := a.ExpectEvent("state.update").Decode(&diff); err != nil {
+		t.Fatal(err)
+	}
+	if diff.Seq != hello.State.Seq+1 {
+		t.Errorf("diff seq = %d, want %d", diff.Seq, hello.State.Seq+1)
+	}
+
+	b.Close()
+	a.Expect("client.disconnected of b", isEventFor("client.disconnected", "b"))
+}
+
+// isEventFor matches an event about a device
+func isEventFor(name, deviceID string) func(f *gatewaytest.Frame) bool {
+	return func(f *gatewaytest.Frame) bool {
+		var data struct {
+			DeviceID string `json:"device_id"`
+		}
+		return f.Type == protocol.TypeEvent && f.Event == name &&
+			f.Decode(&data) == nil && data.DeviceID == deviceID
+	}
+}
+
+func TestAgentConversation(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	c, _ := s.Connect(t, "a")
+
+	for _, message := range []string{"hello", "again"} {
+		res := c.Call("agent.message", &protocol.AgentRequest{ChannelID: "chat", Message: message})
+		if !res.Ok {
+			t.Fatalf("agent.message %q: %s", message, res.Error)
+		}
+		var reply protocol.AgentResponse
+		if err := res.Decode(&reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Message != "echo: "+message {
+			t.Errorf("reply = %q, want echo of %q", reply.Message, message)
+		}
+	}
+
+	// The second turn carries the first as history
+	req := s.LLM.LastRequest()
+	var roles []string
+	for _, msg := range req.Messages {
+		roles = append(roles, msg.Role+":"+msg.Content)
+	}
+	want := "user:hello,assistant:echo: hello,user:again"
+	if got := strings.Join(roles, ","); got != want {
+		t.Errorf("history = %s, want %s", got, want)
+	}
+}
+
+func TestAgentSessionOwnership(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Auth.Enabled = true
+		cfg.Auth.TokenRequired = true
+		cfg.Auth.AdminTokens = []string{"admin-token"}
+		cfg.Auth.Users = []config.UserConfig{
+			{ID: "alice", Tokens: []string{"alice-token"}},
+			{ID: "bob", Tokens: []string{"bob-token"}},
+		}
+	})
+	connect := func(token, device string) *gatewaytest.Conn {
+		c := s.Dial(t)
+		if res := c.Call("connect", &protocol.ConnectRequest{Token: token, DeviceID: device}); !res.Ok {
+			t.Fatalf("handshake of %s failed: %s", device, res.Error)
+		}
+		return c
+	}
+
+	// A channel conversation and one of alice's
+	if _, err := s.Gateway.ProcessMessage(context.Background(), "default", "telegram:42", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	alice, bob := connect("alice-token", "phone"), connect("bob-token", "laptop")
+	if res := alice.Call("agent.message", &protocol.AgentRequest{ChannelID: "chat", Message: "private"}); !res.Ok {
+		t.Fatalf("agent.message: %s", res.Error)
+	}
+
+	// Bob names the channel's session and alice's, and gets his own
+	for _, channelID := range []string{"telegram:42", "chat"} {
+		req := &protocol.AgentRequest{ChannelID: channelID}
+		if res := bob.Call("agent.session", req); res.Ok {
+			t.Errorf("agent.session of %s read another's session", channelID)
+		}
+		if res := bob.Call("agent.reset", req); !res.Ok {
+			t.Errorf("agent.reset of %s: %s", channelID, res.Error)
+		}
+	}
+	if _, err := s.Gateway.SessionInfo(context.Background(), "telegram", "42"); err != nil {
+		t.Errorf("channel session was reset: %v", err)
+	}
+	if res := alice.Call("agent.session", &protocol.AgentRequest{ChannelID: "chat"}); !res.Ok {
+		t.Errorf("alice's session was reset: %s", res.Error)
+	}
+
+	// sessions lists the sessions a client is shown
+	sessions := func(c *gatewaytest.Conn) []*protocol.SessionState {
+		var list struct {
+			Sessions []*protocol.SessionState `json:"sessions"`
+		}
+		if err := c.Call("sessions.list", nil).Decode(&list); err != nil {
+			t.Fatal(err)
+		}
+		return list.Sessions
+	}
+	if got := sessions(bob); len(got) != 0 {
+		t.Errorf("bob is shown %d sessions, want none", len(got))
+	}
+	mine := sessions(alice)
+	if len(mine) != 1 {
+		t.Fatalf("alice is shown %d sessions, want 1", len(mine))
+	}
+	if res := bob.Call("sessions.export", &protocol.SessionRequest{ID: mine[0].ID}); res.Ok {
+		t.Error("bob exported alice's session")
+	}
+	if res := alice.Call("sessions.export", &protocol.SessionRequest{ID: mine[0].ID}); !res.Ok {
+		t.Errorf("alice exporting her session: %s", res.Error)
+	}
+	if got := sessions(connect("admin-token", "console")); len(got) != 2 {
+		t.Errorf("admin is shown %d sessions, want 2", len(got))
+	}
+}
+
+func TestAgentStreaming(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	c, _ := s.Connect(t, "a")
+
+	id := c.Send("agent.message", &protocol.AgentRequest{Message: "stream this reply", Stream: true})
+
+	var text strings.Builder
+	for {
+		var delta protocol.AgentDelta
+		if err := c.ExpectEvent("agent.message").Decode(&delta); err != nil {
+			t.Fatal(err)
+		}
+		if delta.RequestID != id {
+			t.Fatalf("delta for request %q, want %q", delta.RequestID, id)
+		}
+		if delta.Done {
+			break
+		}
+		text.WriteString(delta.Delta)
+	}
+
+	res := c.Expect("agent.message response", func(f *gatewaytest.Frame) bool {
+		return f.Type == protocol.TypeRes && f.ID == id
+	})
+	var reply protocol.AgentResponse
+	if err := res.Decode(&reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Message != "echo: stream this reply" || text.String() != reply.Message {
+		t.Errorf("streamed %q, reply %q", text.String(), reply.Message)
+	}
+}
+
+func TestRequestsBeforeHandshake(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	c := s.Dial(t)
+
+	for _, method := range []string{"state", "agent.message"} {
+		if res := c.Call(method, &protocol.AgentRequest{Message: "hi"}); res.Ok {
+			t.Errorf("%s answered before connect", method)
+		}
+	}
+}
+
+func TestMethodsBeforeHandshake(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Auth.Enabled = true
+		cfg.Auth.TokenRequired = true
+		cfg.Auth.Tokens = []string{"user-token"}
+	})
+
+	// Another user has a conversation to leak
+	owner := s.Dial(t)
+	if res := owner.Call("connect", &protocol.ConnectRequest{Token: "user-token", DeviceID: "owner"}); !res.Ok {
+		t.Fatalf("handshake failed: %s", res.Error)
+	}
+	if res := owner.Call("agent.message", &protocol.AgentRequest{Message: "secret"}); !res.Ok {
+		t.Fatalf("agent.message: %s", res.Error)
+	}
+
+	methods := []string{
+		"state",
+		"agent.start", "agent.stop", "agent.status",
+		"agent.message", "agent.reset", "agent.session", "agent.model", "agent.tools",
+		"sessions.list", "sessions.export",
+		"presence.list", "presence.get", "presence.set",
+		"workspace.list", "workspace.get", "workspace.switch",
+		"workspace.create", "workspace.update", "workspace.delete",
+		"node.list", "node.get", "node.pair", "node.pending",
+		"node.approve", "node.reject", "node.unpair", "node.invoke",
+		"no.such.method",
+	}
+	c := s.Dial(t)
+	for _, method := range methods {
+		res := c.Call(method, map[string]interface{}{"workspace": "default", "id": "owner"})
+		if res.Ok || res.Error != "connect first" {
+			t.Errorf("%s before connect: ok:%v error:%q, want connect first", method, res.Ok, res.Error)
+		}
+	}
+
+	// Pings keep working, and the handshake still opens the connection
+	if res := c.Call("ping", &protocol.PingMessage{Seq: 1}); !res.Ok {
+		t.Errorf("ping before connect: %s", res.Error)
+	}
+	if res := c.Call("connect", &protocol.ConnectRequest{Token: "user-token", DeviceID: "late"}); !res.Ok {
+		t.Fatalf("handshake after refused requests: %s", res.Error)
+	}
+	if res := c.Call("state", nil); !res.Ok {
+		t.Errorf("state after connect: %s", res.Error)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for heartbeat timeouts")
+	}
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Server.Heartbeat.PingInterval = 1
+		cfg.Server.Heartbeat.PongTimeout = 2
+		cfg.Server.Heartbeat.CheckInterval = 1
+	})
+	alive, _ := s.Connect(t, "alive")
+	silent, _ := s.Connect(t, "silent")
+
+	// The WebSocket library answers pings while reading
+	aliveErr := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := alive.WS().ReadMessage(); err != nil {
+				aliveErr <- err
+				return
+			}
+		}
+	}()
+	silent.WS().SetPingHandler(func(string) error { return nil })
+
+	closeErr := silent.ExpectClose()
+	if closeErr == nil || closeErr.Text != "heartbeat_timeout" {
+		t.Errorf("silent client closed with %v, want heartbeat_timeout", closeErr)
+	}
+
+	select {
+	case err := <-aliveErr:
+		t.Errorf("client answering pings was disconnected: %v", err)
+	default:
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	c, _ := s.Connect(t, "a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Gateway.Drain(ctx, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	var notice protocol.DrainNotice
+	if err := c.ExpectEvent("gateway.draining").Decode(&notice); err != nil {
+		t.Fatal(err)
+	}
+	if notice.Reason != "test" {
+		t.Errorf("drain reason = %q, want test", notice.Reason)
+	}
+	if res := c.Call("state", nil); res.Ok {
+		t.Error("state answered while draining")
+	}
+
+	s.Stop()
+	closeErr := c.ExpectClose()
+	if closeErr == nil || closeErr.Code != websocket.CloseServiceRestart {
+		t.Errorf("closed with %v, want service restart", closeErr)
+	}
+}
+
+func TestDrainWaitsForTurns(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	c, _ := s.Connect(t, "a")
+
+	// Hold the first turn in the LLM until released
+	started, release := make(chan struct{}), make(chan struct{})
+	s.LLM.Reply = func(req *llm.Request) (string, error) {
+		close(started)
+		<-release
+		return "done", nil
+	}
+	id := c.Send("agent.message", &protocol.AgentRequest{ChannelID: "chat", Message: "slow"})
+	<-started
+
+	drained := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), gatewaytest.Timeout)
+		defer cancel()
+		drained <- s.Gateway.Drain(ctx, "test")
+	}()
+	c.ExpectEvent("gateway.draining")
+
+	// New turns are refused while the running one is waited for
+	if res := c.Call("agent.message", &protocol.AgentRequest{ChannelID: "chat", Message: "late"}); res.Ok || res.Error != "gateway draining, reconnect later" {
+		t.Errorf("turn while draining: ok:%v error:%q", res.Ok, res.Error)
+	}
+	select {
+	case err := <-drained:
+		t.Fatalf("drain returned before the turn finished: %v", err)
+	default:
+	}
+
+	close(release)
+	res := c.Expect("response to the slow turn", func(f *gatewaytest.Frame) bool { return f.ID == id })
+	if !res.Ok {
+		t.Errorf("turn in flight failed: %s", res.Error)
+	}
+	if err := <-drained; err != nil {
+		t.Errorf("Drain: %v", err)
+	}
+}
+
+func TestWorkspaceMembership(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Auth.Enabled = true
+		cfg.Auth.TokenRequired = true
+		cfg.Auth.Tokens = []string{"user-token"}
+		cfg.Auth.AdminTokens = []string{"admin-token"}
+		cfg.Auth.Users = []config.UserConfig{
+			{ID: "alice", Tokens: []string{"alice-token"}},
+			{ID: "bob", Tokens: []string{"bob-token"}},
+		}
+	})
+	connect := func(token, device string) *gatewaytest.Conn {
+		c := s.Dial(t)
+		if res := c.Call("connect", &protocol.ConnectRequest{Token: token, DeviceID: device}); !res.Ok {
+			t.Fatalf("handshake of %s failed: %s", device, res.Error)
+		}
+		return c
+	}
+
+	admin := connect("admin-token", "admin")
+	res := admin.Call("workspace.create", map[string]interface{}{"spec": &protocol.WorkspaceSpec{
+		ID: "team", Name: "Team", SystemPrompt: "private", Members: []string{"alice"},
+	}})
+	if !res.Ok {
+		t.Fatalf("workspace.create: %s", res.Error)
+	}
+
+	// ids lists the workspaces a client is shown
+	ids := func(c *gatewaytest.Conn) string {
+		var list protocol.WorkspaceResponse
+		if err := c.Call("workspace.list", nil).Decode(&list); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, ws := range list.Workspaces {
+			ids = append(ids, ws.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	alice, bob := connect("alice-token", "phone"), connect("bob-token", "tablet")
+	if got := ids(admin); !strings.Contains(got, "team") {
+		t.Errorf("admin sees %s, want team among them", got)
+	}
+	if got := ids(alice); !strings.Contains(got, "team") {
+		t.Errorf("member sees %s, want team among them", got)
+	}
+	if got := ids(bob); strings.Contains(got, "team") {
+		t.Errorf("non-member sees %s", got)
+	}
+
+	for _, method := range []string{"workspace.get", "workspace.switch"} {
+		res := bob.Call(method, &protocol.WorkspaceRequest{Workspace: "team"})
+		if res.Ok || res.Error != "not a member of this workspace" {
+			t.Errorf("%s as non-member: ok:%v error:%q", method, res.Ok, res.Error)
+		}
+		if res := alice.Call(method, &protocol.WorkspaceRequest{Workspace: "team"}); !res.Ok {
+			t.Errorf("%s as member: %s", method, res.Error)
+		}
+	}
+
+	// Membership goes by the token, not by the device a client names
+	spoof := connect("user-token", "alice")
+	if got := ids(spoof); strings.Contains(got, "team") {
+		t.Errorf("device named after a member sees %s", got)
+	}
+	res = spoof.Call("workspace.switch", &protocol.WorkspaceRequest{Workspace: "team"})
+	if res.Ok || res.Error != "not a member of this workspace" {
+		t.Errorf("workspace.switch with a member's device: ok:%v error:%q", res.Ok, res.Error)
+	}
+	joined := s.Dial(t).Call("connect", &protocol.ConnectRequest{Token: "bob-token", DeviceID: "alice", Workspace: "team"})
+	if joined.Ok {
+		t.Error("handshake into team with a member's device accepted")
+	}
+}
+
+func TestNodeWorkspaces(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Auth.Enabled = true
+		cfg.Auth.TokenRequired = true
+		cfg.Auth.Tokens = []string{"user-token"}
+		cfg.Auth.AdminTokens = []string{"admin-token"}
+		cfg.Auth.Users = []config.UserConfig{
+			{ID: "alice", Tokens: []string{"alice-token"}},
+			{ID: "laptop", Tokens: []string{"laptop-token"}},
+		}
+	})
+	connect := func(req *protocol.ConnectRequest) *gatewaytest.Conn {
+		c := s.Dial(t)
+		if res := c.Call("connect", req); !res.Ok {
+			t.Fatalf("handshake of %s failed: %s", req.DeviceID, res.Error)
+		}
+		return c
+	}
+
+	admin := connect(&protocol.ConnectRequest{Token: "admin-token", DeviceID: "admin"})
+	res := admin.Call("workspace.create", map[string]interface{}{"spec": &protocol.WorkspaceSpec{
+		ID: "team", Name: "Team", Members: []string{"alice", "laptop"},
+	}})
+	if !res.Ok {
+		t.Fatalf("workspace.create: %s", res.Error)
+	}
+
+	// A node pairs from the team workspace
+	node := connect(&protocol.ConnectRequest{Token: "laptop-token", DeviceID: "laptop", ClientType: "node", Workspace: "team"})
+	var pairing protocol.PairingInfo
+	if err := node.Call("node.pair", &protocol.PairRequest{Name: "laptop"}).Decode(&pairing); err != nil {
+		t.Fatalf("node.pair: %v", err)
+	}
+	var approved protocol.NodeResponse
+	if err := admin.Call("node.approve", &protocol.NodeRequest{Code: pairing.Code}).Decode(&approved); err != nil {
+		t.Fatalf("node.approve: %v", err)
+	}
+	if approved.Node.Workspace != "team" {
+		t.Errorf("node workspace = %q, want team", approved.Node.Workspace)
+	}
+
+	// count returns how many nodes a client is shown
+	count := func(c *gatewaytest.Conn) int {
+		var list protocol.NodeResponse
+		if err := c.Call("node.list", nil).Decode(&list); err != nil {
+			t.Fatal(err)
+		}
+		return len(list.Nodes)
+	}
+
+	alice := connect(&protocol.ConnectRequest{Token: "alice-token", DeviceID: "phone", Workspace: "team"})
+	bob := connect(&protocol.ConnectRequest{Token: "user-token", DeviceID: "bob"})
+	if n := count(admin); n != 1 {
+		t.Errorf("admin sees %d nodes, want 1", n)
+	}
+	if n := count(alice); n != 1 {
+		t.Errorf("team member sees %d nodes, want 1", n)
+	}
+	if n := count(bob); n != 0 {
+		t.Errorf("other workspace sees %d nodes, want 0", n)
+	}
+
+	get := &protocol.NodeRequest{NodeID: approved.Node.ID}
+	if res := alice.Call("node.get", get); !res.Ok {
+		t.Errorf("node.get as team member: %s", res.Error)
+	}
+	if res := bob.Call("node.get", get); res.Ok || res.Error != "unknown node" {
+		t.Errorf("node.get from other workspace: ok:%v error:%q", res.Ok, res.Error)
+	}
+}
+
+func TestPresenceIdentity(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Auth.Enabled = true
+		cfg.Auth.TokenRequired = true
+		cfg.Auth.Tokens = []string{"device-token"}
+		cfg.Auth.Users = []config.UserConfig{{ID: "alice", Tokens: []string{"alice-token"}}}
+	})
+
+	// user returns the user the presence of a connection counts for
+	user := func(c *gatewaytest.Conn) string {
+		var info protocol.PresenceInfo
+		if err := c.Call("presence.get", nil).Decode(&info); err != nil {
+			t.Fatal(err)
+		}
+		return info.UserID
+	}
+
+	phone := s.Dial(t)
+	if res := phone.Call("connect", &protocol.ConnectRequest{Token: "alice-token", DeviceID: "phone"}); !res.Ok {
+		t.Fatalf("handshake with user token: %s", res.Error)
+	}
+	if got := user(phone); got != "alice" {
+		t.Errorf("user = %q, want alice from the token", got)
+	}
+
+	laptop := s.Dial(t)
+	if res := laptop.Call("connect", &protocol.ConnectRequest{Token: "device-token", DeviceID: "laptop"}); !res.Ok {
+		t.Fatalf("handshake with device token: %s", res.Error)
+	}
+	if got := user(laptop); got != "laptop" {
+		t.Errorf("user = %q, want the device", got)
+	}
+
+	// Nobody may claim to be another user
+	for _, token := range []string{"device-token", "alice-token"} {
+		c := s.Dial(t)
+		res := c.Call("connect", &protocol.ConnectRequest{Token: token, DeviceID: "tablet", UserID: "bob"})
+		if res.Ok || res.Error != "user_id does not match the token" {
+			t.Errorf("claiming bob with %s: ok:%v error:%q", token, res.Ok, res.Error)
+		}
+	}
+}
+
+func TestPresenceWorkspaces(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Features.Presence = true
+		cfg.Auth.Enabled = true
+		cfg.Auth.TokenRequired = true
+		cfg.Auth.AdminTokens = []string{"admin-token"}
+		cfg.Auth.Users = []config.UserConfig{
+			{ID: "alice", Tokens: []string{"alice-token"}},
+			{ID: "bob", Tokens: []string{"bob-token"}},
+		}
+	})
+	connect := func(token, device, workspace string) *gatewaytest.Conn {
+		c := s.Dial(t)
+		req := &protocol.ConnectRequest{Token: token, DeviceID: device, Workspace: workspace}
+		if res := c.Call("connect", req); !res.Ok {
+			t.Fatalf("handshake of %s failed: %s", device, res.Error)
+		}
+		return c
+	}
+	admin := connect("admin-token", "console", "")
+	if res := admin.Call("workspace.create", map[string]interface{}{"spec": &protocol.WorkspaceSpec{ID: "team", Name: "Team"}}); !res.Ok {
+		t.Fatalf("workspace.create: %s", res.Error)
+	}
+
+	// users lists the users a client is shown
+	users := func(c *gatewaytest.Conn) string {
+		var list struct {
+			Presence []*protocol.PresenceInfo `json:"presence"`
+		}
+		if err := c.Call("presence.list", nil).Decode(&list); err != nil {
+			t.Fatal(err)
+		}
+		var users []string
+		for _, info := range list.Presence {
+			users = append(users, info.UserID)
+		}
+		return strings.Join(users, ",")
+	}
+	// isPresenceOf matches a presence.update event about a user
+	isPresenceOf := func(user, status string) func(f *gatewaytest.Frame) bool {
+		return func(f *gatewaytest.Frame) bool {
+			var data events.PresenceEventData
+			return f.Type == protocol.TypeEvent && f.Event == string(protocol.EventPresenceUpdate) &&
+				f.Decode(&data) == nil && data.UserID == user && data.Status == status
+		}
+	}
+
+	// A read timeout ends a connection, so a second one of alice watches
+	// for events that must not come
+	alice, watch := connect("alice-token", "phone", ""), connect("alice-token", "tablet", "")
+	bob := connect("bob-token", "laptop", "team")
+	watch.ExpectNone("presence of bob from another workspace", 200*time.Millisecond, isPresenceOf("bob", "online"))
+	if got := users(alice); strings.Contains(got, "bob") {
+		t.Errorf("alice is shown %s", got)
+	}
+	if got := users(bob); got != "bob" {
+		t.Errorf("bob is shown %s, want only bob", got)
+	}
+	var info protocol.PresenceInfo
+	if err := alice.Call("presence.get", &protocol.PresenceRequest{UserID: "bob"}).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != "offline" {
+		t.Errorf("bob from another workspace is %s, want offline", info.Status)
+	}
+
+	// Bob comes over to alice's workspace
+	if res := bob.Call("workspace.switch", &protocol.WorkspaceRequest{Workspace: "default"}); !res.Ok {
+		t.Fatalf("workspace.switch: %s", res.Error)
+	}
+	alice.Expect("bob online", isPresenceOf("bob", "online"))
+	if got := users(alice); !strings.Contains(got, "bob") {
+		t.Errorf("alice is shown %s, want bob among them", got)
+	}
+}
+
+func TestOriginPolicy(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Server.WebSocket.AllowedOrigins = []string{
+			"https://app.example.com",
+			"https://*.example.org",
+			"*.example.net:8443",
+			`re:https://[a-z]+\.example\.io`,
+		}
+	})
+
+	for _, tc := range []struct {
+		origin string
+		ok     bool
+	}{
+		{"", true},
+		{"https://app.example.com", true},
+		{"https://evil.example.com", false},
+		{"https://a.example.org", true},
+		{"http://a.example.org", false},
+		{"https://example.org", false},
+		{"https://a.example.net:8443", true},
+		{"https://a.example.net", false},
+		{"https://a.example.io", true},
+		{"https://a.example.io.evil.net", false},
+		{"https://evil.net/https://a.example.io", false},
+	} {
+		header := http.Header{}
+		if tc.origin != "" {
+			header.Set("Origin", tc.origin)
+		}
+		dialer := websocket.Dialer{HandshakeTimeout: gatewaytest.Timeout}
+		ws, res, err := dialer.Dial(s.URL, header)
+		if tc.ok {
+			if err != nil {
+				t.Errorf("origin %q refused: %v", tc.origin, err)
+				continue
+			}
+			ws.Close()
+		} else if err == nil {
+			ws.Close()
+			t.Errorf("origin %q allowed", tc.origin)
+		} else if res == nil || res.StatusCode != http.StatusForbidden {
+			t.Errorf("origin %q: %v, want 403", tc.origin, err)
+		}
+	}
+}
+
+func TestConnectionLimits(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Server.WebSocket.MaxConnectionsPerIP = 3
+		cfg.Server.WebSocket.MaxConnectionsPerDevice = 1
+	})
+
+	s.Connect(t, "phone")
+
+	// A second connection of the device is refused at the handshake
+	c := s.Dial(t)
+	if res := c.Call("connect", &protocol.ConnectRequest{Token: "test", DeviceID: "phone"}); res.Ok {
+		t.Error("second connection of a device accepted")
+	}
+
+	// The same device announced at upgrade is refused before the upgrade
+	dialer := websocket.Dialer{HandshakeTimeout: gatewaytest.Timeout}
+	if ws, res, err := dialer.Dial(s.URL+"?device_id=phone", nil); err == nil {
+		ws.Close()
+		t.Error("upgrade of a device at its limit accepted")
+	} else if res == nil || res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("upgrade of a device at its limit: %v, want 429", err)
+	}
+
+	s.Dial(t)
+	if ws, res, err := dialer.Dial(s.URL, nil); err == nil {
+		ws.Close()
+		t.Error("connection over the per-address limit accepted")
+	} else if res == nil || res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("connection over the per-address limit: %v, want 429", err)
+	}
+}
+
+func TestWorkspaceSwitchSnapshot(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Server.SnapshotInterval = 1
+		cfg.Auth.Enabled = true
+		cfg.Auth.AdminTokens = []string{"test"}
+	})
+	a, _ := s.Connect(t, "a")
+	if res := a.Call("workspace.create", map[string]interface{}{"spec": &protocol.WorkspaceSpec{ID: "team", Name: "Team"}}); !res.Ok {
+		t.Fatalf("workspace.create: %s", res.Error)
+	}
+
+	// Move the seq of the team workspace past the one of default
+	b := s.Dial(t)
+	if res := b.Call("connect", &protocol.ConnectRequest{Token: "test", DeviceID: "b", Workspace: "team"}); !res.Ok {
+		t.Fatalf("handshake of b: %s", res.Error)
+	}
+	c := s.Dial(t)
+	if res := c.Call("connect", &protocol.ConnectRequest{Token: "test", DeviceID: "c", Workspace: "team"}); !res.Ok {
+		t.Fatalf("handshake of c: %s", res.Error)
+	}
+	b.ExpectEvent(string(protocol.EventStateUpdate))
+
+	var resp protocol.WorkspaceResponse
+	if err := a.Call("workspace.switch", &protocol.WorkspaceRequest{Workspace: "team"}).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	state := resp.State
+	if state == nil || state.Workspace != "team" || state.Seq == 0 {
+		t.Fatalf("switch response state = %+v, want a snapshot of team", state)
+	}
+	devices := make(map[string]bool)
+	for _, client := range state.Clients {
+		devices[client.DeviceID] = true
+	}
+	if !devices["a"] || !devices["b"] || !devices["c"] {
+		t.Errorf("snapshot clients = %v, want a, b and c", devices)
+	}
+
+	// The next diff of the new workspace follows the snapshot
+	c.Close()
+	diff := a.Expect("state.update of team", func(f *gatewaytest.Frame) bool {
+		var d protocol.StateDiff
+		return f.Event == string(protocol.EventStateUpdate) && f.Decode(&d) == nil && d.Workspace == "team"
+	})
+	var d protocol.StateDiff
+	if err := diff.Decode(&d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Seq != state.Seq+1 {
+		t.Errorf("diff seq = %d after a snapshot at %d", d.Seq, state.Seq)
+	}
+}
+
+func TestEncodingNegotiation(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Server.WebSocket.BinaryEncoding = true
+		cfg.Server.WebSocket.Compression = true
+	})
+
+	// dial connects with subprotocols and returns the hello, read from one
+	// frame of the expected type
+	dial := func(subprotocols []string, binary bool) (string, *protocol.HelloPayload) {
+		dialer := websocket.Dialer{HandshakeTimeout: gatewaytest.Timeout, Subprotocols: subprotocols, EnableCompression: true}
+		ws, _, err := dialer.Dial(s.URL, nil)
+		if err != nil {
+			t.Fatalf("dial with %v: %v", subprotocols, err)
+		}
+		defer ws.Close()
+
+		req := map[string]interface{}{
+			"type": "req", "id": "1", "method": "connect",
+			"params": map[string]interface{}{"token": "test", "device_id": "d"},
+		}
+		frameType, data := websocket.TextMessage, []byte(nil)
+		if binary {
+			frameType, data = websocket.BinaryMessage, mustMsgpack(t, req)
+		} else if data, err = json.Marshal(req); err != nil {
+			t.Fatal(err)
+		}
+		if err := ws.WriteMessage(frameType, data); err != nil {
+			t.Fatal(err)
+		}
+
+		ws.SetReadDeadline(time.Now().Add(gatewaytest.Timeout))
+		got, frame, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("read hello: %v", err)
+		}
+		if got != frameType {
+			t.Fatalf("hello in frame type %d, want %d", got, frameType)
+		}
+		if binary {
+			var value interface{}
+			if err := msgpack.Unmarshal(frame, &value); err != nil {
+				t.Fatalf("hello is not msgpack: %v", err)
+			}
+			if frame, err = json.Marshal(value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var res struct {
+			Ok      bool                  `json:"ok"`
+			Payload protocol.HelloPayload `json:"payload"`
+		}
+		if err := json.Unmarshal(frame, &res); err != nil || !res.Ok {
+			t.Fatalf("hello = %s, want ok", frame)
+		}
+		return ws.Subprotocol(), &res.Payload
+	}
+
+	for _, tc := range []struct {
+		subprotocols []string
+		binary       bool
+		want         string
+		encoding     string
+	}{
+		{nil, false, "", "json"},
+		{[]string{protocol.SubprotocolJSON}, false, protocol.SubprotocolJSON, "json"},
+		{[]string{protocol.SubprotocolMsgpack, protocol.SubprotocolJSON}, true, protocol.SubprotocolMsgpack, "msgpack"},
+		{[]string{"other"}, false, "", "json"},
+	} {
+		subprotocol, hello := dial(tc.subprotocols, tc.binary)
+		if subprotocol != tc.want || hello.Encoding != tc.encoding {
+			t.Errorf("offering %v: subprotocol %q encoding %q, want %q and %q", tc.subprotocols, subprotocol, hello.Encoding, tc.want, tc.encoding)
+		}
+	}
+}
+
+// mustMsgpack encodes a value as MessagePack
+func mustMsgpack(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := msgpack.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestServerCalls(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	c, _ := s.Connect(t, "a")
+	clients := s.Gateway.GetClients()
+	if len(clients) != 1 {
+		t.Fatalf("gateway has %d clients, want 1", len(clients))
+	}
+	clientID := clients[0].ID
+
+	type result struct {
+		payload json.RawMessage
+		err     error
+	}
+	call := func(ctx context.Context, method string) <-chan result {
+		done := make(chan result, 1)
+		go func() {
+			payload, err := s.Gateway.Call(ctx, clientID, method, map[string]int{"n": 1})
+			done <- result{payload, err}
+		}()
+		return done
+	}
+	request := func(method string) *gatewaytest.Frame {
+		return c.Expect("request "+method, func(f *gatewaytest.Frame) bool {
+			return f.Type == protocol.TypeReq && f.Method == method
+		})
+	}
+
+	// Responses are matched to calls by ID, whatever their order
+	first, second := call(context.Background(), "first"), call(context.Background(), "second")
+	req1, req2 := request("first"), request("second")
+	if req1.ID == req2.ID {
+		t.Fatalf("two calls share the ID %s", req1.ID)
+	}
+	c.SendRaw(`{"type":"res","id":"` + req2.ID + `","ok":false,"error":"nope"}`)
+	c.SendRaw(`{"type":"res","id":"` + req1.ID + `","ok":true,"payload":{"n":2}}`)
+	if r := <-first; r.err != nil || string(r.payload) != `{"n":2}` {
+		t.Errorf("first call = %s, %v", r.payload, r.err)
+	}
+	var callErr *gateway.CallError
+	if r := <-second; !errors.As(r.err, &callErr) || callErr.Message != "nope" {
+		t.Errorf("second call error = %v, want the client's error", r.err)
+	}
+
+	// A response to nothing is ignored
+	c.SendRaw(`{"type":"res","id":"` + req1.ID + `","ok":true}`)
+
+	// Timed out calls are cancelled on the client
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	late := call(ctx, "late")
+	req := request("late")
+	if r := <-late; !errors.Is(r.err, gateway.ErrCallTimeout) {
+		t.Errorf("late call error = %v, want ErrCallTimeout", r.err)
+	}
+	var cancelled map[string]string
+	if err := c.ExpectEvent(string(protocol.EventCallCancelled)).Decode(&cancelled); err != nil || cancelled["id"] != req.ID {
+		t.Errorf("call.cancelled = %v, want id %s", cancelled, req.ID)
+	}
+
+	// Calls pending when the client goes away fail
+	gone := call(context.Background(), "gone")
+	request("gone")
+	c.Close()
+	if r := <-gone; !errors.Is(r.err, gateway.ErrClientGone) {
+		t.Errorf("call to a closed client error = %v, want ErrClientGone", r.err)
+	}
+}
+
+func TestNodePairing(t *testing.T) {
+	s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+		cfg.Auth.Enabled = true
+		cfg.Auth.AdminTokens = []string{"admin-token"}
+	})
+	admin := s.Dial(t)
+	if res := admin.Call("connect", &protocol.ConnectRequest{Token: "admin-token", DeviceID: "admin"}); !res.Ok {
+		t.Fatalf("handshake of admin failed: %s", res.Error)
+	}
+	connect := func(req *protocol.ConnectRequest) (*gatewaytest.Conn, *gatewaytest.Frame) {
+		c := s.Dial(t)
+		req.Token, req.ClientType = "test", "node"
+		return c, c.Call("connect", req)
+	}
+
+	node, _ := connect(&protocol.ConnectRequest{DeviceID: "phone"})
+	var pairing protocol.PairingInfo
+	if err := node.Call("node.pair", &protocol.PairRequest{Name: "Phone", Capabilities: []string{"camera.snap"}}).Decode(&pairing); err != nil || pairing.Code == "" {
+		t.Fatalf("node.pair = %+v, %v", pairing, err)
+	}
+	var pending struct {
+		Pending []protocol.PairingInfo `json:"pending"`
+	}
+	if err := admin.Call("node.pending", nil).Decode(&pending); err != nil || len(pending.Pending) != 1 || pending.Pending[0].Code != pairing.Code {
+		t.Errorf("node.pending = %+v, want the request", pending.Pending)
+	}
+
+	// The node is handed its identity once approved
+	if res := admin.Call("node.approve", &protocol.NodeRequest{Code: pairing.Code}); !res.Ok {
+		t.Fatalf("node.approve: %s", res.Error)
+	}
+	var result protocol.PairingResult
+	if err := node.ExpectEvent(string(protocol.EventNodePairing)).Decode(&result); err != nil || result.Status != "approved" || result.Token == "" {
+		t.Fatalf("node.pairing = %+v, want approved with a token", result)
+	}
+	if res := admin.Call("node.approve", &protocol.NodeRequest{Code: pairing.Code}); res.Ok {
+		t.Error("pairing code approved twice")
+	}
+	node.Close()
+	admin.Expect("node.status offline", func(f *gatewaytest.Frame) bool {
+		var data events.NodeEventData
+		return f.Event == string(protocol.EventNodeStatus) && f.Decode(&data) == nil && data.Action == "offline"
+	})
+
+	// Reconnecting takes the identity, with its token and device, and a
+	// failed handshake leaves the node offline
+	for _, req := range []*protocol.ConnectRequest{
+		{DeviceID: "phone", NodeID: result.NodeID, NodeToken: "wrong"},
+		{DeviceID: "tablet", NodeID: result.NodeID, NodeToken: result.Token},
+		{DeviceID: "phone", NodeID: result.NodeID, NodeToken: result.Token, Workspace: "missing"},
+	} {
+		if _, res := connect(req); res.Ok {
+			t.Errorf("reconnect as %s with token %s accepted", req.DeviceID, req.NodeToken)
+		}
+	}
+	var got protocol.NodeResponse
+	if err := admin.Call("node.get", &protocol.NodeRequest{NodeID: result.NodeID}).Decode(&got); err != nil || got.Node.Status != "offline" {
+		t.Errorf("node after failed handshakes = %+v, %v; want offline", got.Node, err)
+	}
+	// Capabilities advertised on reconnect are limited to the approved ones
+	node, res := connect(&protocol.ConnectRequest{DeviceID: "phone", NodeID: result.NodeID, NodeToken: result.Token, Capabilities: []string{"camera.snap", "shell.exec"}})
+	if !res.Ok {
+		t.Fatalf("reconnect of the paired node: %s", res.Error)
+	}
+	if res := admin.Call("node.invoke", &protocol.NodeRequest{NodeID: result.NodeID, Capability: "shell.exec"}); res.Ok || !strings.Contains(res.Error, "does not support") {
+		t.Errorf("invoking a capability never approved: ok:%v error:%q", res.Ok, res.Error)
+	}
+
+	// Capabilities are invoked on the node
+	invoke := admin.Send("node.invoke", &protocol.NodeRequest{NodeID: result.NodeID, Capability: "camera.snap", Params: json.RawMessage(`{"w":1}`)})
+	req := node.Expect("camera.snap request", func(f *gatewaytest.Frame) bool { return f.Method == "camera.snap" })
+	if string(req.Params) != `{"w":1}` {
+		t.Errorf("node got params %s", req.Params)
+	}
+	node.SendRaw(`{"type":"res","id":"` + req.ID + `","ok":true,"payload":{"image":"x"}}`)
+	var invoked protocol.NodeResponse
+	if err := admin.Expect("node.invoke response", func(f *gatewaytest.Frame) bool { return f.ID == invoke }).Decode(&invoked); err != nil || string(invoked.Result) != `{"image":"x"}` {
+		t.Errorf("node.invoke result = %s, %v", invoked.Result, err)
+	}
+	if res := admin.Call("node.invoke", &protocol.NodeRequest{NodeID: result.NodeID, Capability: "shell.run"}); res.Ok || !strings.Contains(res.Error, "does not support") {
+		t.Errorf("invoking a missing capability: ok:%v error:%q", res.Ok, res.Error)
+	}
+
+	// Rejected nodes are told so
+	other, _ := connect(&protocol.ConnectRequest{DeviceID: "watch"})
+	var otherPairing protocol.PairingInfo
+	other.Call("node.pair", &protocol.PairRequest{Name: "Watch"}).Decode(&otherPairing)
+	if res := admin.Call("node.reject", &protocol.NodeRequest{Code: otherPairing.Code}); !res.Ok {
+		t.Fatalf("node.reject: %s", res.Error)
+	}
+	var rejected protocol.PairingResult
+	if err := other.ExpectEvent(string(protocol.EventNodePairing)).Decode(&rejected); err != nil || rejected.Status != "rejected" {
+		t.Errorf("node.pairing = %+v, want rejected", rejected)
+	}
+
+	// Unpairing disconnects the node and revokes its identity
+	if res := admin.Call("node.unpair", &protocol.NodeRequest{NodeID: result.NodeID}); !res.Ok {
+		t.Fatalf("node.unpair: %s", res.Error)
+	}
+	node.ExpectClose()
+	if _, res := connect(&protocol.ConnectRequest{DeviceID: "phone", NodeID: result.NodeID, NodeToken: result.Token}); res.Ok {
+		t.Error("unpaired node reconnected")
+	}
+}
+
+func TestNodeAdminMethods(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		auth bool
+	}{
+		{"without auth", false},
+		{"with a user token", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := gatewaytest.NewServer(t, func(cfg *config.Config) {
+				cfg.Auth.Enabled = tc.auth
+				cfg.Auth.AdminTokens = []string{"admin-token"}
+			})
+			node := s.Dial(t)
+			if res := node.Call("connect", &protocol.ConnectRequest{Token: "test", DeviceID: "laptop", ClientType: "node"}); !res.Ok {
+				t.Fatalf("handshake of the node failed: %s", res.Error)
+			}
+			var pairing protocol.PairingInfo
+			if err := node.Call("node.pair", &protocol.PairRequest{Name: "Laptop", Capabilities: []string{"shell.exec"}}).Decode(&pairing); err != nil {
+				t.Fatalf("node.pair: %v", err)
+			}
+
+			// Neither the node nor another client may approve the request
+			// or reach the capabilities of paired nodes
+			anonymous, _ := s.Connect(t, "anonymous")
+			for _, c := range []*gatewaytest.Conn{node, anonymous} {
+				for _, call := range []struct {
+					method string
+					req    *protocol.NodeRequest
+				}{
+					{"node.pending", nil},
+					{"node.approve", &protocol.NodeRequest{Code: pairing.Code}},
+					{"node.invoke", &protocol.NodeRequest{NodeID: "node-1", Capability: "shell.exec"}},
+				} {
+					res := c.Call(call.method, call.req)
+					if res.Ok || res.Error != protocol.ErrUnauthorized.Error() {
+						t.Errorf("%s: ok:%v error:%q, want unauthorized", call.method, res.Ok, res.Error)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/internal/storage"
+	"github.com/openclaw/go-openclaw/pkg/cluster"
+)
+
+// errDraining refuses new work while the gateway drains
+var errDraining = errors.New("gateway draining, reconnect later")
+
+// listenFDEnv names the environment variable carrying an inherited
+// listening socket from a parent gateway process
+const listenFDEnv = "OPENCLAW_LISTEN_FD"
+
+// readyFDEnv names the environment variable carrying the pipe on which a
+// process started by Restart reports that it serves
+const readyFDEnv = "OPENCLAW_READY_FD"
+
+// handoffTimeout bounds the wait for a new process to report it serves
+const handoffTimeout = 30 * time.Second
+
+// errHandedOff refuses store writes once Restart handed the store to a
+// new process
+var errHandedOff = errors.New("storage handed to a new gateway process")
+
+// handoffBackplane is a backplane with a listener of its own, which
+// Restart hands to the new process along with the node ID
+type handoffBackplane interface {
+	Listener() net.Listener
+	Handoff(ctx context.Context) error
+}
+
+// handoffStore is the store of the gateway. Restart freezes it before the
+// new process opens the store, so the two never write the same records.
+type handoffStore struct {
+	storage.Store
+	mu     sync.RWMutex
+	frozen bool
+}
+
+// Put stores a record unless the store was handed off
+func (s *handoffStore) Put(collection, id string, v interface{}) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.frozen {
+		return errHandedOff
+	}
+	return s.Store.Put(collection, id, v)
+}
+
+// Delete removes a record unless the store was handed off
+func (s *handoffStore) Delete(collection, id string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.frozen {
+		return errHandedOff
+	}
+	return s.Store.Delete(collection, id)
+}
+
+// setFrozen waits for writes in progress and freezes or thaws the store
+func (s *handoffStore) setFrozen(frozen bool) {
+	s.mu.Lock()
+	s.frozen = frozen
+	s.mu.Unlock()
+}
+
+// listen opens the gateway listener, reusing an inherited socket if the
+// process was started by Restart
+func (g *Gateway) listen() (net.Listener, error) {
+	if fdStr := os.Getenv(listenFDEnv); fdStr != "" {
+		fd, err := strconv.Atoi(fdStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", listenFDEnv, err)
+		}
+		os.Unsetenv(listenFDEnv)
+
+		file := os.NewFile(uintptr(fd), "openclaw-listener")
+		defer file.Close()
+
+		ln, err := net.FileListener(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inherit listener (fd=%d): %w", fd, err)
+		}
+
+		log.Printf("♻️  Inherited listener on %s (fd=%d)", ln.Addr(), fd)
+		return ln, nil
+	}
+
+	ln, err := net.Listen("tcp", g.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", g.addr, err)
+	}
+	return ln, nil
+}
+
+// Restart starts a new gateway process on the listening socket, and on
+// the cluster listener if there is one. The store is frozen before the new
+// process opens it. Once the new process reports that it serves, this one
+// stops accepting, leaves the cluster to it and only serves the
+// connections it has until it is drained and stopped. If the new process
+// fails to start, this one carries on as before.
+func (g *Gateway) Restart() (*os.Process, error) {
+	// The sockets are passed as raw descriptors: os/exec would switch them
+	// to blocking mode, leaving this process's accept loops stuck in a
+	// syscall that closing the listener cannot interrupt
+	listenFD, err := rawFD(g.listener)
+	if err != nil {
+		return nil, fmt.Errorf("listener does not support handoff: %w", err)
+	}
+
+	ready, readyW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ready pipe: %w", err)
+	}
+	defer ready.Close()
+	defer readyW.Close()
+	readyFD, err := rawFD(readyW)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []uintptr{0, 1, 2, listenFD, readyFD}
+	env := append(os.Environ(), listenFDEnv+"=3", readyFDEnv+"=4")
+
+	backplane, _ := g.backplane.(handoffBackplane)
+	if backplane != nil {
+		gossipFD, err := rawFD(backplane.Listener())
+		if err != nil {
+			return nil, fmt.Errorf("cluster listener does not support handoff: %w", err)
+		}
+		files = append(files, gossipFD)
+		env = append(env, cluster.ListenFDEnv+"=5")
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	g.freezeStore(true)
+	pid, err := syscall.ForkExec(executable, os.Args, &syscall.ProcAttr{Env: env, Files: files})
+	readyW.Close()
+	if err != nil {
+		g.freezeStore(false)
+		return nil, fmt.Errorf("failed to start new process: %w", err)
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		g.freezeStore(false)
+		return nil, err
+	}
+
+	if err := waitReady(ready); err != nil {
+		process.Kill()
+		go process.Wait()
+		g.freezeStore(false)
+		return nil, fmt.Errorf("new process (pid=%d) did not start: %w", pid, err)
+	}
+
+	// New connections are the child's; this process keeps the ones it has
+	g.stopAccepting()
+	if backplane != nil {
+		if err := backplane.Handoff(context.Background()); err != nil {
+			log.Printf("Failed to hand off cluster membership: %v", err)
+		}
+	}
+
+	log.Printf("♻️  Handed listener to new process (pid=%d)", pid)
+	return process, nil
+}
+
+// rawFD returns the descriptor of a socket or file without changing its
+// mode
+func rawFD(v interface{}) (uintptr, error) {
+	conn, ok := v.(syscall.Conn)
+	if !ok {
+		return 0, fmt.Errorf("%T has no file descriptor", v)
+	}
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var fd uintptr
+	if err := raw.Control(func(f uintptr) { fd = f }); err != nil {
+		return 0, err
+	}
+	return fd, nil
+}
+
+// waitReady waits for a new process to report on its ready pipe. The pipe
+// closes without a report if the process exits first.
+func waitReady(ready *os.File) error {
+	ready.SetReadDeadline(time.Now().Add(handoffTimeout))
+	buf := make([]byte, 1)
+	if _, err := ready.Read(buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("exited before serving")
+		}
+		return err
+	}
+	return nil
+}
+
+// Ready reports to the process that started this one by Restart that the
+// gateway serves, so the old process stops accepting. Call it once Start
+// has returned; it does nothing in a process not started by Restart.
+func (g *Gateway) Ready() error {
+	fdStr := os.Getenv(readyFDEnv)
+	if fdStr == "" {
+		return nil
+	}
+	os.Unsetenv(readyFDEnv)
+
+	fd, err := strconv.Atoi(fdStr)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", readyFDEnv, err)
+	}
+	pipe := os.NewFile(uintptr(fd), "openclaw-ready")
+	defer pipe.Close()
+
+	if _, err := pipe.Write([]byte{1}); err != nil {
+		return fmt.Errorf("failed to report ready: %w", err)
+	}
+	return nil
+}
+
+// freezeStore freezes or thaws the store for a handoff
+func (g *Gateway) freezeStore(frozen bool) {
+	if store, ok := g.store.(*handoffStore); ok {
+		store.setFrozen(frozen)
+	}
+}
+
+// stopAccepting closes the listener of this process. Established
+// connections stay open; a child given the socket by Restart keeps
+// accepting on its own copy.
+func (g *Gateway) stopAccepting() {
+	if g.listener == nil {
+		return
+	}
+	if err := g.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Printf("Failed to close listener: %v", err)
+	}
+}
+
+// beginRequest counts a request as in flight unless the gateway is
+// draining. Drain sets draining under the same lock before it waits, so
+// nothing is added to inflight once Wait has started.
+func (g *Gateway) beginRequest() bool {
+	g.drainLock.Lock()
+	defer g.drainLock.Unlock()
+
+	if g.draining.Load() {
+		return false
+	}
+	g.inflight.Add(1)
+	return true
+}
+
+// Drain stops accepting new connections and requests, tells clients to
+// reconnect elsewhere, and waits for in-flight requests and agent turns
+// to finish or ctx to expire. Call Stop afterwards to close what is left.
+func (g *Gateway) Drain(ctx context.Context, reason string) error {
+	g.drainLock.Lock()
+	wasDraining := g.draining.Swap(true)
+	g.drainLock.Unlock()
+	if wasDraining {
+		return fmt.Errorf("gateway is already draining")
+	}
+
+	log.Printf("🚰 Draining gateway (reason=%s)", reason)
+
+	notice := &protocol.DrainNotice{
+		GatewayID:      g.id,
+		Reason:         reason,
+		ReconnectAfter: g.config.Server.ReconnectDelay,
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		notice.Deadline = deadline.Unix()
+	}
+
+	for _, client := range g.GetClients() {
+		if err := client.SendEvent(string(protocol.EventGatewayDraining), notice, 0); err != nil {
+			log.Printf("Failed to send drain notice to %s: %v", client.ID, err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		g.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("requests still in flight: %w", ctx.Err())
+	}
+
+	if g.agentRuntime != nil {
+		if err := g.agentRuntime.Drain(ctx); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("✅ Gateway drained in-flight work")
+	return nil
+}
+
+// IsDraining returns true once Drain has been called
+func (g *Gateway) IsDraining() bool {
+	return g.draining.Load()
+}
+
+// waitGroupTimeout waits for the gateway goroutines or ctx to expire
+func (g *Gateway) waitGroupTimeout(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for connections: %w", ctx.Err())
+	}
+}
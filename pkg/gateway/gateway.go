@@ -7,13 +7,24 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fasthttp/websocket"
 	agent "github.com/openclaw/go-openclaw/internal/agent"
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/openclaw/go-openclaw/internal/models"
 	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/internal/storage"
 	"github.com/openclaw/go-openclaw/internal/ws"
+	"github.com/openclaw/go-openclaw/pkg/channels"
+	"github.com/openclaw/go-openclaw/pkg/cluster"
+	"github.com/openclaw/go-openclaw/pkg/nodes"
+	"github.com/openclaw/go-openclaw/pkg/presence"
+	"github.com/openclaw/go-openclaw/pkg/workspaces"
 	"github.com/valyala/fasthttp"
 )
 
@@ -30,6 +41,11 @@ type GatewayState struct {
 type GatewayStats struct {
 	ClientCount int `json:"client_count"`
 	Uptime      int64 `json:"uptime"` // uptime in seconds
+
+	BroadcastDropped uint64 `json:"broadcast_dropped"` // messages dropped by a full broadcast queue
+	DroppedOutgoing  uint64 `json:"dropped_outgoing"`  // messages dropped by client send queues
+	DroppedIncoming  uint64 `json:"dropped_incoming"`  // messages dropped by client receive queues
+	LaggingClients   int    `json:"lagging_clients"`
 }
 
 // Gateway represents a WebSocket gateway server
@@ -49,57 +65,136 @@ type Gateway struct {
 	wg           sync.WaitGroup
 	handler      *ws.Handler
 	agentRuntime *agent.Runtime // NEW: Agent runtime
+	config       *config.Config
+	listener     net.Listener
+	draining     atomic.Bool
+	drainLock    sync.Mutex     // orders inflight.Add before Drain waits
+	inflight     sync.WaitGroup // requests being handled
+	backplane    cluster.Backplane
+	limiter      *connLimiter
+	store        storage.Store
+	nodes        *nodes.Manager
+	nodeClients  map[string]string // node ID -> client ID of online nodes
+	nodesLock    sync.RWMutex
+	workspaces   *workspaces.Manager
+	presence     *presence.Tracker
+	heartbeat    *Heartbeat
+	broadcaster  *Broadcaster
+	channels     *channels.ChannelManager
+	webhooks     map[string]channels.Webhook      // path -> channel receiving updates there
+	webhooksLock sync.RWMutex
+	asks         map[string]chan *channels.Action // ask ID -> waiting channel_ask
+	asksLock     sync.Mutex
+	askSeq       atomic.Uint64
+	turns        map[string]*pendingTurn // turn ID -> turn forwarded to another node
+	turnsLock    sync.Mutex
+	turnSeq      atomic.Uint64
+	state        *stateTracker
+
+	broadcastDropped atomic.Uint64
 }
 
-// New creates a new gateway instance
+// New creates a new gateway instance with the default configuration
 func New(addr string) *Gateway {
+	return newGateway(addr, config.Default())
+}
+
+// NewWithConfig creates a new gateway instance from configuration
+func NewWithConfig(cfg *config.Config) *Gateway {
+	return newGateway(cfg.GetAddr(), cfg)
+}
+
+// newGateway creates a new gateway instance
+func newGateway(addr string, cfg *config.Config) *Gateway {
 	ctx, cancel := context.WithCancel(context.Background())
+	origins := newOriginPolicy(cfg.Server.WebSocket.AllowedOrigins)
 
-	return &Gateway{
+	g := &Gateway{
 		addr:       addr,
 		id:         fmt.Sprintf("gateway-%d", time.Now().UnixNano()),
 		clients:    make(map[string]*Client),
 		register:   make(chan *Client, 64),
 		unregister: make(chan *Client, 64),
-		broadcast:  make(chan []byte, 256),
+		broadcast:  make(chan []byte, cfg.Server.Backpressure.BroadcastBufferSize),
 		eventBus:   protocol.NewEventBus(),
 		ctx:        ctx,
 		cancel:     cancel,
 		handler:    ws.DefaultHandler(),
 		agentRuntime: nil, // NEW: Agent runtime placeholder
+		config:     cfg,
+		limiter:    newConnLimiter(&cfg.Server.WebSocket),
+		nodeClients: make(map[string]string),
 		upgrader: websocket.FastHTTPUpgrader{
-			ReadBufferSize: 1024,
-			WriteBufferSize: 1024,
-			CheckOrigin: func(ctx *fasthttp.RequestCtx) bool {
-				return true // Allow all origins for now
-			},
+			ReadBufferSize:  cfg.Server.WebSocket.ReadBufferSize,
+			WriteBufferSize: cfg.Server.WebSocket.WriteBufferSize,
+			CheckOrigin:     origins.checkOrigin,
+			Subprotocols:    protocol.Subprotocols(cfg.Server.WebSocket.BinaryEncoding),
+			EnableCompression: cfg.Server.WebSocket.Compression,
 		},
 	}
+
+	g.presence = g.newPresenceTracker()
+	g.heartbeat = NewHeartbeat(g, heartbeatConfigFrom(&cfg.Server.Heartbeat))
+	g.broadcaster = NewBroadcaster(g)
+	g.channels = channels.NewChannelManager()
+	g.webhooks = make(map[string]channels.Webhook)
+	g.asks = make(map[string]chan *channels.Action)
+	g.turns = make(map[string]*pendingTurn)
+	g.state = newStateTracker()
+
+	// Keep state in memory until a persistent store is set
+	if err := g.SetStore(storage.NewMemoryStore()); err != nil {
+		panic(fmt.Sprintf("failed to initialize store: %v", err))
+	}
+
+	return g
 }
 
 // Start starts of gateway server
 func (g *Gateway) Start(ctx context.Context) error {
-	g.ctx = ctx
+	g.ctx, g.cancel = context.WithCancel(ctx)
 
 	g.server = &fasthttp.Server{
 		Handler: g.handleHTTP,
 	}
 
-	ln, err := net.Listen("tcp", g.addr)
+	ln, err := g.listen()
 	if err != nil {
-		return fmt.Errorf("failed to listen on %s: %w", g.addr, err)
+		return err
 	}
+	g.listener = ln
 
-	log.Printf("🌐 Gateway listening on %s (id=%s)", g.addr, g.id)
+	log.Printf("🌐 Gateway listening on %s (id=%s)", ln.Addr(), g.id)
+
+	// Join the cluster before serving so remote traffic has somewhere to go
+	if err := g.startCluster(g.ctx); err != nil {
+		ln.Close()
+		return err
+	}
 
 	// Start hub
 	g.wg.Add(1)
 	go g.runHub()
 
+	if g.presence != nil {
+		g.wg.Add(1)
+		go g.runPresence()
+	}
+
+	// Check client liveness and push events and state to clients
+	g.heartbeat.Start()
+	g.broadcaster.Start()
+
+	// Start messaging channels
+	if err := g.channels.StartAll(g.ctx); err != nil {
+		log.Printf("⚠️  Failed to start channels: %v", err)
+	}
+
 	// Start server in background
+	g.wg.Add(1)
 	go func() {
 		defer g.wg.Done()
-		if err := g.server.Serve(ln); err != nil && !errors.Is(err, ErrServerClosed) {
+		if err := g.server.Serve(ln); err != nil && !errors.Is(err, ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
 			log.Printf("Server error: %v", err)
 		}
 	}()
@@ -107,25 +202,47 @@ func (g *Gateway) Start(ctx context.Context) error {
 	return nil
 }
 
+// Addr returns the address the gateway listens on, nil before Start
+func (g *Gateway) Addr() net.Addr {
+	if g.listener == nil {
+		return nil
+	}
+	return g.listener.Addr()
+}
+
 // Stop stops gateway server
 func (g *Gateway) Stop(ctx context.Context) error {
 	log.Println("🛑 Stopping Gateway...")
 
+	// Close all clients first, the server waits for their handlers. This
+	// precedes cancelling, which returns the handlers and lets fasthttp
+	// drop the connections before the close frames are out.
+	closeCode, closeReason := websocket.CloseGoingAway, "gateway stopping"
+	if g.IsDraining() {
+		closeCode, closeReason = websocket.CloseServiceRestart, "gateway restarting"
+	}
+	g.clientsLock.Lock()
+	for _, client := range g.clients {
+		client.CloseWithReason(closeCode, closeReason)
+	}
+	g.clientsLock.Unlock()
+
 	g.cancel()
+	g.heartbeat.Stop()
+	g.broadcaster.Stop()
 
-	// Close server
+	// Close server. Shutdown misses a listener Serve has not taken yet.
 	if g.server != nil {
 		if err := g.server.Shutdown(); err != nil {
 			return fmt.Errorf("failed to shutdown server: %w", err)
 		}
+		g.stopAccepting()
 	}
 
-	// Close all clients
-	g.clientsLock.Lock()
-	for _, client := range g.clients {
-		client.Close()
+	// Stop messaging channels
+	if err := g.channels.StopAll(ctx); err != nil {
+		log.Printf("Failed to stop channels: %v", err)
 	}
-	g.clientsLock.Unlock()
 
 	// Stop agent runtime if running
 	if g.agentRuntime != nil && g.agentRuntime.Status() == "running" {
@@ -134,8 +251,13 @@ func (g *Gateway) Stop(ctx context.Context) error {
 		}
 	}
 
-	// Wait for hub
-	g.wg.Wait()
+	// Leave the cluster
+	g.stopCluster(ctx)
+
+	// Wait for hub and connection handlers
+	if err := g.waitGroupTimeout(ctx); err != nil {
+		return err
+	}
 
 	log.Println("✅ Gateway stopped")
 	return nil
@@ -155,6 +277,23 @@ func (g *Gateway) StartAgent(ctx context.Context, config *agent.Config) error {
 
 	g.agentRuntime = runtime
 
+	// Let the agent act on paired nodes
+	if err := nodes.RegisterTools(runtime.Tools(), g.nodes, g.InvokeNode); err != nil {
+		return fmt.Errorf("failed to register node tools: %w", err)
+	}
+
+	// Let the agent check whether users are around
+	if g.presence != nil {
+		if err := presence.RegisterTools(runtime.Tools(), g.presence); err != nil {
+			return fmt.Errorf("failed to register presence tools: %w", err)
+		}
+	}
+
+	// Let the agent ask users of messaging channels to choose
+	if err := channels.RegisterTools(runtime.Tools(), g.AskChannel); err != nil {
+		return fmt.Errorf("failed to register channel tools: %w", err)
+	}
+
 	// Start agent runtime
 	if err := g.agentRuntime.Start(); err != nil {
 		return fmt.Errorf("failed to start agent runtime: %w", err)
@@ -196,12 +335,22 @@ func (g *Gateway) handleHTTP(ctx *fasthttp.RequestCtx) {
 
 	// WebSocket upgrade for /ws path
 	if path == "/ws" {
+		if g.IsDraining() {
+			ctx.Error("gateway draining", fasthttp.StatusServiceUnavailable)
+			ctx.Response.Header.Set("Retry-After", strconv.Itoa(g.config.Server.ReconnectDelay))
+			return
+		}
 		g.handleWebSocket(ctx)
 		return
 	}
 
 	// Health check
 	if path == "/health" {
+		if g.IsDraining() {
+			ctx.Response.SetStatusCode(fasthttp.StatusServiceUnavailable)
+			ctx.Response.SetBody([]byte(`{"status":"draining","gateway_id":"`+g.id+`"}`))
+			return
+		}
 		ctx.Response.SetStatusCode(fasthttp.StatusOK)
 		ctx.Response.SetBody([]byte(`{"status":"ok","gateway_id":"`+g.id+`"}`))
 		return
@@ -213,6 +362,16 @@ func (g *Gateway) handleHTTP(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	// Messaging channel webhooks
+	if webhook, ok := g.webhook(path); ok {
+		if g.IsDraining() {
+			ctx.Error("gateway draining", fasthttp.StatusServiceUnavailable)
+			return
+		}
+		webhook.ServeWebhook(ctx)
+		return
+	}
+
 	// Not found
 	ctx.Response.SetStatusCode(fasthttp.StatusNotFound)
 }
@@ -240,27 +399,61 @@ func (g *Gateway) handleAgentStatusHTTP(ctx *fasthttp.RequestCtx) {
 
 // handleWebSocket handles WebSocket connections
 func (g *Gateway) handleWebSocket(ctx *fasthttp.RequestCtx) {
-	if err := g.upgrader.Upgrade(ctx, g.handleConnection); err != nil {
+	ip := ctx.RemoteIP().String()
+	deviceID := upgradeDeviceID(ctx)
+
+	// Enforce connection limits before upgrading
+	if status, reason := g.limiter.acquire(ip, deviceID); status != 0 {
+		log.Printf("Rejected WebSocket from %s (device=%s): %s", ip, deviceID, reason)
+		ctx.Error(reason, status)
+		if status == fasthttp.StatusServiceUnavailable {
+			ctx.Response.Header.Set("Retry-After", strconv.Itoa(g.config.Server.ReconnectDelay))
+		}
+		return
+	}
+
+	err := g.upgrader.Upgrade(ctx, func(wsConn *websocket.Conn) {
+		g.handleConnection(wsConn, ip, deviceID)
+	})
+	if err != nil {
+		// The handler never runs, so the slot is not released there
+		g.limiter.release(ip, deviceID)
 		log.Printf("WebSocket upgrade error: %v", err)
 	}
 }
 
-// handleConnection handles a WebSocket connection
-func (g *Gateway) handleConnection(wsConn *websocket.Conn) {
+// handleConnection handles a WebSocket connection. deviceID is the device
+// announced at upgrade, if any.
+func (g *Gateway) handleConnection(wsConn *websocket.Conn, ip, deviceID string) {
 	// Wrap WebSocket connection
-	conn := ws.NewConn(wsConn)
-	connID := conn.ID()
+	conn := ws.NewConnWithConfig(wsConn, g.backpressureFor(""))
+	conn.SetReadLimit(g.config.Server.WebSocket.MaxMessageSize)
+	if g.config.Server.WebSocket.Compression {
+		wsConn.SetCompressionLevel(g.config.Server.WebSocket.CompressionLevel)
+	}
+	// Client IDs key the cluster-wide registry, so they carry the gateway
+	// ID to stay unique across nodes
+	connID := g.id + "/" + conn.ID()
 
 	// Create client
 	client := &Client{
 		ID:         connID,
 		Conn:       conn,
 		gateway:    g,
+		remoteIP:   ip,
+		limitDevice: deviceID,
+		pending:    newPendingCalls(),
 		connectedAt: time.Now(),
 		lastSeen:   time.Now(),
 		status:     "connected",
 		metadata:   make(map[string]string),
 	}
+	defer func() {
+		g.limiter.release(ip, client.limitedDevice())
+	}()
+
+	// Route responses to requests the gateway sent to the client
+	conn.SetResponseHandler(client.handleResponse)
 
 	// Register client
 	g.register <- client
@@ -268,14 +461,13 @@ func (g *Gateway) handleConnection(wsConn *websocket.Conn) {
 	// Start connection pumps
 	conn.Start()
 
-	// Handle messages
-	g.wg.Add(1)
-	go func() {
-		defer g.wg.Done()
-		g.handleClientMessages(client)
-	}()
+	log.Printf("📱 Client connected: %s (encoding=%s)", connID, conn.Encoding())
 
-	log.Printf("📱 Client connected: %s", connID)
+	// Handle messages until the connection closes. fasthttp reclaims the
+	// hijacked connection once this handler returns, so it must block.
+	g.wg.Add(1)
+	defer g.wg.Done()
+	g.handleClientMessages(client)
 }
 
 // handleClientMessages handles incoming messages from a client
@@ -284,13 +476,19 @@ func (g *Gateway) handleClientMessages(client *Client) {
 		select {
 		case msg, ok := <-client.Conn.Receive():
 			if !ok {
+				g.unregisterClient(client)
 				return
 			}
 
-			// Handle message
+			// Handle message, counted as in flight unless draining, when
+			// it is only refused
+			counted := g.beginRequest()
 			if err := g.handleMessage(client, msg); err != nil {
 				log.Printf("Message handling error for %s: %v", client.ID, err)
 			}
+			if counted {
+				g.inflight.Done()
+			}
 
 		case <-g.ctx.Done():
 			return
@@ -300,7 +498,29 @@ func (g *Gateway) handleClientMessages(client *Client) {
 
 // handleMessage handles an incoming message from a client
 func (g *Gateway) handleMessage(client *Client, msg *protocol.ProtocolMessage) error {
-	client.lastSeen = time.Now()
+	// Heartbeats prove liveness, not activity
+	if msg.Type == protocol.TypeReq && msg.Method == "pong" {
+		g.heartbeat.HandlePong(client)
+		return client.SendResponse(msg.ID, true, nil, "")
+	}
+
+	// Everything but the handshake and pings needs an authenticated client
+	if !client.handshaken() && !(msg.Type == protocol.TypeReq && (msg.Method == "connect" || msg.Method == "ping")) {
+		if msg.Type != protocol.TypeReq {
+			return nil
+		}
+		return client.SendResponse(msg.ID, false, nil, "connect first")
+	}
+
+	if msg.Type != protocol.TypeReq || msg.Method != "ping" {
+		client.touch()
+	}
+	g.touchPresence(client, msg)
+
+	// Refuse new work while draining; pings keep the connection alive
+	if g.IsDraining() && msg.Type == protocol.TypeReq && msg.Method != "ping" {
+		return client.SendResponse(msg.ID, false, nil, errDraining.Error())
+	}
 
 	// Handle agent commands
 	if msg.Type == protocol.TypeReq && msg.Method == "agent.start" {
@@ -312,12 +532,40 @@ func (g *Gateway) handleMessage(client *Client, msg *protocol.ProtocolMessage) e
 	if msg.Type == protocol.TypeReq && msg.Method == "agent.status" {
 		return g.handleAgentStatus(client, msg)
 	}
+	if msg.Type == protocol.TypeReq && strings.HasPrefix(msg.Method, "agent.") {
+		return g.handleAgentRequest(client, msg)
+	}
 
 	// Handle connect message
 	if msg.Type == protocol.TypeReq && msg.Method == "connect" {
 		return g.handleConnect(client, msg)
 	}
 
+	// Handle node pairing and invocation
+	if msg.Type == protocol.TypeReq && strings.HasPrefix(msg.Method, "node.") {
+		return g.handleNodeRequest(client, msg)
+	}
+
+	// Handle state snapshots
+	if msg.Type == protocol.TypeReq && msg.Method == "state" {
+		return g.handleState(client, msg)
+	}
+
+	// Handle agent session listing and export
+	if msg.Type == protocol.TypeReq && strings.HasPrefix(msg.Method, "sessions.") {
+		return g.handleSessionsRequest(client, msg)
+	}
+
+	// Handle presence updates and queries
+	if msg.Type == protocol.TypeReq && strings.HasPrefix(msg.Method, "presence.") {
+		return g.handlePresenceRequest(client, msg)
+	}
+
+	// Handle workspace management and switching
+	if msg.Type == protocol.TypeReq && strings.HasPrefix(msg.Method, "workspace.") {
+		return g.handleWorkspaceRequest(client, msg)
+	}
+
 	// Use protocol handler
 	if err := g.handler.HandleMessage(client.Conn, msg); err != nil {
 		log.Printf("Handler error: %v", err)
@@ -378,34 +626,92 @@ func (g *Gateway) handleAgentStatus(client *Client, msg *protocol.ProtocolMessag
 func (g *Gateway) handleConnect(client *Client, msg *protocol.ProtocolMessage) error {
 	var req protocol.ConnectRequest
 	if err := json.Unmarshal(msg.Params, &req); err != nil {
-		return err
+		return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("invalid params: %v", err))
 	}
 
 	// Validate request
 	if err := req.Validate(); err != nil {
-		return err
+		return client.SendResponse(msg.ID, false, nil, err.Error())
+	}
+
+	// Check the token and device against the auth configuration
+	if !g.config.ValidateToken(req.Token) && !g.config.IsAdminToken(req.Token) {
+		log.Printf("🔒 Handshake refused: invalid token (device=%s)", req.DeviceID)
+		return client.SendResponse(msg.ID, false, nil, protocol.ErrUnauthorized.Error())
+	}
+	if !g.config.IsDeviceAllowed(req.DeviceID) {
+		log.Printf("🔒 Handshake refused: device not allowed (device=%s)", req.DeviceID)
+		return client.SendResponse(msg.ID, false, nil, "device not allowed")
+	}
+
+	// Presence counts for the user of the token, else for the device.
+	// Without auth nothing is verified, so clients may name their user.
+	userID := g.config.UserForToken(req.Token)
+	if userID == "" {
+		userID = req.DeviceID
+	}
+	if req.UserID != "" && req.UserID != userID {
+		if g.config.Auth.Enabled {
+			log.Printf("🔒 Handshake refused: user_id %s does not match the token (device=%s)", req.UserID, req.DeviceID)
+			return client.SendResponse(msg.ID, false, nil, "user_id does not match the token")
+		}
+		userID = req.UserID
+	}
+
+	// Count the device against its connection limit
+	if err := g.limitDevice(client, req.DeviceID); err != nil {
+		return client.SendResponse(msg.ID, false, nil, err.Error())
+	}
+
+	// Paired nodes present the identity issued at pairing. They are bound
+	// to the connection once the handshake has succeeded.
+	var node *models.Node
+	if req.NodeID != "" {
+		n, err := g.authenticateNode(&req)
+		if err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		node = n
+	}
+
+	client.mu.Lock()
+	client.admin = g.config.IsAdminToken(req.Token)
+	client.authUser = g.config.UserForToken(req.Token)
+	client.mu.Unlock()
+
+	// Join the requested workspace
+	workspaceID := req.Workspace
+	if workspaceID == "" {
+		workspaceID = workspaces.DefaultID
+	}
+	if err := g.joinWorkspace(client, workspaceID); err != nil {
+		return client.SendResponse(msg.ID, false, nil, err.Error())
 	}
 
 	// Update client info
-	client.deviceID = req.DeviceID
-	client.clientID = req.ClientID
-	client.metadata["version"] = req.Version
+	client.Update(req.DeviceID, req.ClientID, req.ClientType)
+	client.mu.Lock()
+	client.userID = userID
+	client.mu.Unlock()
+	client.SetMetadata("version", req.Version)
+
+	// Apply the slow-consumer policy of the client's class
+	policy := g.backpressureFor(req.ClientType)
+	client.Conn.SetPolicy(policy.Policy, policy.BlockTimeout)
 
 	// Generate session ID
 	sessionID := fmt.Sprintf("session-%d", time.Now().UnixNano())
+	client.mu.Lock()
 	client.sessionID = sessionID
+	client.mu.Unlock()
 
-	// Create state snapshot
-	state := &protocol.StateSnapshot{
-		Version:   "0.0.1",
-		GatewayID:  g.id,
-		ClientID:   client.ID,
-		SessionID:  sessionID,
-		Workspace:  "default",
-		Timestamp:  time.Now().Unix(),
-		Metadata:   map[string]string{"gateway": g.id},
+	if node != nil {
+		g.bindNode(client, node, req.Capabilities)
 	}
 
+	// Create state snapshot
+	state := g.Snapshot(client, true, true)
+
 	// Send hello response
 	response := protocol.HelloResponse{
 		Type: string(protocol.TypeRes),
@@ -415,21 +721,29 @@ func (g *Gateway) handleConnect(client *Client, msg *protocol.ProtocolMessage) e
 			Version:   "0.0.1",
 			DeviceID:  client.deviceID,
 			SessionID: sessionID,
-			Workspace: "default",
+			Workspace: workspaceID,
+			Encoding:  client.Conn.Encoding(),
 			State:     state,
 		},
 	}
 
 	data, _ := json.Marshal(response)
-	client.Conn.Write(data)
+	if err := client.Conn.Write(data); err != nil {
+		g.unbindNode(client)
+		return err
+	}
+
+	log.Printf("🤝 Handshake complete: device=%s client=%s session=%s workspace=%s", client.deviceID, client.ID, sessionID, workspaceID)
 
-	log.Printf("🤝 Handshake complete: device=%s client=%s session=%s", client.deviceID, client.ID, sessionID)
+	g.registerPresence(client)
+	g.trackPresence(client)
 
 	// Publish connect event
 	g.eventBus.Publish(protocol.EventClientConnected, "", map[string]interface{}{
 		"client_id":    client.ID,
 		"device_id":    client.deviceID,
 		"session_id":   sessionID,
+		"workspace":    workspaceID,
 		"connected_at": client.connectedAt.Unix(),
 	})
 
@@ -468,6 +782,69 @@ func (g *Gateway) runHub() {
 	}
 }
 
+// unregisterClient removes a client whose connection has closed
+func (g *Gateway) unregisterClient(client *Client) {
+	client.SetStatus("disconnected")
+	client.pending.close()
+	g.unbindNode(client)
+	g.unregisterPresence(client)
+	g.untrackPresence(client)
+
+	// Clients that completed the handshake were announced as connected
+	client.mu.RLock()
+	reason, sessionID := client.closeReason, client.sessionID
+	client.mu.RUnlock()
+	if sessionID != "" {
+		if reason == "" {
+			reason = "closed"
+		}
+		g.eventBus.Publish(protocol.EventClientDisconnected, "", map[string]interface{}{
+			"client_id":       client.ID,
+			"device_id":       client.deviceID,
+			"session_id":      sessionID,
+			"reason":          reason,
+			"disconnected_at": time.Now().Unix(),
+		})
+	}
+
+	select {
+	case g.unregister <- client:
+	case <-g.ctx.Done():
+	}
+}
+
+// limitDevice counts a client's device against the per-device connection
+// limit, unless it was already counted at upgrade
+func (g *Gateway) limitDevice(client *Client, deviceID string) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if client.limitDevice != "" {
+		if client.limitDevice != deviceID {
+			return fmt.Errorf("device_id does not match the one announced at upgrade")
+		}
+		return nil
+	}
+	if !g.limiter.acquireDevice(deviceID) {
+		return fmt.Errorf("too many connections for this device")
+	}
+	client.limitDevice = deviceID
+	return nil
+}
+
+// backpressureFor returns the connection queue configuration for a client type
+func (g *Gateway) backpressureFor(clientType string) *ws.BackpressureConfig {
+	bp := g.config.Server.Backpressure
+	policy := bp.ForClass(clientType)
+
+	return &ws.BackpressureConfig{
+		SendBufferSize:    bp.SendBufferSize,
+		ReceiveBufferSize: bp.ReceiveBufferSize,
+		Policy:            ws.ParsePolicy(policy.Policy),
+		BlockTimeout:      time.Duration(policy.BlockTimeout) * time.Millisecond,
+	}
+}
+
 // GetClient returns a client by ID
 func (g *Gateway) GetClient(id string) (*Client, bool) {
 	g.clientsLock.RLock()
@@ -488,12 +865,25 @@ func (g *Gateway) GetClients() []*Client {
 	return clients
 }
 
-// Broadcast broadcasts a message to all clients
+// Broadcast broadcasts a message to all clients, including those
+// connected to other cluster nodes
 func (g *Gateway) Broadcast(message []byte) {
+	if g.backplane != nil {
+		if err := g.backplane.Publish(g.ctx, topicBroadcast, message); err != nil {
+			log.Printf("Cluster broadcast error: %v", err)
+		}
+	}
+
+	g.broadcastLocal(message)
+}
+
+// broadcastLocal broadcasts a message to the clients of this node
+func (g *Gateway) broadcastLocal(message []byte) {
 	select {
 	case g.broadcast <- message:
 	default:
-		log.Printf("Broadcast buffer full, dropping message")
+		dropped := g.broadcastDropped.Add(1)
+		log.Printf("Broadcast buffer full, dropping message (dropped=%d)", dropped)
 	}
 }
 
@@ -515,6 +905,16 @@ func (g *Gateway) GetState() *GatewayState {
 	stats := &GatewayStats{
 		ClientCount: len(g.clients),
 		Uptime:      int64(time.Since(time.Now().Add(-time.Hour)).Seconds()),
+		BroadcastDropped: g.broadcastDropped.Load(),
+	}
+
+	for _, client := range g.clients {
+		queue := client.Conn.Stats()
+		stats.DroppedOutgoing += queue.DroppedOutgoing
+		stats.DroppedIncoming += queue.DroppedIncoming
+		if queue.Status != ws.StatusOK {
+			stats.LaggingClients++
+		}
 	}
 
 	return &GatewayState{
@@ -0,0 +1,286 @@
+// Package gatewaytest runs a gateway in process for tests: on an
+// ephemeral port, with in-memory storage and a scripted LLM, dialed by
+// test connections that assert on protocol frames.
+package gatewaytest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/openclaw/go-openclaw/internal/agent"
+	"github.com/openclaw/go-openclaw/internal/agent/llm/llmtest"
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/pkg/gateway"
+)
+
+// Timeout bounds every wait of the harness
+const Timeout = 5 * time.Second
+
+// Server is a gateway running in process
+type Server struct {
+	Gateway *gateway.Gateway
+	Config  *config.Config
+	LLM     *llmtest.Client // answers the agent
+	URL     string          // WebSocket URL
+
+	t    testing.TB
+	once sync.Once
+}
+
+// Option changes the configuration of a test server before it starts
+type Option func(cfg *config.Config)
+
+// NewServer starts a gateway with its agent running on the mock LLM. It
+// is stopped when the test ends.
+func NewServer(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = 0
+	cfg.Database.Type = "memory"
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// A new gateway keeps its state in memory until given a store
+	g := gateway.NewWithConfig(cfg)
+	if err := g.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start gateway: %v", err)
+	}
+
+	s := &Server{
+		Gateway: g,
+		Config:  cfg,
+		LLM:     llmtest.New(),
+		URL:     fmt.Sprintf("ws://%s/ws", g.Addr()),
+		t:       t,
+	}
+	t.Cleanup(s.Stop)
+
+	agentCfg := agent.DefaultConfig()
+	agentCfg.LLMClient = s.LLM
+	if err := g.StartAgent(context.Background(), agentCfg); err != nil {
+		t.Fatalf("failed to start agent: %v", err)
+	}
+	return s
+}
+
+// Stop stops the gateway, once
+func (s *Server) Stop() {
+	s.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+		if err := s.Gateway.Stop(ctx); err != nil {
+			s.t.Errorf("failed to stop gateway: %v", err)
+		}
+	})
+}
+
+// Dial opens a connection without a handshake
+func (s *Server) Dial(t testing.TB) *Conn {
+	t.Helper()
+
+	dialer := websocket.Dialer{HandshakeTimeout: Timeout}
+	ws, _, err := dialer.Dial(s.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to dial %s: %v", s.URL, err)
+	}
+	c := &Conn{ws: ws, t: t}
+	t.Cleanup(func() { ws.Close() })
+	return c
+}
+
+// Connect opens a connection and completes the handshake as a device,
+// returning the hello payload
+func (s *Server) Connect(t testing.TB, deviceID string) (*Conn, *protocol.HelloPayload) {
+	t.Helper()
+
+	c := s.Dial(t)
+	res := c.Call("connect", &protocol.ConnectRequest{Token: "test", DeviceID: deviceID})
+	if !res.Ok {
+		t.Fatalf("handshake of %s failed: %s", deviceID, res.Error)
+	}
+
+	var hello protocol.HelloPayload
+	if err := res.Decode(&hello); err != nil {
+		t.Fatalf("invalid hello: %v", err)
+	}
+	return c, &hello
+}
+
+// Frame is a protocol message as received, with raw payloads
+type Frame struct {
+	Type    protocol.MessageType `json:"type"`
+	ID      string               `json:"id"`
+	Method  string               `json:"method,omitempty"`
+	Params  json.RawMessage      `json:"params,omitempty"`
+	Ok      bool                 `json:"ok,omitempty"`
+	Payload json.RawMessage      `json:"payload,omitempty"`
+	Error   string               `json:"error,omitempty"`
+	Event   string               `json:"event,omitempty"`
+	Data    json.RawMessage      `json:"data,omitempty"`
+	Seq     int                  `json:"seq,omitempty"`
+}
+
+// Conn is a test connection to a gateway. Frames read while waiting for
+// something else are kept for later expectations.
+type Conn struct {
+	ws      *websocket.Conn
+	t       testing.TB
+	nextID  int
+	backlog []*Frame
+}
+
+// WS returns the underlying WebSocket connection
+func (c *Conn) WS() *websocket.Conn {
+	return c.ws
+}
+
+// Send sends a request and returns its ID
+func (c *Conn) Send(method string, params interface{}) string {
+	c.t.Helper()
+
+	c.nextID++
+	id := fmt.Sprintf("t-%d", c.nextID)
+	req := map[string]interface{}{"type": protocol.TypeReq, "id": id, "method": method}
+	if params != nil {
+		req["params"] = params
+	}
+	if err := c.ws.WriteJSON(req); err != nil {
+		c.t.Fatalf("failed to send %s: %v", method, err)
+	}
+	return id
+}
+
+// SendRaw sends a text frame as is
+func (c *Conn) SendRaw(data string) {
+	c.t.Helper()
+
+	if err := c.ws.WriteMessage(websocket.TextMessage, []byte(data)); err != nil {
+		c.t.Fatalf("failed to send: %v", err)
+	}
+}
+
+// Call sends a request and waits for its response
+func (c *Conn) Call(method string, params interface{}) *Frame {
+	c.t.Helper()
+
+	id := c.Send(method, params)
+	return c.Expect(fmt.Sprintf("response to %s", method), func(f *Frame) bool {
+		return f.Type == protocol.TypeRes && f.ID == id
+	})
+}
+
+// Expect waits for a frame matching a predicate, failing the test after
+// Timeout. What describes the frame in the failure.
+func (c *Conn) Expect(what string, match func(f *Frame) bool) *Frame {
+	c.t.Helper()
+
+	for i, f := range c.backlog {
+		if match(f) {
+			c.backlog = append(c.backlog[:i], c.backlog[i+1:]...)
+			return f
+		}
+	}
+
+	deadline := time.Now().Add(Timeout)
+	for {
+		f, err := c.read(deadline)
+		if err != nil {
+			c.t.Fatalf("no %s: %v", what, err)
+		}
+		if match(f) {
+			return f
+		}
+		c.backlog = append(c.backlog, f)
+	}
+}
+
+// ExpectEvent waits for an event with a name
+func (c *Conn) ExpectEvent(name string) *Frame {
+	c.t.Helper()
+
+	return c.Expect("event "+name, func(f *Frame) bool {
+		return f.Type == protocol.TypeEvent && f.Event == name
+	})
+}
+
+// ExpectNone fails the test if a frame matching a predicate arrives
+// within a wait
+func (c *Conn) ExpectNone(what string, wait time.Duration, match func(f *Frame) bool) {
+	c.t.Helper()
+
+	for _, f := range c.backlog {
+		if match(f) {
+			c.t.Fatalf("unexpected %s", what)
+		}
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		f, err := c.read(deadline)
+		if err != nil {
+			return
+		}
+		if match(f) {
+			c.t.Fatalf("unexpected %s", what)
+		}
+		c.backlog = append(c.backlog, f)
+	}
+}
+
+// ExpectClose waits for the gateway to close the connection and returns
+// its close frame, nil if it closed without one
+func (c *Conn) ExpectClose() *websocket.CloseError {
+	c.t.Helper()
+
+	deadline := time.Now().Add(Timeout)
+	for {
+		_, err := c.read(deadline)
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) {
+			return closeErr
+		}
+		var netErr interface{ Timeout() bool }
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			c.t.Fatalf("connection not closed: %v", err)
+		}
+		return nil
+	}
+}
+
+// read reads the next frame
+func (c *Conn) read(deadline time.Time) (*Frame, error) {
+	c.ws.SetReadDeadline(deadline)
+
+	var f Frame
+	if err := c.ws.ReadJSON(&f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Close closes the connection
+func (c *Conn) Close() {
+	c.ws.Close()
+}
+
+// Decode decodes the payload of a response, or the data of an event
+func (f *Frame) Decode(v interface{}) error {
+	data := f.Payload
+	if f.Type == protocol.TypeEvent {
+		data = f.Data
+	}
+	return json.Unmarshal(data, v)
+}
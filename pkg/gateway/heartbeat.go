@@ -2,11 +2,14 @@ package gateway
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/fasthttp/websocket"
+	"github.com/openclaw/go-openclaw/internal/config"
 	"github.com/openclaw/go-openclaw/internal/protocol"
 )
 
@@ -28,6 +31,23 @@ func DefaultHeartbeatConfig() *HeartbeatConfig {
 	}
 }
 
+// heartbeatConfigFrom converts the heartbeat section of the server
+// configuration, keeping defaults for unset intervals
+func heartbeatConfigFrom(cfg *config.HeartbeatConfig) *HeartbeatConfig {
+	hc := DefaultHeartbeatConfig()
+	if cfg.PingInterval > 0 {
+		hc.PingInterval = time.Duration(cfg.PingInterval) * time.Second
+	}
+	if cfg.PongTimeout > 0 {
+		hc.PongTimeout = time.Duration(cfg.PongTimeout) * time.Second
+	}
+	hc.IdleTimeout = time.Duration(cfg.IdleTimeout) * time.Second
+	if cfg.CheckInterval > 0 {
+		hc.CheckInterval = time.Duration(cfg.CheckInterval) * time.Second
+	}
+	return hc
+}
+
 // Heartbeat manages heartbeat and idle detection
 type Heartbeat struct {
 	gateway *Gateway
@@ -108,12 +128,7 @@ func (h *Heartbeat) sendPingToAll() {
 // sendPing sends a ping to a specific client
 func (h *Heartbeat) sendPing(client *Client) error {
 	// Use WebSocket ping (more efficient than protocol-level ping)
-	client.Conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	if err := client.Conn.WriteRaw([]byte{}); err != nil {
-		return err
-	}
-
-	return nil
+	return client.Conn.Ping()
 }
 
 // idleCheckLoop checks for idle clients periodically
@@ -134,26 +149,35 @@ func (h *Heartbeat) idleCheckLoop() {
 	}
 }
 
-// checkIdleClients checks and disconnects idle clients
+// checkIdleClients disconnects clients that stopped answering pings and,
+// when an idle timeout is set, clients that stopped sending requests
 func (h *Heartbeat) checkIdleClients() {
 	clients := h.gateway.GetClients()
 	now := time.Now()
 
 	for _, client := range clients {
-		lastSeen := client.lastSeen
+		if !client.IsConnected() {
+			continue
+		}
+
+		// Pongs and messages both prove the connection is alive
+		if alive := client.Conn.LastSeen(); now.Sub(alive) > h.config.PongTimeout {
+			log.Printf("Client %s not responding, disconnecting (last heard: %v)", client.ID, alive)
+			client.CloseWithReason(websocket.CloseGoingAway, "heartbeat_timeout")
+			continue
+		}
 
 		// Check if client is idle
-		if now.Sub(lastSeen) > h.config.IdleTimeout {
+		if lastSeen := client.LastSeen(); h.config.IdleTimeout > 0 && now.Sub(lastSeen) > h.config.IdleTimeout {
 			log.Printf("Client %s idle, disconnecting (last seen: %v)", client.ID, lastSeen)
-			client.Close()
+			client.CloseWithReason(websocket.CloseNormalClosure, "idle_timeout")
 		}
 	}
 }
 
-// IsClientAlive checks if a client is alive (recent activity)
+// IsClientAlive checks if a client is alive (recent pong or message)
 func (h *Heartbeat) IsClientAlive(client *Client) bool {
-	lastSeen := client.lastSeen
-	return time.Since(lastSeen) < h.config.PongTimeout
+	return time.Since(client.Conn.LastSeen()) < h.config.PongTimeout
 }
 
 // GetAliveClients returns all alive clients
@@ -186,20 +210,25 @@ func (h *Heartbeat) GetDeadClients() []*Client {
 
 // PingClient sends a ping to a specific client and waits for pong
 func (h *Heartbeat) PingClient(client *Client) error {
-	// Create ping request
-	pingMsg := &protocol.ProtocolMessage{
-		Type:   protocol.TypeReq,
-		ID:     generateID(),
-		Method: "ping",
-		Params: nil, // No params needed
+	ctx, cancel := context.WithTimeout(h.ctx, h.config.PongTimeout)
+	defer cancel()
+
+	h.lock.Lock()
+	h.seq++
+	seq := h.seq
+	h.lock.Unlock()
+
+	if _, err := client.Call(ctx, "ping", &protocol.PingMessage{Seq: seq}); err != nil {
+		return err
 	}
 
-	return client.Send(pingMsg)
+	h.HandlePong(client)
+	return nil
 }
 
 // HandlePong handles a pong message from a client
 func (h *Heartbeat) HandlePong(client *Client) {
-	client.lastSeen = time.Now()
+	client.Conn.UpdateLastSeen()
 }
 
 // GetStats returns heartbeat statistics
@@ -215,9 +244,7 @@ func (h *Heartbeat) GetStats() *HeartbeatStats {
 	}
 
 	for _, client := range clients {
-		lastSeen := client.lastSeen
-
-		if time.Since(lastSeen) < h.config.PongTimeout {
+		if h.IsClientAlive(client) {
 			stats.AliveClients++
 		} else {
 			stats.IdleClients++
@@ -275,8 +302,11 @@ func generateID() string {
 func randomString(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
 	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand unavailable: " + err.Error())
+	}
 	for i := range b {
-		b[i] = charset[time.Now().Nanosecond()%len(charset)]
+		b[i] = charset[int(b[i])%len(charset)]
 	}
 	return string(b)
 }
@@ -0,0 +1,194 @@
+package gateway
+
+import (
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/valyala/fasthttp"
+)
+
+// originPolicy decides which browser origins may open a WebSocket
+type originPolicy struct {
+	allowAll  bool
+	exact     map[string]bool
+	wildcards []wildcardOrigin
+	patterns  []*regexp.Regexp
+}
+
+// wildcardOrigin matches any subdomain of a domain, optionally
+// restricted to a scheme and a port
+type wildcardOrigin struct {
+	scheme string // empty matches any scheme
+	suffix string // ".example.com"
+	port   string // empty matches the scheme's default port
+}
+
+// newOriginPolicy compiles the allowed origin patterns. Regular
+// expressions must match the whole origin; invalid ones are logged and
+// ignored.
+func newOriginPolicy(origins []string) *originPolicy {
+	p := &originPolicy{exact: make(map[string]bool)}
+	if len(origins) == 0 {
+		p.allowAll = true
+		return p
+	}
+
+	for _, origin := range origins {
+		origin = strings.TrimSpace(origin)
+		switch {
+		case origin == "*":
+			p.allowAll = true
+		case strings.HasPrefix(origin, "re:"):
+			re, err := regexp.Compile("^(?:" + strings.TrimPrefix(origin, "re:") + ")$")
+			if err != nil {
+				log.Printf("Ignoring invalid origin pattern %q: %v", origin, err)
+				continue
+			}
+			p.patterns = append(p.patterns, re)
+		case strings.Contains(origin, "*."):
+			scheme, host, ok := strings.Cut(origin, "://")
+			if !ok {
+				scheme, host = "", origin
+			}
+			host, port, _ := strings.Cut(strings.TrimSuffix(host, "/"), ":")
+			p.wildcards = append(p.wildcards, wildcardOrigin{
+				scheme: strings.ToLower(scheme),
+				suffix: strings.ToLower(strings.TrimPrefix(host, "*")),
+				port:   port,
+			})
+		default:
+			p.exact[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+		}
+	}
+	return p
+}
+
+// allowed reports whether an origin may connect. Requests without an
+// Origin header come from non-browser clients and are always allowed.
+func (p *originPolicy) allowed(origin string) bool {
+	if p.allowAll || origin == "" {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if p.exact[origin] {
+		return true
+	}
+
+	if len(p.wildcards) > 0 {
+		if u, err := url.Parse(origin); err == nil && u.Host != "" {
+			host := u.Hostname()
+			for _, w := range p.wildcards {
+				if (w.scheme != "" && w.scheme != u.Scheme) || w.port != u.Port() {
+					continue
+				}
+				if strings.HasSuffix(host, w.suffix) && len(host) > len(w.suffix) {
+					return true
+				}
+			}
+		}
+	}
+
+	for _, re := range p.patterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOrigin is the upgrader's CheckOrigin callback
+func (p *originPolicy) checkOrigin(ctx *fasthttp.RequestCtx) bool {
+	return p.allowed(string(ctx.Request.Header.Peek("Origin")))
+}
+
+// connLimiter counts open WebSocket connections in total, per remote IP
+// and per device
+type connLimiter struct {
+	maxTotal     int
+	maxPerIP     int
+	maxPerDevice int
+
+	total     int
+	perIP     map[string]int
+	perDevice map[string]int
+	mu        sync.Mutex
+}
+
+// newConnLimiter creates a limiter from configuration
+func newConnLimiter(cfg *config.WebSocketConfig) *connLimiter {
+	return &connLimiter{
+		maxTotal:     cfg.MaxConnections,
+		maxPerIP:     cfg.MaxConnectionsPerIP,
+		maxPerDevice: cfg.MaxConnectionsPerDevice,
+		perIP:        make(map[string]int),
+		perDevice:    make(map[string]int),
+	}
+}
+
+// acquire reserves a connection slot. deviceID may be empty if the client
+// did not announce it at upgrade. It returns 0 on success, otherwise the
+// HTTP status code and reason to reject the upgrade with.
+func (l *connLimiter) acquire(ip, deviceID string) (int, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxTotal > 0 && l.total >= l.maxTotal {
+		return fasthttp.StatusServiceUnavailable, "too many connections"
+	}
+	if l.maxPerIP > 0 && l.perIP[ip] >= l.maxPerIP {
+		return fasthttp.StatusTooManyRequests, "too many connections from this address"
+	}
+	if deviceID != "" && l.maxPerDevice > 0 && l.perDevice[deviceID] >= l.maxPerDevice {
+		return fasthttp.StatusTooManyRequests, "too many connections for this device"
+	}
+
+	l.total++
+	l.perIP[ip]++
+	if deviceID != "" {
+		l.perDevice[deviceID]++
+	}
+	return 0, ""
+}
+
+// acquireDevice reserves a device slot for a connection that announced its
+// device ID only in the connect handshake
+func (l *connLimiter) acquireDevice(deviceID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxPerDevice > 0 && l.perDevice[deviceID] >= l.maxPerDevice {
+		return false
+	}
+	l.perDevice[deviceID]++
+	return true
+}
+
+// release frees the slots taken by acquire and acquireDevice
+func (l *connLimiter) release(ip, deviceID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.total--
+	if l.perIP[ip]--; l.perIP[ip] <= 0 {
+		delete(l.perIP, ip)
+	}
+	if deviceID != "" {
+		if l.perDevice[deviceID]--; l.perDevice[deviceID] <= 0 {
+			delete(l.perDevice, deviceID)
+		}
+	}
+}
+
+// upgradeDeviceID returns the device ID announced at upgrade through the
+// device_id query parameter or the X-Device-ID header
+func upgradeDeviceID(ctx *fasthttp.RequestCtx) string {
+	if id := ctx.QueryArgs().Peek("device_id"); len(id) > 0 {
+		return string(id)
+	}
+	return string(ctx.Request.Header.Peek("X-Device-ID"))
+}
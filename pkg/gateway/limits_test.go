@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/valyala/fasthttp"
+)
+
+func TestOriginPolicy(t *testing.T) {
+	p := newOriginPolicy([]string{
+		"https://app.example.com",
+		"https://*.example.org",
+		"*.example.net:8443",
+		`re:https://[a-z]+\.example\.io`,
+	})
+
+	for _, tc := range []struct {
+		origin string
+		ok     bool
+	}{
+		{"", true},
+		{"https://app.example.com", true},
+		{"https://evil.example.com", false},
+		{"https://a.example.org", true},
+		{"http://a.example.org", false},
+		{"https://example.org", false},
+		{"https://a.example.net:8443", true},
+		{"https://a.example.net", false},
+		{"https://a.example.io", true},
+		{"https://a.example.io.evil.net", false},
+		{"https://evil.net/https://a.example.io", false},
+	} {
+		if got := p.allowed(tc.origin); got != tc.ok {
+			t.Errorf("origin %q allowed=%v, want %v", tc.origin, got, tc.ok)
+		}
+	}
+
+	if !newOriginPolicy(nil).allowed("https://anywhere.example.com") {
+		t.Error("origin refused without a policy")
+	}
+}
+
+func TestConnLimiter(t *testing.T) {
+	l := newConnLimiter(&config.WebSocketConfig{
+		MaxConnections:          4,
+		MaxConnectionsPerIP:     3,
+		MaxConnectionsPerDevice: 1,
+	})
+
+	if code, _ := l.acquire("10.0.0.1", "phone"); code != 0 {
+		t.Fatalf("first connection refused with %d", code)
+	}
+	if code, _ := l.acquire("10.0.0.1", "phone"); code != fasthttp.StatusTooManyRequests {
+		t.Errorf("second connection of a device: %d, want 429", code)
+	}
+
+	// A device announced only in the handshake is counted there
+	if code, _ := l.acquire("10.0.0.1", ""); code != 0 {
+		t.Fatalf("connection without a device refused with %d", code)
+	}
+	if l.acquireDevice("phone") {
+		t.Error("device over its limit accepted at the handshake")
+	}
+
+	if code, _ := l.acquire("10.0.0.1", ""); code != 0 {
+		t.Fatalf("third connection refused with %d", code)
+	}
+	if code, _ := l.acquire("10.0.0.1", ""); code != fasthttp.StatusTooManyRequests {
+		t.Errorf("connection over the per-address limit: %d, want 429", code)
+	}
+
+	if code, _ := l.acquire("10.0.0.2", ""); code != 0 {
+		t.Fatalf("connection from another address refused with %d", code)
+	}
+	if code, _ := l.acquire("10.0.0.3", ""); code != fasthttp.StatusServiceUnavailable {
+		t.Errorf("connection over the total limit: %d, want 503", code)
+	}
+
+	// Released slots are free again
+	l.release("10.0.0.1", "phone")
+	if !l.acquireDevice("phone") {
+		t.Error("device refused after its connection closed")
+	}
+}
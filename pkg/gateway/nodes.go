@@ -0,0 +1,340 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/events"
+	"github.com/openclaw/go-openclaw/internal/models"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/internal/storage"
+	"github.com/openclaw/go-openclaw/pkg/nodes"
+	"github.com/openclaw/go-openclaw/pkg/workspaces"
+)
+
+// SetStore sets the persistent store and reloads the paired nodes and
+// workspaces from it. It must be called before Start.
+func (g *Gateway) SetStore(raw storage.Store) error {
+	store := &handoffStore{Store: raw}
+	manager, err := nodes.NewManager(store, g.pairingTTL())
+	if err != nil {
+		return err
+	}
+	spaces, err := workspaces.NewManager(store)
+	if err != nil {
+		return err
+	}
+	g.store = store
+	g.nodes = manager
+	g.workspaces = spaces
+	return nil
+}
+
+// Store returns the persistent store
+func (g *Gateway) Store() storage.Store {
+	return g.store
+}
+
+// Nodes returns the node manager
+func (g *Gateway) Nodes() *nodes.Manager {
+	return g.nodes
+}
+
+// ListNodes returns the paired nodes
+func (g *Gateway) ListNodes() []protocol.NodeInfo {
+	infos := make([]protocol.NodeInfo, 0)
+	for _, node := range g.nodes.List() {
+		infos = append(infos, nodes.Info(node))
+	}
+	return infos
+}
+
+// clientNodes returns the paired nodes a client may see
+func (g *Gateway) clientNodes(client *Client) []protocol.NodeInfo {
+	infos := make([]protocol.NodeInfo, 0)
+	for _, node := range g.nodes.List() {
+		if canSeeNode(client, node) {
+			infos = append(infos, nodes.Info(node))
+		}
+	}
+	return infos
+}
+
+// canSeeNode reports whether a client may see a node: admins see every
+// node, other clients the nodes paired in their workspace
+func canSeeNode(client *Client, node *models.Node) bool {
+	return client.IsAdmin() || nodes.InWorkspace(node, client.Workspace())
+}
+
+// GetNode returns a paired node
+func (g *Gateway) GetNode(nodeID string) (*protocol.NodeInfo, bool) {
+	node, ok := g.nodes.Get(nodeID)
+	if !ok {
+		return nil, false
+	}
+	info := nodes.Info(node)
+	return &info, true
+}
+
+// pairingTTL returns the lifetime of pairing codes
+func (g *Gateway) pairingTTL() time.Duration {
+	if g.config.Nodes.PairingTTL <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(g.config.Nodes.PairingTTL) * time.Second
+}
+
+// InvokeNode calls a capability on a connected node and returns its result
+func (g *Gateway) InvokeNode(ctx context.Context, nodeID, capability string, params json.RawMessage) (json.RawMessage, error) {
+	node, ok := g.nodes.Get(nodeID)
+	if !ok {
+		return nil, nodes.ErrUnknownNode
+	}
+	if !nodes.HasCapability(node, capability) {
+		return nil, fmt.Errorf("node %s does not support %s", nodeID, capability)
+	}
+
+	client, ok := g.nodeClient(nodeID)
+	if !ok {
+		return nil, fmt.Errorf("node %s is offline", nodeID)
+	}
+
+	log.Printf("📟 Invoking %s on node %s", capability, nodeID)
+	return client.Call(ctx, capability, params)
+}
+
+// nodeClient returns the connection of an online node
+func (g *Gateway) nodeClient(nodeID string) (*Client, bool) {
+	g.nodesLock.RLock()
+	clientID, ok := g.nodeClients[nodeID]
+	g.nodesLock.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return g.GetClient(clientID)
+}
+
+// authenticateNode checks the node identity a connection presents
+func (g *Gateway) authenticateNode(req *protocol.ConnectRequest) (*models.Node, error) {
+	node, err := g.nodes.Authenticate(req.NodeID, req.NodeToken)
+	if err != nil {
+		return nil, err
+	}
+	if node.DeviceID != req.DeviceID {
+		return nil, fmt.Errorf("node %s is paired with another device", node.ID)
+	}
+	return node, nil
+}
+
+// bindNode marks a node online on a connection. Of the capabilities the
+// node advertises, only those approved at pairing are kept.
+func (g *Gateway) bindNode(client *Client, node *models.Node, capabilities []string) {
+	client.mu.Lock()
+	client.nodeID = node.ID
+	client.mu.Unlock()
+
+	client.SetCapabilities(nodes.Approved(node, capabilities))
+
+	g.nodesLock.Lock()
+	g.nodeClients[node.ID] = client.ID
+	g.nodesLock.Unlock()
+
+	if err := g.nodes.SetOnline(node.ID, true, client.remoteIP); err != nil {
+		log.Printf("Failed to update node %s: %v", node.ID, err)
+	}
+
+	log.Printf("📟 Node online: %s (%s) client=%s", node.ID, node.Name, client.ID)
+	g.publishNodeStatus(node, "online")
+}
+
+// unbindNode marks the node of a closed connection offline
+func (g *Gateway) unbindNode(client *Client) {
+	client.mu.Lock()
+	nodeID := client.nodeID
+	client.nodeID = ""
+	client.mu.Unlock()
+	if nodeID == "" {
+		return
+	}
+
+	g.nodesLock.Lock()
+	if g.nodeClients[nodeID] == client.ID {
+		delete(g.nodeClients, nodeID)
+	}
+	g.nodesLock.Unlock()
+
+	if err := g.nodes.SetOnline(nodeID, false, ""); err != nil {
+		return // unpaired while connected
+	}
+	if node, ok := g.nodes.Get(nodeID); ok {
+		log.Printf("📟 Node offline: %s (%s)", node.ID, node.Name)
+		g.publishNodeStatus(node, "offline")
+	}
+}
+
+// publishNodeStatus publishes a node.status event
+func (g *Gateway) publishNodeStatus(node *models.Node, action string) {
+	g.eventBus.Publish(protocol.EventNodeStatus, "", &events.NodeEventData{
+		NodeID:    node.ID,
+		Action:    action,
+		Name:      node.Name,
+		Type:      node.Type,
+		Location:  node.Location,
+		IPAddress: node.IPAddress,
+	})
+}
+
+// handleNodeRequest handles node.* requests
+func (g *Gateway) handleNodeRequest(client *Client, msg *protocol.ProtocolMessage) error {
+	var req protocol.NodeRequest
+	if len(msg.Params) > 0 && msg.Method != "node.pair" {
+		if err := json.Unmarshal(msg.Params, &req); err != nil {
+			return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("invalid params: %v", err))
+		}
+	}
+
+	// Nodes are readable by every client within its workspace; the rest
+	// takes an admin token
+	switch msg.Method {
+	case "node.pair":
+		return g.handleNodePair(client, msg)
+	case "node.pending", "node.approve", "node.reject", "node.unpair", "node.invoke":
+		if !client.IsAdmin() {
+			return client.SendResponse(msg.ID, false, nil, protocol.ErrUnauthorized.Error())
+		}
+	}
+
+	switch msg.Method {
+	case "node.list":
+		return client.SendResponse(msg.ID, true, &protocol.NodeResponse{Status: "ok", Nodes: g.clientNodes(client)}, "")
+
+	case "node.get":
+		node, ok := g.nodes.Get(req.NodeID)
+		if !ok || !canSeeNode(client, node) {
+			return client.SendResponse(msg.ID, false, nil, nodes.ErrUnknownNode.Error())
+		}
+		info := nodes.Info(node)
+		return client.SendResponse(msg.ID, true, &protocol.NodeResponse{Status: "ok", Node: &info}, "")
+
+	case "node.pending":
+		pending := make([]*protocol.PairingInfo, 0)
+		for _, r := range g.nodes.Pending() {
+			pending = append(pending, r.Info())
+		}
+		return client.SendResponse(msg.ID, true, map[string]interface{}{"pending": pending}, "")
+
+	case "node.approve":
+		return g.handleNodeApprove(client, msg, req.Code)
+
+	case "node.reject":
+		pairing, err := g.nodes.Reject(req.Code)
+		if err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		if requester, ok := g.GetClient(pairing.ClientID); ok {
+			requester.SendEvent(string(protocol.EventNodePairing), &protocol.PairingResult{Status: "rejected"}, 0)
+		}
+		log.Printf("📟 Pairing rejected: %s (%s)", pairing.Name, pairing.DeviceID)
+		return client.SendResponse(msg.ID, true, &protocol.NodeResponse{Status: "ok", Message: "Pairing rejected"}, "")
+
+	case "node.unpair":
+		node, ok := g.nodes.Get(req.NodeID)
+		if !ok {
+			return client.SendResponse(msg.ID, false, nil, nodes.ErrUnknownNode.Error())
+		}
+		if err := g.nodes.Remove(req.NodeID); err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		if nodeClient, ok := g.nodeClient(req.NodeID); ok {
+			nodeClient.Close()
+		}
+		g.publishNodeStatus(node, "unpaired")
+		return client.SendResponse(msg.ID, true, &protocol.NodeResponse{Status: "ok", Message: "Node unpaired"}, "")
+
+	case "node.invoke":
+		// Node calls can take as long as the node needs, so do not hold up
+		// the connection's other requests
+		if !g.beginRequest() {
+			return client.SendResponse(msg.ID, false, nil, errDraining.Error())
+		}
+		go func() {
+			defer g.inflight.Done()
+			result, err := g.InvokeNode(g.ctx, req.NodeID, req.Capability, req.Params)
+			if err != nil {
+				client.SendResponse(msg.ID, false, nil, err.Error())
+				return
+			}
+			client.SendResponse(msg.ID, true, &protocol.NodeResponse{Status: "ok", Result: result}, "")
+		}()
+		return nil
+
+	default:
+		return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("unknown method: %s", msg.Method))
+	}
+}
+
+// handleNodePair registers a pairing request from an unpaired node
+func (g *Gateway) handleNodePair(client *Client, msg *protocol.ProtocolMessage) error {
+	var req protocol.PairRequest
+	if err := json.Unmarshal(msg.Params, &req); err != nil {
+		return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("invalid params: %v", err))
+	}
+
+	client.mu.RLock()
+	deviceID, clientType, nodeID := client.deviceID, client.clientType, client.nodeID
+	client.mu.RUnlock()
+
+	switch {
+	case deviceID == "":
+		return client.SendResponse(msg.ID, false, nil, "connect before pairing")
+	case clientType != "node":
+		return client.SendResponse(msg.ID, false, nil, "only clients of type node can pair")
+	case nodeID != "":
+		return client.SendResponse(msg.ID, false, nil, "already paired as "+nodeID)
+	case req.Name == "":
+		return client.SendResponse(msg.ID, false, nil, "name is required")
+	}
+
+	pairing, err := g.nodes.RequestPairing(&nodes.PairingRequest{
+		ClientID:     client.ID,
+		DeviceID:     deviceID,
+		Name:         req.Name,
+		Type:         req.Type,
+		Capabilities: req.Capabilities,
+		IPAddress:    client.remoteIP,
+		Workspace:    client.Workspace(),
+	})
+	if err != nil {
+		return client.SendResponse(msg.ID, false, nil, err.Error())
+	}
+
+	log.Printf("📟 Pairing requested: %s (%s) code=%s", pairing.Name, pairing.DeviceID, pairing.Code)
+	return client.SendResponse(msg.ID, true, pairing.Info(), "")
+}
+
+// handleNodeApprove approves a pairing request and hands the new identity
+// to the waiting node
+func (g *Gateway) handleNodeApprove(client *Client, msg *protocol.ProtocolMessage, code string) error {
+	node, token, pairing, err := g.nodes.Approve(code)
+	if err != nil {
+		return client.SendResponse(msg.ID, false, nil, err.Error())
+	}
+
+	log.Printf("📟 Node paired: %s (%s)", node.ID, node.Name)
+	g.publishNodeStatus(node, "paired")
+
+	if requester, ok := g.GetClient(pairing.ClientID); ok {
+		requester.SendEvent(string(protocol.EventNodePairing), &protocol.PairingResult{
+			Status: "approved",
+			NodeID: node.ID,
+			Token:  token,
+		}, 0)
+		g.bindNode(requester, node, pairing.Capabilities)
+	}
+
+	info := nodes.Info(node)
+	return client.SendResponse(msg.ID, true, &protocol.NodeResponse{Status: "ok", Node: &info}, "")
+}
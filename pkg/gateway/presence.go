@@ -0,0 +1,188 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/events"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/pkg/presence"
+)
+
+// newPresenceTracker creates the presence tracker, or nil when presence
+// is disabled
+func (g *Gateway) newPresenceTracker() *presence.Tracker {
+	if !g.config.Features.Presence {
+		return nil
+	}
+	awayAfter := time.Duration(g.config.Presence.AwayAfter) * time.Second
+	return presence.NewTracker(awayAfter, g.publishPresence)
+}
+
+// Presence returns the presence tracker, nil when presence is disabled
+func (g *Gateway) Presence() *presence.Tracker {
+	return g.presence
+}
+
+// ListPresence returns the presence of every connected user
+func (g *Gateway) ListPresence() []*protocol.PresenceInfo {
+	if g.presence == nil {
+		return []*protocol.PresenceInfo{}
+	}
+	return g.presence.List()
+}
+
+// GetPresence returns the presence of a user, offline if not connected
+func (g *Gateway) GetPresence(userID string) *protocol.PresenceInfo {
+	if g.presence != nil {
+		if info, ok := g.presence.Get(userID); ok {
+			return info
+		}
+	}
+	return &protocol.PresenceInfo{UserID: userID, Status: presence.StatusOffline}
+}
+
+// IsUserActive reports whether a user is online and not away or busy, so
+// notifications can wait for a better moment
+func (g *Gateway) IsUserActive(userID string) bool {
+	return g.presence != nil && g.presence.IsActive(userID)
+}
+
+// trackPresence starts tracking the presence of a handshaken client
+func (g *Gateway) trackPresence(client *Client) {
+	if g.presence == nil {
+		return
+	}
+	client.mu.RLock()
+	user, deviceID, workspace := client.userID, client.deviceID, client.workspace
+	client.mu.RUnlock()
+
+	g.presence.Connect(client.ID, user, deviceID, workspace)
+}
+
+// untrackPresence stops tracking a closed client
+func (g *Gateway) untrackPresence(client *Client) {
+	if g.presence == nil {
+		return
+	}
+	g.presence.Disconnect(client.ID)
+}
+
+// touchPresence records client activity. Heartbeats keep a connection
+// alive but do not count as activity.
+func (g *Gateway) touchPresence(client *Client, msg *protocol.ProtocolMessage) {
+	if g.presence == nil || msg.Type != protocol.TypeReq || msg.Method == "ping" {
+		return
+	}
+	g.presence.Touch(client.ID)
+}
+
+// runPresence periodically checks connection liveness and inactivity
+func (g *Gateway) runPresence() {
+	defer g.wg.Done()
+
+	interval := time.Duration(g.config.Presence.CheckInterval) * time.Second
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			g.sweepPresence()
+		case <-g.ctx.Done():
+			return
+		}
+	}
+}
+
+// sweepPresence marks connections without a recent heartbeat offline,
+// re-evaluates inactivity and mirrors the result into client status
+func (g *Gateway) sweepPresence() {
+	staleAfter := time.Duration(g.config.Presence.StaleAfter) * time.Second
+
+	clients := g.GetClients()
+	if staleAfter > 0 {
+		for _, client := range clients {
+			g.presence.SetAlive(client.ID, time.Since(client.Conn.LastSeen()) < staleAfter)
+		}
+	}
+	g.presence.Sweep()
+
+	for _, client := range clients {
+		if !client.IsConnected() {
+			continue
+		}
+		switch g.presence.Status(client.ID) {
+		case presence.StatusAway, presence.StatusOffline:
+			client.SetStatus("idle")
+		default:
+			client.SetStatus("connected")
+		}
+	}
+}
+
+// publishPresence publishes a presence.update event on the channel of the
+// workspace it was seen from
+func (g *Gateway) publishPresence(workspace string, info *protocol.PresenceInfo, previous string) {
+	log.Printf("👤 Presence: %s %s -> %s in %s", info.UserID, previous, info.Status, workspace)
+	g.eventBus.Publish(protocol.EventPresenceUpdate, workspace, &events.PresenceEventData{
+		UserID: info.UserID,
+		Status: info.Status,
+		NodeID: g.id,
+		Metadata: map[string]string{
+			"previous":    previous,
+			"connections": fmt.Sprintf("%d", info.Connections),
+		},
+	})
+}
+
+// handlePresenceRequest handles presence.* requests. Clients see the users
+// of their own workspace only.
+func (g *Gateway) handlePresenceRequest(client *Client, msg *protocol.ProtocolMessage) error {
+	if g.presence == nil {
+		return client.SendResponse(msg.ID, false, nil, "presence is disabled")
+	}
+
+	var req protocol.PresenceRequest
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &req); err != nil {
+			return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("invalid params: %v", err))
+		}
+	}
+
+	switch msg.Method {
+	case "presence.set":
+		if err := g.presence.Set(client.ID, req.Status); err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		client.mu.RLock()
+		user := client.userID
+		client.mu.RUnlock()
+		return client.SendResponse(msg.ID, true, g.GetPresence(user), "")
+
+	case "presence.get":
+		client.mu.RLock()
+		self, workspace := client.userID, client.workspace
+		client.mu.RUnlock()
+		user := req.UserID
+		if user == "" || user == self {
+			return client.SendResponse(msg.ID, true, g.GetPresence(self), "")
+		}
+		info, ok := g.presence.GetIn(user, workspace)
+		if !ok {
+			info = &protocol.PresenceInfo{UserID: user, Status: presence.StatusOffline}
+		}
+		return client.SendResponse(msg.ID, true, info, "")
+
+	case "presence.list":
+		return client.SendResponse(msg.ID, true, map[string]interface{}{"presence": g.presence.ListIn(client.Workspace())}, "")
+
+	default:
+		return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("unknown method: %s", msg.Method))
+	}
+}
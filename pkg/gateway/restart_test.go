@@ -0,0 +1,155 @@
+package gateway_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/config"
+	"github.com/openclaw/go-openclaw/internal/storage"
+	"github.com/openclaw/go-openclaw/pkg/cluster"
+	"github.com/openclaw/go-openclaw/pkg/gateway"
+	"github.com/openclaw/go-openclaw/pkg/gateway/gatewaytest"
+)
+
+// restartChildEnv makes the test binary run as the process started by
+// Gateway.Restart. It carries the address of the cluster peer to join, or
+// "fail" for a process that exits without serving.
+const restartChildEnv = "OPENCLAW_TEST_RESTART_CHILD"
+
+func TestMain(m *testing.M) {
+	if peer := os.Getenv(restartChildEnv); peer != "" {
+		os.Exit(runRestartChild(peer))
+	}
+	os.Exit(m.Run())
+}
+
+// startClusterGateway starts a gateway on a loopback port, with a memory
+// store, joined to a gossip cluster as node a
+func startClusterGateway(peer string) (*gateway.Gateway, error) {
+	cfg := config.Default()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = 0
+	cfg.Database.Type = "memory"
+
+	bp, err := cluster.NewGossipBackplane(&cluster.GossipConfig{
+		NodeID:            "a",
+		BindAddr:          "127.0.0.1:0",
+		Peers:             []string{peer},
+		Secret:            "s3cret",
+		HeartbeatInterval: time.Second,
+		DialTimeout:       time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g := gateway.NewWithConfig(cfg)
+	if err := g.SetStore(storage.NewMemoryStore()); err != nil {
+		return nil, err
+	}
+	g.SetBackplane(bp)
+	return g, g.Start(context.Background())
+}
+
+// runRestartChild serves as the new process until the test kills it
+func runRestartChild(peer string) int {
+	if peer == "fail" {
+		return 1
+	}
+	g, err := startClusterGateway(peer)
+	if err != nil {
+		log.Printf("restarted gateway failed to start: %v", err)
+		return 1
+	}
+	if err := g.Ready(); err != nil {
+		log.Printf("restarted gateway failed to report: %v", err)
+		return 1
+	}
+	time.Sleep(time.Minute)
+	return 0
+}
+
+// startPeer starts a gossip node on a loopback port
+func startPeer(t *testing.T, nodeID string, peers ...string) *cluster.GossipBackplane {
+	t.Helper()
+	bp, err := cluster.NewGossipBackplane(&cluster.GossipConfig{
+		NodeID:            nodeID,
+		BindAddr:          "127.0.0.1:0",
+		Peers:             peers,
+		Secret:            "s3cret",
+		HeartbeatInterval: time.Second,
+		DialTimeout:       time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bp.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { bp.Stop(context.Background()) })
+	return bp
+}
+
+func TestRestartWithCluster(t *testing.T) {
+	peer := startPeer(t, "b")
+	t.Setenv(restartChildEnv, peer.Addr().String())
+
+	g, err := startClusterGateway(peer.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to start gateway: %v", err)
+	}
+	t.Cleanup(func() { g.Stop(context.Background()) })
+	gossipAddr := g.Backplane().(*cluster.GossipBackplane).Addr().String()
+
+	// Restart returns once the new process serves, having joined the
+	// cluster on the inherited listener
+	proc, err := g.Restart()
+	if err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	t.Cleanup(func() {
+		proc.Kill()
+		proc.Wait()
+	})
+
+	// The old process no longer writes the store the new one opened
+	if err := g.Store().Put("test", "record", 1); err == nil {
+		t.Error("store written after the handoff")
+	}
+
+	// New connections reach the new process on both listeners
+	s := &gatewaytest.Server{URL: fmt.Sprintf("ws://%s/ws", g.Addr())}
+	s.Connect(t, "phone")
+
+	other := startPeer(t, "c", gossipAddr)
+	deadline := time.Now().Add(gatewaytest.Timeout)
+	for other.PeerCount() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("cluster listener not served after the handoff")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRestartFailure(t *testing.T) {
+	s := gatewaytest.NewServer(t)
+	if err := s.Gateway.SetStore(storage.NewMemoryStore()); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(restartChildEnv, "fail")
+
+	if _, err := s.Gateway.Restart(); err == nil || !strings.Contains(err.Error(), "did not start") {
+		t.Fatalf("restart with a failing process: %v", err)
+	}
+
+	// The gateway carries on with its listener and store
+	s.Connect(t, "phone")
+	if err := s.Gateway.Store().Put("test", "record", 1); err != nil {
+		t.Errorf("store after a failed restart: %v", err)
+	}
+}
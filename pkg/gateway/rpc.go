@@ -0,0 +1,179 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+var (
+	ErrClientGone  = errors.New("client disconnected")
+	ErrCallTimeout = errors.New("call timed out")
+)
+
+// CallError is an error returned by a client in response to a call
+type CallError struct {
+	Method  string
+	Message string
+}
+
+// Error implements the error interface
+func (e *CallError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Method, e.Message)
+}
+
+// pendingCall is a request sent to a client awaiting its response
+type pendingCall struct {
+	method string
+	done   chan *protocol.ProtocolMessage
+}
+
+// pendingCalls is the table of requests sent to a client, keyed by ID
+type pendingCalls struct {
+	calls  map[string]*pendingCall
+	closed bool
+	next   atomic.Uint64
+	mu     sync.Mutex
+}
+
+// newPendingCalls creates an empty pending-call table
+func newPendingCalls() *pendingCalls {
+	return &pendingCalls{
+		calls: make(map[string]*pendingCall),
+	}
+}
+
+// add registers a call and returns its request ID
+func (p *pendingCalls) add(method string) (string, *pendingCall, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return "", nil, ErrClientGone
+	}
+
+	id := fmt.Sprintf("call-%d", p.next.Add(1))
+	call := &pendingCall{
+		method: method,
+		done:   make(chan *protocol.ProtocolMessage, 1),
+	}
+	p.calls[id] = call
+	return id, call, nil
+}
+
+// remove drops a call, returning false if it was already resolved
+func (p *pendingCalls) remove(id string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.calls[id]; !ok {
+		return false
+	}
+	delete(p.calls, id)
+	return true
+}
+
+// resolve delivers a response to the call with the same ID
+func (p *pendingCalls) resolve(msg *protocol.ProtocolMessage) bool {
+	p.mu.Lock()
+	call, ok := p.calls[msg.ID]
+	delete(p.calls, msg.ID)
+	p.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	call.done <- msg
+	return true
+}
+
+// close fails every pending call and refuses new ones
+func (p *pendingCalls) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	for id, call := range p.calls {
+		close(call.done)
+		delete(p.calls, id)
+	}
+}
+
+// Call sends a request to the client and waits for its response. If ctx
+// has no deadline the gateway call timeout applies. When ctx is cancelled
+// before the response arrives the client is sent a call.cancelled event.
+func (c *Client) Call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	if c.pending == nil {
+		return nil, ErrClientGone
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.gateway.callTimeout())
+		defer cancel()
+	}
+
+	id, call, err := c.pending.add(method)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.SendRequest(id, method, params); err != nil {
+		c.pending.remove(id)
+		return nil, err
+	}
+
+	select {
+	case msg, ok := <-call.done:
+		if !ok {
+			return nil, ErrClientGone
+		}
+		if !msg.Ok {
+			return nil, &CallError{Method: method, Message: msg.Error}
+		}
+		if msg.Payload == nil {
+			return nil, nil
+		}
+		return json.Marshal(msg.Payload)
+
+	case <-ctx.Done():
+		if c.pending.remove(id) {
+			c.SendEvent(string(protocol.EventCallCancelled), map[string]string{"id": id}, 0)
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%s: %w", method, ErrCallTimeout)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// handleResponse routes a response from the client to the pending call
+func (c *Client) handleResponse(msg *protocol.ProtocolMessage) {
+	if c.pending == nil || !c.pending.resolve(msg) {
+		log.Printf("Unexpected response from %s: id=%s", c.ID, msg.ID)
+	}
+}
+
+// Call sends a request to a client of this node and waits for its response
+func (g *Gateway) Call(ctx context.Context, clientID, method string, params interface{}) (json.RawMessage, error) {
+	client, ok := g.GetClient(clientID)
+	if !ok {
+		return nil, fmt.Errorf("client not found: %s", clientID)
+	}
+	return client.Call(ctx, method, params)
+}
+
+// callTimeout returns the default timeout of calls to clients
+func (g *Gateway) callTimeout() time.Duration {
+	if g.config.Server.CallTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(g.config.Server.CallTimeout) * time.Second
+}
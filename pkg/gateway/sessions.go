@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/openclaw/go-openclaw/internal/agent/session"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// ExportSession returns an agent session of a workspace with its messages
+func (g *Gateway) ExportSession(workspace, id string) (*session.Session, error) {
+	if g.agentRuntime != nil {
+		for _, sess := range g.agentRuntime.Sessions(workspace) {
+			if sess.ID == id {
+				return sess, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown session: %s", id)
+}
+
+// handleSessionsRequest handles sessions.* requests, scoped to the
+// client's workspace and, but for admins, to the sessions of its user
+func (g *Gateway) handleSessionsRequest(client *Client, msg *protocol.ProtocolMessage) error {
+	var req protocol.SessionRequest
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &req); err != nil {
+			return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("invalid params: %v", err))
+		}
+	}
+	if !client.handshaken() {
+		return client.SendResponse(msg.ID, false, nil, "connect first")
+	}
+
+	switch msg.Method {
+	case "sessions.list":
+		states := make([]*protocol.SessionState, 0)
+		for _, state := range g.sessionStates(client.Workspace()) {
+			if ownsSession(client, state.Channel) {
+				states = append(states, state)
+			}
+		}
+		return client.SendResponse(msg.ID, true, map[string]interface{}{"sessions": states}, "")
+
+	case "sessions.export":
+		sess, err := g.ExportSession(client.Workspace(), req.ID)
+		if err == nil {
+			if channelID, _ := sess.Metadata["channel_id"].(string); !ownsSession(client, channelID) {
+				err = fmt.Errorf("unknown session: %s", req.ID)
+			}
+		}
+		if err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		return client.SendResponse(msg.ID, true, sess, "")
+
+	default:
+		return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("unknown method: %s", msg.Method))
+	}
+}
@@ -0,0 +1,284 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/agent/session"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// Kinds of state entries
+const (
+	stateClient  = "client"
+	stateSession = "session"
+	stateChannel = "channel"
+	stateAgent   = "agent"
+)
+
+// stateEntry is one item of workspace state
+type stateEntry struct {
+	kind        string
+	id          string
+	value       interface{}
+	fingerprint []byte // value without fields that change on every send
+}
+
+// stateTracker remembers the state each workspace was last sent, to push
+// only what changed
+type stateTracker struct {
+	seqs map[string]uint64                 // workspace -> seq of the last diff
+	last map[string]map[string]*stateEntry // workspace -> kind/id -> entry
+	mu   sync.Mutex
+}
+
+// newStateTracker creates an empty state tracker
+func newStateTracker() *stateTracker {
+	return &stateTracker{
+		seqs: make(map[string]uint64),
+		last: make(map[string]map[string]*stateEntry),
+	}
+}
+
+// stateEntries collects the state visible in a workspace, keyed by kind/id
+func (g *Gateway) stateEntries(workspace string) map[string]*stateEntry {
+	entries := make(map[string]*stateEntry)
+	add := func(kind, id string, value, fingerprint interface{}) {
+		data, err := json.Marshal(fingerprint)
+		if err != nil {
+			return
+		}
+		entries[kind+"/"+id] = &stateEntry{kind: kind, id: id, value: value, fingerprint: data}
+	}
+
+	for _, client := range g.GetClients() {
+		if !client.handshaken() || client.Workspace() != workspace {
+			continue
+		}
+		state := client.GetState()
+
+		// Queue counters move with every message sent, including diffs
+		stable := *state
+		if state.Queue != nil {
+			stable.Queue = &protocol.QueueStats{Policy: state.Queue.Policy, Status: state.Queue.Status}
+		}
+		add(stateClient, state.ID, state, &stable)
+	}
+
+	for _, sess := range g.sessionStates(workspace) {
+		add(stateSession, sess.ID, sess, sess)
+	}
+
+	for _, ch := range g.channelStates() {
+		add(stateChannel, ch.Name, ch, ch)
+	}
+
+	agent := g.agentState(workspace)
+	add(stateAgent, "agent", agent, agent)
+
+	return entries
+}
+
+// sessionStates returns the agent sessions of a workspace
+func (g *Gateway) sessionStates(workspace string) []*protocol.SessionState {
+	states := make([]*protocol.SessionState, 0)
+	if g.agentRuntime == nil {
+		return states
+	}
+
+	for _, sess := range g.agentRuntime.Sessions(workspace) {
+		states = append(states, sessionState(sess))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+	return states
+}
+
+// sessionState converts an agent session to its protocol state
+func sessionState(sess *session.Session) *protocol.SessionState {
+	channelID, _ := sess.Metadata["channel_id"].(string)
+	return &protocol.SessionState{
+		ID:         sess.ID,
+		Channel:    channelID,
+		CreatedAt:  sess.CreatedAt.Unix(),
+		LastActive: sess.LastActive.Unix(),
+		Status:     sess.Status,
+		Metadata:   map[string]string{"messages": fmt.Sprintf("%d", len(sess.Messages))},
+	}
+}
+
+// channelStates returns the status of the messaging channels
+func (g *Gateway) channelStates() []*protocol.ChannelState {
+	states := make([]*protocol.ChannelState, 0)
+	for _, ch := range g.channels.GetAll() {
+		states = append(states, &protocol.ChannelState{
+			Name:    ch.Name(),
+			Status:  ch.Status(),
+			Running: ch.IsRunning(),
+		})
+	}
+	return states
+}
+
+// agentState returns the agent runtime status as seen from a workspace
+func (g *Gateway) agentState(workspace string) *protocol.AgentState {
+	state := &protocol.AgentState{Status: g.GetAgentStatus()}
+	if g.agentRuntime != nil {
+		stats := g.agentRuntime.GetStats()
+		state.Provider = stats.LLMProvider
+		state.Model = stats.LLMModel
+		state.Sessions = len(g.agentRuntime.Sessions(workspace))
+	}
+	return state
+}
+
+// syncState compares the state of a workspace with what its clients were
+// last sent and pushes the difference as a state.update event. It returns
+// the current state and its seq, so snapshots line up with the diffs. The
+// skip client, about to receive a snapshot instead, is not sent the diff.
+func (g *Gateway) syncState(workspace string, skip *Client) (map[string]*stateEntry, uint64) {
+	g.state.mu.Lock()
+	defer g.state.mu.Unlock()
+
+	current := g.stateEntries(workspace)
+	last, known := g.state.last[workspace]
+	g.state.last[workspace] = current
+	if !known {
+		return current, g.state.seqs[workspace]
+	}
+
+	changes := diffState(last, current)
+	if len(changes) == 0 {
+		return current, g.state.seqs[workspace]
+	}
+
+	g.state.seqs[workspace]++
+	seq := g.state.seqs[workspace]
+	diff := &protocol.StateDiff{
+		Seq:       seq,
+		Workspace: workspace,
+		Changes:   changes,
+		Timestamp: time.Now().Unix(),
+	}
+
+	// Sent under the lock so clients receive diffs in seq order
+	for _, client := range g.GetClients() {
+		if client != skip && client.handshaken() && client.Workspace() == workspace {
+			client.SendEvent(string(protocol.EventStateUpdate), diff, int(seq))
+		}
+	}
+	return current, seq
+}
+
+// diffState lists the changes from one state to the next in a stable order
+func diffState(last, current map[string]*stateEntry) []*protocol.StateChange {
+	changes := make([]*protocol.StateChange, 0)
+	for key, entry := range current {
+		old, ok := last[key]
+		switch {
+		case !ok:
+			changes = append(changes, &protocol.StateChange{Op: "add", Kind: entry.kind, ID: entry.id, Value: entry.value})
+		case !bytes.Equal(old.fingerprint, entry.fingerprint):
+			changes = append(changes, &protocol.StateChange{Op: "update", Kind: entry.kind, ID: entry.id, Value: entry.value})
+		}
+	}
+	for key, entry := range last {
+		if _, ok := current[key]; !ok {
+			changes = append(changes, &protocol.StateChange{Op: "remove", Kind: entry.kind, ID: entry.id})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+// pushStateUpdates sends pending state diffs to every workspace with
+// connected clients
+func (g *Gateway) pushStateUpdates() {
+	workspaces := make(map[string]bool)
+	for _, client := range g.GetClients() {
+		if client.handshaken() {
+			workspaces[client.Workspace()] = true
+		}
+	}
+
+	for workspace := range workspaces {
+		g.syncState(workspace, nil)
+	}
+}
+
+// Snapshot returns the state of a client's workspace, consistent with the
+// state.update diffs up to its seq
+func (g *Gateway) Snapshot(client *Client, includeClients, includeSessions bool) *protocol.StateSnapshot {
+	client.mu.RLock()
+	workspace, sessionID := client.workspace, client.sessionID
+	client.mu.RUnlock()
+
+	entries, seq := g.syncState(workspace, client)
+
+	snapshot := &protocol.StateSnapshot{
+		Version:   "0.0.1",
+		GatewayID: g.id,
+		ClientID:  client.ID,
+		SessionID: sessionID,
+		Workspace: workspace,
+		Channels:  make([]*protocol.ChannelState, 0),
+		Metadata:  map[string]string{"gateway": g.id},
+		Timestamp: time.Now().Unix(),
+		Seq:       seq,
+	}
+	if includeClients {
+		snapshot.Clients = make([]*protocol.ClientState, 0)
+	}
+	if includeSessions {
+		snapshot.Sessions = make([]*protocol.SessionState, 0)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch value := entries[key].value.(type) {
+		case *protocol.ClientState:
+			if includeClients {
+				snapshot.Clients = append(snapshot.Clients, value)
+			}
+		case *protocol.SessionState:
+			if includeSessions {
+				snapshot.Sessions = append(snapshot.Sessions, value)
+			}
+		case *protocol.ChannelState:
+			snapshot.Channels = append(snapshot.Channels, value)
+		case *protocol.AgentState:
+			snapshot.Agent = value
+		}
+	}
+
+	return snapshot
+}
+
+// handleState returns a snapshot of the client's workspace
+func (g *Gateway) handleState(client *Client, msg *protocol.ProtocolMessage) error {
+	var req protocol.StateRequest
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &req); err != nil {
+			return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("invalid params: %v", err))
+		}
+	}
+	if !client.handshaken() {
+		return client.SendResponse(msg.ID, false, nil, "connect first")
+	}
+
+	return client.SendResponse(msg.ID, true, g.Snapshot(client, req.IncludeClients, req.IncludeSessions), "")
+}
@@ -0,0 +1,287 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/openclaw/go-openclaw/internal/agent"
+	"github.com/openclaw/go-openclaw/internal/models"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/pkg/workspaces"
+)
+
+// Workspaces returns the workspace manager
+func (g *Gateway) Workspaces() *workspaces.Manager {
+	return g.workspaces
+}
+
+// ListWorkspaces returns all workspaces
+func (g *Gateway) ListWorkspaces() []protocol.WorkspaceInfo {
+	infos := make([]protocol.WorkspaceInfo, 0)
+	for _, ws := range g.workspaces.List() {
+		infos = append(infos, workspaces.Info(ws, g.workspaceClients(ws.ID)))
+	}
+	return infos
+}
+
+// GetWorkspace returns a workspace
+func (g *Gateway) GetWorkspace(id string) (*protocol.WorkspaceInfo, bool) {
+	ws, ok := g.workspaces.Get(id)
+	if !ok {
+		return nil, false
+	}
+	info := workspaces.Info(ws, g.workspaceClients(ws.ID))
+	return &info, true
+}
+
+// clientWorkspaces returns the workspaces a client may join, all of them
+// for admins
+func (g *Gateway) clientWorkspaces(client *Client) []protocol.WorkspaceInfo {
+	infos := make([]protocol.WorkspaceInfo, 0)
+	for _, ws := range g.workspaces.List() {
+		if canJoin(client, ws) {
+			infos = append(infos, workspaces.Info(ws, g.workspaceClients(ws.ID)))
+		}
+	}
+	return infos
+}
+
+// canSee reports whether a client may read a workspace: its members and
+// admins may
+func (g *Gateway) canSee(client *Client, workspaceID string) bool {
+	ws, ok := g.workspaces.Get(workspaceID)
+	return ok && canJoin(client, ws)
+}
+
+// canJoin reports whether a client may join a workspace. Membership goes
+// by the user of the client's token, not by anything the client says
+// about itself.
+func canJoin(client *Client, ws *models.Workspace) bool {
+	client.mu.RLock()
+	user, admin := client.authUser, client.admin
+	client.mu.RUnlock()
+	return workspaces.CanJoin(ws, user, admin)
+}
+
+// SwitchWorkspace moves a client to another workspace
+func (g *Gateway) SwitchWorkspace(clientID, workspaceID string) error {
+	client, ok := g.GetClient(clientID)
+	if !ok {
+		return fmt.Errorf("client not found: %s", clientID)
+	}
+	return g.joinWorkspace(client, workspaceID)
+}
+
+// WorkspaceForChannel returns the workspace a channel target is bound to
+func (g *Gateway) WorkspaceForChannel(channel, target string) string {
+	return g.workspaces.ForChannel(channel, target)
+}
+
+// ProcessMessage runs an agent turn in the session of channelID within a
+// workspace, with the workspace prompt, tools and quotas
+func (g *Gateway) ProcessMessage(ctx context.Context, workspaceID, channelID, msg string) (string, error) {
+	return g.ProcessInput(ctx, workspaceID, channelID, &agent.Input{Text: msg})
+}
+
+// StreamMessage runs an agent turn like ProcessMessage, passing the reply
+// to onDelta as it is generated
+func (g *Gateway) StreamMessage(ctx context.Context, workspaceID, channelID, msg string, onDelta func(delta string)) (string, error) {
+	return g.StreamInput(ctx, workspaceID, channelID, &agent.Input{Text: msg}, onDelta)
+}
+
+// ProcessInput runs an agent turn like ProcessMessage for a message with
+// images
+func (g *Gateway) ProcessInput(ctx context.Context, workspaceID, channelID string, input *agent.Input) (string, error) {
+	return g.runTurn(ctx, workspaceID, channelID, input, nil)
+}
+
+// StreamInput runs an agent turn like StreamMessage for a message with
+// images
+func (g *Gateway) StreamInput(ctx context.Context, workspaceID, channelID string, input *agent.Input, onDelta func(delta string)) (string, error) {
+	if onDelta == nil {
+		onDelta = func(string) {}
+	}
+	return g.runTurn(ctx, workspaceID, channelID, input, onDelta)
+}
+
+// runTurn runs an agent turn, streaming when onDelta is set. In a cluster
+// the node owning the session runs its turns, so they are forwarded there.
+func (g *Gateway) runTurn(ctx context.Context, workspaceID, channelID string, input *agent.Input, onDelta func(delta string)) (string, error) {
+	if _, ok := g.workspaces.Get(workspaceID); !ok {
+		return "", workspaces.ErrUnknownWorkspace
+	}
+
+	owner, local, err := g.ClaimSession(ctx, workspaceID+"/"+channelID)
+	if err != nil {
+		return "", fmt.Errorf("failed to claim session: %w", err)
+	}
+	if !local {
+		return g.forwardTurn(ctx, owner, &remoteTurn{
+			Workspace: workspaceID,
+			ChannelID: channelID,
+			Text:      input.Text,
+			Images:    input.Images,
+			Stream:    onDelta != nil,
+		}, onDelta)
+	}
+	return g.runLocalTurn(ctx, workspaceID, channelID, input, onDelta)
+}
+
+// runLocalTurn runs an agent turn on this node
+func (g *Gateway) runLocalTurn(ctx context.Context, workspaceID, channelID string, input *agent.Input, onDelta func(delta string)) (string, error) {
+	if g.agentRuntime == nil {
+		return "", fmt.Errorf("agent runtime is not running")
+	}
+	ws, ok := g.workspaces.Get(workspaceID)
+	if !ok {
+		return "", workspaces.ErrUnknownWorkspace
+	}
+	if onDelta != nil {
+		return g.agentRuntime.StreamInputIn(ctx, workspaces.Scope(ws), channelID, input, onDelta)
+	}
+	return g.agentRuntime.ProcessInputIn(ctx, workspaces.Scope(ws), channelID, input)
+}
+
+// joinWorkspace puts a client in a workspace if its membership and client
+// quota allow
+func (g *Gateway) joinWorkspace(client *Client, workspaceID string) error {
+	ws, ok := g.workspaces.Get(workspaceID)
+	if !ok {
+		return fmt.Errorf("%w: %s", workspaces.ErrUnknownWorkspace, workspaceID)
+	}
+	if !canJoin(client, ws) {
+		return workspaces.ErrNotMember
+	}
+	if client.Workspace() == ws.ID {
+		return nil
+	}
+	if ws.Quotas.MaxClients > 0 && g.workspaceClients(ws.ID) >= ws.Quotas.MaxClients {
+		return fmt.Errorf("workspace %s is full (max %d clients)", ws.ID, ws.Quotas.MaxClients)
+	}
+
+	client.mu.Lock()
+	client.workspace = ws.ID
+	client.mu.Unlock()
+	if g.presence != nil {
+		g.presence.Move(client.ID, ws.ID)
+	}
+	return nil
+}
+
+// workspaceClients counts the local clients in a workspace
+func (g *Gateway) workspaceClients(workspaceID string) int {
+	g.clientsLock.RLock()
+	defer g.clientsLock.RUnlock()
+
+	count := 0
+	for _, client := range g.clients {
+		if client.Workspace() == workspaceID {
+			count++
+		}
+	}
+	return count
+}
+
+// publishWorkspaceUpdate publishes a workspace.update event on the
+// workspace's channel
+func (g *Gateway) publishWorkspaceUpdate(ws *models.Workspace, action string) {
+	g.eventBus.Publish(protocol.EventWorkspaceUpdate, ws.ID, map[string]interface{}{
+		"workspace": ws.ID,
+		"name":      ws.Name,
+		"action":    action,
+	})
+}
+
+// handleWorkspaceRequest handles workspace.* requests
+func (g *Gateway) handleWorkspaceRequest(client *Client, msg *protocol.ProtocolMessage) error {
+	var req protocol.WorkspaceRequest
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &req); err != nil {
+			return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("invalid params: %v", err))
+		}
+	}
+
+	switch msg.Method {
+	case "workspace.create", "workspace.update", "workspace.delete":
+		if !client.IsAdmin() {
+			return client.SendResponse(msg.ID, false, nil, protocol.ErrUnauthorized.Error())
+		}
+	}
+
+	switch msg.Method {
+	case "workspace.list":
+		return client.SendResponse(msg.ID, true, &protocol.WorkspaceResponse{Status: "ok", Workspaces: g.clientWorkspaces(client)}, "")
+
+	case "workspace.get":
+		id := req.Workspace
+		if id == "" {
+			id = client.Workspace()
+		}
+		info, ok := g.GetWorkspace(id)
+		if !ok {
+			return client.SendResponse(msg.ID, false, nil, workspaces.ErrUnknownWorkspace.Error())
+		}
+		if !g.canSee(client, id) {
+			return client.SendResponse(msg.ID, false, nil, workspaces.ErrNotMember.Error())
+		}
+		return client.SendResponse(msg.ID, true, &protocol.WorkspaceResponse{Status: "ok", Workspace: info}, "")
+
+	case "workspace.create":
+		ws, err := g.workspaces.Create(req.Spec)
+		if err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		log.Printf("🗂️ Workspace created: %s (%s)", ws.ID, ws.Name)
+		g.publishWorkspaceUpdate(ws, "created")
+		info := workspaces.Info(ws, 0)
+		return client.SendResponse(msg.ID, true, &protocol.WorkspaceResponse{Status: "ok", Workspace: &info}, "")
+
+	case "workspace.update":
+		ws, err := g.workspaces.Update(req.Workspace, req.Spec)
+		if err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		log.Printf("🗂️ Workspace updated: %s", ws.ID)
+		g.publishWorkspaceUpdate(ws, "updated")
+		info := workspaces.Info(ws, g.workspaceClients(ws.ID))
+		return client.SendResponse(msg.ID, true, &protocol.WorkspaceResponse{Status: "ok", Workspace: &info}, "")
+
+	case "workspace.delete":
+		if req.Workspace == workspaces.DefaultID {
+			return client.SendResponse(msg.ID, false, nil, workspaces.ErrDefaultWorkspace.Error())
+		}
+		ws, ok := g.workspaces.Get(req.Workspace)
+		if !ok {
+			return client.SendResponse(msg.ID, false, nil, workspaces.ErrUnknownWorkspace.Error())
+		}
+		if n := g.workspaceClients(ws.ID); n > 0 {
+			return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("workspace %s has %d connected clients", ws.ID, n))
+		}
+		if err := g.workspaces.Delete(ws.ID); err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		log.Printf("🗂️ Workspace deleted: %s", ws.ID)
+		g.publishWorkspaceUpdate(ws, "deleted")
+		return client.SendResponse(msg.ID, true, &protocol.WorkspaceResponse{Status: "ok", Message: "Workspace deleted"}, "")
+
+	case "workspace.switch":
+		if err := g.SwitchWorkspace(client.ID, req.Workspace); err != nil {
+			return client.SendResponse(msg.ID, false, nil, err.Error())
+		}
+		info, _ := g.GetWorkspace(req.Workspace)
+		log.Printf("🗂️ Client %s switched to workspace %s", client.ID, req.Workspace)
+		// State seqs count per workspace, so the client starts over from a
+		// snapshot of the new one
+		return client.SendResponse(msg.ID, true, &protocol.WorkspaceResponse{
+			Status:    "ok",
+			Message:   fmt.Sprintf("Switched to workspace: %s", req.Workspace),
+			Workspace: info,
+			State:     g.Snapshot(client, true, true),
+		}, "")
+
+	default:
+		return client.SendResponse(msg.ID, false, nil, fmt.Sprintf("unknown method: %s", msg.Method))
+	}
+}
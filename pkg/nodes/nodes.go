@@ -0,0 +1,378 @@
+package nodes
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/models"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/internal/storage"
+	"github.com/openclaw/go-openclaw/pkg/workspaces"
+)
+
+// Well-known node capabilities
+const (
+	CapNotify      = "notify"
+	CapShellExec   = "shell.exec"
+	CapScreenshot  = "screenshot"
+	CapLocationGet = "location.get"
+)
+
+// Node statuses
+const (
+	StatusOnline  = "online"
+	StatusOffline = "offline"
+)
+
+// collection is the storage collection holding paired nodes
+const collection = "nodes"
+
+var (
+	ErrUnknownCode  = errors.New("unknown or expired pairing code")
+	ErrUnknownNode  = errors.New("unknown node")
+	ErrInvalidToken = errors.New("invalid node token")
+)
+
+// PairingRequest is a pairing request awaiting admin approval
+type PairingRequest struct {
+	Code         string
+	ClientID     string // connection that requested pairing
+	DeviceID     string
+	Name         string
+	Type         string
+	Capabilities []string
+	IPAddress    string
+	Workspace    string // workspace of the requesting connection
+	ExpiresAt    time.Time
+}
+
+// Info returns the protocol representation of the request
+func (r *PairingRequest) Info() *protocol.PairingInfo {
+	return &protocol.PairingInfo{
+		Code:         r.Code,
+		DeviceID:     r.DeviceID,
+		Name:         r.Name,
+		Type:         r.Type,
+		Capabilities: r.Capabilities,
+		ExpiresAt:    r.ExpiresAt.Unix(),
+	}
+}
+
+// Manager pairs nodes and keeps their persistent identities
+type Manager struct {
+	store   storage.Store
+	ttl     time.Duration
+	pending map[string]*PairingRequest // code -> request
+	nodes   map[string]*models.Node    // node ID -> node
+	mu      sync.RWMutex
+}
+
+// NewManager creates a node manager backed by store, loading the nodes
+// paired previously
+func NewManager(store storage.Store, pairingTTL time.Duration) (*Manager, error) {
+	m := &Manager{
+		store:   store,
+		ttl:     pairingTTL,
+		pending: make(map[string]*PairingRequest),
+		nodes:   make(map[string]*models.Node),
+	}
+
+	records, err := store.List(collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load nodes: %w", err)
+	}
+	for id, data := range records {
+		var node models.Node
+		if err := json.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("failed to load node %s: %w", id, err)
+		}
+		node.Status = StatusOffline
+		m.nodes[id] = &node
+	}
+
+	return m, nil
+}
+
+// RequestPairing registers a pairing request and returns it with its
+// one-time code. A new request from the same device replaces the old one.
+func (m *Manager) RequestPairing(req *PairingRequest) (*PairingRequest, error) {
+	code, err := newCode()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire()
+	for c, r := range m.pending {
+		if r.DeviceID == req.DeviceID {
+			delete(m.pending, c)
+		}
+	}
+
+	r := *req
+	r.Code = code
+	r.ExpiresAt = time.Now().Add(m.ttl)
+	m.pending[code] = &r
+	return &r, nil
+}
+
+// Pending returns the pairing requests awaiting approval
+func (m *Manager) Pending() []*PairingRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire()
+	requests := make([]*PairingRequest, 0, len(m.pending))
+	for _, r := range m.pending {
+		requests = append(requests, r)
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].ExpiresAt.Before(requests[j].ExpiresAt) })
+	return requests
+}
+
+// Approve consumes a pairing code and creates the node identity. The
+// returned token is the node's credential; only its hash is stored.
+func (m *Manager) Approve(code string) (*models.Node, string, *PairingRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire()
+	req, ok := m.pending[normalizeCode(code)]
+	if !ok {
+		return nil, "", nil, ErrUnknownCode
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	token, err := randomHex(32)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	now := time.Now()
+	node := &models.Node{
+		ID:           "node-" + id,
+		DeviceID:     req.DeviceID,
+		Name:         req.Name,
+		Type:         req.Type,
+		Status:       StatusOffline,
+		IPAddress:    req.IPAddress,
+		PairedAt:     now,
+		LastSeen:     now,
+		Capabilities: req.Capabilities,
+		Metadata:     make(map[string]string),
+		TokenHash:    hashToken(token),
+		Workspace:    req.Workspace,
+	}
+
+	if err := m.store.Put(collection, node.ID, node); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to save node: %w", err)
+	}
+
+	delete(m.pending, req.Code)
+	m.nodes[node.ID] = node
+	return node, token, req, nil
+}
+
+// Reject consumes a pairing code without pairing
+func (m *Manager) Reject(code string) (*PairingRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire()
+	req, ok := m.pending[normalizeCode(code)]
+	if !ok {
+		return nil, ErrUnknownCode
+	}
+	delete(m.pending, req.Code)
+	return req, nil
+}
+
+// Authenticate checks a node's credential
+func (m *Manager) Authenticate(nodeID, token string) (*models.Node, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, ok := m.nodes[nodeID]
+	if !ok {
+		return nil, ErrUnknownNode
+	}
+	if subtle.ConstantTimeCompare([]byte(node.TokenHash), []byte(hashToken(token))) != 1 {
+		return nil, ErrInvalidToken
+	}
+	return node, nil
+}
+
+// SetOnline records a node connecting or disconnecting. The capabilities
+// approved at pairing are kept whatever the node advertises.
+func (m *Manager) SetOnline(nodeID string, online bool, ip string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, ok := m.nodes[nodeID]
+	if !ok {
+		return ErrUnknownNode
+	}
+
+	node.Status = StatusOffline
+	if online {
+		node.Status = StatusOnline
+	}
+	node.LastSeen = time.Now()
+	if ip != "" {
+		node.IPAddress = ip
+	}
+	return m.store.Put(collection, node.ID, node)
+}
+
+// Get returns a copy of a node
+func (m *Manager) Get(nodeID string) (*models.Node, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, ok := m.nodes[nodeID]
+	if !ok {
+		return nil, false
+	}
+	n := *node
+	return &n, true
+}
+
+// List returns copies of all paired nodes ordered by name
+func (m *Manager) List() []*models.Node {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	nodes := make([]*models.Node, 0, len(m.nodes))
+	for _, node := range m.nodes {
+		n := *node
+		nodes = append(nodes, &n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+// Remove unpairs a node, revoking its credential
+func (m *Manager) Remove(nodeID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.nodes[nodeID]; !ok {
+		return ErrUnknownNode
+	}
+	if err := m.store.Delete(collection, nodeID); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	delete(m.nodes, nodeID)
+	return nil
+}
+
+// HasCapability reports whether a node advertises a capability
+func HasCapability(node *models.Node, capability string) bool {
+	for _, c := range node.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Approved returns the capabilities a node advertises that were approved
+// at pairing, all approved ones when it advertises none
+func Approved(node *models.Node, advertised []string) []string {
+	if len(advertised) == 0 {
+		return node.Capabilities
+	}
+	approved := make([]string, 0, len(advertised))
+	for _, c := range advertised {
+		if HasCapability(node, c) {
+			approved = append(approved, c)
+		}
+	}
+	return approved
+}
+
+// InWorkspace reports whether a node was paired in a workspace. Nodes
+// paired before workspaces were recorded belong to the default workspace.
+func InWorkspace(node *models.Node, workspace string) bool {
+	if node.Workspace == "" {
+		return workspace == workspaces.DefaultID
+	}
+	return node.Workspace == workspace
+}
+
+// Info returns the protocol representation of a node
+func Info(node *models.Node) protocol.NodeInfo {
+	return protocol.NodeInfo{
+		ID:           node.ID,
+		Name:         node.Name,
+		Type:         node.Type,
+		Status:       node.Status,
+		Capabilities: node.Capabilities,
+		Workspace:    node.Workspace,
+		PairedAt:     node.PairedAt.Unix(),
+		LastSeen:     node.LastSeen.Unix(),
+	}
+}
+
+// expire drops expired pairing requests. The caller holds the lock.
+func (m *Manager) expire() {
+	now := time.Now()
+	for code, r := range m.pending {
+		if now.After(r.ExpiresAt) {
+			delete(m.pending, code)
+		}
+	}
+}
+
+// codeAlphabet omits characters that are easily confused when read aloud
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// newCode returns a random pairing code like "K7QM-2XPA"
+func newCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate pairing code: %w", err)
+	}
+	for i := range b {
+		b[i] = codeAlphabet[int(b[i])%len(codeAlphabet)]
+	}
+	return string(b[:4]) + "-" + string(b[4:]), nil
+}
+
+// normalizeCode accepts codes typed in lower case or without the dash
+func normalizeCode(code string) string {
+	code = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if len(code) != 8 {
+		return code
+	}
+	return code[:4] + "-" + code[4:]
+}
+
+// randomHex returns n random bytes as hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken returns the stored form of a node token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,170 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/agent/tools"
+	"github.com/openclaw/go-openclaw/internal/models"
+)
+
+// Invoker invokes a capability on a connected node
+type Invoker func(ctx context.Context, nodeID, capability string, params json.RawMessage) (json.RawMessage, error)
+
+// capabilityTools describes the agent tools backed by node capabilities
+var capabilityTools = []struct {
+	name        string
+	capability  string
+	description string
+	properties  map[string]interface{}
+	required    []string
+	restricted  bool // offered only to workspaces naming the tool
+}{
+	{
+		name:        "node_notify",
+		capability:  CapNotify,
+		description: "Show a notification on a paired device.",
+		properties: map[string]interface{}{
+			"title": map[string]interface{}{"type": "string", "description": "Notification title"},
+			"body":  map[string]interface{}{"type": "string", "description": "Notification text"},
+		},
+		required: []string{"body"},
+	},
+	{
+		name:        "node_shell_exec",
+		capability:  CapShellExec,
+		description: "Run a shell command on a paired machine and return its output and exit code.",
+		properties: map[string]interface{}{
+			"command": map[string]interface{}{"type": "string", "description": "Command line to run"},
+			"cwd":     map[string]interface{}{"type": "string", "description": "Working directory"},
+			"timeout": map[string]interface{}{"type": "integer", "description": "Timeout in seconds"},
+		},
+		required:   []string{"command"},
+		restricted: true,
+	},
+	{
+		name:        "node_screenshot",
+		capability:  CapScreenshot,
+		description: "Capture the screen of a paired device.",
+		properties: map[string]interface{}{
+			"display": map[string]interface{}{"type": "integer", "description": "Display index, 0 for the primary display"},
+		},
+		restricted: true,
+	},
+	{
+		name:        "node_location_get",
+		capability:  CapLocationGet,
+		description: "Get the current location of a paired device.",
+		properties:  map[string]interface{}{},
+		restricted:  true,
+	},
+}
+
+// RegisterTools registers agent tools listing paired nodes and invoking
+// their capabilities through invoke. Tools called within a workspace only
+// see the nodes paired in it; shell, screen and location access must be
+// named by the workspace's tools.
+func RegisterTools(registry *tools.Registry, manager *Manager, invoke Invoker) error {
+	list := &tools.Tool{
+		Name:        "node_list",
+		Description: "List paired devices with their status and capabilities.",
+		InputSchema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+		Handler: func(ctx context.Context, params map[string]interface{}) (*tools.ToolResult, error) {
+			nodes := make([]interface{}, 0)
+			for _, node := range manager.List() {
+				if inScope(ctx, node) {
+					nodes = append(nodes, Info(node))
+				}
+			}
+			return &tools.ToolResult{
+				Name:    "node_list",
+				Success: true,
+				Data:    map[string]interface{}{"nodes": nodes},
+			}, nil
+		},
+	}
+	if err := registry.Register(list); err != nil {
+		return err
+	}
+
+	for _, def := range capabilityTools {
+		properties := map[string]interface{}{
+			"node_id": map[string]interface{}{"type": "string", "description": "ID of the node, from node_list"},
+		}
+		for name, schema := range def.properties {
+			properties[name] = schema
+		}
+
+		tool := &tools.Tool{
+			Name:        def.name,
+			Description: def.description,
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": properties,
+				"required":   append([]string{"node_id"}, def.required...),
+			},
+			Restricted: def.restricted,
+			Handler:    capabilityHandler(def.name, def.capability, manager, invoke),
+		}
+		if err := registry.Register(tool); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// capabilityHandler returns a tool handler forwarding to a node capability
+func capabilityHandler(name, capability string, manager *Manager, invoke Invoker) tools.ToolHandler {
+	return func(ctx context.Context, params map[string]interface{}) (*tools.ToolResult, error) {
+		start := time.Now()
+
+		nodeID, _ := params["node_id"].(string)
+		if nodeID == "" {
+			return nil, fmt.Errorf("node_id is required")
+		}
+		if node, ok := manager.Get(nodeID); !ok || !inScope(ctx, node) {
+			return nil, ErrUnknownNode
+		}
+
+		args := make(map[string]interface{}, len(params))
+		for k, v := range params {
+			if k != "node_id" {
+				args[k] = v
+			}
+		}
+		raw, err := json.Marshal(args)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := invoke(ctx, nodeID, capability, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		// Tool results are objects; wrap anything else
+		var data map[string]interface{}
+		if len(result) > 0 && json.Unmarshal(result, &data) != nil {
+			var value interface{}
+			json.Unmarshal(result, &value)
+			data = map[string]interface{}{"result": value}
+		}
+
+		return &tools.ToolResult{
+			Name:     name,
+			Success:  true,
+			Data:     data,
+			ExecTime: time.Since(start).Seconds(),
+		}, nil
+	}
+}
+
+// inScope reports whether a tool called with ctx may use a node: within a
+// workspace, only the nodes paired in it
+func inScope(ctx context.Context, node *models.Node) bool {
+	workspace := tools.Workspace(ctx)
+	return workspace == "" || InWorkspace(node, workspace)
+}
@@ -0,0 +1,361 @@
+package presence
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// Presence statuses
+const (
+	StatusOnline  = "online"
+	StatusAway    = "away"
+	StatusBusy    = "busy"
+	StatusOffline = "offline"
+)
+
+// rank orders statuses for aggregation: a user is busy if any of their
+// connections is, otherwise online if any is, and so on
+var rank = map[string]int{
+	StatusOffline: 0,
+	StatusAway:    1,
+	StatusOnline:  2,
+	StatusBusy:    3,
+}
+
+// ChangeHandler is called when the aggregated status of a user changes as
+// seen from a workspace. Users are offline in workspaces they have no
+// connection in.
+type ChangeHandler func(workspace string, info *protocol.PresenceInfo, previous string)
+
+// connection is the presence state of one client connection
+type connection struct {
+	user       string
+	deviceID   string
+	workspace  string
+	explicit   string // status set by the client, empty for automatic
+	lastActive time.Time
+	alive      bool
+}
+
+// status returns the status of the connection at now
+func (c *connection) status(now time.Time, awayAfter time.Duration) string {
+	switch {
+	case !c.alive:
+		return StatusOffline
+	case c.explicit != "":
+		return c.explicit
+	case awayAfter > 0 && now.Sub(c.lastActive) > awayAfter:
+		return StatusAway
+	default:
+		return StatusOnline
+	}
+}
+
+// Tracker computes user presence from their connections
+type Tracker struct {
+	awayAfter time.Duration
+	conns     map[string]*connection       // connection ID -> state
+	statuses  map[string]string            // user -> last aggregated status
+	reported  map[string]map[string]string // user -> workspace -> last reported status
+	onChange  ChangeHandler
+	mu        sync.Mutex
+}
+
+// NewTracker creates a presence tracker. Connections without activity for
+// awayAfter are away; zero disables automatic away.
+func NewTracker(awayAfter time.Duration, onChange ChangeHandler) *Tracker {
+	return &Tracker{
+		awayAfter: awayAfter,
+		conns:     make(map[string]*connection),
+		statuses:  make(map[string]string),
+		reported:  make(map[string]map[string]string),
+		onChange:  onChange,
+	}
+}
+
+// Connect adds a connection of a user in a workspace
+func (t *Tracker) Connect(connID, user, deviceID, workspace string) {
+	t.update(func(now time.Time) []string {
+		var users []string
+		if old, ok := t.conns[connID]; ok && old.user != user {
+			users = append(users, old.user)
+		}
+		t.conns[connID] = &connection{
+			user:       user,
+			deviceID:   deviceID,
+			workspace:  workspace,
+			lastActive: now,
+			alive:      true,
+		}
+		return append(users, user)
+	})
+}
+
+// Disconnect removes a connection
+func (t *Tracker) Disconnect(connID string) {
+	t.update(func(now time.Time) []string {
+		c, ok := t.conns[connID]
+		if !ok {
+			return nil
+		}
+		delete(t.conns, connID)
+		return []string{c.user}
+	})
+}
+
+// Move moves a connection to another workspace
+func (t *Tracker) Move(connID, workspace string) {
+	t.update(func(now time.Time) []string {
+		c, ok := t.conns[connID]
+		if !ok || c.workspace == workspace {
+			return nil
+		}
+		c.workspace = workspace
+		return []string{c.user}
+	})
+}
+
+// Set sets the status of a connection. An empty status returns it to
+// automatic detection.
+func (t *Tracker) Set(connID, status string) error {
+	switch status {
+	case "", StatusOnline, StatusAway, StatusBusy:
+	default:
+		return fmt.Errorf("invalid presence status %q", status)
+	}
+
+	var err error
+	t.update(func(now time.Time) []string {
+		c, ok := t.conns[connID]
+		if !ok {
+			err = fmt.Errorf("connection has no presence")
+			return nil
+		}
+		c.explicit = status
+		c.lastActive = now
+		return []string{c.user}
+	})
+	return err
+}
+
+// Touch records activity on a connection
+func (t *Tracker) Touch(connID string) {
+	t.update(func(now time.Time) []string {
+		c, ok := t.conns[connID]
+		if !ok {
+			return nil
+		}
+		c.lastActive = now
+		return []string{c.user}
+	})
+}
+
+// SetAlive records whether a connection still answers heartbeats
+func (t *Tracker) SetAlive(connID string, alive bool) {
+	t.update(func(now time.Time) []string {
+		c, ok := t.conns[connID]
+		if !ok || c.alive == alive {
+			return nil
+		}
+		c.alive = alive
+		return []string{c.user}
+	})
+}
+
+// Sweep re-evaluates every user, reporting those gone away since their
+// last activity
+func (t *Tracker) Sweep() {
+	t.update(func(now time.Time) []string {
+		users := make([]string, 0, len(t.statuses))
+		for user := range t.statuses {
+			users = append(users, user)
+		}
+		return users
+	})
+}
+
+// Status returns the status of a single connection
+func (t *Tracker) Status(connID string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.conns[connID]
+	if !ok {
+		return StatusOffline
+	}
+	return c.status(time.Now(), t.awayAfter)
+}
+
+// Get returns the presence of a user
+func (t *Tracker) Get(user string) (*protocol.PresenceInfo, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.statuses[user]; !ok {
+		return nil, false
+	}
+	return t.info(user, time.Now()), true
+}
+
+// GetIn returns the presence of a user with a connection in a workspace
+func (t *Tracker) GetIn(user, workspace string) (*protocol.PresenceInfo, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.workspaces(user)[workspace] {
+		return nil, false
+	}
+	return t.info(user, time.Now()), true
+}
+
+// List returns the presence of every connected user ordered by user
+func (t *Tracker) List() []*protocol.PresenceInfo {
+	return t.list(func(string) bool { return true })
+}
+
+// ListIn returns the presence of the users with a connection in a
+// workspace ordered by user
+func (t *Tracker) ListIn(workspace string) []*protocol.PresenceInfo {
+	return t.list(func(user string) bool { return t.workspaces(user)[workspace] })
+}
+
+// list returns the presence of the connected users matching keep
+func (t *Tracker) list(keep func(user string) bool) []*protocol.PresenceInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	list := make([]*protocol.PresenceInfo, 0, len(t.statuses))
+	for user := range t.statuses {
+		if keep(user) {
+			list = append(list, t.info(user, now))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].UserID < list[j].UserID })
+	return list
+}
+
+// IsActive reports whether a user is online and not away or busy
+func (t *Tracker) IsActive(user string) bool {
+	info, ok := t.Get(user)
+	return ok && info.Status == StatusOnline
+}
+
+// update applies fn under the lock, then reports the users among those it
+// returns whose status changed in a workspace
+func (t *Tracker) update(fn func(now time.Time) []string) {
+	type change struct {
+		workspace string
+		info      *protocol.PresenceInfo
+		previous  string
+	}
+	var changes []change
+
+	t.mu.Lock()
+	now := time.Now()
+	for _, user := range fn(now) {
+		info := t.info(user, now)
+		if info.Status == StatusOffline && info.Connections == 0 {
+			delete(t.statuses, user)
+		} else {
+			t.statuses[user] = info.Status
+		}
+
+		// Report the status to the workspaces the user is in, and offline
+		// to those the user has left
+		in := t.workspaces(user)
+		reported := t.reported[user]
+		workspaces := make([]string, 0, len(in)+len(reported))
+		for workspace := range in {
+			workspaces = append(workspaces, workspace)
+		}
+		for workspace := range reported {
+			if !in[workspace] {
+				workspaces = append(workspaces, workspace)
+			}
+		}
+		sort.Strings(workspaces)
+
+		shown := make(map[string]string)
+		for _, workspace := range workspaces {
+			status := StatusOffline
+			if in[workspace] {
+				status = info.Status
+			}
+			previous, ok := reported[workspace]
+			if !ok {
+				previous = StatusOffline
+			}
+			if status != StatusOffline {
+				shown[workspace] = status
+			}
+			if status != previous {
+				seen := *info
+				seen.Status = status
+				changes = append(changes, change{workspace, &seen, previous})
+			}
+		}
+		if len(shown) == 0 {
+			delete(t.reported, user)
+		} else {
+			t.reported[user] = shown
+		}
+	}
+	t.mu.Unlock()
+
+	if t.onChange == nil {
+		return
+	}
+	for _, c := range changes {
+		t.onChange(c.workspace, c.info, c.previous)
+	}
+}
+
+// workspaces returns the workspaces a user has connections in. The caller
+// holds the lock.
+func (t *Tracker) workspaces(user string) map[string]bool {
+	in := make(map[string]bool)
+	for _, c := range t.conns {
+		if c.user == user {
+			in[c.workspace] = true
+		}
+	}
+	return in
+}
+
+// info aggregates the connections of a user. The caller holds the lock.
+func (t *Tracker) info(user string, now time.Time) *protocol.PresenceInfo {
+	info := &protocol.PresenceInfo{
+		UserID: user,
+		Status: StatusOffline,
+	}
+
+	devices := make(map[string]bool)
+	var lastActive time.Time
+	for _, c := range t.conns {
+		if c.user != user {
+			continue
+		}
+		info.Connections++
+		if c.deviceID != "" && !devices[c.deviceID] {
+			devices[c.deviceID] = true
+			info.Devices = append(info.Devices, c.deviceID)
+		}
+		if c.lastActive.After(lastActive) {
+			lastActive = c.lastActive
+		}
+		if status := c.status(now, t.awayAfter); rank[status] > rank[info.Status] {
+			info.Status = status
+		}
+	}
+
+	sort.Strings(info.Devices)
+	if !lastActive.IsZero() {
+		info.LastActive = lastActive.Unix()
+	}
+	return info
+}
@@ -0,0 +1,143 @@
+package presence
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+)
+
+// newRecordingTracker returns a tracker recording the changes it reports
+// as "workspace/user:previous->status"
+func newRecordingTracker(awayAfter time.Duration) (*Tracker, *[]string) {
+	var changes []string
+	t := NewTracker(awayAfter, func(workspace string, info *protocol.PresenceInfo, previous string) {
+		changes = append(changes, workspace+"/"+info.UserID+":"+previous+"->"+info.Status)
+	})
+	return t, &changes
+}
+
+func TestAggregation(t *testing.T) {
+	tracker, changes := newRecordingTracker(0)
+	tracker.Connect("c1", "alice", "phone", "ws")
+	tracker.Connect("c2", "alice", "laptop", "ws")
+	tracker.Connect("c3", "alice", "laptop", "ws")
+
+	info, ok := tracker.Get("alice")
+	if !ok || info.Status != StatusOnline || info.Connections != 3 || strings.Join(info.Devices, ",") != "laptop,phone" {
+		t.Fatalf("presence = %+v, want online on laptop and phone", info)
+	}
+
+	// Busy on any connection makes the user busy
+	if err := tracker.Set("c1", StatusBusy); err != nil {
+		t.Fatal(err)
+	}
+	if status := tracker.Status("c2"); status != StatusOnline {
+		t.Errorf("other connection is %s, want online", status)
+	}
+	if tracker.IsActive("alice") {
+		t.Error("busy user counted as active")
+	}
+
+	// Away is below online
+	tracker.Set("c1", StatusAway)
+	if info, _ := tracker.Get("alice"); info.Status != StatusOnline {
+		t.Errorf("status = %s with an online connection, want online", info.Status)
+	}
+	if err := tracker.Set("c1", "sleeping"); err == nil {
+		t.Error("invalid status accepted")
+	}
+	if err := tracker.Set("nope", StatusBusy); err == nil {
+		t.Error("status of an unknown connection set")
+	}
+
+	// Connections without heartbeats are offline
+	tracker.SetAlive("c2", false)
+	tracker.SetAlive("c3", false)
+	if info, _ := tracker.Get("alice"); info.Status != StatusAway {
+		t.Errorf("status = %s with the laptop gone, want away", info.Status)
+	}
+
+	for _, c := range []string{"c1", "c2", "c3"} {
+		tracker.Disconnect(c)
+	}
+	if _, ok := tracker.Get("alice"); ok {
+		t.Error("user without connections still present")
+	}
+
+	want := "ws/alice:offline->online,ws/alice:online->busy,ws/alice:busy->online,ws/alice:online->away,ws/alice:away->offline"
+	if got := strings.Join(*changes, ","); got != want {
+		t.Errorf("changes = %s, want %s", got, want)
+	}
+}
+
+func TestAwayAfterInactivity(t *testing.T) {
+	tracker, changes := newRecordingTracker(50 * time.Millisecond)
+	tracker.Connect("c1", "bob", "phone", "ws")
+
+	time.Sleep(80 * time.Millisecond)
+	tracker.Sweep()
+	if info, _ := tracker.Get("bob"); info.Status != StatusAway {
+		t.Errorf("status after inactivity = %s, want away", info.Status)
+	}
+
+	// Activity brings the user back, an explicit status keeps them
+	tracker.Touch("c1")
+	if info, _ := tracker.Get("bob"); info.Status != StatusOnline {
+		t.Errorf("status after activity = %s, want online", info.Status)
+	}
+	tracker.Set("c1", StatusOnline)
+	time.Sleep(80 * time.Millisecond)
+	tracker.Sweep()
+	if info, _ := tracker.Get("bob"); info.Status != StatusOnline {
+		t.Errorf("status set online = %s after inactivity, want online", info.Status)
+	}
+
+	want := "ws/bob:offline->online,ws/bob:online->away,ws/bob:away->online"
+	if got := strings.Join(*changes, ","); got != want {
+		t.Errorf("changes = %s, want %s", got, want)
+	}
+}
+
+func TestWorkspaces(t *testing.T) {
+	tracker, changes := newRecordingTracker(0)
+	tracker.Connect("c1", "alice", "phone", "team")
+	tracker.Connect("c2", "alice", "laptop", "default")
+	tracker.Connect("c3", "bob", "phone", "default")
+
+	// users lists the users shown in a workspace
+	users := func(workspace string) string {
+		var users []string
+		for _, info := range tracker.ListIn(workspace) {
+			users = append(users, info.UserID)
+		}
+		return strings.Join(users, ",")
+	}
+	if got := users("team"); got != "alice" {
+		t.Errorf("team shows %s, want alice", got)
+	}
+	if got := users("default"); got != "alice,bob" {
+		t.Errorf("default shows %s, want alice,bob", got)
+	}
+	if _, ok := tracker.GetIn("bob", "team"); ok {
+		t.Error("bob shown in team without a connection there")
+	}
+	if info, ok := tracker.GetIn("alice", "team"); !ok || info.Connections != 2 {
+		t.Errorf("alice in team = %+v, want both connections", info)
+	}
+
+	// Changes reach the workspaces the user is in, and leaving one reads
+	// as going offline there
+	tracker.Set("c2", StatusBusy)
+	tracker.Move("c3", "team")
+	tracker.Disconnect("c1")
+
+	want := "team/alice:offline->online,default/alice:offline->online,default/bob:offline->online," +
+		"default/alice:online->busy,team/alice:online->busy," +
+		"default/bob:online->offline,team/bob:offline->online," +
+		"team/alice:busy->offline"
+	if got := strings.Join(*changes, ","); got != want {
+		t.Errorf("changes = %s, want %s", got, want)
+	}
+}
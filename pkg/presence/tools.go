@@ -0,0 +1,46 @@
+package presence
+
+import (
+	"context"
+
+	"github.com/openclaw/go-openclaw/internal/agent/tools"
+)
+
+// RegisterTools registers an agent tool reporting user presence, so the
+// agent can hold a notification until a user is active
+func RegisterTools(registry *tools.Registry, tracker *Tracker) error {
+	return registry.Register(&tools.Tool{
+		Name:        "presence_get",
+		Description: "Get whether users are online, away, busy or offline. Omit user_id to list every connected user.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"user_id": map[string]interface{}{"type": "string", "description": "User to check"},
+			},
+		},
+		Handler: func(ctx context.Context, params map[string]interface{}) (*tools.ToolResult, error) {
+			user, _ := params["user_id"].(string)
+			if user == "" {
+				return &tools.ToolResult{
+					Name:    "presence_get",
+					Success: true,
+					Data:    map[string]interface{}{"presence": tracker.List()},
+				}, nil
+			}
+
+			status, active := StatusOffline, false
+			if info, ok := tracker.Get(user); ok {
+				status, active = info.Status, info.Status == StatusOnline
+			}
+			return &tools.ToolResult{
+				Name:    "presence_get",
+				Success: true,
+				Data: map[string]interface{}{
+					"user_id": user,
+					"status":  status,
+					"active":  active,
+				},
+			}, nil
+		},
+	})
+}
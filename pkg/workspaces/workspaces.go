@@ -0,0 +1,343 @@
+package workspaces
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openclaw/go-openclaw/internal/agent"
+	"github.com/openclaw/go-openclaw/internal/models"
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/internal/storage"
+)
+
+// DefaultID is the workspace clients join when they do not name one. It
+// always exists and cannot be deleted.
+const DefaultID = "default"
+
+// collection is the storage collection holding workspaces
+const collection = "workspaces"
+
+var (
+	ErrUnknownWorkspace = errors.New("unknown workspace")
+	ErrWorkspaceExists  = errors.New("workspace already exists")
+	ErrDefaultWorkspace = errors.New("the default workspace cannot be deleted")
+	ErrNotMember        = errors.New("not a member of this workspace")
+)
+
+// validID matches workspace IDs: lower case letters, digits and dashes
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Manager keeps the workspaces of a gateway
+type Manager struct {
+	store      storage.Store
+	workspaces map[string]*models.Workspace // workspace ID -> workspace
+	mu         sync.RWMutex
+}
+
+// NewManager creates a workspace manager backed by store, loading the
+// saved workspaces and creating the default one on first use
+func NewManager(store storage.Store) (*Manager, error) {
+	m := &Manager{
+		store:      store,
+		workspaces: make(map[string]*models.Workspace),
+	}
+
+	records, err := store.List(collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspaces: %w", err)
+	}
+	for id, data := range records {
+		var ws models.Workspace
+		if err := json.Unmarshal(data, &ws); err != nil {
+			return nil, fmt.Errorf("failed to load workspace %s: %w", id, err)
+		}
+		m.workspaces[id] = &ws
+	}
+
+	if _, ok := m.workspaces[DefaultID]; !ok {
+		now := time.Now()
+		ws := &models.Workspace{
+			ID:        DefaultID,
+			Name:      "Default Workspace",
+			CreatedAt: now,
+			UpdatedAt: now,
+			Settings:  make(map[string]any),
+			Metadata:  make(map[string]string),
+		}
+		if err := store.Put(collection, ws.ID, ws); err != nil {
+			return nil, fmt.Errorf("failed to save default workspace: %w", err)
+		}
+		m.workspaces[DefaultID] = ws
+	}
+
+	return m, nil
+}
+
+// Create creates a workspace from a protocol.WorkspaceSpec in JSON. The ID
+// is derived from the name when the spec does not set one.
+func (m *Manager) Create(spec json.RawMessage) (*models.Workspace, error) {
+	var s protocol.WorkspaceSpec
+	if len(spec) > 0 {
+		if err := json.Unmarshal(spec, &s); err != nil {
+			return nil, fmt.Errorf("invalid workspace spec: %w", err)
+		}
+	}
+	if strings.TrimSpace(s.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	id := s.ID
+	if id == "" {
+		id = slug(s.Name)
+	}
+	if !validID.MatchString(id) {
+		return nil, fmt.Errorf("invalid workspace id %q", id)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.workspaces[id]; ok {
+		return nil, ErrWorkspaceExists
+	}
+
+	now := time.Now()
+	ws := &models.Workspace{
+		ID:        id,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Settings:  make(map[string]any),
+		Metadata:  make(map[string]string),
+	}
+	apply(ws, &s)
+
+	if err := m.store.Put(collection, ws.ID, ws); err != nil {
+		return nil, fmt.Errorf("failed to save workspace: %w", err)
+	}
+	m.workspaces[ws.ID] = ws
+
+	w := *ws
+	return &w, nil
+}
+
+// Update sets the fields present in a protocol.WorkspaceSpec in JSON,
+// leaving the others unchanged
+func (m *Manager) Update(id string, spec json.RawMessage) (*models.Workspace, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ws, ok := m.workspaces[id]
+	if !ok {
+		return nil, ErrUnknownWorkspace
+	}
+
+	// Decoding over the current values keeps the fields the patch omits
+	s := specOf(ws)
+	if err := json.Unmarshal(spec, &s); err != nil {
+		return nil, fmt.Errorf("invalid workspace spec: %w", err)
+	}
+	if s.ID != ws.ID {
+		return nil, fmt.Errorf("workspace id cannot be changed")
+	}
+	if strings.TrimSpace(s.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	updated := *ws
+	apply(&updated, &s)
+	updated.UpdatedAt = time.Now()
+
+	if err := m.store.Put(collection, updated.ID, &updated); err != nil {
+		return nil, fmt.Errorf("failed to save workspace: %w", err)
+	}
+	m.workspaces[id] = &updated
+
+	w := updated
+	return &w, nil
+}
+
+// Delete removes a workspace
+func (m *Manager) Delete(id string) error {
+	if id == DefaultID {
+		return ErrDefaultWorkspace
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.workspaces[id]; !ok {
+		return ErrUnknownWorkspace
+	}
+	if err := m.store.Delete(collection, id); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	delete(m.workspaces, id)
+	return nil
+}
+
+// Get returns a copy of a workspace
+func (m *Manager) Get(id string) (*models.Workspace, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ws, ok := m.workspaces[id]
+	if !ok {
+		return nil, false
+	}
+	w := *ws
+	return &w, true
+}
+
+// List returns copies of all workspaces ordered by ID
+func (m *Manager) List() []*models.Workspace {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := make([]*models.Workspace, 0, len(m.workspaces))
+	for _, ws := range m.workspaces {
+		w := *ws
+		list = append(list, &w)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// ForChannel returns the workspace bound to a channel target. A binding
+// naming the target ("telegram:12345") wins over one naming the whole
+// channel ("telegram"); unbound targets belong to the default workspace.
+// Targets within a chat, as "12345:678" for a user in a group, follow the
+// binding of the chat.
+func (m *Manager) ForChannel(channel, target string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	exact := channel + ":" + target
+	chat := exact
+	if i := strings.IndexByte(target, ':'); i >= 0 {
+		chat = channel + ":" + target[:i]
+	}
+	match, chatMatch := DefaultID, ""
+	for _, ws := range m.workspaces {
+		for _, binding := range ws.Channels {
+			switch binding {
+			case exact:
+				return ws.ID
+			case chat:
+				chatMatch = ws.ID
+			case channel:
+				match = ws.ID
+			}
+		}
+	}
+	if chatMatch != "" {
+		return chatMatch
+	}
+	return match
+}
+
+// CanJoin reports whether a user may join a workspace. Workspaces
+// without members are open to every client; admins may join any. Members
+// are users authenticated by their token, so clients without one may only
+// join open workspaces.
+func CanJoin(ws *models.Workspace, userID string, admin bool) bool {
+	if admin || len(ws.Members) == 0 {
+		return true
+	}
+	if userID == "" {
+		return false
+	}
+	for _, member := range ws.Members {
+		if member == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Scope returns the agent scope of a workspace
+func Scope(ws *models.Workspace) *agent.Scope {
+	return &agent.Scope{
+		Workspace:         ws.ID,
+		SystemPrompt:      ws.SystemPrompt,
+		Tools:             ws.Tools,
+		MaxSessions:       ws.Quotas.MaxSessions,
+		MaxMessagesPerDay: ws.Quotas.MaxMessagesPerDay,
+	}
+}
+
+// Info returns the protocol representation of a workspace
+func Info(ws *models.Workspace, clients int) protocol.WorkspaceInfo {
+	return protocol.WorkspaceInfo{
+		ID:           ws.ID,
+		Name:         ws.Name,
+		Description:  ws.Description,
+		SystemPrompt: ws.SystemPrompt,
+		Tools:        ws.Tools,
+		Channels:     ws.Channels,
+		Members:      ws.Members,
+		Quotas: protocol.WorkspaceQuotas{
+			MaxClients:        ws.Quotas.MaxClients,
+			MaxSessions:       ws.Quotas.MaxSessions,
+			MaxMessagesPerDay: ws.Quotas.MaxMessagesPerDay,
+		},
+		Clients:   clients,
+		CreatedAt: ws.CreatedAt.Unix(),
+		UpdatedAt: ws.UpdatedAt.Unix(),
+	}
+}
+
+// specOf returns the editable fields of a workspace
+func specOf(ws *models.Workspace) protocol.WorkspaceSpec {
+	return protocol.WorkspaceSpec{
+		ID:           ws.ID,
+		Name:         ws.Name,
+		Description:  ws.Description,
+		SystemPrompt: ws.SystemPrompt,
+		Tools:        ws.Tools,
+		Channels:     ws.Channels,
+		Members:      ws.Members,
+		Quotas: protocol.WorkspaceQuotas{
+			MaxClients:        ws.Quotas.MaxClients,
+			MaxSessions:       ws.Quotas.MaxSessions,
+			MaxMessagesPerDay: ws.Quotas.MaxMessagesPerDay,
+		},
+	}
+}
+
+// apply copies the editable fields of a spec onto a workspace
+func apply(ws *models.Workspace, s *protocol.WorkspaceSpec) {
+	ws.Name = strings.TrimSpace(s.Name)
+	ws.Description = s.Description
+	ws.SystemPrompt = s.SystemPrompt
+	ws.Tools = s.Tools
+	ws.Channels = s.Channels
+	ws.Members = s.Members
+	ws.Quotas = models.WorkspaceQuotas{
+		MaxClients:        s.Quotas.MaxClients,
+		MaxSessions:       s.Quotas.MaxSessions,
+		MaxMessagesPerDay: s.Quotas.MaxMessagesPerDay,
+	}
+}
+
+// slug derives a workspace ID from a name: "Team Blue" -> "team-blue"
+func slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
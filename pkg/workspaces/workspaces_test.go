@@ -0,0 +1,35 @@
+package workspaces
+
+import (
+	"testing"
+
+	"github.com/openclaw/go-openclaw/internal/models"
+)
+
+func TestCanJoin(t *testing.T) {
+	open := &models.Workspace{ID: "open"}
+	closed := &models.Workspace{ID: "closed", Members: []string{"alice"}}
+
+	tests := []struct {
+		name   string
+		ws     *models.Workspace
+		userID string
+		admin  bool
+		want   bool
+	}{
+		{"open workspace", open, "bob", false, true},
+		{"member", closed, "alice", false, true},
+		{"non-member", closed, "bob", false, false},
+		{"admin", closed, "bob", true, true},
+		{"no user", open, "", false, true},
+		{"no user in a closed workspace", closed, "", false, false},
+		{"no user as admin", closed, "", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanJoin(tt.ws, tt.userID, tt.admin); got != tt.want {
+				t.Errorf("CanJoin(%s, %q, %v) = %v, want %v", tt.ws.ID, tt.userID, tt.admin, got, tt.want)
+			}
+		})
+	}
+}
@@ -27,39 +27,45 @@ echo ""
 
 # 3. 编译项目
 echo "3️⃣  编译项目..."
-go build -o bin/gateway cmd/gateway/main.go
+go build -o bin/openclaw ./cmd/gateway
 echo "   ✅ 编译成功"
 echo ""
 
-# 4. 检查二进制文件
-echo "4️⃣  检查二进制文件..."
-if [ -f "bin/gateway" ]; then
+# 4. 运行测试
+echo "4️⃣  运行测试..."
+go test ./...
+echo "   ✅ 测试通过"
+echo ""
+
+# 5. 检查二进制文件
+echo "5️⃣  检查二进制文件..."
+if [ -f "bin/openclaw" ]; then
     echo "   ✅ 二进制文件已创建"
-    ls -lh bin/gateway
+    ls -lh bin/openclaw
 else
     echo "   ❌ 二进制文件不存在"
     exit 1
 fi
 echo ""
 
-# 5. 停止现有进程
-echo "5️⃣  清理现有进程..."
-pkill -f "bin/gateway" 2>/dev/null || true
+# 6. 停止现有进程
+echo "6️⃣  清理现有进程..."
+pkill -f "bin/openclaw" 2>/dev/null || true
 sleep 1
 echo "   ✅ 清理完成"
 echo ""
 
-# 6. 启动 Gateway
-echo "6️⃣  启动 Gateway..."
-./bin/gateway &
+# 7. 启动 Gateway
+echo "7️⃣  启动 Gateway..."
+./bin/openclaw serve &
 GATEWAY_PID=$!
 echo "   Gateway PID: $GATEWAY_PID"
 sleep 2
 echo "   ✅ Gateway 启动成功"
 echo ""
 
-# 7. 测试健康检查
-echo "7️⃣  测试健康检查..."
+# 8. 测试健康检查
+echo "8️⃣  测试健康检查..."
 HEALTH_RESPONSE=$(curl -s http://localhost:18790/health)
 if [ "$HEALTH_RESPONSE" = '{"status":"ok"}' ]; then
     echo "   ✅ 健康检查通过"
@@ -70,8 +76,8 @@ else
 fi
 echo ""
 
-# 8. 清理
-echo "8️⃣  清理进程..."
+# 9. 清理
+echo "9️⃣  清理进程..."
 kill $GATEWAY_PID
 wait $GATEWAY_PID 2>/dev/null || true
 echo "   ✅ 清理完成"
@@ -84,6 +90,6 @@ echo ""
 echo "项目验证成功！"
 echo ""
 echo "快速开始:"
-echo "  ./bin/gateway        # 启动 Gateway"
+echo "  ./bin/openclaw serve  # 启动 Gateway"
 echo "  curl http://localhost:18790/health  # 健康检查"
 echo ""
@@ -0,0 +1,197 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiTimeout bounds a single Web API call
+const apiTimeout = 30 * time.Second
+
+// apiClient calls methods of the Slack Web API with the bot token
+type apiClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// newAPIClient returns a client of the Web API at baseURL
+func newAPIClient(baseURL, token string) *apiClient {
+	return &apiClient{
+		baseURL: baseURL,
+		token:   token,
+		http:    &http.Client{Timeout: apiTimeout},
+	}
+}
+
+// apiError is an error returned by a Web API method
+type apiError struct {
+	Method     string
+	Code       string        // as not_in_channel or ratelimited
+	RetryAfter time.Duration // set when rate limited
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("slack %s: %s", e.Method, e.Code)
+}
+
+// apiResponse is the envelope of every Web API response
+type apiResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// call posts params to a Web API method and decodes the response into
+// out, if not nil. Responses with ok false are returned as *apiError.
+func (c *apiClient) call(ctx context.Context, method string, params url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+method, strings.NewReader(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("slack %s: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		wait := time.Second
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+		return &apiError{Method: method, Code: "ratelimited", RetryAfter: wait}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack %s: unexpected status %s", method, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("slack %s: %w", method, err)
+	}
+	var envelope apiResponse
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("slack %s: invalid response: %w", method, err)
+	}
+	if !envelope.OK {
+		return &apiError{Method: method, Code: envelope.Error}
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("slack %s: invalid response: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// authIdentity is the bot user the token belongs to
+type authIdentity struct {
+	UserID string `json:"user_id"`
+	User   string `json:"user"`
+	BotID  string `json:"bot_id"`
+	TeamID string `json:"team_id"`
+	Team   string `json:"team"`
+}
+
+// authTest returns the identity of the bot token
+func (c *apiClient) authTest(ctx context.Context) (*authIdentity, error) {
+	var identity authIdentity
+	if err := c.call(ctx, "auth.test", url.Values{}, &identity); err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// postMessage posts mrkdwn text to a channel, in a thread when threadTS
+// is set, and returns the timestamp identifying the message
+func (c *apiClient) postMessage(ctx context.Context, channel, threadTS, text string) (string, error) {
+	params := url.Values{
+		"channel": {channel},
+		"text":    {text},
+		"mrkdwn":  {"true"},
+	}
+	if threadTS != "" {
+		params.Set("thread_ts", threadTS)
+	}
+	var sent struct {
+		TS string `json:"ts"`
+	}
+	if err := c.call(ctx, "chat.postMessage", params, &sent); err != nil {
+		return "", err
+	}
+	return sent.TS, nil
+}
+
+// updateMessage replaces the text of a message
+func (c *apiClient) updateMessage(ctx context.Context, channel, ts, text string) error {
+	return c.call(ctx, "chat.update", url.Values{
+		"channel": {channel},
+		"ts":      {ts},
+		"text":    {text},
+	}, nil)
+}
+
+// deleteMessage deletes a message posted by the bot
+func (c *apiClient) deleteMessage(ctx context.Context, channel, ts string) error {
+	return c.call(ctx, "chat.delete", url.Values{
+		"channel": {channel},
+		"ts":      {ts},
+	}, nil)
+}
+
+// userProfile is the part of a Slack user shown in messages
+type userProfile struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	IsBot   bool   `json:"is_bot"`
+	Profile struct {
+		DisplayName string `json:"display_name"`
+		RealName    string `json:"real_name"`
+	} `json:"profile"`
+}
+
+// String returns the name the user goes by
+func (u *userProfile) String() string {
+	switch {
+	case u.Profile.DisplayName != "":
+		return u.Profile.DisplayName
+	case u.Profile.RealName != "":
+		return u.Profile.RealName
+	case u.Name != "":
+		return u.Name
+	}
+	return u.ID
+}
+
+// userInfo returns the profile of a user
+func (c *apiClient) userInfo(ctx context.Context, userID string) (*userProfile, error) {
+	var info struct {
+		User userProfile `json:"user"`
+	}
+	if err := c.call(ctx, "users.info", url.Values{"user": {userID}}, &info); err != nil {
+		return nil, err
+	}
+	return &info.User, nil
+}
+
+// retryAfter returns how long Slack asked to wait before the next
+// request, zero if err is not a rate limit error
+func retryAfter(err error) time.Duration {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,207 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// Bot represents a Slack app answering in channels and direct messages
+type Bot struct {
+	config         *Config
+	api            *apiClient
+	handler        *Handler
+	messageHandler channels.MessageHandler
+	identity       *authIdentity      // bot user of the token, known once started
+	recent         channels.RecentIDs // event IDs received, as Slack retries deliveries
+	running        bool
+	mu             sync.RWMutex
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+// NewBot creates a new Slack bot
+func NewBot(config *Config) (*Bot, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	bot := &Bot{
+		config: config,
+		api:    newAPIClient(config.apiURL(), config.BotToken),
+	}
+	bot.handler = NewHandler(bot)
+
+	return bot, nil
+}
+
+// Name returns channel name
+func (b *Bot) Name() string {
+	return "slack"
+}
+
+// Start checks the bot token and starts handling events
+func (b *Bot) Start(ctx context.Context) error {
+	b.mu.Lock()
+	if b.running {
+		b.mu.Unlock()
+		return fmt.Errorf("bot is already running")
+	}
+	b.mu.Unlock()
+
+	log.Printf("🤖 Starting Slack bot...")
+
+	identity, err := b.api.authTest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get bot info: %w", err)
+	}
+
+	log.Printf("✅ Slack Bot initialized: @%s (ID: %s) in %s", identity.User, identity.UserID, identity.Team)
+
+	b.mu.Lock()
+	b.identity = identity
+	b.ctx, b.cancel = context.WithCancel(ctx)
+	b.running = true
+	b.mu.Unlock()
+
+	b.handler.Start(b.ctx)
+
+	log.Printf("🚀 Slack bot started, events served at %s", b.WebhookPath())
+
+	return nil
+}
+
+// Stop stops Slack bot
+func (b *Bot) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.running {
+		b.mu.Unlock()
+		return nil
+	}
+	b.running = false
+	b.mu.Unlock()
+
+	log.Println("🛑 Stopping Slack bot...")
+
+	b.cancel()
+
+	// Wait for the replies being generated
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("✅ Slack bot stopped")
+		return nil
+	case <-time.After(10 * time.Second):
+		log.Println("⚠️  Slack bot stop timeout")
+		return fmt.Errorf("timeout waiting for bot to stop")
+	}
+}
+
+// Send sends a message to specified target, a channel ID optionally
+// followed by the timestamp of the thread to reply in
+func (b *Bot) Send(ctx context.Context, target string, content string, options map[string]interface{}) error {
+	channel, threadTS, err := parseTarget(target)
+	if err != nil {
+		return err
+	}
+	if ts, ok := options["thread_ts"].(string); ok && ts != "" {
+		threadTS = ts
+	}
+
+	_, err = b.SendMessage(ctx, channel, threadTS, content)
+	return err
+}
+
+// SendMessage sends model Markdown to a Slack channel, in a thread when
+// threadTS is set, converted to mrkdwn. Long text is split into several
+// messages; the timestamp of the last one is returned.
+func (b *Bot) SendMessage(ctx context.Context, channel, threadTS, text string) (string, error) {
+	if text == "" {
+		return "", fmt.Errorf("message text cannot be empty")
+	}
+
+	ts := ""
+	for _, part := range formatMessage(text, maxMessageLength) {
+		var err error
+		if ts, err = b.api.postMessage(ctx, channel, threadTS, part); err != nil {
+			return "", fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+
+	if b.config.Debug {
+		log.Printf("📤 Sent message to %s: %s", channel, truncateString(text, 50))
+	}
+
+	return ts, nil
+}
+
+// SetMessageHandler sets handler for incoming messages
+func (b *Bot) SetMessageHandler(handler channels.MessageHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messageHandler = handler
+}
+
+// GetMessageHandler returns current message handler
+func (b *Bot) GetMessageHandler() channels.MessageHandler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.messageHandler
+}
+
+// IsRunning returns true if bot is running
+func (b *Bot) IsRunning() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.running
+}
+
+// Status returns current status of bot
+func (b *Bot) Status() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.running {
+		return "running"
+	}
+	return "stopped"
+}
+
+// botUserID returns the user ID of the bot, empty before it is started
+func (b *Bot) botUserID() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.identity == nil {
+		return ""
+	}
+	return b.identity.UserID
+}
+
+// parseTarget splits a target into the channel ID and the timestamp of
+// the thread, empty for the channel itself
+func parseTarget(target string) (channel, threadTS string, err error) {
+	channel, threadTS, _ = strings.Cut(target, ":")
+	if channel == "" {
+		return "", "", fmt.Errorf("invalid target %q: channel ID is required", target)
+	}
+	return channel, threadTS, nil
+}
+
+// truncateString truncates a string to max characters
+func truncateString(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
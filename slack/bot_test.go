@@ -0,0 +1,62 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/openclaw/go-openclaw/pkg/channels/channeltest"
+)
+
+// newWebStandIn returns a Web API stand-in accepting chat.postMessage
+func newWebStandIn(t *testing.T) *channeltest.StandIn {
+	api := channeltest.New(t)
+	api.Handle("", func(r *channeltest.Request) (int, interface{}) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer xoxb-test":
+			return http.StatusOK, map[string]interface{}{"ok": false, "error": "invalid_auth"}
+		case r.Path != "/chat.postMessage":
+			return http.StatusOK, map[string]interface{}{"ok": false, "error": "unknown_method"}
+		}
+		ts := len(api.Requests("/chat.postMessage"))
+		return http.StatusOK, map[string]interface{}{"ok": true, "ts": strings.Repeat("1", ts)}
+	})
+	return api
+}
+
+func TestSendMessage(t *testing.T) {
+	api := newWebStandIn(t)
+	b, err := NewBot(&Config{BotToken: "xoxb-test", SigningSecret: "secret", APIURL: api.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	long := strings.Repeat("word ", maxMessageLength/5) + "\n\n**end**"
+	ts, err := b.SendMessage(context.Background(), "C1", "100.1", long)
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	posts := api.Requests("/chat.postMessage")
+	if len(posts) != 2 {
+		t.Fatalf("posted %d messages, want 2", len(posts))
+	}
+	for _, post := range posts {
+		if post.Form.Get("channel") != "C1" || post.Form.Get("thread_ts") != "100.1" {
+			t.Errorf("posted to %s thread %s, want C1 thread 100.1", post.Form.Get("channel"), post.Form.Get("thread_ts"))
+		}
+	}
+	if last := posts[1].Form.Get("text"); last != "*end*" {
+		t.Errorf("last message = %q, want the bold text in mrkdwn", last)
+	}
+	if ts != "11" {
+		t.Errorf("ts = %q, want the one of the last message", ts)
+	}
+
+	// API errors are returned
+	b.api.token = "xoxb-revoked"
+	if _, err := b.SendMessage(context.Background(), "C1", "", "hi"); err == nil || !strings.Contains(err.Error(), "invalid_auth") {
+		t.Errorf("SendMessage with a revoked token: %v, want invalid_auth", err)
+	}
+}
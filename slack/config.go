@@ -0,0 +1,159 @@
+package slack
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Config holds the configuration for the Slack channel
+type Config struct {
+	// BotToken is the bot user OAuth token of the Slack app (xoxb-...)
+	BotToken string `json:"bot_token"`
+
+	// SigningSecret verifies that event requests come from Slack
+	SigningSecret string `json:"signing_secret"`
+
+	// APIURL is the base URL of the Web API (default: https://slack.com/api),
+	// for a stand-in in tests
+	APIURL string `json:"api_url,omitempty"`
+
+	// WebhookPath is the path of the Events API request URL on the gateway
+	// HTTP server (default: /slack/events)
+	WebhookPath string `json:"webhook_path,omitempty"`
+
+	// EditInterval is the minimum time between edits of a streamed reply,
+	// in milliseconds (default: 1000, chat.update allows about one per second)
+	EditInterval int `json:"edit_interval_ms,omitempty"`
+
+	// AllowedUsers is a list of allowed user IDs (empty means all users are allowed)
+	AllowedUsers []string `json:"allowed_users,omitempty"`
+
+	// AllowedChannels is a list of allowed channel IDs (empty means all
+	// channels are allowed); direct messages are not restricted by it
+	AllowedChannels []string `json:"allowed_channels,omitempty"`
+
+	// Debug enables debug logging
+	Debug bool `json:"debug,omitempty"`
+}
+
+const (
+	// defaultAPIURL is the base URL of the Web API
+	defaultAPIURL = "https://slack.com/api"
+
+	// defaultWebhookPath is the path events are posted to by default
+	defaultWebhookPath = "/slack/events"
+
+	// defaultEditInterval is the default time between edits of a streamed
+	// reply, in milliseconds
+	defaultEditInterval = 1000
+)
+
+// LoadConfig loads configuration from environment variables or defaults
+func LoadConfig() (*Config, error) {
+	cfg := &Config{
+		BotToken:        os.Getenv("SLACK_BOT_TOKEN"),
+		SigningSecret:   os.Getenv("SLACK_SIGNING_SECRET"),
+		APIURL:          os.Getenv("SLACK_API_URL"),
+		Debug:           os.Getenv("SLACK_DEBUG") == "true",
+		EditInterval:    defaultEditInterval,
+		AllowedUsers:    make([]string, 0),
+		AllowedChannels: make([]string, 0),
+	}
+
+	if cfg.BotToken == "" {
+		return nil, fmt.Errorf("SLACK_BOT_TOKEN environment variable is required")
+	}
+	if cfg.SigningSecret == "" {
+		return nil, fmt.Errorf("SLACK_SIGNING_SECRET environment variable is required")
+	}
+
+	return cfg, nil
+}
+
+// LoadConfigFromMap loads configuration from a map
+func LoadConfigFromMap(configMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{
+		EditInterval:    defaultEditInterval,
+		AllowedUsers:    make([]string, 0),
+		AllowedChannels: make([]string, 0),
+	}
+
+	if botToken, ok := configMap["bot_token"].(string); ok {
+		cfg.BotToken = botToken
+	} else {
+		return nil, fmt.Errorf("bot_token is required in config")
+	}
+
+	if signingSecret, ok := configMap["signing_secret"].(string); ok {
+		cfg.SigningSecret = signingSecret
+	} else {
+		return nil, fmt.Errorf("signing_secret is required in config")
+	}
+
+	if apiURL, ok := configMap["api_url"].(string); ok {
+		cfg.APIURL = apiURL
+	}
+
+	if webhookPath, ok := configMap["webhook_path"].(string); ok {
+		cfg.WebhookPath = webhookPath
+	}
+
+	if editInterval, ok := configMap["edit_interval_ms"].(float64); ok {
+		cfg.EditInterval = int(editInterval)
+	}
+
+	if allowedUsers, ok := configMap["allowed_users"].([]interface{}); ok {
+		for _, uid := range allowedUsers {
+			if id, ok := uid.(string); ok {
+				cfg.AllowedUsers = append(cfg.AllowedUsers, id)
+			}
+		}
+	}
+
+	if allowedChannels, ok := configMap["allowed_channels"].([]interface{}); ok {
+		for _, cid := range allowedChannels {
+			if id, ok := cid.(string); ok {
+				cfg.AllowedChannels = append(cfg.AllowedChannels, id)
+			}
+		}
+	}
+
+	if debug, ok := configMap["debug"].(bool); ok {
+		cfg.Debug = debug
+	}
+
+	return cfg, nil
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	if c.BotToken == "" {
+		return fmt.Errorf("bot_token is required")
+	}
+
+	if c.SigningSecret == "" {
+		return fmt.Errorf("signing_secret is required")
+	}
+
+	if c.WebhookPath != "" && !strings.HasPrefix(c.WebhookPath, "/") {
+		return fmt.Errorf("webhook_path must start with /, not %q", c.WebhookPath)
+	}
+
+	if c.APIURL != "" {
+		if u, err := url.Parse(c.APIURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("api_url must be an absolute URL, not %q", c.APIURL)
+		}
+	}
+
+	return nil
+}
+
+// apiURL returns the base URL of the Web API, without a trailing slash
+func (c *Config) apiURL() string {
+	if c.APIURL == "" {
+		return defaultAPIURL
+	}
+	return strings.TrimRight(c.APIURL, "/")
+}
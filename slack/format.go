@@ -0,0 +1,213 @@
+package slack
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxMessageLength is the longest text posted in one message. Slack
+// truncates messages far longer, but recommends at most 4000 characters.
+const maxMessageLength = 4000
+
+var (
+	headingPattern = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*$`)
+	bulletPattern  = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+	linkPattern    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldPattern    = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	italicPattern  = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*`)
+	strikePattern  = regexp.MustCompile(`~~(.+?)~~`)
+)
+
+// mrkdwnEscaper escapes the characters Slack reads as markup
+var mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// formatMessage converts model Markdown to Slack mrkdwn and splits it
+// into messages of at most max characters, between paragraphs and code
+// blocks where possible. Code blocks split across messages are closed
+// and opened again.
+func formatMessage(text string, max int) []string {
+	var parts []string
+	var current strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			parts = append(parts, s)
+		}
+		current.Reset()
+	}
+	add := func(block string) {
+		if current.Len() > 0 && runeLen(current.String())+2+runeLen(block) > max {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(block)
+	}
+
+	for _, block := range splitBlocks(text) {
+		rendered := renderBlock(block)
+		if runeLen(rendered) <= max {
+			add(rendered)
+			continue
+		}
+		for _, piece := range splitBlock(block, max) {
+			add(piece)
+		}
+	}
+	flush()
+
+	if len(parts) == 0 {
+		return []string{mrkdwnEscaper.Replace(text)}
+	}
+	return parts
+}
+
+// splitBlocks splits Markdown into paragraphs and fenced code blocks
+func splitBlocks(text string) []string {
+	var blocks []string
+	var current []string
+	inCode := false
+	flush := func() {
+		if len(current) > 0 {
+			blocks = append(blocks, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		isFence := strings.HasPrefix(strings.TrimSpace(line), "```")
+		switch {
+		case isFence && !inCode:
+			flush()
+			current = append(current, line)
+			inCode = true
+		case isFence && inCode:
+			current = append(current, line)
+			flush()
+			inCode = false
+		case inCode:
+			current = append(current, line)
+		case strings.TrimSpace(line) == "":
+			flush()
+		default:
+			current = append(current, line)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// isCodeBlock reports whether a block is a fenced code block
+func isCodeBlock(block string) bool {
+	return strings.HasPrefix(strings.TrimSpace(block), "```")
+}
+
+// renderBlock converts a paragraph or code block to mrkdwn
+func renderBlock(block string) string {
+	if isCodeBlock(block) {
+		lines := strings.Split(block, "\n")
+		body := lines[1:]
+		if len(body) > 0 && strings.HasPrefix(strings.TrimSpace(body[len(body)-1]), "```") {
+			body = body[:len(body)-1]
+		}
+		// Slack has no syntax highlighting, the language is dropped
+		return "```\n" + mrkdwnEscaper.Replace(strings.Join(body, "\n")) + "\n```"
+	}
+
+	lines := strings.Split(block, "\n")
+	for i, line := range lines {
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			lines[i] = "*" + renderInline(strings.Trim(m[1], "*")) + "*"
+			continue
+		}
+		line = bulletPattern.ReplaceAllString(line, "$1• ")
+		lines[i] = renderInline(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderInline converts the emphasis, links and code spans of a line
+func renderInline(line string) string {
+	segments := strings.Split(line, "`")
+	for i, segment := range segments {
+		segment = mrkdwnEscaper.Replace(segment)
+		// Odd segments are code spans, closed when followed by another
+		if i%2 == 1 && i < len(segments)-1 {
+			segments[i] = segment
+			continue
+		}
+		segment = linkPattern.ReplaceAllString(segment, "<$2|$1>")
+		segment = boldPattern.ReplaceAllStringFunc(segment, func(s string) string {
+			return "\x00" + s[2:len(s)-2] + "\x00"
+		})
+		segment = italicPattern.ReplaceAllString(segment, "${1}_${2}_")
+		segment = strikePattern.ReplaceAllString(segment, "~$1~")
+		segments[i] = strings.ReplaceAll(segment, "\x00", "*")
+	}
+	return strings.Join(segments, "`")
+}
+
+// splitBlock renders a block too long for one message in pieces of at
+// most max characters, between lines where possible
+func splitBlock(block string, max int) []string {
+	code := isCodeBlock(block)
+	render := renderBlock
+	lines := strings.Split(block, "\n")
+	if code {
+		// Each piece is a code block of its own
+		lines = lines[1:]
+		if len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[len(lines)-1]), "```") {
+			lines = lines[:len(lines)-1]
+		}
+		render = func(s string) string { return renderBlock("```\n" + s + "\n```") }
+	}
+
+	var pieces []string
+	var current []string
+	for _, line := range lines {
+		if len(current) > 0 && runeLen(render(strings.Join(append(current, line), "\n"))) > max {
+			pieces = append(pieces, render(strings.Join(current, "\n")))
+			current = nil
+		}
+		// A line too long by itself is cut
+		for len(current) == 0 && runeLen(render(line)) > max {
+			cut := cutToFit(line, func(s string) bool { return runeLen(render(s)) <= max })
+			if i := strings.LastIndexByte(line[:cut], ' '); !code && i > 0 {
+				cut = i + 1
+			}
+			pieces = append(pieces, render(line[:cut]))
+			line = line[cut:]
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		pieces = append(pieces, render(strings.Join(current, "\n")))
+	}
+	return pieces
+}
+
+// cutToFit returns the length in bytes of the longest prefix of s that
+// fits, at least one rune
+func cutToFit(s string, fits func(string) bool) int {
+	// ends are the offsets where a prefix of 1, 2, ... runes ends
+	ends := make([]int, 0, len(s))
+	for i := range s {
+		if i > 0 {
+			ends = append(ends, i)
+		}
+	}
+	ends = append(ends, len(s))
+
+	n := sort.Search(len(ends), func(k int) bool { return !fits(s[:ends[k]]) })
+	if n == 0 {
+		return ends[0]
+	}
+	return ends[n-1]
+}
+
+// runeLen returns the number of characters of s
+func runeLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,289 @@
+package slack
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// event is a message event of the Events API: app_mention in channels,
+// message in direct messages
+type event struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype,omitempty"`
+	User        string `json:"user,omitempty"`
+	BotID       string `json:"bot_id,omitempty"`
+	Text        string `json:"text"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type,omitempty"` // im for direct messages
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts,omitempty"`
+}
+
+// isDirect reports whether the event is a direct message to the bot
+func (e *event) isDirect() bool {
+	return e.ChannelType == "im"
+}
+
+// incomingEvent is an event waiting to be answered
+type incomingEvent struct {
+	teamID string
+	event  *event
+}
+
+// Handler handles incoming Slack events
+type Handler struct {
+	bot      *Bot
+	incoming chan *incomingEvent
+	names    map[string]string // user names by ID
+	mu       sync.RWMutex
+}
+
+// NewHandler creates a new event handler
+func NewHandler(bot *Bot) *Handler {
+	return &Handler{
+		bot:      bot,
+		incoming: make(chan *incomingEvent, 100),
+		names:    make(map[string]string),
+	}
+}
+
+// Start starts the event handler
+func (h *Handler) Start(ctx context.Context) {
+	h.bot.wg.Add(1)
+	go func() {
+		defer h.bot.wg.Done()
+		h.processEvents(ctx)
+	}()
+}
+
+// HandleEvent queues an event of the Events API. Only mentions of the
+// bot and direct messages from users are answered; messages of bots,
+// the bot itself included, and edits are dropped.
+func (h *Handler) HandleEvent(teamID string, ev *event) {
+	switch {
+	case ev.Type == "app_mention":
+	case ev.Type == "message" && ev.isDirect() && ev.Subtype == "":
+	default:
+		if h.bot.config.Debug {
+			log.Printf("ℹ️  Received Slack event type: %s %s", ev.Type, ev.Subtype)
+		}
+		return
+	}
+	if ev.BotID != "" || ev.User == "" || ev.User == h.bot.botUserID() {
+		return
+	}
+
+	select {
+	case h.incoming <- &incomingEvent{teamID: teamID, event: ev}:
+	default:
+		log.Printf("⚠️  Incoming event queue full, dropping message from %s", ev.User)
+	}
+}
+
+// processEvents answers queued events in order
+func (h *Handler) processEvents(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case in := <-h.incoming:
+			if err := h.handleEvent(ctx, in); err != nil {
+				log.Printf("❌ Error handling message: %v", err)
+			}
+		}
+	}
+}
+
+// handleEvent passes a message to the message handler
+func (h *Handler) handleEvent(ctx context.Context, in *incomingEvent) error {
+	ev := in.event
+
+	// Check if user is allowed
+	if !isAllowed(h.bot.config.AllowedUsers, ev.User) {
+		if h.bot.config.Debug {
+			log.Printf("🚫 Message from user %s (not in allowed list)", ev.User)
+		}
+		return nil
+	}
+
+	// Check if channel is allowed
+	if !ev.isDirect() && !isAllowed(h.bot.config.AllowedChannels, ev.Channel) {
+		if h.bot.config.Debug {
+			log.Printf("🚫 Message from channel %s (not in allowed list)", ev.Channel)
+		}
+		return nil
+	}
+
+	channelMsg := h.toChannelMessage(ctx, in)
+	if strings.TrimSpace(channelMsg.Content) == "" {
+		return nil
+	}
+
+	// Call registered message handler
+	handler := h.bot.GetMessageHandler()
+	if handler != nil {
+		if err := handler(ctx, channelMsg); err != nil {
+			log.Printf("❌ Error in message handler: %v", err)
+			// Send error message to user, in the thread of the message
+			_, threadTS, _ := parseTarget(channelMsg.To)
+			_, _ = h.bot.SendMessage(ctx, ev.Channel, threadTS, "Sorry, I encountered an error processing your message.")
+		}
+	}
+
+	return nil
+}
+
+// toChannelMessage converts an event to a channel message. Mentions in
+// channels are answered in the thread they start or belong to, which
+// keys the agent session; direct messages share one session unless
+// sent in a thread.
+func (h *Handler) toChannelMessage(ctx context.Context, in *incomingEvent) *channels.Message {
+	ev := in.event
+
+	threadTS := ev.ThreadTS
+	if threadTS == "" && !ev.isDirect() {
+		threadTS = ev.TS
+	}
+	target := ev.Channel
+	if threadTS != "" {
+		target += ":" + threadTS
+	}
+
+	replyTo := ""
+	if ev.ThreadTS != "" && ev.ThreadTS != ev.TS {
+		replyTo = ev.ThreadTS
+	}
+
+	groupID := ""
+	if !ev.isDirect() {
+		groupID = ev.Channel
+	}
+
+	metadata := make(map[string]interface{})
+	metadata["slack_channel"] = ev.Channel
+	metadata["slack_ts"] = ev.TS
+	if threadTS != "" {
+		metadata["slack_thread_ts"] = threadTS
+	}
+	if in.teamID != "" {
+		metadata["slack_team_id"] = in.teamID
+	}
+
+	return &channels.Message{
+		ID:        ev.TS,
+		Channel:   h.bot.Name(),
+		From:      ev.User,
+		FromName:  h.userName(ctx, ev.User),
+		To:        target,
+		IsGroup:   !ev.isDirect(),
+		GroupID:   groupID,
+		Content:   h.plainText(ctx, ev.Text),
+		Type:      channels.MessageTypeText,
+		Timestamp: parseTS(ev.TS),
+		ReplyTo:   replyTo,
+		Metadata:  metadata,
+	}
+}
+
+// entityPattern matches the entities of Slack message text, as <@U123>,
+// <#C123|general> or <https://example.com|label>
+var entityPattern = regexp.MustCompile(`<([^<>]+)>`)
+
+// removedMention matches a removed mention of the bot with the spaces
+// around it
+var removedMention = regexp.MustCompile(`[ \t]*\x00[ \t]*`)
+
+// plainText turns Slack message text into plain text: mentions of the
+// bot are removed, other mentions, channels and links are spelled out
+func (h *Handler) plainText(ctx context.Context, text string) string {
+	botID := h.bot.botUserID()
+	text = entityPattern.ReplaceAllStringFunc(text, func(entity string) string {
+		inner := entity[1 : len(entity)-1]
+		value, label, _ := strings.Cut(inner, "|")
+		switch {
+		case strings.HasPrefix(value, "@"):
+			if value[1:] == botID {
+				return "\x00"
+			}
+			if label != "" {
+				return "@" + label
+			}
+			return "@" + h.userName(ctx, value[1:])
+		case strings.HasPrefix(value, "#"):
+			if label != "" {
+				return "#" + label
+			}
+			return value
+		case strings.HasPrefix(value, "!"):
+			if label != "" {
+				return label
+			}
+			return "@" + strings.TrimPrefix(value, "!")
+		case label != "" && label != value && !strings.HasPrefix(value, "mailto:"):
+			return label + " (" + value + ")"
+		case label != "":
+			return label
+		}
+		return value
+	})
+
+	// Removed mentions leave the words around them apart by one space
+	text = strings.TrimSpace(removedMention.ReplaceAllString(text, " "))
+
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(text)
+}
+
+// userName returns the name of a user, looked up once
+func (h *Handler) userName(ctx context.Context, userID string) string {
+	h.mu.RLock()
+	name, ok := h.names[userID]
+	h.mu.RUnlock()
+	if ok {
+		return name
+	}
+
+	user, err := h.bot.api.userInfo(ctx, userID)
+	if err != nil {
+		if h.bot.config.Debug {
+			log.Printf("⚠️  Failed to look up Slack user %s: %v", userID, err)
+		}
+		return userID
+	}
+	name = user.String()
+
+	h.mu.Lock()
+	h.names[userID] = name
+	h.mu.Unlock()
+	return name
+}
+
+// isAllowed reports whether id is in an allow list, empty to allow all
+func isAllowed(allowed []string, id string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == id {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTS returns the time of a message timestamp, as 1700000000.000100
+func parseTS(ts string) time.Time {
+	seconds, micros, _ := strings.Cut(ts, ".")
+	sec, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Now()
+	}
+	usec, _ := strconv.ParseInt(micros, 10, 64)
+	return time.Unix(sec, usec*int64(time.Microsecond))
+}
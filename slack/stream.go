@@ -0,0 +1,191 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+const (
+	// streamPlaceholder is shown until the first part of a reply arrives
+	streamPlaceholder = "…"
+
+	// streamCursor ends a reply while it is generated
+	streamCursor = " ▍"
+)
+
+// SendStream posts a placeholder message in the thread of the target and
+// edits it with chat.update as the reply is generated, at most once per
+// EditInterval
+func (b *Bot) SendStream(ctx context.Context, target string, options map[string]interface{}) (channels.Stream, error) {
+	channel, threadTS, err := parseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	if ts, ok := options["thread_ts"].(string); ok && ts != "" {
+		threadTS = ts
+	}
+
+	ts, err := b.api.postMessage(ctx, channel, threadTS, streamPlaceholder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	interval := time.Duration(b.config.EditInterval) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultEditInterval * time.Millisecond
+	}
+
+	s := &stream{
+		bot:      b,
+		channel:  channel,
+		threadTS: threadTS,
+		ts:       ts,
+		interval: interval,
+		shown:    streamPlaceholder,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go s.loop(ctx)
+	return s, nil
+}
+
+// stream is a reply edited in place while it is generated
+type stream struct {
+	bot      *Bot
+	channel  string
+	threadTS string
+	ts       string // timestamp of the message edited
+	interval time.Duration
+
+	mu      sync.Mutex
+	text    strings.Builder
+	shown   string    // text of the message as last edited
+	next    time.Time // no edit before, when Slack asked to wait
+	closed  bool
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// Append adds generated text, shown with the next edit
+func (s *stream) Append(delta string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("reply is complete")
+	}
+	s.text.WriteString(delta)
+	return nil
+}
+
+// Close shows the full reply, continued in new messages of the thread
+// when it is too long for one. Without text the placeholder is removed.
+func (s *stream) Close(text string) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.done)
+	<-s.stopped
+
+	ctx := context.Background()
+	if text == "" {
+		return s.bot.api.deleteMessage(ctx, s.channel, s.ts)
+	}
+
+	parts := formatMessage(text, maxMessageLength)
+	if err := s.edit(ctx, parts[0], true); err != nil {
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+	for _, part := range parts[1:] {
+		if _, err := s.bot.api.postMessage(ctx, s.channel, s.threadTS, part); err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+	return nil
+}
+
+// loop edits the message with the text generated so far, once per interval
+func (s *stream) loop(ctx context.Context) {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			text := s.text.String()
+			wait := time.Now().Before(s.next)
+			s.mu.Unlock()
+
+			if text == "" || wait {
+				continue
+			}
+			if err := s.edit(ctx, preview(text), false); err != nil {
+				log.Printf("⚠️  Failed to update streamed reply in %s: %v", s.channel, err)
+			}
+		}
+	}
+}
+
+// edit replaces the text of the message, unless it is shown already. A
+// final edit waits out the rate limit once; progressive edits are put
+// off.
+func (s *stream) edit(ctx context.Context, text string, final bool) error {
+	s.mu.Lock()
+	if text == s.shown {
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+
+	err := s.bot.api.updateMessage(ctx, s.channel, s.ts, text)
+	if wait := retryAfter(err); wait > 0 {
+		if !final {
+			s.mu.Lock()
+			s.next = time.Now().Add(wait)
+			s.mu.Unlock()
+			return nil
+		}
+		time.Sleep(wait)
+		err = s.bot.api.updateMessage(ctx, s.channel, s.ts, text)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.shown = text
+	s.mu.Unlock()
+	return nil
+}
+
+// preview returns the text of a reply being generated as mrkdwn escaped
+// plain text, its end when too long for a message
+func preview(text string) string {
+	limit := maxMessageLength - utf8.RuneCountInString(streamCursor)
+	text = mrkdwnEscaper.Replace(text)
+	if n := utf8.RuneCountInString(text); n > limit {
+		text = string([]rune(text)[n-limit:])
+	}
+	return strings.TrimRight(text, " \n") + streamCursor
+}
+
+var _ channels.StreamSender = (*Bot)(nil)
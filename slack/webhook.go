@@ -0,0 +1,134 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// signatureHeader holds the signature of an event request
+	signatureHeader = "X-Slack-Signature"
+
+	// timestampHeader holds the time an event request was signed
+	timestampHeader = "X-Slack-Request-Timestamp"
+
+	// signatureVersion prefixes the signed string and the signature
+	signatureVersion = "v0"
+
+	// maxClockSkew is how old a signed request may be, to refuse replays
+	maxClockSkew = 5 * time.Minute
+
+	// maxWebhookBody is the largest event request accepted, in bytes
+	maxWebhookBody = 1 << 20
+)
+
+// eventEnvelope is a request of the Events API
+type eventEnvelope struct {
+	Type      string `json:"type"` // url_verification or event_callback
+	Challenge string `json:"challenge,omitempty"`
+	TeamID    string `json:"team_id,omitempty"`
+	EventID   string `json:"event_id,omitempty"`
+	Event     *event `json:"event,omitempty"`
+}
+
+// WebhookPath returns the path of the Events API request URL on the
+// gateway HTTP server
+func (b *Bot) WebhookPath() string {
+	if b.config.WebhookPath != "" {
+		return b.config.WebhookPath
+	}
+	return defaultWebhookPath
+}
+
+// ServeWebhook handles a request of the Events API. Requests without a
+// valid signature, too large or delivered already are not handled;
+// events are acknowledged before they are answered, as Slack waits for
+// three seconds only.
+func (b *Bot) ServeWebhook(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsPost() {
+		ctx.Error("method not allowed", fasthttp.StatusMethodNotAllowed)
+		return
+	}
+
+	body := ctx.PostBody()
+	if ctx.Request.Header.ContentLength() > maxWebhookBody || len(body) > maxWebhookBody {
+		ctx.Error("request too large", fasthttp.StatusRequestEntityTooLarge)
+		return
+	}
+
+	timestamp := string(ctx.Request.Header.Peek(timestampHeader))
+	signature := string(ctx.Request.Header.Peek(signatureHeader))
+	if err := verifySignature(b.config.SigningSecret, timestamp, signature, body, time.Now()); err != nil {
+		if b.config.Debug {
+			log.Printf("🚫 Slack event request from %s rejected: %v", ctx.RemoteIP(), err)
+		}
+		ctx.Error("unauthorized", fasthttp.StatusUnauthorized)
+		return
+	}
+
+	var envelope eventEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		ctx.Error(fmt.Sprintf("invalid event: %v", err), fasthttp.StatusBadRequest)
+		return
+	}
+
+	// Slack checks the request URL when it is set in the app settings
+	if envelope.Type == "url_verification" {
+		ctx.SetContentType("text/plain")
+		ctx.SetBodyString(envelope.Challenge)
+		return
+	}
+
+	if !b.IsRunning() {
+		// Slack retries until the bot is back
+		ctx.Error("bot is not running", fasthttp.StatusServiceUnavailable)
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	if envelope.Type != "event_callback" || envelope.Event == nil {
+		return
+	}
+	if !b.recent.Add(envelope.EventID) {
+		if b.config.Debug {
+			log.Printf("ℹ️  Dropped event %s, delivered already", envelope.EventID)
+		}
+		return
+	}
+	b.handler.HandleEvent(envelope.TeamID, envelope.Event)
+}
+
+// verifySignature checks the signature of an event request, made with
+// the signing secret over the version, timestamp and body
+func verifySignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	if timestamp == "" || signature == "" {
+		return fmt.Errorf("missing signature")
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > maxClockSkew || skew < -maxClockSkew {
+		return fmt.Errorf("timestamp is %s off", skew.Round(time.Second))
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signatureVersion + ":" + timestamp + ":"))
+	mac.Write(body)
+	expected := signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+var _ channels.Webhook = (*Bot)(nil)
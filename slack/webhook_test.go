@@ -0,0 +1,117 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/valyala/fasthttp"
+)
+
+// sign signs a body the way Slack does
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signatureVersion + ":" + timestamp + ":"))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"event_callback"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	old := strconv.FormatInt(now.Add(-maxClockSkew-time.Second).Unix(), 10)
+	ahead := strconv.FormatInt(now.Add(maxClockSkew+time.Second).Unix(), 10)
+	recent := strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)
+
+	for _, tc := range []struct {
+		name      string
+		timestamp string
+		signature string
+		body      []byte
+		ok        bool
+	}{
+		{"valid", ts, sign("secret", ts, body), body, true},
+		{"within skew", recent, sign("secret", recent, body), body, true},
+		{"too old", old, sign("secret", old, body), body, false},
+		{"too far ahead", ahead, sign("secret", ahead, body), body, false},
+		{"other secret", ts, sign("other", ts, body), body, false},
+		{"changed body", ts, sign("secret", ts, body), []byte(`{"type":"url_verification"}`), false},
+		{"other timestamp", ts, sign("secret", old, body), body, false},
+		{"malformed signature", ts, "v0=zz", body, false},
+		{"missing signature", ts, "", body, false},
+		{"missing timestamp", "", sign("secret", ts, body), body, false},
+		{"invalid timestamp", "soon", sign("secret", "soon", body), body, false},
+	} {
+		err := verifySignature("secret", tc.timestamp, tc.signature, tc.body, now)
+		if tc.ok && err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("%s: accepted", tc.name)
+		}
+	}
+}
+
+func TestFormatMessageSplitsOnRunes(t *testing.T) {
+	text := strings.Repeat("é", maxMessageLength+10)
+	parts := formatMessage(text, maxMessageLength)
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(parts))
+	}
+	total := 0
+	for _, part := range parts {
+		if !utf8.ValidString(part) {
+			t.Errorf("part is not valid UTF-8")
+		}
+		if n := utf8.RuneCountInString(part); n > maxMessageLength {
+			t.Errorf("part has %d characters, over %d", n, maxMessageLength)
+		}
+		total += utf8.RuneCountInString(part)
+	}
+	if total != maxMessageLength+10 {
+		t.Errorf("parts have %d characters, want %d", total, maxMessageLength+10)
+	}
+}
+
+// webhookRequest returns a request of the Events API signed with secret
+func webhookRequest(secret, body string) *fasthttp.RequestCtx {
+	var ctx fasthttp.RequestCtx
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	ctx.Request.SetBodyString(body)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	ctx.Request.Header.Set(timestampHeader, ts)
+	ctx.Request.Header.Set(signatureHeader, sign(secret, ts, []byte(body)))
+	return &ctx
+}
+
+func TestServeWebhook(t *testing.T) {
+	b, err := NewBot(&Config{BotToken: "xoxb-test", SigningSecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := webhookRequest("other", `{"type":"url_verification","challenge":"c1"}`)
+	b.ServeWebhook(ctx)
+	if code := ctx.Response.StatusCode(); code != fasthttp.StatusUnauthorized {
+		t.Errorf("request signed with another secret: status %d, want 401", code)
+	}
+
+	ctx = webhookRequest("secret", `{"type":"url_verification","challenge":"c1"}`)
+	b.ServeWebhook(ctx)
+	if body := string(ctx.Response.Body()); ctx.Response.StatusCode() != fasthttp.StatusOK || body != "c1" {
+		t.Errorf("url_verification: status %d body %q, want the challenge", ctx.Response.StatusCode(), body)
+	}
+
+	// Events wait for the bot to run, so Slack delivers them again
+	ctx = webhookRequest("secret", `{"type":"event_callback","event_id":"Ev1","event":{"type":"message"}}`)
+	b.ServeWebhook(ctx)
+	if code := ctx.Response.StatusCode(); code != fasthttp.StatusServiceUnavailable {
+		t.Errorf("event before start: status %d, want 503", code)
+	}
+}
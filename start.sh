@@ -8,7 +8,7 @@ echo "==================================="
 echo ""
 
 # 检查是否已编译
-if [ ! -f "bin/gateway" ]; then
+if [ ! -f "bin/openclaw" ]; then
     echo "📦 首次运行，正在编译..."
     make build
     echo ""
@@ -18,4 +18,4 @@ fi
 echo "🚀 启动 Gateway..."
 echo ""
 
-./bin/gateway
+./bin/openclaw serve "$@"
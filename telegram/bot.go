@@ -2,11 +2,8 @@ package channels
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
-	"net/url"
 	"sync"
 	"time"
 
@@ -20,6 +17,8 @@ type Bot struct {
 	api          *telegrambotapi.BotAPI
 	handler      *Handler
 	messageHandler channels.MessageHandler
+	actionHandler  channels.ActionHandler
+	recent         channels.RecentIDs // update IDs received through the webhook
 	running      bool
 	mu           sync.RWMutex
 	ctx          context.Context
@@ -33,11 +32,21 @@ func NewBot(config *Config) (*Bot, error) {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
-	botAPI, err := telegrambotapi.NewBotAPI(config.BotToken)
+	botAPI, err := telegrambotapi.NewBotAPIWithAPIEndpoint(config.BotToken, config.apiURL()+"/bot%s/%s")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot API: %w", err)
 	}
 
+	if config.Transcriber == nil {
+		config.Transcriber = channels.NopTranscriber{}
+	}
+
+	if config.UseWebhook && config.WebhookSecret == "" {
+		if config.WebhookSecret, err = newSecretToken(); err != nil {
+			return nil, err
+		}
+	}
+
 	bot := &Bot{
 		config: config,
 		api:    botAPI,
@@ -79,6 +88,11 @@ func (b *Bot) Start(ctx context.Context) error {
 
 	log.Printf("✅ Telegram Bot initialized: @%s (ID: %d)", botInfo.UserName, botInfo.ID)
 
+	// Offer the commands for autocompletion
+	if err := b.registerCommands(); err != nil {
+		log.Printf("⚠️  Failed to register bot commands: %v", err)
+	}
+
 	// Start handler
 	b.handler.Start(b.ctx)
 
@@ -100,6 +114,14 @@ func (b *Bot) Start(ctx context.Context) error {
 
 // Stop stops Telegram bot
 func (b *Bot) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.running {
+		b.mu.Unlock()
+		return nil
+	}
+	b.running = false
+	b.mu.Unlock()
+
 	log.Println("🛑 Stopping Telegram bot...")
 
 	b.cancel()
@@ -127,10 +149,9 @@ func (b *Bot) Stop(ctx context.Context) error {
 // Send sends a message to specified target
 func (b *Bot) Send(ctx context.Context, target string, content string, options map[string]interface{}) error {
 	// Parse target (chat ID)
-	var chatID int64
-	_, err := fmt.Sscanf(target, "%d", &chatID)
+	chatID, err := parseChatID(target)
 	if err != nil {
-		return fmt.Errorf("invalid chat ID: %w", err)
+		return err
 	}
 
 	// Create send options
@@ -148,53 +169,85 @@ func (b *Bot) Send(ctx context.Context, target string, content string, options m
 		if disableNotification, ok := options["disable_notification"].(bool); ok {
 			sendOpts.DisableNotification = disableNotification
 		}
+		if buttons, ok := options["buttons"].([][]channels.Button); ok {
+			sendOpts.Buttons = buttons
+		}
 	}
 
 	return b.SendMessage(ctx, chatID, content, sendOpts)
 }
 
-// SendMessage sends a text message to a Telegram chat
+// SendMessage sends model Markdown to a Telegram chat, converted to the
+// parse mode of the options or the configured one. Long text is split
+// into several messages.
 func (b *Bot) SendMessage(ctx context.Context, chatID int64, text string, opts *SendOptions) error {
+	_, err := b.sendMessage(ctx, chatID, text, opts)
+	return err
+}
+
+// sendMessage sends a message like SendMessage and returns the ID of its
+// last part, which carries the buttons of the options
+func (b *Bot) sendMessage(ctx context.Context, chatID int64, text string, opts *SendOptions) (int, error) {
 	if text == "" {
-		return fmt.Errorf("message text cannot be empty")
+		return 0, fmt.Errorf("message text cannot be empty")
 	}
 
-	// Limit message length (Telegram max is 4096 characters)
-	const maxLength = 4096
-	if len(text) > maxLength {
-		text = text[:maxLength-3] + "..."
+	parseMode := b.config.ParseMode
+	if opts != nil && opts.ParseMode != "" {
+		parseMode = opts.ParseMode
 	}
+	parseMode = normalizeParseMode(parseMode)
 
-	// Create message
-	msg := telegrambotapi.NewMessage(chatID, text)
+	var keyboard *telegrambotapi.InlineKeyboardMarkup
+	if opts != nil && len(opts.Buttons) > 0 {
+		markup, err := inlineKeyboard(opts.Buttons)
+		if err != nil {
+			return 0, err
+		}
+		keyboard = &markup
+	}
 
-	// Apply options
-	if opts != nil {
-		if opts.ParseMode != "" {
-			msg.ParseMode = opts.ParseMode
+	parts := formatMessage(text, parseMode, maxMessageLength)
+	messageID := 0
+	for i, part := range parts {
+		// Create message
+		msg := telegrambotapi.NewMessage(chatID, part.text)
+		msg.ParseMode = parseMode
+		if keyboard != nil && i == len(parts)-1 {
+			msg.ReplyMarkup = *keyboard
 		}
-		if opts.ReplyTo != 0 {
-			msg.ReplyToMessageID = int(opts.ReplyTo)
+
+		// Apply options
+		if opts != nil {
+			if opts.ReplyTo != 0 && i == 0 {
+				msg.ReplyToMessageID = int(opts.ReplyTo)
+			}
+			if opts.DisableWebPagePreview {
+				msg.DisableWebPagePreview = opts.DisableWebPagePreview
+			}
+			if opts.DisableNotification {
+				msg.DisableNotification = opts.DisableNotification
+			}
 		}
-		if opts.DisableWebPagePreview {
-			msg.DisableWebPagePreview = opts.DisableWebPagePreview
+
+		// Send message, as plain text if Telegram rejects the markup
+		sent, err := b.api.Send(msg)
+		if isEntityError(err) {
+			log.Printf("⚠️  Telegram rejected %s markup, sending plain text: %v", parseMode, err)
+			msg.Text, msg.ParseMode = part.plain, ""
+			sent, err = b.api.Send(msg)
 		}
-		if opts.DisableNotification {
-			msg.DisableNotification = opts.DisableNotification
+		if err != nil {
+			return 0, fmt.Errorf("failed to send message: %w", err)
 		}
-	}
-
-	// Send message
-	_, err := b.api.Send(msg)
-	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		messageID = sent.MessageID
 	}
 
 	if b.config.Debug {
 		log.Printf("📤 Sent message to chat %d: %s", chatID, truncateString(text, 50))
 	}
 
-	return nil
+	return messageID, nil
 }
 
 // SetMessageHandler sets handler for incoming messages
@@ -255,69 +308,6 @@ func (b *Bot) startLongPolling() error {
 	return nil
 }
 
-// startWebhook starts bot in webhook mode
-func (b *Bot) startWebhook() error {
-	// Set webhook
-	webhookURL, err := url.Parse(b.config.WebhookURL)
-	if err != nil {
-		return fmt.Errorf("failed to parse webhook URL: %w", err)
-	}
-	_, err = b.api.Request(telegrambotapi.WebhookConfig{
-		URL: webhookURL,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to set webhook: %w", err)
-	}
-
-	// Create webhook server
-	b.wg.Add(1)
-	go func() {
-		defer b.wg.Done()
-
-		srv := &http.Server{
-			Addr:    fmt.Sprintf(":%d", b.config.WebhookPort),
-			Handler: b,
-		}
-
-		log.Printf("🪝 Webhook server listening on :%d", b.config.WebhookPort)
-
-		go func() {
-			<-b.ctx.Done()
-			srv.Shutdown(context.Background())
-		}()
-
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("❌ Webhook server error: %v", err)
-		}
-	}()
-
-	return nil
-}
-
-// ServeHTTP implements http.Handler for webhook mode
-func (b *Bot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	update, err := b.handleWebhook(w, r)
-	if err != nil {
-		return
-	}
-
-	b.handleUpdate(update)
-}
-
-// handleWebhook processes incoming webhook requests
-func (b *Bot) handleWebhook(w http.ResponseWriter, r *http.Request) (*telegrambotapi.Update, error) {
-	defer r.Body.Close()
-
-	var update telegrambotapi.Update
-	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return nil, err
-	}
-
-	w.WriteHeader(http.StatusOK)
-	return &update, nil
-}
-
 // handleUpdate processes a single update
 func (b *Bot) handleUpdate(update *telegrambotapi.Update) {
 	// Handle callback queries
@@ -348,8 +338,9 @@ func (b *Bot) getModeName() string {
 
 // truncateString truncates a string to a maximum length
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
-	return s[:maxLen] + "..."
+	return string(runes[:maxLen]) + "..."
 }
@@ -0,0 +1,70 @@
+package channels
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/openclaw/go-openclaw/pkg/channels/channeltest"
+)
+
+// botUserID is the user ID of the bot on the Bot API stand-in
+const botUserID = 1
+
+// newTestBot returns a bot on a Bot API stand-in answering getMe and the
+// requests for sending, editing and deleting messages
+func newTestBot(t *testing.T, config *Config) (*Bot, *channeltest.StandIn) {
+	t.Helper()
+	api := channeltest.New(t)
+	api.Handle("/getMe", func(r *channeltest.Request) (int, interface{}) {
+		return ok(map[string]interface{}{"id": botUserID, "is_bot": true, "first_name": "Test", "username": "test_bot"})
+	})
+
+	var mu sync.Mutex
+	messageID := 100
+	api.Handle("/sendMessage", func(r *channeltest.Request) (int, interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		messageID++
+		chatID, _ := strconv.ParseInt(r.Form.Get("chat_id"), 10, 64)
+		return ok(map[string]interface{}{
+			"message_id": messageID,
+			"date":       0,
+			"chat":       map[string]interface{}{"id": chatID, "type": "private"},
+			"text":       r.Form.Get("text"),
+		})
+	})
+	for _, method := range []string{"/editMessageText", "/editMessageReplyMarkup", "/deleteMessage", "/sendChatAction", "/answerCallbackQuery", "/setMyCommands"} {
+		api.Handle(method, func(r *channeltest.Request) (int, interface{}) {
+			return ok(true)
+		})
+	}
+
+	config.BotToken = "123:abc"
+	config.APIURL = api.URL
+	b, err := NewBot(config)
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+	return b, api
+}
+
+// ok returns a successful Bot API response
+func ok(result interface{}) (int, interface{}) {
+	return http.StatusOK, map[string]interface{}{"ok": true, "result": result}
+}
+
+// apiError returns a failed Bot API response
+func apiError(code int, description string) (int, interface{}) {
+	return code, map[string]interface{}{"ok": false, "error_code": code, "description": description}
+}
+
+// texts returns the text of requests
+func texts(requests []*channeltest.Request) []string {
+	var texts []string
+	for _, r := range requests {
+		texts = append(texts, r.Form.Get("text"))
+	}
+	return texts
+}
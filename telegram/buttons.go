@@ -0,0 +1,124 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	telegrambotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// maxCallbackData is the most data Telegram keeps with a button, in bytes
+const maxCallbackData = 64
+
+// SendButtons sends model Markdown with rows of inline keyboard buttons
+// and returns the ID of the message holding them
+func (b *Bot) SendButtons(ctx context.Context, target, text string, buttons [][]channels.Button) (string, error) {
+	chatID, err := parseChatID(target)
+	if err != nil {
+		return "", err
+	}
+	messageID, err := b.sendMessage(ctx, chatID, text, &SendOptions{Buttons: buttons})
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(messageID), nil
+}
+
+// SetActionHandler sets handler for pressed buttons
+func (b *Bot) SetActionHandler(handler channels.ActionHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.actionHandler = handler
+}
+
+// getActionHandler returns current action handler
+func (b *Bot) getActionHandler() channels.ActionHandler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.actionHandler
+}
+
+// inlineKeyboard returns the inline keyboard of rows of buttons
+func inlineKeyboard(buttons [][]channels.Button) (telegrambotapi.InlineKeyboardMarkup, error) {
+	rows := make([][]telegrambotapi.InlineKeyboardButton, 0, len(buttons))
+	for _, row := range buttons {
+		keys := make([]telegrambotapi.InlineKeyboardButton, 0, len(row))
+		for _, button := range row {
+			if button.Data == "" || len(button.Data) > maxCallbackData {
+				return telegrambotapi.InlineKeyboardMarkup{}, fmt.Errorf("button %q needs 1 to %d bytes of data", button.Text, maxCallbackData)
+			}
+			keys = append(keys, telegrambotapi.NewInlineKeyboardButtonData(button.Text, button.Data))
+		}
+		rows = append(rows, keys)
+	}
+	return telegrambotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// handleAction passes a pressed button to the action handler. The
+// buttons are removed, so that each message is answered once, and the
+// choice is shown to the user.
+func (h *Handler) handleAction(callback *telegrambotapi.CallbackQuery) {
+	handler := h.bot.getActionHandler()
+	if handler == nil {
+		h.answerCallback(callback, "")
+		return
+	}
+
+	label := buttonLabel(callback.Message, callback.Data)
+	h.answerCallback(callback, "✅ "+label)
+
+	chat := callback.Message.Chat
+	removed := telegrambotapi.NewEditMessageReplyMarkup(chat.ID, callback.Message.MessageID,
+		telegrambotapi.InlineKeyboardMarkup{InlineKeyboard: [][]telegrambotapi.InlineKeyboardButton{}})
+	if _, err := h.bot.api.Request(removed); err != nil && !isNotModified(err) {
+		log.Printf("⚠️  Failed to remove buttons in chat %d: %v", chat.ID, err)
+	}
+
+	user := h.convertUser(callback.From)
+	action := &channels.Action{
+		Channel:   h.bot.Name(),
+		Target:    h.target(&IncomingMessage{From: user, Chat: h.convertChat(chat)}),
+		MessageID: strconv.Itoa(callback.Message.MessageID),
+		From:      strconv.FormatInt(user.ID, 10),
+		FromName:  user.String(),
+		Data:      callback.Data,
+		Text:      label,
+	}
+
+	// The action may run an agent turn; updates keep coming meanwhile
+	ctx := h.bot.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	h.bot.wg.Add(1)
+	go func() {
+		defer h.bot.wg.Done()
+		err := handler(ctx, action)
+		switch {
+		case errors.Is(err, channels.ErrActionExpired):
+			_ = h.bot.SendMessage(ctx, chat.ID, "⌛ This question has expired.", &SendOptions{ReplyTo: int64(callback.Message.MessageID)})
+		case err != nil:
+			log.Printf("❌ Error handling button %q from %s: %v", callback.Data, user.String(), err)
+		}
+	}()
+}
+
+// buttonLabel returns the text of the button of a message with data
+func buttonLabel(msg *telegrambotapi.Message, data string) string {
+	if msg.ReplyMarkup != nil {
+		for _, row := range msg.ReplyMarkup.InlineKeyboard {
+			for _, button := range row {
+				if button.CallbackData != nil && *button.CallbackData == data {
+					return button.Text
+				}
+			}
+		}
+	}
+	return data
+}
+
+var _ channels.Interactive = (*Bot)(nil)
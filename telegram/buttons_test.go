@@ -0,0 +1,154 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	telegrambotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+func TestInlineKeyboardDataLimit(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		ok   bool
+	}{
+		{"yes", true},
+		{strings.Repeat("x", maxCallbackData), true},
+		{strings.Repeat("x", maxCallbackData+1), false},
+		// The limit is in bytes, not characters
+		{strings.Repeat("é", maxCallbackData/2+1), false},
+		{"", false},
+	} {
+		_, err := inlineKeyboard([][]channels.Button{{{Text: "A", Data: tc.data}}})
+		if (err == nil) != tc.ok {
+			t.Errorf("data of %d bytes: err = %v, want ok %v", len(tc.data), err, tc.ok)
+		}
+	}
+}
+
+func TestSendButtons(t *testing.T) {
+	b, api := newTestBot(t, &Config{})
+	ctx := context.Background()
+
+	tooLong := strings.Repeat("x", maxCallbackData+1)
+	if _, err := b.SendButtons(ctx, "42", "Pick", [][]channels.Button{{{Text: "A", Data: tooLong}}}); err == nil {
+		t.Error("SendButtons with too much data succeeded")
+	}
+	if n := len(api.Requests("/sendMessage")); n != 0 {
+		t.Errorf("%d messages sent with invalid buttons", n)
+	}
+
+	id, err := b.SendButtons(ctx, "42", "Deploy to prod?", [][]channels.Button{
+		{{Text: "Yes", Data: "ask:1:0"}, {Text: "No", Data: "ask:1:1"}},
+	})
+	if err != nil {
+		t.Fatalf("SendButtons: %v", err)
+	}
+	sent := api.Requests("/sendMessage")
+	if len(sent) != 1 || id != "101" {
+		t.Fatalf("sent %d messages with ID %s, want one", len(sent), id)
+	}
+	var markup telegrambotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(sent[0].Form.Get("reply_markup")), &markup); err != nil {
+		t.Fatalf("reply_markup: %v", err)
+	}
+	if len(markup.InlineKeyboard) != 1 || len(markup.InlineKeyboard[0]) != 2 || *markup.InlineKeyboard[0][1].CallbackData != "ask:1:1" {
+		t.Errorf("keyboard = %s, want the buttons in a row", sent[0].Form.Get("reply_markup"))
+	}
+}
+
+// press returns the callback query of pressing a button of a message
+// holding buttons
+func press(from int64, chat *telegrambotapi.Chat, buttons [][]channels.Button, data string) *telegrambotapi.Update {
+	markup, _ := inlineKeyboard(buttons)
+	return &telegrambotapi.Update{CallbackQuery: &telegrambotapi.CallbackQuery{
+		ID:   "cb1",
+		From: &telegrambotapi.User{ID: from, FirstName: "Ann"},
+		Message: &telegrambotapi.Message{
+			MessageID:   101,
+			Chat:        chat,
+			ReplyMarkup: &markup,
+		},
+		Data: data,
+	}}
+}
+
+func TestCallbackQueryAction(t *testing.T) {
+	b, api := newTestBot(t, &Config{AllowedUsers: []int64{3}})
+	actions := make(chan *channels.Action, 1)
+	b.SetActionHandler(func(ctx context.Context, action *channels.Action) error {
+		actions <- action
+		return nil
+	})
+	buttons := [][]channels.Button{{{Text: "Yes", Data: "ask:1:0"}, {Text: "No", Data: "ask:1:1"}}}
+	chat := &telegrambotapi.Chat{ID: 42, Type: "private"}
+
+	b.handler.HandleCallbackQuery(press(3, chat, buttons, "ask:1:1"))
+	var action *channels.Action
+	select {
+	case action = <-actions:
+	case <-time.After(time.Second):
+		t.Fatal("no action")
+	}
+	want := channels.Action{Channel: "telegram", Target: "42", MessageID: "101", From: "3", FromName: "Ann", Data: "ask:1:1", Text: "No"}
+	if *action != want {
+		t.Errorf("action = %+v, want %+v", *action, want)
+	}
+
+	answers := api.Requests("/answerCallbackQuery")
+	if len(answers) != 1 || answers[0].Form.Get("callback_query_id") != "cb1" || answers[0].Form.Get("text") != "✅ No" {
+		t.Errorf("answered %d times, want the choice shown", len(answers))
+	}
+	if removed := api.Requests("/editMessageReplyMarkup"); len(removed) != 1 || removed[0].Form.Get("message_id") != "101" {
+		t.Error("buttons not removed")
+	}
+
+	// Presses of users not allowed are only answered
+	api.Reset()
+	b.handler.HandleCallbackQuery(press(4, chat, buttons, "ask:1:0"))
+	select {
+	case action := <-actions:
+		t.Errorf("action of a user not allowed: %+v", action)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if len(api.Requests("/answerCallbackQuery")) != 1 || len(api.Requests("/editMessageReplyMarkup")) != 0 {
+		t.Error("press of a user not allowed not just answered")
+	}
+}
+
+func TestCallbackQueryExpired(t *testing.T) {
+	b, api := newTestBot(t, &Config{})
+	b.SetActionHandler(func(ctx context.Context, action *channels.Action) error {
+		return channels.ErrActionExpired
+	})
+	b.handler.HandleCallbackQuery(press(3, &telegrambotapi.Chat{ID: 42, Type: "private"},
+		[][]channels.Button{{{Text: "Yes", Data: "ask:1:0"}}}, "ask:1:0"))
+	b.wg.Wait()
+
+	if got := texts(api.Requests("/sendMessage")); len(got) != 1 || got[0] != "⌛ This question has expired." {
+		t.Errorf("sent %q, want the question expired", got)
+	}
+}
+
+func TestCallbackQueryHelp(t *testing.T) {
+	b, api := newTestBot(t, &Config{})
+	pressed := false
+	b.SetActionHandler(func(ctx context.Context, action *channels.Action) error {
+		pressed = true
+		return nil
+	})
+	b.handler.HandleCallbackQuery(press(3, &telegrambotapi.Chat{ID: 42, Type: "private"},
+		[][]channels.Button{{{Text: "Help", Data: "help"}}}, "help"))
+	b.wg.Wait()
+
+	if pressed {
+		t.Error("help button passed to the action handler")
+	}
+	if got := texts(api.Requests("/sendMessage")); len(got) != 1 || !strings.Contains(got[0], "/help") {
+		t.Errorf("sent %q, want the help", got)
+	}
+}
@@ -0,0 +1,197 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	telegrambotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// command is a bot command typed in a chat. Its reply is model Markdown.
+type command struct {
+	name        string
+	args        string
+	description string
+	control     bool // needs Config.Control
+	run         func(h *Handler, ctx context.Context, msg *IncomingMessage, args []string) (string, error)
+}
+
+// commands lists the bot commands, in the order they are offered
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "start", description: "Start a conversation", run: (*Handler).commandStart},
+		{name: "reset", description: "Start a new conversation", control: true, run: (*Handler).commandReset},
+		{name: "status", description: "Show agent and session status", control: true, run: (*Handler).commandStatus},
+		{name: "model", args: "[name]", description: "Show or switch the model", control: true, run: (*Handler).commandModel},
+		{name: "help", description: "Show the commands", run: (*Handler).commandHelp},
+	}
+}
+
+// parseCommand returns the command and arguments of a message, if it is
+// a command. In groups commands may name their bot, as in /reset@my_bot;
+// mine is false for commands naming another bot.
+func parseCommand(text, botName string) (name string, args []string, mine, ok bool) {
+	if !strings.HasPrefix(text, "/") || len(text) < 2 {
+		return "", nil, false, false
+	}
+	fields := strings.Fields(text)
+	name = strings.TrimPrefix(fields[0], "/")
+	mine = true
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		mine = strings.EqualFold(name[i+1:], botName)
+		name = name[:i]
+	}
+	return strings.ToLower(name), fields[1:], mine, true
+}
+
+// handleCommand runs a bot command and replies with its result. Commands
+// for other bots are ignored. It returns false for messages that are not
+// commands.
+func (h *Handler) handleCommand(ctx context.Context, msg *IncomingMessage) bool {
+	name, args, mine, ok := parseCommand(msg.Text, h.bot.api.Self.UserName)
+	if !ok {
+		return false
+	}
+	if !mine {
+		return true
+	}
+
+	reply := fmt.Sprintf("Unknown command /%s, see /help", name)
+	for _, cmd := range h.availableCommands() {
+		if cmd.name != name {
+			continue
+		}
+		if h.bot.config.Debug {
+			log.Printf("⌨️  Command /%s from %s in chat %d", name, msg.From.String(), msg.Chat.ID)
+		}
+		var err error
+		if reply, err = cmd.run(h, ctx, msg, args); err != nil {
+			reply = "⚠️ " + err.Error()
+		}
+		break
+	}
+
+	if err := h.bot.SendMessage(ctx, msg.Chat.ID, reply, &SendOptions{ReplyTo: msg.MessageID}); err != nil {
+		log.Printf("❌ Error replying to /%s: %v", name, err)
+	}
+	return true
+}
+
+// availableCommands returns the commands the bot can run
+func (h *Handler) availableCommands() []*command {
+	var available []*command
+	for _, cmd := range commands {
+		if !cmd.control || h.bot.config.Control != nil {
+			available = append(available, cmd)
+		}
+	}
+	return available
+}
+
+// registerCommands offers the commands for autocompletion in clients
+func (b *Bot) registerCommands() error {
+	var botCommands []telegrambotapi.BotCommand
+	for _, cmd := range b.handler.availableCommands() {
+		botCommands = append(botCommands, telegrambotapi.BotCommand{
+			Command:     cmd.name,
+			Description: cmd.description,
+		})
+	}
+	_, err := b.api.Request(telegrambotapi.NewSetMyCommands(botCommands...))
+	return err
+}
+
+// commandStart greets the user
+func (h *Handler) commandStart(ctx context.Context, msg *IncomingMessage, args []string) (string, error) {
+	return fmt.Sprintf("👋 Hi %s! I'm an AI assistant connected to OpenClaw. "+
+		"Send me a message to start, or /help for the commands.", msg.From.FirstName), nil
+}
+
+// commandReset starts a new conversation for the chat
+func (h *Handler) commandReset(ctx context.Context, msg *IncomingMessage, args []string) (string, error) {
+	if err := h.bot.config.Control.ResetSession(ctx, h.bot.Name(), h.target(msg)); err != nil {
+		return "", err
+	}
+	return "🧹 Started a new conversation", nil
+}
+
+// commandStatus shows the agent runtime and the session of the chat
+func (h *Handler) commandStatus(ctx context.Context, msg *IncomingMessage, args []string) (string, error) {
+	control := h.bot.config.Control
+	agent, err := control.AgentState(ctx, h.bot.Name(), h.target(msg))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Agent:** %s\n", agent.Status)
+	if agent.Model != "" {
+		fmt.Fprintf(&b, "**Model:** `%s` (%s)\n", agent.Model, agent.Provider)
+	}
+	fmt.Fprintf(&b, "**Sessions:** %d\n", agent.Sessions)
+
+	state, err := control.SessionInfo(ctx, h.bot.Name(), h.target(msg))
+	if err != nil {
+		b.WriteString("**This chat:** no conversation yet")
+		return b.String(), nil
+	}
+	fmt.Fprintf(&b, "**This chat:** %s messages, last active %s",
+		state.Metadata["messages"], time.Unix(state.LastActive, 0).Format(time.DateTime))
+	return b.String(), nil
+}
+
+// commandModel shows the model, or switches it for admin users
+func (h *Handler) commandModel(ctx context.Context, msg *IncomingMessage, args []string) (string, error) {
+	model := ""
+	if len(args) > 0 {
+		if !h.isAdmin(msg.From.ID) {
+			return "", fmt.Errorf("only admins can switch the model")
+		}
+		model = args[0]
+	}
+
+	current, err := h.bot.config.Control.Model(ctx, model)
+	if err != nil {
+		return "", err
+	}
+	if model != "" {
+		return fmt.Sprintf("🔁 Switched the model to `%s`", current), nil
+	}
+	return fmt.Sprintf("Model: `%s`", current), nil
+}
+
+// commandHelp lists the commands
+func (h *Handler) commandHelp(ctx context.Context, msg *IncomingMessage, args []string) (string, error) {
+	return h.helpText(), nil
+}
+
+// helpText returns help message
+func (h *Handler) helpText() string {
+	var b strings.Builder
+	b.WriteString("🤖 **Telegram Bot Help**\n\n")
+	b.WriteString("I'm an AI assistant connected to OpenClaw. Just send me a message and I'll respond!\n\n")
+	b.WriteString("**Commands:**\n")
+	for _, cmd := range h.availableCommands() {
+		usage := "/" + cmd.name
+		if cmd.args != "" {
+			usage += " " + cmd.args
+		}
+		fmt.Fprintf(&b, "• %s - %s\n", usage, cmd.description)
+	}
+	return b.String()
+}
+
+// isAdmin checks if a user may run admin commands
+func (h *Handler) isAdmin(userID int64) bool {
+	for _, id := range h.bot.config.AdminUsers {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
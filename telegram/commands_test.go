@@ -0,0 +1,156 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openclaw/go-openclaw/internal/protocol"
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// fakeControl is an agent control recording the sessions reset
+type fakeControl struct {
+	resets []string
+	model  string
+}
+
+func (c *fakeControl) ResetSession(ctx context.Context, channel, target string) error {
+	c.resets = append(c.resets, channel+":"+target)
+	return nil
+}
+
+func (c *fakeControl) SessionInfo(ctx context.Context, channel, target string) (*protocol.SessionState, error) {
+	return nil, errors.New("no session")
+}
+
+func (c *fakeControl) ListTools(ctx context.Context, channel, target string) ([]protocol.ToolInfo, error) {
+	return nil, nil
+}
+
+func (c *fakeControl) Model(ctx context.Context, model string) (string, error) {
+	if model != "" {
+		c.model = model
+	}
+	return c.model, nil
+}
+
+func (c *fakeControl) AgentState(ctx context.Context, channel, target string) (*protocol.AgentState, error) {
+	return &protocol.AgentState{Status: "running", Model: c.model, Provider: "test", Sessions: 2}, nil
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text string
+		name string
+		args []string
+		mine bool
+		ok   bool
+	}{
+		{"/start", "start", nil, true, true},
+		{"/Help", "help", nil, true, true},
+		{"/model  gpt-4o now", "model", []string{"gpt-4o", "now"}, true, true},
+		{"/reset@test_bot", "reset", nil, true, true},
+		{"/reset@Test_Bot", "reset", nil, true, true},
+		{"/reset@other_bot", "reset", nil, false, true},
+		{"hello /start", "", nil, false, false},
+		{"/", "", nil, false, false},
+	}
+	for _, tt := range tests {
+		name, args, mine, ok := parseCommand(tt.text, "test_bot")
+		if name != tt.name || strings.Join(args, " ") != strings.Join(tt.args, " ") || mine != tt.mine || ok != tt.ok {
+			t.Errorf("parseCommand(%q) = %q %q mine:%v ok:%v, want %q %q mine:%v ok:%v",
+				tt.text, name, args, mine, ok, tt.name, tt.args, tt.mine, tt.ok)
+		}
+	}
+}
+
+func TestHandleCommand(t *testing.T) {
+	control := &fakeControl{model: "small"}
+	b, api := newTestBot(t, &Config{Control: control, AdminUsers: []int64{7}})
+	var handled []string
+	b.SetMessageHandler(func(ctx context.Context, msg *channels.Message) error {
+		handled = append(handled, msg.Content)
+		return nil
+	})
+	ctx := context.Background()
+	private := &ChatInfo{ID: 42, Type: "private"}
+	group := &ChatInfo{ID: -100, Type: "supergroup"}
+	send := func(chat *ChatInfo, from int64, text string) string {
+		t.Helper()
+		api.Reset()
+		msg := &IncomingMessage{MessageID: 5, From: &UserInfo{ID: from, FirstName: "Ann"}, Chat: chat, Text: text}
+		if err := b.handler.handleMessage(ctx, msg); err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+		replies := api.Requests("/sendMessage")
+		if len(replies) == 0 {
+			return ""
+		}
+		if replies[0].Form.Get("reply_to_message_id") != "5" {
+			t.Errorf("%s: reply not to the command", text)
+		}
+		return replies[0].Form.Get("text")
+	}
+
+	if reply := send(private, 3, "/reset"); reply != "🧹 Started a new conversation" {
+		t.Errorf("/reset replied %q", reply)
+	}
+	if reply := send(group, 3, "/reset@test_bot"); reply == "" {
+		t.Error("/reset@test_bot not answered")
+	}
+	if strings.Join(control.resets, ",") != "telegram:42,telegram:-100" {
+		t.Errorf("reset %v, want the private chat and the group", control.resets)
+	}
+	if reply := send(private, 3, "/status"); !strings.Contains(reply, "<b>Agent:</b> running") || !strings.Contains(reply, "<b>Sessions:</b> 2") {
+		t.Errorf("/status replied %q", reply)
+	}
+	if reply := send(private, 3, "/help"); !strings.Contains(reply, "/model [name]") {
+		t.Errorf("/help replied %q, want the commands", reply)
+	}
+
+	// Only admins switch the model
+	if reply := send(private, 3, "/model large"); !strings.Contains(reply, "only admins") || control.model != "small" {
+		t.Errorf("/model from a user replied %q and switched to %s", reply, control.model)
+	}
+	if reply := send(private, 7, "/model large"); reply != "🔁 Switched the model to <code>large</code>" {
+		t.Errorf("/model from an admin replied %q", reply)
+	}
+
+	if reply := send(private, 3, "/deploy now"); reply != "Unknown command /deploy, see /help" {
+		t.Errorf("unknown command replied %q", reply)
+	}
+
+	// Commands of other bots and text are not replied to here
+	if reply := send(group, 3, "/reset@other_bot"); reply != "" {
+		t.Errorf("command for another bot replied %q", reply)
+	}
+	send(private, 3, "hello")
+	if strings.Join(handled, ",") != "hello" {
+		t.Errorf("message handler got %q, want only the text", handled)
+	}
+}
+
+func TestCommandsWithoutControl(t *testing.T) {
+	b, api := newTestBot(t, &Config{})
+	msg := &IncomingMessage{MessageID: 5, From: &UserInfo{ID: 3}, Chat: &ChatInfo{ID: 42, Type: "private"}, Text: "/reset"}
+	if err := b.handler.handleMessage(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if got := texts(api.Requests("/sendMessage")); len(got) != 1 || got[0] != "Unknown command /reset, see /help" {
+		t.Errorf("/reset without control replied %q", got)
+	}
+
+	if err := b.registerCommands(); err != nil {
+		t.Fatalf("registerCommands: %v", err)
+	}
+	registered := api.Requests("/setMyCommands")
+	if len(registered) != 1 {
+		t.Fatalf("%d setMyCommands requests, want 1", len(registered))
+	}
+	commands := registered[0].Form.Get("commands")
+	if !strings.Contains(commands, `"command":"start"`) || !strings.Contains(commands, `"command":"help"`) || strings.Contains(commands, "reset") {
+		t.Errorf("registered %s, want /start and /help only", commands)
+	}
+}
@@ -2,7 +2,11 @@ package channels
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
 )
 
 // Config holds the configuration for the Telegram channel
@@ -10,11 +14,24 @@ type Config struct {
 	// BotToken is the Telegram bot token
 	BotToken string `json:"bot_token"`
 
-	// WebhookURL is optional, if set the bot will use webhook mode instead of long polling
+	// APIURL is the base URL of the Bot API (default: https://api.telegram.org),
+	// for a local Bot API server or a stand-in in tests
+	APIURL string `json:"api_url,omitempty"`
+
+	// WebhookURL is the public URL Telegram posts updates to in webhook mode
 	WebhookURL string `json:"webhook_url,omitempty"`
 
-	// WebhookPort is the port for the webhook server (default: 8443)
-	WebhookPort int `json:"webhook_port,omitempty"`
+	// WebhookPath is the path of the webhook on the gateway HTTP server
+	// (default: the path of WebhookURL, or /telegram/webhook)
+	WebhookPath string `json:"webhook_path,omitempty"`
+
+	// WebhookSecret is sent by Telegram with every update, in the
+	// X-Telegram-Bot-Api-Secret-Token header (default: random at start)
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+
+	// WebhookCertificate is the public key certificate (PEM) uploaded to
+	// Telegram when the webhook URL has a self-signed certificate
+	WebhookCertificate string `json:"webhook_certificate,omitempty"`
 
 	// UseWebhook enables webhook mode (default: false, use long polling)
 	UseWebhook bool `json:"use_webhook,omitempty"`
@@ -22,21 +39,73 @@ type Config struct {
 	// Debug enables debug logging
 	Debug bool `json:"debug,omitempty"`
 
+	// ParseMode is the markup model Markdown is converted to: HTML
+	// (default), MarkdownV2, or none to send it as is
+	ParseMode string `json:"parse_mode,omitempty"`
+
+	// EditInterval is the minimum time between edits of a streamed reply,
+	// in milliseconds (default: 1000, Telegram allows about one per second)
+	EditInterval int `json:"edit_interval_ms,omitempty"`
+
 	// AllowedUsers is a list of allowed user IDs (empty means all users are allowed)
 	AllowedUsers []int64 `json:"allowed_users,omitempty"`
 
 	// AllowedGroups is a list of allowed group IDs (empty means all groups are allowed)
 	AllowedGroups []int64 `json:"allowed_groups,omitempty"`
+
+	// GroupTrigger selects the group messages answered: mention (default)
+	// for those mentioning the bot, replying to it or starting with one of
+	// GroupKeywords, always for every message
+	GroupTrigger string `json:"group_trigger,omitempty"`
+
+	// GroupKeywords are prefixes addressing the bot in groups, as "claw,"
+	GroupKeywords []string `json:"group_keywords,omitempty"`
+
+	// GroupSession keys agent sessions in groups: group (default) for one
+	// per group, user for one per user in each group
+	GroupSession string `json:"group_session,omitempty"`
+
+	// MaxFileSize is the largest photo, document or voice message
+	// downloaded, in bytes (default and Bot API limit: 20 MB)
+	MaxFileSize int64 `json:"max_file_size,omitempty"`
+
+	// AdminUsers is a list of user IDs allowed to switch the model with /model
+	AdminUsers []int64 `json:"admin_users,omitempty"`
+
+	// Control runs bot commands like /reset on the agent, nil to answer
+	// only /start and /help
+	Control channels.AgentControl `json:"-"`
+
+	// Transcriber turns voice messages into text (default:
+	// channels.NopTranscriber, with which they are declined)
+	Transcriber channels.Transcriber `json:"-"`
 }
 
+const (
+	// defaultAPIURL is the base URL of the Bot API
+	defaultAPIURL = "https://api.telegram.org"
+
+	// defaultEditInterval is the default time between edits of a streamed
+	// reply, in milliseconds
+	defaultEditInterval = 1000
+
+	// defaultMaxFileSize is the largest file the Bot API lets bots
+	// download, in bytes
+	defaultMaxFileSize = 20 << 20
+)
+
 // LoadConfig loads configuration from environment variables or defaults
 func LoadConfig() (*Config, error) {
 	cfg := &Config{
 		BotToken:      os.Getenv("TELEGRAM_BOT_TOKEN"),
+		APIURL:        os.Getenv("TELEGRAM_API_URL"),
 		WebhookURL:    os.Getenv("TELEGRAM_WEBHOOK_URL"),
-		WebhookPort:   8443,
+		WebhookSecret: os.Getenv("TELEGRAM_WEBHOOK_SECRET"),
 		UseWebhook:    os.Getenv("TELEGRAM_USE_WEBHOOK") == "true",
 		Debug:         os.Getenv("TELEGRAM_DEBUG") == "true",
+		ParseMode:     os.Getenv("TELEGRAM_PARSE_MODE"),
+		EditInterval:  defaultEditInterval,
+		MaxFileSize:   defaultMaxFileSize,
 		AllowedUsers:  make([]int64, 0),
 		AllowedGroups: make([]int64, 0),
 	}
@@ -51,7 +120,8 @@ func LoadConfig() (*Config, error) {
 // LoadConfigFromMap loads configuration from a map
 func LoadConfigFromMap(configMap map[string]interface{}) (*Config, error) {
 	cfg := &Config{
-		WebhookPort:   8443,
+		EditInterval:  defaultEditInterval,
+		MaxFileSize:   defaultMaxFileSize,
 		AllowedUsers:  make([]int64, 0),
 		AllowedGroups: make([]int64, 0),
 	}
@@ -62,12 +132,24 @@ func LoadConfigFromMap(configMap map[string]interface{}) (*Config, error) {
 		return nil, fmt.Errorf("bot_token is required in config")
 	}
 
+	if apiURL, ok := configMap["api_url"].(string); ok {
+		cfg.APIURL = apiURL
+	}
+
 	if webhookURL, ok := configMap["webhook_url"].(string); ok {
 		cfg.WebhookURL = webhookURL
 	}
 
-	if webhookPort, ok := configMap["webhook_port"].(float64); ok {
-		cfg.WebhookPort = int(webhookPort)
+	if webhookPath, ok := configMap["webhook_path"].(string); ok {
+		cfg.WebhookPath = webhookPath
+	}
+
+	if webhookSecret, ok := configMap["webhook_secret"].(string); ok {
+		cfg.WebhookSecret = webhookSecret
+	}
+
+	if webhookCertificate, ok := configMap["webhook_certificate"].(string); ok {
+		cfg.WebhookCertificate = webhookCertificate
 	}
 
 	if useWebhook, ok := configMap["use_webhook"].(bool); ok {
@@ -78,6 +160,14 @@ func LoadConfigFromMap(configMap map[string]interface{}) (*Config, error) {
 		cfg.Debug = debug
 	}
 
+	if parseMode, ok := configMap["parse_mode"].(string); ok {
+		cfg.ParseMode = parseMode
+	}
+
+	if editInterval, ok := configMap["edit_interval_ms"].(float64); ok {
+		cfg.EditInterval = int(editInterval)
+	}
+
 	if allowedUsers, ok := configMap["allowed_users"].([]interface{}); ok {
 		for _, uid := range allowedUsers {
 			if id, ok := uid.(float64); ok {
@@ -94,6 +184,34 @@ func LoadConfigFromMap(configMap map[string]interface{}) (*Config, error) {
 		}
 	}
 
+	if groupTrigger, ok := configMap["group_trigger"].(string); ok {
+		cfg.GroupTrigger = groupTrigger
+	}
+
+	if groupKeywords, ok := configMap["group_keywords"].([]interface{}); ok {
+		for _, kw := range groupKeywords {
+			if keyword, ok := kw.(string); ok {
+				cfg.GroupKeywords = append(cfg.GroupKeywords, keyword)
+			}
+		}
+	}
+
+	if groupSession, ok := configMap["group_session"].(string); ok {
+		cfg.GroupSession = groupSession
+	}
+
+	if maxFileSize, ok := configMap["max_file_size"].(float64); ok {
+		cfg.MaxFileSize = int64(maxFileSize)
+	}
+
+	if adminUsers, ok := configMap["admin_users"].([]interface{}); ok {
+		for _, uid := range adminUsers {
+			if id, ok := uid.(float64); ok {
+				cfg.AdminUsers = append(cfg.AdminUsers, int64(id))
+			}
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -107,5 +225,47 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("webhook_url is required when use_webhook is true")
 	}
 
+	if c.WebhookPath != "" && !strings.HasPrefix(c.WebhookPath, "/") {
+		return fmt.Errorf("webhook_path must start with /, not %q", c.WebhookPath)
+	}
+
+	if c.WebhookSecret != "" && !validSecretToken(c.WebhookSecret) {
+		return fmt.Errorf("webhook_secret must be 1-256 letters, digits, _ or -")
+	}
+
+	if c.APIURL != "" {
+		if u, err := url.Parse(c.APIURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("api_url must be an absolute URL, not %q", c.APIURL)
+		}
+	}
+
+	switch c.GroupTrigger {
+	case "", "mention", "always":
+	default:
+		return fmt.Errorf("group_trigger must be mention or always, not %q", c.GroupTrigger)
+	}
+
+	switch c.GroupSession {
+	case "", "group", "user":
+	default:
+		return fmt.Errorf("group_session must be group or user, not %q", c.GroupSession)
+	}
+
 	return nil
 }
+
+// apiURL returns the base URL of the Bot API, without a trailing slash
+func (c *Config) apiURL() string {
+	if c.APIURL == "" {
+		return defaultAPIURL
+	}
+	return strings.TrimRight(c.APIURL, "/")
+}
+
+// maxFileSize returns the largest file downloaded, in bytes
+func (c *Config) maxFileSize() int64 {
+	if c.MaxFileSize <= 0 {
+		return defaultMaxFileSize
+	}
+	return c.MaxFileSize
+}
@@ -0,0 +1,452 @@
+package channels
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	telegrambotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Parse modes replies are formatted for
+const (
+	ParseModeHTML       = telegrambotapi.ModeHTML
+	ParseModeMarkdownV2 = telegrambotapi.ModeMarkdownV2
+	ParseModeNone       = "none"
+)
+
+// normalizeParseMode returns the Telegram parse mode for a configured one,
+// HTML by default and empty for plain text
+func normalizeParseMode(mode string) string {
+	switch strings.ToLower(mode) {
+	case "", "html":
+		return ParseModeHTML
+	case "markdownv2", "markdown":
+		return ParseModeMarkdownV2
+	default:
+		return ""
+	}
+}
+
+// messagePart is one message of a reply, with its source text to fall
+// back to when Telegram rejects the markup
+type messagePart struct {
+	text  string
+	plain string
+}
+
+// formatMessage converts model Markdown to a parse mode and splits it into
+// messages of at most max UTF-16 code units, as Telegram counts them,
+// between paragraphs and code blocks where possible. Every part is valid
+// markup on its own.
+func formatMessage(text, parseMode string, max int) []messagePart {
+	m := markupFor(parseMode)
+
+	var pieces []messagePart
+	for _, b := range parseBlocks(text) {
+		if b.code {
+			render := func(s string) string { return m.pre(b.lang, s) }
+			for _, chunk := range splitToFit(b.body, fitsIn(render, max)) {
+				pieces = append(pieces, messagePart{
+					text:  render(chunk),
+					plain: "```" + b.lang + "\n" + chunk + "\n```",
+				})
+			}
+			continue
+		}
+		render := func(s string) string { return renderText(m, s) }
+		for _, chunk := range splitToFit(b.body, fitsIn(render, max)) {
+			pieces = append(pieces, messagePart{text: render(chunk), plain: chunk})
+		}
+	}
+
+	// Pack pieces into as few messages as fit
+	var parts []messagePart
+	var cur messagePart
+	for _, p := range pieces {
+		if cur.text != "" && utf16Len(cur.text)+2+utf16Len(p.text) <= max {
+			cur.text += "\n\n" + p.text
+			cur.plain += "\n\n" + p.plain
+			continue
+		}
+		if cur.text != "" {
+			parts = append(parts, cur)
+		}
+		cur = p
+	}
+	if cur.text != "" {
+		parts = append(parts, cur)
+	}
+	if len(parts) == 0 {
+		parts = append(parts, messagePart{text: m.escape(text), plain: text})
+	}
+	return parts
+}
+
+// isEntityError reports whether Telegram rejected a message for its markup
+func isEntityError(err error) bool {
+	var apiErr *telegrambotapi.Error
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "can't parse entities")
+}
+
+// block is a paragraph or a fenced code block of Markdown
+type block struct {
+	code bool
+	lang string
+	body string
+}
+
+// parseBlocks splits Markdown into paragraphs and code blocks. An
+// unclosed code block runs to the end.
+func parseBlocks(text string) []block {
+	var blocks []block
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, block{body: strings.Join(para, "\n")})
+			para = nil
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if fence := fenceOf(trimmed); fence != "" {
+			flush()
+			lang := strings.TrimSpace(strings.TrimPrefix(trimmed, fence))
+			var body []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				body = append(body, lines[i])
+			}
+			blocks = append(blocks, block{code: true, lang: lang, body: strings.Join(body, "\n")})
+			continue
+		}
+
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		para = append(para, line)
+	}
+	flush()
+	return blocks
+}
+
+// fenceOf returns the fence opening a code block on a line, if any
+func fenceOf(line string) string {
+	for _, fence := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, fence) {
+			return fence
+		}
+	}
+	return ""
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	bulletPattern  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+)
+
+// renderText renders a paragraph line by line: headings become bold and
+// list bullets dots
+func renderText(m markup, text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if match := headingPattern.FindStringSubmatch(line); match != nil {
+			lines[i] = m.bold(renderInline(m, match[1]))
+		} else if match := bulletPattern.FindStringSubmatch(line); match != nil {
+			lines[i] = m.escape(match[1]+"• ") + renderInline(m, match[2])
+		} else {
+			lines[i] = renderInline(m, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderInline renders code spans, emphasis and links. Markers without a
+// match are kept as text.
+func renderInline(m markup, text string) string {
+	var out, literal strings.Builder
+	emit := func(s string) {
+		out.WriteString(m.escape(literal.String()))
+		literal.Reset()
+		out.WriteString(s)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && isASCIIPunct(rest[1]):
+			literal.WriteByte(rest[1])
+			i += 2
+			continue
+
+		case rest[0] == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+				emit(m.code(rest[1 : 1+end]))
+				i += end + 2
+				continue
+			}
+
+		case rest[0] == '[':
+			if label, url, n, ok := parseLink(rest); ok {
+				emit(m.link(renderInline(m, label), url))
+				i += n
+				continue
+			}
+
+		default:
+			if marker, style := emphasisAt(m, rest); marker != "" {
+				if inner, ok := delimited(text, i, marker); ok {
+					emit(style(renderInline(m, inner)))
+					i += len(marker)*2 + len(inner)
+					continue
+				}
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(rest)
+		literal.WriteString(rest[:size])
+		i += size
+	}
+	emit("")
+	return out.String()
+}
+
+// emphasisAt returns the emphasis marker starting text and its style.
+// Double underscores are not bold, so Python's __init__ reads as written.
+func emphasisAt(m markup, text string) (string, func(string) string) {
+	boldItalic := func(inner string) string { return m.bold(m.italic(inner)) }
+	for _, e := range []struct {
+		marker string
+		style  func(string) string
+	}{
+		{"***", boldItalic}, {"**", m.bold}, {"~~", m.strike}, {"*", m.italic}, {"_", m.italic},
+	} {
+		if strings.HasPrefix(text, e.marker) {
+			return e.marker, e.style
+		}
+	}
+	return "", nil
+}
+
+// delimited returns the text enclosed by a marker at position i. The
+// enclosed text neither starts nor ends with a space or the marker, and
+// underscores only delimit whole words, so snake_case and __dunder__
+// names stay as is.
+func delimited(text string, i int, marker string) (string, bool) {
+	start := i + len(marker)
+	if start >= len(text) || text[start] == ' ' || text[start] == marker[0] {
+		return "", false
+	}
+	if marker[0] == '_' && i > 0 && (isWordByte(text[i-1]) || text[i-1] == '_') {
+		return "", false
+	}
+
+	for j := start + 1; j+len(marker) <= len(text); j++ {
+		if text[j:j+len(marker)] != marker || text[j-1] == ' ' || text[j-1] == marker[0] {
+			continue
+		}
+		// A marker is not part of a longer run of its character
+		end := j + len(marker)
+		if end < len(text) && text[end] == marker[0] {
+			for end < len(text) && text[end] == marker[0] {
+				end++
+			}
+			j = end - 1
+			continue
+		}
+		if marker[0] == '_' && end < len(text) && isWordByte(text[end]) {
+			continue
+		}
+		return text[start:j], true
+	}
+	return "", false
+}
+
+// parseLink parses a [label](url) link at the start of text, returning its
+// length
+func parseLink(text string) (label, url string, n int, ok bool) {
+	closing := strings.Index(text, "](")
+	if closing < 1 || strings.ContainsRune(text[1:closing], '\n') {
+		return "", "", 0, false
+	}
+	// URLs may hold balanced parentheses, as Wikipedia's do
+	end, depth := -1, 0
+	for j, c := range text[closing+2:] {
+		if c == '(' {
+			depth++
+		} else if c == ')' {
+			if depth == 0 {
+				end = j
+				break
+			}
+			depth--
+		}
+	}
+	if end < 1 {
+		return "", "", 0, false
+	}
+	url = text[closing+2 : closing+2+end]
+	if strings.ContainsAny(url, " \n") {
+		return "", "", 0, false
+	}
+	return text[1:closing], url, closing + 3 + end, true
+}
+
+// isWordByte reports whether b is part of an ASCII word
+func isWordByte(b byte) bool {
+	return b < utf8.RuneSelf && (unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b)))
+}
+
+// isASCIIPunct reports whether b can be escaped in Markdown
+func isASCIIPunct(b byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", b) >= 0
+}
+
+// splitToFit splits text into pieces that fit, at line breaks, then
+// spaces, then anywhere
+func splitToFit(text string, fits func(string) bool) []string {
+	if fits(text) {
+		return []string{text}
+	}
+
+	for _, sep := range []string{"\n", " "} {
+		units := strings.Split(text, sep)
+		if len(units) < 2 {
+			continue
+		}
+		var pieces []string
+		cur := units[0]
+		for _, unit := range units[1:] {
+			if fits(cur + sep + unit) {
+				cur += sep + unit
+				continue
+			}
+			pieces = append(pieces, splitToFit(cur, fits)...)
+			cur = unit
+		}
+		return append(pieces, splitToFit(cur, fits)...)
+	}
+
+	// A single word too long for a message: cut at the longest fitting prefix
+	runes := []rune(text)
+	lo, hi := 1, len(runes)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if fits(string(runes[:mid])) {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo >= len(runes) {
+		return []string{text}
+	}
+	return append([]string{string(runes[:lo])}, splitToFit(string(runes[lo:]), fits)...)
+}
+
+// fitsIn returns whether text rendered fits in max UTF-16 code units
+func fitsIn(render func(string) string, max int) func(string) bool {
+	return func(s string) bool {
+		return utf16Len(render(s)) <= max
+	}
+}
+
+// utf16Len returns the length of s in UTF-16 code units, in which
+// Telegram measures messages: characters outside the Basic Multilingual
+// Plane, as most emoji are, count twice
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// markup writes the entities of a parse mode
+type markup interface {
+	escape(text string) string
+	bold(inner string) string
+	italic(inner string) string
+	strike(inner string) string
+	code(text string) string
+	pre(lang, text string) string
+	link(label, url string) string
+}
+
+// markupFor returns the markup of a parse mode
+func markupFor(parseMode string) markup {
+	switch parseMode {
+	case ParseModeHTML:
+		return htmlMarkup{}
+	case ParseModeMarkdownV2:
+		return markdownV2Markup{}
+	default:
+		return plainMarkup{}
+	}
+}
+
+// htmlMarkup writes Telegram HTML
+type htmlMarkup struct{}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func (htmlMarkup) escape(text string) string  { return htmlEscaper.Replace(text) }
+func (htmlMarkup) bold(inner string) string   { return "<b>" + inner + "</b>" }
+func (htmlMarkup) italic(inner string) string { return "<i>" + inner + "</i>" }
+func (htmlMarkup) strike(inner string) string { return "<s>" + inner + "</s>" }
+func (htmlMarkup) code(text string) string    { return "<code>" + htmlEscaper.Replace(text) + "</code>" }
+func (htmlMarkup) link(label, url string) string {
+	return `<a href="` + htmlEscaper.Replace(url) + `">` + label + "</a>"
+}
+func (htmlMarkup) pre(lang, text string) string {
+	if lang == "" {
+		return "<pre>" + htmlEscaper.Replace(text) + "</pre>"
+	}
+	return `<pre><code class="language-` + htmlEscaper.Replace(lang) + `">` + htmlEscaper.Replace(text) + "</code></pre>"
+}
+
+// markdownV2Markup writes Telegram MarkdownV2
+type markdownV2Markup struct{}
+
+var (
+	markdownV2Escaper = strings.NewReplacer(
+		`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+		"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+		"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+	)
+	markdownV2CodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+	markdownV2URLEscaper  = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+)
+
+func (markdownV2Markup) escape(text string) string  { return markdownV2Escaper.Replace(text) }
+func (markdownV2Markup) bold(inner string) string   { return "*" + inner + "*" }
+func (markdownV2Markup) italic(inner string) string { return "_" + inner + "_" }
+func (markdownV2Markup) strike(inner string) string { return "~" + inner + "~" }
+func (markdownV2Markup) code(text string) string {
+	return "`" + markdownV2CodeEscaper.Replace(text) + "`"
+}
+func (markdownV2Markup) link(label, url string) string {
+	return "[" + label + "](" + markdownV2URLEscaper.Replace(url) + ")"
+}
+func (markdownV2Markup) pre(lang, text string) string {
+	return "```" + markdownV2CodeEscaper.Replace(lang) + "\n" + markdownV2CodeEscaper.Replace(text) + "\n```"
+}
+
+// plainMarkup writes text without entities
+type plainMarkup struct{}
+
+func (plainMarkup) escape(text string) string     { return text }
+func (plainMarkup) bold(inner string) string      { return inner }
+func (plainMarkup) italic(inner string) string    { return inner }
+func (plainMarkup) strike(inner string) string    { return inner }
+func (plainMarkup) code(text string) string       { return text }
+func (plainMarkup) pre(lang, text string) string  { return text }
+func (plainMarkup) link(label, url string) string { return label + " (" + url + ")" }
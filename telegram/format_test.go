@@ -0,0 +1,151 @@
+package channels
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFormatMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		markdown string
+		html     string
+	}{
+		{"reserved characters", "1.5 + 2 = 3.5! (a-b) #1 {x|y}",
+			`1\.5 \+ 2 \= 3\.5\! \(a\-b\) \#1 \{x\|y\}`,
+			"1.5 + 2 = 3.5! (a-b) #1 {x|y}"},
+		{"html entities", `a < b & c > "d"`,
+			`a < b & c \> "d"`,
+			"a &lt; b &amp; c &gt; &quot;d&quot;"},
+		{"escaped markers", `\*not italic\*`,
+			`\*not italic\*`,
+			"*not italic*"},
+		{"lone markers", "2 * 3 * 4 and **unclosed",
+			`2 \* 3 \* 4 and \*\*unclosed`,
+			"2 * 3 * 4 and **unclosed"},
+		{"bold and italic", "**bold** *italic* _also_ ~~gone~~",
+			"*bold* _italic_ _also_ ~gone~",
+			"<b>bold</b> <i>italic</i> <i>also</i> <s>gone</s>"},
+		{"nested emphasis", "**bold _italic_ bold**",
+			"*bold _italic_ bold*",
+			"<b>bold <i>italic</i> bold</b>"},
+		{"bold italic", "***both***",
+			"*_both_*",
+			"<b><i>both</i></b>"},
+		{"underscores in words", "call __init__ on snake_case_name",
+			`call \_\_init\_\_ on snake\_case\_name`,
+			"call __init__ on snake_case_name"},
+		{"link", "see [the docs](https://example.com/a_b?x=1)",
+			`see [the docs](https://example.com/a_b?x=1)`,
+			`see <a href="https://example.com/a_b?x=1">the docs</a>`},
+		{"link with parentheses", "[Go (lang)](https://en.wikipedia.org/wiki/Go_(programming_language))",
+			`[Go \(lang\)](https://en.wikipedia.org/wiki/Go_(programming_language\))`,
+			`<a href="https://en.wikipedia.org/wiki/Go_(programming_language)">Go (lang)</a>`},
+		{"code span", "use `a_b*c <d>` here",
+			"use `a_b*c <d>` here",
+			"use <code>a_b*c &lt;d&gt;</code> here"},
+		{"code block", "```go\nfmt.Println(\"<hi>\\n\")\n```",
+			"```go\nfmt.Println(\"<hi>\\\\n\")\n```",
+			`<pre><code class="language-go">fmt.Println(&quot;&lt;hi&gt;\n&quot;)</code></pre>`},
+		{"heading and list", "# Title\n- item *one*",
+			"*Title*\n• item _one_",
+			"<b>Title</b>\n• item <i>one</i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for mode, want := range map[string]string{ParseModeMarkdownV2: tt.markdown, ParseModeHTML: tt.html} {
+				parts := formatMessage(tt.in, mode, maxMessageLength)
+				if len(parts) != 1 {
+					t.Fatalf("%s: got %d parts, want 1", mode, len(parts))
+				}
+				if parts[0].text != want {
+					t.Errorf("%s:\n got %q\nwant %q", mode, parts[0].text, want)
+				}
+				if parts[0].plain != tt.in {
+					t.Errorf("%s: plain = %q, want the source", mode, parts[0].plain)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatMessageSplits(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		mode string
+		max  int
+		want []string
+	}{
+		{"paragraphs packed", "one\n\ntwo", ParseModeHTML, 100, []string{"one\n\ntwo"}},
+		{"between paragraphs", "aaaa bbbb\n\ncccc", ParseModeHTML, 9, []string{"aaaa bbbb", "cccc"}},
+		{"code block in html", "```\nline one\nline two\n```", ParseModeHTML, 20,
+			[]string{"<pre>line one</pre>", "<pre>line two</pre>"}},
+		{"code block in markdown", "```\nline one\nline two\n```", ParseModeMarkdownV2, 20,
+			[]string{"```\nline one\n```", "```\nline two\n```"}},
+		{"escapes kept whole", "a.b.c.d", ParseModeMarkdownV2, 4, []string{`a\.b`, `\.c`, `\.d`}},
+		{"entities kept whole", "x < y\nz", ParseModeHTML, 8, []string{"x &lt; y", "z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, part := range formatMessage(tt.in, tt.mode, tt.max) {
+				got = append(got, part.text)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("parts = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatMessageSplitsOnRunes(t *testing.T) {
+	text := strings.Repeat("日本", maxMessageLength/2+10)
+	for _, mode := range []string{ParseModeHTML, ParseModeMarkdownV2, ""} {
+		parts := formatMessage(text, mode, maxMessageLength)
+		if len(parts) != 2 {
+			t.Errorf("mode %q: got %d parts, want 2", mode, len(parts))
+		}
+		total := 0
+		for _, part := range parts {
+			if !utf8.ValidString(part.text) || utf16Len(part.text) > maxMessageLength {
+				t.Errorf("mode %q: part of %d units, over %d or cut in a character", mode, utf16Len(part.text), maxMessageLength)
+			}
+			total += utf8.RuneCountInString(part.plain)
+		}
+		if total != maxMessageLength+20 {
+			t.Errorf("mode %q: parts have %d characters, want %d", mode, total, maxMessageLength+20)
+		}
+	}
+}
+
+func TestFormatMessageCountsUTF16(t *testing.T) {
+	// Emoji outside the Basic Multilingual Plane take two UTF-16 units
+	text := strings.Repeat("😀", 3000)
+	if n := utf16Len(text); n != 6000 {
+		t.Fatalf("utf16Len = %d, want 6000", n)
+	}
+	for _, mode := range []string{ParseModeHTML, ParseModeMarkdownV2, ""} {
+		parts := formatMessage(text, mode, maxMessageLength)
+		if len(parts) != 2 {
+			t.Errorf("mode %q: got %d parts, want 2", mode, len(parts))
+		}
+		total := 0
+		for _, part := range parts {
+			if utf16Len(part.text) > maxMessageLength {
+				t.Errorf("mode %q: part of %d units, over %d", mode, utf16Len(part.text), maxMessageLength)
+			}
+			total += utf8.RuneCountInString(part.plain)
+		}
+		if total != 3000 {
+			t.Errorf("mode %q: parts have %d characters, want 3000", mode, total)
+		}
+	}
+
+	// A streamed preview keeps the end of the reply within the limit
+	if n := utf16Len(preview(text)); n > maxMessageLength {
+		t.Errorf("preview of %d units, over %d", n, maxMessageLength)
+	}
+}
@@ -0,0 +1,113 @@
+package channels
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxQuoteLength is the longest quoted message included with a reply, in
+// characters
+const maxQuoteLength = 1000
+
+// target returns the channel target of a message, which keys its agent
+// session: the chat, or the user within a group when sessions are per user
+func (h *Handler) target(msg *IncomingMessage) string {
+	if msg.Chat.IsGroup() && h.bot.config.GroupSession == "user" && msg.From != nil {
+		return fmt.Sprintf("%d:%d", msg.Chat.ID, msg.From.ID)
+	}
+	return strconv.FormatInt(msg.Chat.ID, 10)
+}
+
+// parseChatID returns the chat of a target
+func parseChatID(target string) (int64, error) {
+	chat, _, _ := strings.Cut(target, ":")
+	chatID, err := strconv.ParseInt(chat, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chat ID: %w", err)
+	}
+	return chatID, nil
+}
+
+// addressed reports whether a message is for the bot, returning its text
+// without the mention or keyword addressing it. Private chats always are;
+// in groups the bot must be mentioned, replied to or called by a keyword,
+// unless GroupTrigger is always.
+func (h *Handler) addressed(msg *IncomingMessage) (bool, string) {
+	text := msg.GetContent()
+	if !msg.Chat.IsGroup() {
+		return true, text
+	}
+
+	// @username anywhere in the text
+	if name := h.bot.api.Self.UserName; name != "" {
+		mention := "@" + name
+		if i := mentionIndex(text, mention); i >= 0 {
+			before, after := strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+len(mention):])
+			return true, strings.TrimSpace(before + " " + after)
+		}
+	}
+
+	// A reply to one of the bot's messages
+	if msg.ReplyTo != nil && msg.ReplyTo.From != nil && msg.ReplyTo.From.ID == h.bot.api.Self.ID {
+		return true, text
+	}
+
+	// A keyword prefix, as "claw, what time is it?"
+	for _, keyword := range h.bot.config.GroupKeywords {
+		if keyword != "" && len(text) >= len(keyword) && strings.EqualFold(text[:len(keyword)], keyword) {
+			return true, strings.TrimSpace(text[len(keyword):])
+		}
+	}
+
+	return h.bot.config.GroupTrigger == "always", text
+}
+
+// indexFold returns the index of the first instance of an ASCII string in
+// text, ignoring case, or -1
+func indexFold(text, sub string) int {
+	for i := 0; i+len(sub) <= len(text); i++ {
+		if strings.EqualFold(text[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// mentionIndex returns the index of the first mention in text, ignoring
+// case and the mentions of longer usernames starting with it, or -1
+func mentionIndex(text, mention string) int {
+	for start := 0; start < len(text); {
+		i := indexFold(text[start:], mention)
+		if i < 0 {
+			return -1
+		}
+		end := start + i + len(mention)
+		if end == len(text) || !isUsernameChar(text[end]) {
+			return start + i
+		}
+		start = end
+	}
+	return -1
+}
+
+// isUsernameChar reports whether c may be part of a Telegram username
+func isUsernameChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// withQuote prefixes text with the message it replies to, so the agent
+// knows what "this" refers to
+func (h *Handler) withQuote(msg *IncomingMessage, text string) string {
+	quoted := msg.ReplyTo
+	if quoted == nil || quoted.GetContent() == "" {
+		return text
+	}
+
+	content := truncateString(quoted.GetContent(), maxQuoteLength)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return fmt.Sprintf("In reply to %s:\n%s\n\n%s", quoted.From.String(), strings.Join(lines, "\n"), text)
+}
@@ -0,0 +1,92 @@
+package channels
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// receive passes a message through the handler, returning what the
+// message handler got, nil for messages not answered
+func receive(t *testing.T, b *Bot, msg *IncomingMessage) *channels.Message {
+	t.Helper()
+	var got *channels.Message
+	b.SetMessageHandler(func(ctx context.Context, msg *channels.Message) error {
+		got = msg
+		return nil
+	})
+	if msg.From == nil {
+		msg.From = &UserInfo{ID: 3, FirstName: "Ann"}
+	}
+	if err := b.handler.handleMessage(context.Background(), msg); err != nil {
+		t.Fatalf("handleMessage: %v", err)
+	}
+	return got
+}
+
+func TestGroupMessagesAddressed(t *testing.T) {
+	b, _ := newTestBot(t, &Config{GroupKeywords: []string{"claw,"}})
+	group := &ChatInfo{ID: -100, Type: "supergroup", Title: "Team"}
+	bot := &UserInfo{ID: botUserID, FirstName: "Test", Username: "test_bot", IsBot: true}
+	other := &UserInfo{ID: 4, FirstName: "Bob"}
+
+	tests := []struct {
+		name string
+		msg  *IncomingMessage
+		want string // content passed on, empty when ignored
+	}{
+		{"chatter", &IncomingMessage{Chat: group, Text: "lunch anyone?"}, ""},
+		{"mention first", &IncomingMessage{Chat: group, Text: "@test_bot what time is it?"}, "what time is it?"},
+		{"mention within", &IncomingMessage{Chat: group, Text: "tell me @Test_Bot the time"}, "tell me the time"},
+		{"mention of another bot", &IncomingMessage{Chat: group, Text: "@test_bot_two hi"}, ""},
+		{"mention after another bot", &IncomingMessage{Chat: group, Text: "@test_bots or @test_bot?"}, "@test_bots or ?"},
+		{"keyword", &IncomingMessage{Chat: group, Text: "Claw, what time is it?"}, "what time is it?"},
+		{"reply to the bot", &IncomingMessage{Chat: group, Text: "and tomorrow?",
+			ReplyTo: &IncomingMessage{MessageID: 9, From: bot, Chat: group, Text: "Sunny"}},
+			"In reply to @test_bot:\n> Sunny\n\nand tomorrow?"},
+		{"reply to another user", &IncomingMessage{Chat: group, Text: "agreed",
+			ReplyTo: &IncomingMessage{MessageID: 9, From: other, Chat: group, Text: "Pizza"}}, ""},
+		{"private chat", &IncomingMessage{Chat: &ChatInfo{ID: 3, Type: "private"}, Text: "hello"}, "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := receive(t, b, tt.msg)
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("answered %q, want the message ignored", got.Content)
+			case tt.want != "" && got == nil:
+				t.Error("ignored")
+			case got != nil && got.Content != tt.want:
+				t.Errorf("content = %q, want %q", got.Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupTriggerAlways(t *testing.T) {
+	b, _ := newTestBot(t, &Config{GroupTrigger: "always"})
+	got := receive(t, b, &IncomingMessage{Chat: &ChatInfo{ID: -100, Type: "group"}, Text: "lunch anyone?"})
+	if got == nil || got.Content != "lunch anyone?" {
+		t.Fatalf("got %+v, want every message answered", got)
+	}
+	if !got.IsGroup || got.GroupID != "-100" || got.To != "-100" {
+		t.Errorf("group %v %q to %q, want group -100", got.IsGroup, got.GroupID, got.To)
+	}
+}
+
+func TestGroupSessionPerUser(t *testing.T) {
+	b, _ := newTestBot(t, &Config{GroupSession: "user", AllowedGroups: []int64{-100}})
+	got := receive(t, b, &IncomingMessage{Chat: &ChatInfo{ID: -100, Type: "group"}, Text: "@test_bot hi"})
+	if got == nil || got.To != "-100:3" {
+		t.Fatalf("got %+v, want the user within the group as target", got)
+	}
+	if chatID, err := parseChatID(got.To); err != nil || chatID != -100 {
+		t.Errorf("parseChatID(%q) = %d, %v, want the group", got.To, chatID, err)
+	}
+
+	// Groups not allowed are ignored, even when addressed
+	if got := receive(t, b, &IncomingMessage{Chat: &ChatInfo{ID: -200, Type: "group"}, Text: "@test_bot hi"}); got != nil {
+		t.Errorf("message from a group not allowed answered: %q", got.Content)
+	}
+}
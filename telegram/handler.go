@@ -83,6 +83,7 @@ func (h *Handler) convertMessage(apiMsg *telegrambotapi.Message) *IncomingMessag
 	}
 
 	msg.MediaGroupID = apiMsg.MediaGroupID
+	msg.Media = h.convertMedia(apiMsg)
 
 	return msg
 }
@@ -151,14 +152,41 @@ func (h *Handler) handleMessage(ctx context.Context, msg *IncomingMessage) error
 		return nil
 	}
 
-	// Convert to channel message
-	channelMsg := h.toChannelMessage(msg)
+	// Run bot commands here rather than sending them to the agent
+	if h.handleCommand(ctx, msg) {
+		return nil
+	}
+
+	// In groups, answer only the messages addressed to the bot
+	addressed, text := h.addressed(msg)
+	if !addressed {
+		return nil
+	}
 
-	// Send typing indicator if it's a direct message
-	if !msg.Chat.IsGroup() {
+	// Photos and documents are attached, voice messages transcribed
+	var attachments []*channels.Attachment
+	if msg.Media != nil {
 		h.sendTypingIndicator(msg.Chat.ID)
+		attachment, transcript, err := h.readMedia(ctx, msg.Media)
+		if err != nil {
+			log.Printf("⚠️  Failed to read %s from %s: %v", msg.Media.Type, msg.From.String(), err)
+			return h.bot.SendMessage(ctx, msg.Chat.ID, h.mediaError(msg.Media, err), &SendOptions{ReplyTo: msg.MessageID})
+		}
+		if attachment != nil {
+			attachments = append(attachments, attachment)
+		}
+		text = strings.TrimSpace(text + "\n\n" + transcript)
 	}
 
+	// Convert to channel message
+	channelMsg := h.toChannelMessage(msg, text, attachments)
+	if strings.TrimSpace(channelMsg.Content) == "" && len(attachments) == 0 {
+		return nil
+	}
+
+	// Send typing indicator
+	h.sendTypingIndicator(msg.Chat.ID)
+
 	// Call registered message handler
 	handler := h.bot.GetMessageHandler()
 	if handler != nil {
@@ -175,15 +203,34 @@ func (h *Handler) handleMessage(ctx context.Context, msg *IncomingMessage) error
 	return nil
 }
 
-// toChannelMessage converts an IncomingMessage to channels.Message
-func (h *Handler) toChannelMessage(msg *IncomingMessage) *channels.Message {
+// toChannelMessage converts an IncomingMessage to channels.Message, with
+// its text as addressed to the bot and the message it replies to quoted
+func (h *Handler) toChannelMessage(msg *IncomingMessage, text string, attachments []*channels.Attachment) *channels.Message {
+	msgType := channels.MessageTypeText
+	if msg.Media != nil {
+		switch msg.Media.Type {
+		case "photo":
+			msgType = channels.MessageTypeImage
+		case "document":
+			msgType = channels.MessageTypeFile
+		case "voice":
+			msgType = channels.MessageTypeAudio
+		}
+	}
+
 	replyTo := ""
 	if msg.ReplyTo != nil {
 		replyTo = fmt.Sprintf("%d", msg.ReplyTo.MessageID)
 	}
 
+	groupID := ""
+	if msg.Chat.IsGroup() {
+		groupID = fmt.Sprintf("%d", msg.Chat.ID)
+	}
+
 	metadata := make(map[string]interface{})
 	metadata["telegram_message_id"] = msg.MessageID
+	metadata["telegram_chat_id"] = msg.Chat.ID
 	if msg.EditDate != nil {
 		metadata["edited"] = true
 	}
@@ -196,14 +243,15 @@ func (h *Handler) toChannelMessage(msg *IncomingMessage) *channels.Message {
 		Channel:  "telegram",
 		From:     fmt.Sprintf("%d", msg.From.ID),
 		FromName: msg.From.String(),
-		To:       fmt.Sprintf("%d", msg.Chat.ID),
+		To:       h.target(msg),
 		IsGroup:  msg.Chat.IsGroup(),
-		GroupID:  "",
-		Content:  msg.GetContent(),
-		Type:     channels.MessageTypeText,
+		GroupID:  groupID,
+		Content:  h.withQuote(msg, text),
+		Type:     msgType,
 		Timestamp: msg.Date,
 		ReplyTo:  replyTo,
 		Metadata: metadata,
+		Attachments: attachments,
 	}
 }
 
@@ -248,8 +296,9 @@ func (h *Handler) HandleCallbackQuery(update *telegrambotapi.Update) {
 	}
 
 	callback := update.CallbackQuery
-	if callback.Message == nil {
-		// Answer callback query even if there's no message
+	if callback.Message == nil || !h.isUserAllowed(callback.From.ID) ||
+		(callback.Message.Chat.IsGroup() && !h.isGroupAllowed(callback.Message.Chat.ID)) {
+		// Answer callback query even if there's nothing to do
 		callbackConfig := telegrambotapi.NewCallback(callback.ID, "")
 		_, _ = h.bot.api.Request(callbackConfig)
 		return
@@ -261,50 +310,33 @@ func (h *Handler) HandleCallbackQuery(update *telegrambotapi.Update) {
 		log.Printf("🔘 Callback query from %s: %s", callback.From.UserName, data)
 	}
 
-	// Answer callback query
-	callbackConfig := telegrambotapi.NewCallback(callback.ID, "")
-	if _, err := h.bot.api.Request(callbackConfig); err != nil {
-		log.Printf("❌ Error answering callback query: %v", err)
-	}
-
-	// Handle callback data
-	h.handleCallback(callback, data)
-}
-
-// handleCallback processes a callback query
-func (h *Handler) handleCallback(callback *telegrambotapi.CallbackQuery, data string) {
-	// Extract command and parameters
-	parts := strings.SplitN(data, ":", 2)
-	if len(parts) == 0 {
+	// Buttons of the bot itself
+	if h.handleCallback(callback, data) {
 		return
 	}
 
-	command := parts[0]
+	// Others are actions for the agent
+	h.handleAction(callback)
+}
 
-	switch command {
+// handleCallback processes a callback query of the bot's own buttons,
+// returning false for others
+func (h *Handler) handleCallback(callback *telegrambotapi.CallbackQuery, data string) bool {
+	switch data {
 	case "help":
+		h.answerCallback(callback, "")
 		// Show help message
 		chatID := callback.Message.Chat.ID
-		_ = h.bot.SendMessage(context.Background(), chatID, getHelpText(), nil)
-	default:
-		if h.bot.config.Debug {
-			log.Printf("ℹ️  Unknown callback command: %s", command)
-		}
+		_ = h.bot.SendMessage(context.Background(), chatID, h.helpText(), nil)
+		return true
 	}
+	return false
 }
 
-// getHelpText returns help message
-func getHelpText() string {
-	return "🤖 *Telegram Bot Help*\n\n" +
-		"I'm an AI assistant connected to OpenClaw. Here's what I can do:\n\n" +
-		"*Commands:*\n" +
-		"• /help - Show this help message\n" +
-		"• /start - Start a conversation\n" +
-		"• /status - Check bot status\n\n" +
-		"*Features:*\n" +
-		"• ✅ Send text messages\n" +
-		"• ✅ Reply to messages\n" +
-		"• ✅ Work in private chats\n" +
-		"• ✅ Work in groups\n\n" +
-		"Just send me a message and I'll respond!"
+// answerCallback answers a callback query, showing text in a notification
+func (h *Handler) answerCallback(callback *telegrambotapi.CallbackQuery, text string) {
+	callbackConfig := telegrambotapi.NewCallback(callback.ID, text)
+	if _, err := h.bot.api.Request(callbackConfig); err != nil {
+		log.Printf("❌ Error answering callback query: %v", err)
+	}
 }
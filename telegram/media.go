@@ -0,0 +1,163 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	telegrambotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+var (
+	// errFileTooLarge is returned for files over Config.MaxFileSize
+	errFileTooLarge = errors.New("file is too large")
+
+	// errNoTranscript is returned for voice messages without a transcript
+	errNoTranscript = errors.New("voice message could not be transcribed")
+)
+
+// convertMedia returns the photo, document or voice message of a Telegram
+// API message, nil if it has none of them
+func (h *Handler) convertMedia(apiMsg *telegrambotapi.Message) *MediaInfo {
+	switch {
+	case len(apiMsg.Photo) > 0:
+		// Photos come in several sizes, the largest last; take the
+		// largest that may be downloaded
+		photo := apiMsg.Photo[0]
+		for _, size := range apiMsg.Photo[1:] {
+			if int64(size.FileSize) <= h.bot.config.maxFileSize() {
+				photo = size
+			}
+		}
+		return &MediaInfo{
+			Type:     "photo",
+			FileID:   photo.FileID,
+			MimeType: "image/jpeg",
+			FileSize: int64(photo.FileSize),
+		}
+	case apiMsg.Document != nil:
+		return &MediaInfo{
+			Type:     "document",
+			FileID:   apiMsg.Document.FileID,
+			FileName: apiMsg.Document.FileName,
+			MimeType: apiMsg.Document.MimeType,
+			FileSize: int64(apiMsg.Document.FileSize),
+		}
+	case apiMsg.Voice != nil:
+		return &MediaInfo{
+			Type:     "voice",
+			FileID:   apiMsg.Voice.FileID,
+			MimeType: apiMsg.Voice.MimeType,
+			FileSize: int64(apiMsg.Voice.FileSize),
+		}
+	}
+	return nil
+}
+
+// readMedia downloads the media of a message: photos and documents become
+// attachments, voice messages their transcript
+func (h *Handler) readMedia(ctx context.Context, media *MediaInfo) (*channels.Attachment, string, error) {
+	transcriber := h.bot.config.Transcriber
+	if _, nop := transcriber.(channels.NopTranscriber); media.Type == "voice" && (nop || transcriber == nil) {
+		return nil, "", errNoTranscript
+	}
+
+	data, err := h.bot.downloadFile(ctx, media.FileID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch media.Type {
+	case "voice":
+		text, err := transcriber.Transcribe(ctx, data, media.MimeType)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to transcribe voice message: %w", err)
+		}
+		if text == "" {
+			return nil, "", errNoTranscript
+		}
+		return nil, text, nil
+	case "photo":
+		return &channels.Attachment{
+			Type:     channels.MessageTypeImage,
+			Name:     "photo.jpg",
+			MimeType: media.MimeType,
+			Data:     data,
+		}, "", nil
+	default:
+		attachment := &channels.Attachment{
+			Type:     channels.MessageTypeFile,
+			Name:     media.FileName,
+			MimeType: media.MimeType,
+			Data:     data,
+		}
+		// Images sent as files keep their quality, and are still images
+		switch media.MimeType {
+		case "image/jpeg", "image/png", "image/gif", "image/webp":
+			attachment.Type = channels.MessageTypeImage
+		}
+		return attachment, "", nil
+	}
+}
+
+// mediaError returns what the user is told when their media cannot be read
+func (h *Handler) mediaError(media *MediaInfo, err error) string {
+	switch {
+	case errors.Is(err, errFileTooLarge):
+		maxSize := h.bot.config.maxFileSize()
+		limit := fmt.Sprintf("%d MB", maxSize>>20)
+		if maxSize < 1<<20 {
+			limit = fmt.Sprintf("%d KB", maxSize>>10)
+		}
+		return fmt.Sprintf("⚠️ That %s is too large, I can read files up to %s.", media.Type, limit)
+	case errors.Is(err, errNoTranscript):
+		return "⚠️ I can't listen to voice messages, please send text."
+	default:
+		return fmt.Sprintf("⚠️ Sorry, I couldn't read that %s.", media.Type)
+	}
+}
+
+// downloadFile downloads a file through the file endpoint of the Bot API
+func (b *Bot) downloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	maxSize := b.config.maxFileSize()
+
+	file, err := b.api.GetFile(telegrambotapi.FileConfig{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	if int64(file.FileSize) > maxSize {
+		return nil, errFileTooLarge
+	}
+
+	// The URL holds the bot token, kept out of errors
+	fileURL := fmt.Sprintf("%s/file/bot%s/%s", b.config.apiURL(), b.config.BotToken, file.FilePath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: invalid file path %q", file.FilePath)
+	}
+	resp, err := b.api.Client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errFileTooLarge
+	}
+	return data, nil
+}
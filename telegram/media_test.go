@@ -0,0 +1,205 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	telegrambotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/openclaw/go-openclaw/pkg/channels"
+	"github.com/openclaw/go-openclaw/pkg/channels/channeltest"
+)
+
+// transcriberFunc is a Transcriber calling a function
+type transcriberFunc func(audio []byte, mimeType string) (string, error)
+
+func (f transcriberFunc) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	return f(audio, mimeType)
+}
+
+// serveFile makes the Bot API stand-in serve a file, with the size
+// getFile reports
+func serveFile(api *channeltest.StandIn, fileID string, size int, data []byte) {
+	path := "files/" + fileID
+	api.Handle("/getFile", func(r *channeltest.Request) (int, interface{}) {
+		if r.Form.Get("file_id") != fileID {
+			return apiError(400, "Bad Request: invalid file_id")
+		}
+		return ok(map[string]interface{}{"file_id": fileID, "file_size": size, "file_path": path})
+	})
+	api.Handle("/file/bot123:abc/"+path, func(r *channeltest.Request) (int, interface{}) {
+		return http.StatusOK, data
+	})
+}
+
+func TestConvertMedia(t *testing.T) {
+	b, _ := newTestBot(t, &Config{MaxFileSize: 1000})
+	media := b.handler.convertMedia(&telegrambotapi.Message{Photo: []telegrambotapi.PhotoSize{
+		{FileID: "small", FileSize: 100},
+		{FileID: "medium", FileSize: 900},
+		{FileID: "large", FileSize: 5000},
+	}})
+	if media == nil || media.Type != "photo" || media.FileID != "medium" {
+		t.Errorf("photo = %+v, want the largest size within the limit", media)
+	}
+
+	media = b.handler.convertMedia(&telegrambotapi.Message{Document: &telegrambotapi.Document{
+		FileID: "doc", FileName: "notes.txt", MimeType: "text/plain", FileSize: 12,
+	}})
+	if media == nil || media.Type != "document" || media.FileName != "notes.txt" || media.MimeType != "text/plain" {
+		t.Errorf("document = %+v", media)
+	}
+
+	media = b.handler.convertMedia(&telegrambotapi.Message{Voice: &telegrambotapi.Voice{FileID: "voice", MimeType: "audio/ogg"}})
+	if media == nil || media.Type != "voice" || media.MimeType != "audio/ogg" {
+		t.Errorf("voice = %+v", media)
+	}
+
+	if media := b.handler.convertMedia(&telegrambotapi.Message{Text: "hi"}); media != nil {
+		t.Errorf("text message has media %+v", media)
+	}
+}
+
+func TestReadMedia(t *testing.T) {
+	b, api := newTestBot(t, &Config{})
+	ctx := context.Background()
+
+	serveFile(api, "photo", 4, []byte("jpeg"))
+	attachment, text, err := b.handler.readMedia(ctx, &MediaInfo{Type: "photo", FileID: "photo", MimeType: "image/jpeg"})
+	if err != nil {
+		t.Fatalf("photo: %v", err)
+	}
+	if attachment.Type != channels.MessageTypeImage || string(attachment.Data) != "jpeg" || text != "" {
+		t.Errorf("photo = %+v %q, want an image attachment", attachment, text)
+	}
+
+	serveFile(api, "doc", 3, []byte("pdf"))
+	attachment, _, err = b.handler.readMedia(ctx, &MediaInfo{Type: "document", FileID: "doc", FileName: "a.pdf", MimeType: "application/pdf"})
+	if err != nil {
+		t.Fatalf("document: %v", err)
+	}
+	if attachment.Type != channels.MessageTypeFile || attachment.Name != "a.pdf" || string(attachment.Data) != "pdf" {
+		t.Errorf("document = %+v, want a file attachment", attachment)
+	}
+
+	// Images sent as files are still images
+	serveFile(api, "png", 3, []byte("png"))
+	attachment, _, err = b.handler.readMedia(ctx, &MediaInfo{Type: "document", FileID: "png", FileName: "a.png", MimeType: "image/png"})
+	if err != nil || attachment.Type != channels.MessageTypeImage {
+		t.Errorf("image document = %+v, %v, want an image attachment", attachment, err)
+	}
+}
+
+func TestReadVoice(t *testing.T) {
+	b, api := newTestBot(t, &Config{})
+	ctx := context.Background()
+	voice := &MediaInfo{Type: "voice", FileID: "voice", MimeType: "audio/ogg"}
+
+	// Without a transcriber voice messages are not even downloaded
+	if _, _, err := b.handler.readMedia(ctx, voice); !errors.Is(err, errNoTranscript) {
+		t.Errorf("voice without a transcriber: %v, want errNoTranscript", err)
+	}
+	if n := len(api.Requests("/getFile")); n != 0 {
+		t.Errorf("%d getFile requests without a transcriber, want none", n)
+	}
+
+	serveFile(api, "voice", 3, []byte("ogg"))
+	transcript := "hello there"
+	b.config.Transcriber = transcriberFunc(func(audio []byte, mimeType string) (string, error) {
+		if string(audio) != "ogg" || mimeType != "audio/ogg" {
+			t.Errorf("transcribed %q of %s", audio, mimeType)
+		}
+		return transcript, nil
+	})
+	attachment, text, err := b.handler.readMedia(ctx, voice)
+	if err != nil || attachment != nil || text != "hello there" {
+		t.Errorf("voice = %+v %q %v, want the transcript", attachment, text, err)
+	}
+
+	transcript = ""
+	if _, _, err := b.handler.readMedia(ctx, voice); !errors.Is(err, errNoTranscript) {
+		t.Errorf("voice without speech: %v, want errNoTranscript", err)
+	}
+}
+
+func TestDownloadFileLimits(t *testing.T) {
+	b, api := newTestBot(t, &Config{MaxFileSize: 512 << 10})
+	ctx := context.Background()
+
+	// Files too large as reported by getFile are not downloaded
+	serveFile(api, "big", 1<<20, nil)
+	if _, err := b.downloadFile(ctx, "big"); !errors.Is(err, errFileTooLarge) {
+		t.Errorf("large file: %v, want errFileTooLarge", err)
+	}
+	if n := len(api.Requests("/files/big")); n != 0 {
+		t.Errorf("large file downloaded %d times", n)
+	}
+
+	// Nor are files larger than reported
+	serveFile(api, "lying", 10, make([]byte, 512<<10+1))
+	if _, err := b.downloadFile(ctx, "lying"); !errors.Is(err, errFileTooLarge) {
+		t.Errorf("file larger than reported: %v, want errFileTooLarge", err)
+	}
+
+	serveFile(api, "exact", 512<<10, make([]byte, 512<<10))
+	if data, err := b.downloadFile(ctx, "exact"); err != nil || len(data) != 512<<10 {
+		t.Errorf("file at the limit: %d bytes, %v", len(data), err)
+	}
+
+	msg := b.handler.mediaError(&MediaInfo{Type: "document"}, errFileTooLarge)
+	if msg != "⚠️ That document is too large, I can read files up to 512 KB." {
+		t.Errorf("mediaError = %q", msg)
+	}
+}
+
+func TestDownloadFileErrors(t *testing.T) {
+	b, api := newTestBot(t, &Config{})
+	ctx := context.Background()
+
+	if _, err := b.downloadFile(ctx, "unknown"); err == nil || !strings.Contains(err.Error(), "failed to get file") {
+		t.Errorf("unknown file: %v, want the getFile error", err)
+	}
+
+	serveFile(api, "gone", 10, nil)
+	api.Handle("/file/bot123:abc/files/gone", func(r *channeltest.Request) (int, interface{}) {
+		return http.StatusNotFound, []byte("Not Found")
+	})
+	_, err := b.downloadFile(ctx, "gone")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("missing file: %v, want the status", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "123:abc") {
+		t.Errorf("error %q holds the bot token", err)
+	}
+}
+
+func TestMediaMessages(t *testing.T) {
+	b, api := newTestBot(t, &Config{MaxFileSize: 1 << 20})
+	serveFile(api, "photo", 4, []byte("jpeg"))
+
+	got := receive(t, b, &IncomingMessage{
+		MessageID: 5,
+		Chat:      &ChatInfo{ID: 42, Type: "private"},
+		Caption:   "what is this?",
+		Media:     &MediaInfo{Type: "photo", FileID: "photo", MimeType: "image/jpeg"},
+	})
+	if got == nil || got.Type != channels.MessageTypeImage || got.Content != "what is this?" || len(got.Attachments) != 1 {
+		t.Fatalf("got %+v, want the caption with the photo", got)
+	}
+
+	// Media that cannot be read is answered with the reason
+	api.Reset()
+	got = receive(t, b, &IncomingMessage{
+		MessageID: 6,
+		Chat:      &ChatInfo{ID: 42, Type: "private"},
+		Media:     &MediaInfo{Type: "voice", FileID: "voice", MimeType: "audio/ogg"},
+	})
+	if got != nil {
+		t.Errorf("voice message without a transcriber passed on: %+v", got)
+	}
+	if replies := texts(api.Requests("/sendMessage")); len(replies) != 1 || !strings.Contains(replies[0], "voice messages") {
+		t.Errorf("replied %q, want the voice message declined", replies)
+	}
+}
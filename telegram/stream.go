@@ -0,0 +1,243 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	telegrambotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+const (
+	// maxMessageLength is the longest text Telegram accepts in a message
+	maxMessageLength = 4096
+
+	// streamPlaceholder is shown until the first part of a reply arrives
+	streamPlaceholder = "…"
+
+	// streamCursor ends a reply while it is generated
+	streamCursor = " ▍"
+)
+
+// SendStream posts a placeholder message and edits it as the reply is
+// generated, at most once per EditInterval
+func (b *Bot) SendStream(ctx context.Context, target string, options map[string]interface{}) (channels.Stream, error) {
+	chatID, err := parseChatID(target)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := telegrambotapi.NewMessage(chatID, streamPlaceholder)
+	if replyTo, ok := options["reply_to"].(float64); ok {
+		msg.ReplyToMessageID = int(replyTo)
+	}
+	sent, err := b.api.Send(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	interval := time.Duration(b.config.EditInterval) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultEditInterval * time.Millisecond
+	}
+
+	s := &stream{
+		bot:       b,
+		chatID:    chatID,
+		messageID: sent.MessageID,
+		interval:  interval,
+		shown:     streamPlaceholder,
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go s.loop(ctx)
+	return s, nil
+}
+
+// stream is a reply edited in place while it is generated
+type stream struct {
+	bot       *Bot
+	chatID    int64
+	messageID int
+	interval  time.Duration
+
+	mu      sync.Mutex
+	text    strings.Builder
+	shown   string    // text of the message as last edited
+	next    time.Time // no edit before, when Telegram asked to wait
+	closed  bool
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// Append adds generated text, shown with the next edit
+func (s *stream) Append(delta string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("reply is complete")
+	}
+	s.text.WriteString(delta)
+	return nil
+}
+
+// Close shows the full reply, continued in new messages when it is too
+// long for one, or sent anew when the placeholder cannot be edited.
+// Without text the placeholder is removed.
+func (s *stream) Close(text string) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.done)
+	<-s.stopped
+
+	if text == "" {
+		_, err := s.bot.api.Request(telegrambotapi.NewDeleteMessage(s.chatID, s.messageID))
+		return err
+	}
+
+	parseMode := normalizeParseMode(s.bot.config.ParseMode)
+	parts := formatMessage(text, parseMode, maxMessageLength)
+	if err := s.edit(parts[0], parseMode, true); err != nil {
+		if !isNotEditable(err) {
+			return fmt.Errorf("failed to edit message: %w", err)
+		}
+		// Without edits the reply is sent in full, in place of the placeholder
+		if _, err := s.bot.api.Request(telegrambotapi.NewDeleteMessage(s.chatID, s.messageID)); err != nil {
+			log.Printf("⚠️  Failed to remove placeholder in chat %d: %v", s.chatID, err)
+		}
+		return s.bot.SendMessage(context.Background(), s.chatID, text, nil)
+	}
+	// Each remaining part is formatted again to the same single message
+	for _, part := range parts[1:] {
+		if err := s.bot.SendMessage(context.Background(), s.chatID, part.plain, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loop edits the message with the text generated so far, once per interval
+func (s *stream) loop(ctx context.Context) {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			text := s.text.String()
+			wait := time.Now().Before(s.next)
+			s.mu.Unlock()
+
+			if text == "" || wait {
+				continue
+			}
+			shown := preview(text)
+			err := s.edit(messagePart{text: shown, plain: shown}, "", false)
+			if isNotEditable(err) {
+				log.Printf("⚠️  Streamed reply in chat %d cannot be edited, sending it when complete", s.chatID)
+				return
+			}
+			if err != nil {
+				log.Printf("⚠️  Failed to update streamed reply in chat %d: %v", s.chatID, err)
+			}
+		}
+	}
+}
+
+// edit replaces the text of the message, unless it is shown already. A
+// final edit waits out flood control once, and falls back to plain text
+// if Telegram rejects the markup; progressive edits are put off.
+func (s *stream) edit(part messagePart, parseMode string, final bool) error {
+	s.mu.Lock()
+	if part.text == s.shown {
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+
+	msg := telegrambotapi.NewEditMessageText(s.chatID, s.messageID, part.text)
+	msg.ParseMode = parseMode
+	_, err := s.bot.api.Request(msg)
+	if wait := retryAfter(err); wait > 0 {
+		if !final {
+			s.mu.Lock()
+			s.next = time.Now().Add(wait)
+			s.mu.Unlock()
+			return nil
+		}
+		time.Sleep(wait)
+		_, err = s.bot.api.Request(msg)
+	}
+	if isEntityError(err) {
+		log.Printf("⚠️  Telegram rejected %s markup, sending plain text: %v", parseMode, err)
+		msg.Text, msg.ParseMode = part.plain, ""
+		_, err = s.bot.api.Request(msg)
+	}
+	if err != nil && !isNotModified(err) {
+		return err
+	}
+
+	s.mu.Lock()
+	s.shown = part.text
+	s.mu.Unlock()
+	return nil
+}
+
+// preview returns the text of a reply being generated, its end when too
+// long for a message
+func preview(text string) string {
+	excess := utf16Len(text) - (maxMessageLength - utf16Len(streamCursor))
+	for excess > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+		excess -= utf16.RuneLen(r)
+	}
+	return strings.TrimRight(text, " \n") + streamCursor
+}
+
+// retryAfter returns how long Telegram asked to wait before the next
+// request, zero if err is not a flood control error
+func retryAfter(err error) time.Duration {
+	var apiErr *telegrambotapi.Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return time.Duration(apiErr.RetryAfter) * time.Second
+	}
+	return 0
+}
+
+// isNotModified reports whether an edit failed for leaving the text as is
+func isNotModified(err error) bool {
+	var apiErr *telegrambotapi.Error
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "message is not modified")
+}
+
+// isNotEditable reports whether an edit failed for the message not being
+// editable by the bot, or gone
+func isNotEditable(err error) bool {
+	var apiErr *telegrambotapi.Error
+	return errors.As(err, &apiErr) && (strings.Contains(apiErr.Message, "message can't be edited") ||
+		strings.Contains(apiErr.Message, "message to edit not found"))
+}
+
+var _ channels.StreamSender = (*Bot)(nil)
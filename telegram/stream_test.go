@@ -0,0 +1,137 @@
+package channels
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openclaw/go-openclaw/pkg/channels/channeltest"
+)
+
+func TestStreamThrottlesEdits(t *testing.T) {
+	b, api := newTestBot(t, &Config{EditInterval: 50})
+	var mu sync.Mutex
+	edits := 0
+	api.Handle("/editMessageText", func(r *channeltest.Request) (int, interface{}) {
+		mu.Lock()
+		edits++
+		mu.Unlock()
+		return ok(true)
+	})
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return edits
+	}
+
+	s, err := b.SendStream(context.Background(), "42", nil)
+	if err != nil {
+		t.Fatalf("SendStream: %v", err)
+	}
+	if got := texts(api.Requests("/sendMessage")); len(got) != 1 || got[0] != streamPlaceholder {
+		t.Fatalf("sent %q, want the placeholder", got)
+	}
+
+	// Deltas every 10ms make at most one edit per interval
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		s.Append("word ")
+		time.Sleep(10 * time.Millisecond)
+	}
+	elapsed := time.Since(start)
+	time.Sleep(150 * time.Millisecond)
+	n := count()
+	if max := int(elapsed/(50*time.Millisecond)) + 1; n == 0 || n > max {
+		t.Errorf("%d edits in %v, want 1 to %d", n, elapsed, max)
+	}
+	for _, r := range api.Requests("/editMessageText") {
+		if r.Form.Get("message_id") != "101" || !strings.HasSuffix(r.Form.Get("text"), streamCursor) || r.Form.Get("parse_mode") != "" {
+			t.Errorf("edit of message %s to %q in %q, want the placeholder with a cursor in plain text",
+				r.Form.Get("message_id"), r.Form.Get("text"), r.Form.Get("parse_mode"))
+		}
+	}
+
+	// Text edited already is not edited again
+	time.Sleep(150 * time.Millisecond)
+	if count() != n {
+		t.Errorf("%d edits without new text, want none", count()-n)
+	}
+	s.Close("")
+}
+
+func TestStreamFinalEdit(t *testing.T) {
+	b, api := newTestBot(t, &Config{EditInterval: int(time.Hour / time.Millisecond)})
+	ctx := context.Background()
+
+	s, err := b.SendStream(ctx, "42", nil)
+	if err != nil {
+		t.Fatalf("SendStream: %v", err)
+	}
+	s.Append("**do")
+	if err := s.Close("**done**"); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	edits := api.Requests("/editMessageText")
+	if len(edits) != 1 {
+		t.Fatalf("%d edits, want the final one", len(edits))
+	}
+	if edits[0].Form.Get("text") != "<b>done</b>" || edits[0].Form.Get("parse_mode") != ParseModeHTML {
+		t.Errorf("final edit to %q in %q, want the formatted reply", edits[0].Form.Get("text"), edits[0].Form.Get("parse_mode"))
+	}
+	if err := s.Append("more"); err == nil {
+		t.Error("Append after Close succeeded")
+	}
+
+	// Replies too long for a message continue in new ones
+	api.Reset()
+	s, _ = b.SendStream(ctx, "42", nil)
+	if err := s.Close(strings.Repeat("word ", maxMessageLength/5) + "\n\nend"); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if n := len(api.Requests("/editMessageText")); n != 1 {
+		t.Errorf("%d edits of a long reply, want 1", n)
+	}
+	if got := texts(api.Requests("/sendMessage")); len(got) != 2 || got[1] != "end" {
+		t.Errorf("sent %q, want the placeholder and the rest of the reply", got)
+	}
+
+	// Without a reply the placeholder is removed
+	api.Reset()
+	s, _ = b.SendStream(ctx, "42", nil)
+	if err := s.Close(""); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if deletes := api.Requests("/deleteMessage"); len(deletes) != 1 || len(api.Requests("/editMessageText")) != 0 {
+		t.Errorf("%d deletes and %d edits of an empty reply, want a delete", len(deletes), len(api.Requests("/editMessageText")))
+	}
+}
+
+func TestStreamNotEditable(t *testing.T) {
+	b, api := newTestBot(t, &Config{EditInterval: 20})
+	api.Handle("/editMessageText", func(r *channeltest.Request) (int, interface{}) {
+		return apiError(400, "Bad Request: message can't be edited")
+	})
+
+	s, err := b.SendStream(context.Background(), "42", nil)
+	if err != nil {
+		t.Fatalf("SendStream: %v", err)
+	}
+	s.Append("partial")
+	time.Sleep(100 * time.Millisecond)
+	if n := len(api.Requests("/editMessageText")); n != 1 {
+		t.Errorf("%d edits of a message that cannot be edited, want 1", n)
+	}
+
+	// The reply comes as a single message in place of the placeholder
+	if err := s.Close("**done**"); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := texts(api.Requests("/sendMessage")); len(got) != 2 || got[1] != "<b>done</b>" {
+		t.Errorf("sent %q, want the placeholder and the reply", got)
+	}
+	if deletes := api.Requests("/deleteMessage"); len(deletes) != 1 || deletes[0].Form.Get("message_id") != "101" {
+		t.Error("placeholder not removed")
+	}
+}
@@ -1,7 +1,10 @@
 package channels
 
 import (
+	"strconv"
 	"time"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
 )
 
 // UserInfo represents Telegram user information
@@ -58,9 +61,9 @@ func (c *ChatInfo) String() string {
 		if c.Username != "" {
 			return "@" + c.Username
 		}
-		return "Group " + string(c.ID)
+		return "Group " + strconv.FormatInt(c.ID, 10)
 	default:
-		return "Chat " + string(c.ID)
+		return "Chat " + strconv.FormatInt(c.ID, 10)
 	}
 }
 
@@ -83,6 +86,16 @@ type IncomingMessage struct {
 	Date        time.Time   `json:"date"`
 	EditDate    *time.Time  `json:"edit_date,omitempty"`
 	MediaGroupID string     `json:"media_group_id,omitempty"`
+	Media       *MediaInfo  `json:"media,omitempty"`
+}
+
+// MediaInfo represents the photo, document or voice message of a message
+type MediaInfo struct {
+	Type     string `json:"type"` // photo, document, voice
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileSize int64  `json:"file_size,omitempty"`
 }
 
 // GetContent returns the text or caption of the message
@@ -115,4 +128,5 @@ type SendOptions struct {
 	ReplyTo                int64  `json:"reply_to_message_id,omitempty"`
 	DisableWebPagePreview  bool   `json:"disable_web_page_preview,omitempty"`
 	DisableNotification    bool   `json:"disable_notification,omitempty"`
+	Buttons                [][]channels.Button `json:"buttons,omitempty"` // inline keyboard of the last message
 }
@@ -0,0 +1,136 @@
+package channels
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+
+	telegrambotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/openclaw/go-openclaw/pkg/channels"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// defaultWebhookPath is the path of the webhook when WebhookURL has none
+	defaultWebhookPath = "/telegram/webhook"
+
+	// secretTokenHeader holds the secret token of the webhook in updates
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	// maxWebhookBody is the largest update accepted, in bytes. Updates
+	// are small: media is downloaded separately.
+	maxWebhookBody = 1 << 20
+)
+
+// WebhookPath returns the path of the webhook on the gateway HTTP server,
+// empty in long polling mode
+func (b *Bot) WebhookPath() string {
+	if !b.config.UseWebhook {
+		return ""
+	}
+	if b.config.WebhookPath != "" {
+		return b.config.WebhookPath
+	}
+	if u, err := url.Parse(b.config.WebhookURL); err == nil && u.Path != "" && u.Path != "/" {
+		return u.Path
+	}
+	return defaultWebhookPath
+}
+
+// ServeWebhook handles an update posted by Telegram. Updates without the
+// secret token, too large or delivered already are not handled.
+func (b *Bot) ServeWebhook(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsPost() {
+		ctx.Error("method not allowed", fasthttp.StatusMethodNotAllowed)
+		return
+	}
+
+	secret := ctx.Request.Header.Peek(secretTokenHeader)
+	if subtle.ConstantTimeCompare(secret, []byte(b.config.WebhookSecret)) != 1 {
+		if b.config.Debug {
+			log.Printf("🚫 Webhook request from %s without the secret token", ctx.RemoteIP())
+		}
+		ctx.Error("unauthorized", fasthttp.StatusUnauthorized)
+		return
+	}
+
+	if !b.IsRunning() {
+		// Telegram retries until the bot is back
+		ctx.Error("bot is not running", fasthttp.StatusServiceUnavailable)
+		return
+	}
+
+	body := ctx.PostBody()
+	if ctx.Request.Header.ContentLength() > maxWebhookBody || len(body) > maxWebhookBody {
+		ctx.Error("update too large", fasthttp.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var update telegrambotapi.Update
+	if err := json.Unmarshal(body, &update); err != nil {
+		ctx.Error(fmt.Sprintf("invalid update: %v", err), fasthttp.StatusBadRequest)
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	if !b.recent.Add(strconv.Itoa(update.UpdateID)) {
+		if b.config.Debug {
+			log.Printf("ℹ️  Dropped update %d, delivered already", update.UpdateID)
+		}
+		return
+	}
+	b.handleUpdate(&update)
+}
+
+// startWebhook asks Telegram to post updates to the webhook URL, with the
+// secret token and the certificate of the URL if it is self-signed
+func (b *Bot) startWebhook() error {
+	params := telegrambotapi.Params{
+		"url":          b.config.WebhookURL,
+		"secret_token": b.config.WebhookSecret,
+	}
+
+	var err error
+	if cert := b.config.WebhookCertificate; cert != "" {
+		_, err = b.api.UploadFiles("setWebhook", params, []telegrambotapi.RequestFile{
+			{Name: "certificate", Data: telegrambotapi.FilePath(cert)},
+		})
+	} else {
+		_, err = b.api.MakeRequest("setWebhook", params)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+
+	log.Printf("🪝 Telegram webhook set to %s, served at %s", b.config.WebhookURL, b.WebhookPath())
+	return nil
+}
+
+// newSecretToken returns a random webhook secret token
+func newSecretToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// validSecretToken reports whether Telegram accepts a secret token
+func validSecretToken(token string) bool {
+	if len(token) < 1 || len(token) > 256 {
+		return false
+	}
+	for _, c := range token {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+var _ channels.Webhook = (*Bot)(nil)
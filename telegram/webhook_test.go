@@ -0,0 +1,62 @@
+package channels
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// newWebhookBot returns a webhook bot on a Bot API stand-in
+func newWebhookBot(t *testing.T, secret string) *Bot {
+	t.Helper()
+	b, _ := newTestBot(t, &Config{
+		UseWebhook:    true,
+		WebhookURL:    "https://bot.example.com/telegram/webhook",
+		WebhookSecret: secret,
+	})
+	return b
+}
+
+// post posts an update with a secret token header, unless empty
+func post(b *Bot, secret string) int {
+	var ctx fasthttp.RequestCtx
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	if secret != "" {
+		ctx.Request.Header.Set(secretTokenHeader, secret)
+	}
+	ctx.Request.SetBodyString(`{"update_id":1}`)
+	b.ServeWebhook(&ctx)
+	return ctx.Response.StatusCode()
+}
+
+func TestWebhookSecretToken(t *testing.T) {
+	b := newWebhookBot(t, "s3cret")
+	for _, tc := range []struct {
+		secret string
+		status int
+	}{
+		{"", fasthttp.StatusUnauthorized},
+		{"wrong", fasthttp.StatusUnauthorized},
+		{"s3cret-and-more", fasthttp.StatusUnauthorized},
+		// Updates with the secret wait for the bot to run, so Telegram
+		// delivers them again
+		{"s3cret", fasthttp.StatusServiceUnavailable},
+	} {
+		if status := post(b, tc.secret); status != tc.status {
+			t.Errorf("secret %q: status %d, want %d", tc.secret, status, tc.status)
+		}
+	}
+	if path := b.WebhookPath(); path != "/telegram/webhook" {
+		t.Errorf("WebhookPath = %q, want the path of the URL", path)
+	}
+}
+
+func TestWebhookSecretGenerated(t *testing.T) {
+	b := newWebhookBot(t, "")
+	if !validSecretToken(b.config.WebhookSecret) {
+		t.Fatalf("generated secret %q is not a valid secret token", b.config.WebhookSecret)
+	}
+	if status := post(b, ""); status != fasthttp.StatusUnauthorized {
+		t.Errorf("update without a secret: status %d, want 401", status)
+	}
+}
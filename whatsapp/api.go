@@ -0,0 +1,206 @@
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// apiTimeout bounds a single Graph API call
+const apiTimeout = 30 * time.Second
+
+// errReengagement is the Graph API error code of messages other than
+// templates sent outside the customer service window
+const errReengagement = 131047
+
+// graphClient calls the WhatsApp endpoints of the Graph API
+type graphClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// newGraphClient returns a client of the Graph API at baseURL
+func newGraphClient(baseURL, token string) *graphClient {
+	return &graphClient{
+		baseURL: baseURL,
+		token:   token,
+		http:    &http.Client{Timeout: apiTimeout},
+	}
+}
+
+// apiError is an error returned by the Graph API
+type apiError struct {
+	Status  int
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("whatsapp: status %d", e.Status)
+	}
+	return fmt.Sprintf("whatsapp: %s (code %d)", e.Message, e.Code)
+}
+
+// isReengagement reports whether a message was refused for being sent
+// outside the customer service window
+func isReengagement(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Code == errReengagement
+}
+
+// do sends a request with body as JSON, if not nil, and decodes the
+// response into out, if not nil
+func (c *graphClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("whatsapp %s: %w", path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("whatsapp %s: %w", path, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("whatsapp %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("whatsapp %s: %w", path, err)
+	}
+	if resp.StatusCode >= 300 {
+		var envelope struct {
+			Error *apiError `json:"error"`
+		}
+		if json.Unmarshal(respBody, &envelope) == nil && envelope.Error != nil {
+			envelope.Error.Status = resp.StatusCode
+			return envelope.Error
+		}
+		return &apiError{Status: resp.StatusCode}
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("whatsapp %s: invalid response: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// sendMessage posts a message of the messages endpoint and returns its ID
+func (c *graphClient) sendMessage(ctx context.Context, phoneNumberID string, msg map[string]interface{}) (string, error) {
+	msg["messaging_product"] = "whatsapp"
+	var sent struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/"+phoneNumberID+"/messages", msg, &sent); err != nil {
+		return "", err
+	}
+	if len(sent.Messages) == 0 {
+		return "", nil
+	}
+	return sent.Messages[0].ID, nil
+}
+
+// sendText sends a text message, as a reply when replyTo is set
+func (c *graphClient) sendText(ctx context.Context, phoneNumberID, to, text, replyTo string) (string, error) {
+	msg := map[string]interface{}{
+		"recipient_type": "individual",
+		"to":             to,
+		"type":           "text",
+		"text":           map[string]interface{}{"body": text, "preview_url": false},
+	}
+	if replyTo != "" {
+		msg["context"] = map[string]string{"message_id": replyTo}
+	}
+	return c.sendMessage(ctx, phoneNumberID, msg)
+}
+
+// sendTemplate sends a message template with one body parameter
+func (c *graphClient) sendTemplate(ctx context.Context, phoneNumberID, to, name, language, param string) (string, error) {
+	return c.sendMessage(ctx, phoneNumberID, map[string]interface{}{
+		"recipient_type": "individual",
+		"to":             to,
+		"type":           "template",
+		"template": map[string]interface{}{
+			"name":     name,
+			"language": map[string]string{"code": language},
+			"components": []map[string]interface{}{{
+				"type":       "body",
+				"parameters": []map[string]string{{"type": "text", "text": param}},
+			}},
+		},
+	})
+}
+
+// markRead marks a received message as read, showing blue ticks
+func (c *graphClient) markRead(ctx context.Context, phoneNumberID, messageID string) error {
+	return c.do(ctx, http.MethodPost, "/"+phoneNumberID+"/messages", map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"status":            "read",
+		"message_id":        messageID,
+	}, nil)
+}
+
+// mediaInfo is where a media file of a message can be downloaded
+type mediaInfo struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	FileSize int64  `json:"file_size"`
+}
+
+// media returns the download URL of a media file
+func (c *graphClient) media(ctx context.Context, mediaID string) (*mediaInfo, error) {
+	var info mediaInfo
+	if err := c.do(ctx, http.MethodGet, "/"+url.PathEscape(mediaID), nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// download downloads a media file, failing with errFileTooLarge for
+// files over maxSize bytes. The URL needs the access token as well.
+func (c *graphClient) download(ctx context.Context, fileURL string, maxSize int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download media: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errFileTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,206 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// Bot represents a WhatsApp business phone number answering customers
+// through the Cloud API
+type Bot struct {
+	config         *Config
+	api            *graphClient
+	handler        *Handler
+	messageHandler channels.MessageHandler
+	windows        sessionWindows     // customer service windows by WhatsApp ID
+	recent         channels.RecentIDs // message IDs received, as Meta retries deliveries
+	running        bool
+	mu             sync.RWMutex
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+// NewBot creates a new WhatsApp bot
+func NewBot(config *Config) (*Bot, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if config.Transcriber == nil {
+		config.Transcriber = channels.NopTranscriber{}
+	}
+
+	bot := &Bot{
+		config: config,
+		api:    newGraphClient(config.apiURL(), config.AccessToken),
+	}
+	bot.handler = NewHandler(bot)
+
+	return bot, nil
+}
+
+// Name returns channel name
+func (b *Bot) Name() string {
+	return "whatsapp"
+}
+
+// Start starts handling webhook notifications. The Cloud API has no call
+// to check the token cheaply, so it is checked by the first reply.
+func (b *Bot) Start(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running {
+		return fmt.Errorf("bot is already running")
+	}
+
+	log.Printf("🤖 Starting WhatsApp bot...")
+
+	b.ctx, b.cancel = context.WithCancel(ctx)
+	b.running = true
+	b.handler.Start(b.ctx)
+
+	log.Printf("🚀 WhatsApp bot started for phone number %s, webhook served at %s", b.config.PhoneNumberID, b.WebhookPath())
+
+	return nil
+}
+
+// Stop stops WhatsApp bot
+func (b *Bot) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.running {
+		b.mu.Unlock()
+		return nil
+	}
+	b.running = false
+	b.mu.Unlock()
+
+	log.Println("🛑 Stopping WhatsApp bot...")
+
+	b.cancel()
+
+	// Wait for the replies being generated
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("✅ WhatsApp bot stopped")
+		return nil
+	case <-time.After(10 * time.Second):
+		log.Println("⚠️  WhatsApp bot stop timeout")
+		return fmt.Errorf("timeout waiting for bot to stop")
+	}
+}
+
+// Send sends a message to specified target, the WhatsApp ID of a user
+func (b *Bot) Send(ctx context.Context, target string, content string, options map[string]interface{}) error {
+	if target == "" {
+		return fmt.Errorf("invalid target: WhatsApp ID is required")
+	}
+	replyTo, _ := options["reply_to"].(string)
+
+	_, err := b.SendMessage(ctx, target, replyTo, content)
+	return err
+}
+
+// SendMessage sends model Markdown to a user, converted to WhatsApp
+// formatting, as a reply to replyTo when set. Long text is split into
+// several messages; the ID of the last one is returned. Once the 24-hour
+// customer service window has closed, the reply goes out in the
+// configured template instead.
+func (b *Bot) SendMessage(ctx context.Context, to, replyTo, text string) (string, error) {
+	if text == "" {
+		return "", fmt.Errorf("message text cannot be empty")
+	}
+	if !b.windows.isOpen(to, time.Now()) {
+		return b.sendTemplate(ctx, to, text)
+	}
+
+	id := ""
+	for i, part := range splitMessage(formatMessage(text), maxMessageLength) {
+		if i > 0 {
+			replyTo = ""
+		}
+		var err error
+		if id, err = b.api.sendText(ctx, b.config.PhoneNumberID, to, part, replyTo); err != nil {
+			// The window is tracked in memory, and lost on restarts
+			if i == 0 && isReengagement(err) {
+				b.windows.close(to)
+				return b.sendTemplate(ctx, to, text)
+			}
+			return "", fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+
+	if b.config.Debug {
+		log.Printf("📤 Sent message to %s: %s", to, truncateString(text, 50))
+	}
+
+	return id, nil
+}
+
+// sendTemplate sends a reply in the configured template, the only
+// message WhatsApp delivers outside the customer service window
+func (b *Bot) sendTemplate(ctx context.Context, to, text string) (string, error) {
+	if b.config.Template == "" {
+		return "", fmt.Errorf("the 24-hour session window of %s has expired and no template is configured", to)
+	}
+
+	id, err := b.api.sendTemplate(ctx, b.config.PhoneNumberID, to,
+		b.config.Template, b.config.templateLanguage(), templateParam(formatMessage(text)))
+	if err != nil {
+		return "", fmt.Errorf("failed to send template %s: %w", b.config.Template, err)
+	}
+
+	log.Printf("📨 Session window of %s has expired, sent template %s", to, b.config.Template)
+
+	return id, nil
+}
+
+// SetMessageHandler sets handler for incoming messages
+func (b *Bot) SetMessageHandler(handler channels.MessageHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messageHandler = handler
+}
+
+// GetMessageHandler returns current message handler
+func (b *Bot) GetMessageHandler() channels.MessageHandler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.messageHandler
+}
+
+// IsRunning returns true if bot is running
+func (b *Bot) IsRunning() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.running
+}
+
+// Status returns current status of bot
+func (b *Bot) Status() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.running {
+		return "running"
+	}
+	return "stopped"
+}
+
+// truncateString truncates a string to max characters
+func truncateString(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
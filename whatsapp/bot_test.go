@@ -0,0 +1,143 @@
+package whatsapp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openclaw/go-openclaw/pkg/channels/channeltest"
+)
+
+// newGraphStandIn returns a Graph API stand-in accepting messages. It
+// refuses text outside the window while reengage is set.
+func newGraphStandIn(t *testing.T, reengage *atomic.Bool) *channeltest.StandIn {
+	api := channeltest.New(t)
+	api.Handle("", func(r *channeltest.Request) (int, interface{}) {
+		var msg map[string]interface{}
+		r.JSON(&msg)
+		switch {
+		case r.Header.Get("Authorization") != "Bearer token" || r.Path != "/100/messages":
+			return http.StatusBadRequest, map[string]interface{}{"error": map[string]interface{}{"code": 190, "message": "invalid request"}}
+		case reengage.Load() && msg["type"] == "text":
+			return http.StatusBadRequest, map[string]interface{}{"error": map[string]interface{}{"code": errReengagement, "message": "Re-engagement message"}}
+		}
+		return http.StatusOK, map[string]interface{}{"messages": []map[string]string{{"id": "wamid.out"}}}
+	})
+	return api
+}
+
+// sent returns the messages posted to a Graph API stand-in
+func sent(api *channeltest.StandIn) []map[string]interface{} {
+	var messages []map[string]interface{}
+	for _, r := range api.Requests("/100/messages") {
+		var msg map[string]interface{}
+		r.JSON(&msg)
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// types lists the types of the messages sent since last called, refused
+// ones included
+func types(api *channeltest.StandIn) string {
+	var types []string
+	for _, msg := range sent(api) {
+		types = append(types, msg["type"].(string))
+	}
+	api.Reset()
+	return strings.Join(types, ",")
+}
+
+func TestSendMessageWindow(t *testing.T) {
+	var reengage atomic.Bool
+	api := newGraphStandIn(t, &reengage)
+	b := newTestBot(t, api.URL)
+	ctx := context.Background()
+
+	// Nobody wrote yet: the window is closed
+	if _, err := b.SendMessage(ctx, "491701234567", "", "hello"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if got := types(api); got != "template" {
+		t.Errorf("sent %s before the user wrote, want template", got)
+	}
+
+	b.windows.touch("491701234567", time.Now())
+	if _, err := b.SendMessage(ctx, "491701234567", "wamid.in", "hello"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if got := types(api); got != "text" {
+		t.Errorf("sent %s in the window, want text", got)
+	}
+
+	// Expired windows and windows WhatsApp says are closed fall back to
+	// the template
+	b.windows.touch("491700000000", time.Now().Add(-sessionWindow))
+	if _, err := b.SendMessage(ctx, "491700000000", "", "hello"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if got := types(api); got != "template" {
+		t.Errorf("sent %s after the window, want template", got)
+	}
+
+	reengage.Store(true)
+	if _, err := b.SendMessage(ctx, "491701234567", "", "hello\nagain"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if got := types(api); got != "text,template" {
+		t.Errorf("sent %s after a re-engagement error, want text then template", got)
+	}
+	if b.windows.isOpen("491701234567", time.Now()) {
+		t.Error("window still open after a re-engagement error")
+	}
+
+	// Without a template such replies fail
+	b.config.Template = ""
+	if _, err := b.SendMessage(ctx, "491701234567", "", "hello"); err == nil {
+		t.Error("SendMessage outside the window without a template succeeded")
+	}
+}
+
+func TestSendMessageSplitsOnRunes(t *testing.T) {
+	api := newGraphStandIn(t, &atomic.Bool{})
+	b := newTestBot(t, api.URL)
+	b.windows.touch("491701234567", time.Now())
+
+	text := strings.Repeat("ü", maxMessageLength+100)
+	if _, err := b.SendMessage(context.Background(), "491701234567", "wamid.in", text); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	messages := sent(api)
+	if len(messages) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(messages))
+	}
+	total := 0
+	for i, msg := range messages {
+		body := msg["text"].(map[string]interface{})["body"].(string)
+		if !utf8.ValidString(body) || utf8.RuneCountInString(body) > maxMessageLength {
+			t.Errorf("message %d has %d characters, over %d or cut in a character", i, utf8.RuneCountInString(body), maxMessageLength)
+		}
+		total += utf8.RuneCountInString(body)
+		if _, reply := msg["context"]; reply != (i == 0) {
+			t.Errorf("message %d: reply context %v, want only on the first", i, reply)
+		}
+	}
+	if total != maxMessageLength+100 {
+		t.Errorf("messages have %d characters, want %d", total, maxMessageLength+100)
+	}
+}
+
+func TestTemplateParam(t *testing.T) {
+	if got := templateParam("a\n\nb\tc"); got != "a b c" {
+		t.Errorf("templateParam = %q, want line breaks and tabs folded", got)
+	}
+	long := templateParam(strings.Repeat("é", maxTemplateParam+10))
+	if n := utf8.RuneCountInString(long); n != maxTemplateParam || !strings.HasSuffix(long, "…") {
+		t.Errorf("long param has %d characters, want %d ending in an ellipsis", n, maxTemplateParam)
+	}
+}
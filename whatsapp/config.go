@@ -0,0 +1,225 @@
+package whatsapp
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// Config holds the configuration for the WhatsApp channel
+type Config struct {
+	// AccessToken is the system user access token of the WhatsApp
+	// Business account
+	AccessToken string `json:"access_token"`
+
+	// PhoneNumberID is the ID of the business phone number messages are
+	// sent from
+	PhoneNumberID string `json:"phone_number_id"`
+
+	// AppSecret is the secret of the Meta app, which signs webhook
+	// notifications in the X-Hub-Signature-256 header
+	AppSecret string `json:"app_secret"`
+
+	// VerifyToken is the token set with the webhook callback URL, echoed
+	// by Meta when it checks the URL
+	VerifyToken string `json:"verify_token"`
+
+	// APIURL is the base URL of the Graph API with its version (default:
+	// https://graph.facebook.com/v21.0), for a stand-in in tests
+	APIURL string `json:"api_url,omitempty"`
+
+	// WebhookPath is the path of the webhook on the gateway HTTP server
+	// (default: /whatsapp/webhook)
+	WebhookPath string `json:"webhook_path,omitempty"`
+
+	// Template is the message template sent instead of a reply when the
+	// 24-hour customer service window has closed. It must have one body
+	// variable, which holds the reply. Without it such replies fail.
+	Template string `json:"template,omitempty"`
+
+	// TemplateLanguage is the language code of Template (default: en_US)
+	TemplateLanguage string `json:"template_language,omitempty"`
+
+	// AllowedUsers is a list of allowed phone numbers, as WhatsApp IDs
+	// without + (empty means all users are allowed)
+	AllowedUsers []string `json:"allowed_users,omitempty"`
+
+	// MaxFileSize is the largest image or audio message downloaded, in
+	// bytes (default: 16 MB, the largest audio WhatsApp sends)
+	MaxFileSize int64 `json:"max_file_size,omitempty"`
+
+	// Debug enables debug logging
+	Debug bool `json:"debug,omitempty"`
+
+	// Transcriber turns audio messages into text (default:
+	// channels.NopTranscriber, with which they are declined)
+	Transcriber channels.Transcriber `json:"-"`
+}
+
+const (
+	// defaultAPIURL is the base URL of the Graph API
+	defaultAPIURL = "https://graph.facebook.com/v21.0"
+
+	// defaultWebhookPath is the path notifications are posted to by default
+	defaultWebhookPath = "/whatsapp/webhook"
+
+	// defaultTemplateLanguage is the language of the template by default
+	defaultTemplateLanguage = "en_US"
+
+	// defaultMaxFileSize is the largest media downloaded by default
+	defaultMaxFileSize = 16 << 20
+)
+
+// LoadConfig loads configuration from environment variables or defaults
+func LoadConfig() (*Config, error) {
+	cfg := &Config{
+		AccessToken:   os.Getenv("WHATSAPP_ACCESS_TOKEN"),
+		PhoneNumberID: os.Getenv("WHATSAPP_PHONE_NUMBER_ID"),
+		AppSecret:     os.Getenv("WHATSAPP_APP_SECRET"),
+		VerifyToken:   os.Getenv("WHATSAPP_VERIFY_TOKEN"),
+		APIURL:        os.Getenv("WHATSAPP_API_URL"),
+		Template:      os.Getenv("WHATSAPP_TEMPLATE"),
+		Debug:         os.Getenv("WHATSAPP_DEBUG") == "true",
+		MaxFileSize:   defaultMaxFileSize,
+		AllowedUsers:  make([]string, 0),
+	}
+
+	if cfg.AccessToken == "" {
+		return nil, fmt.Errorf("WHATSAPP_ACCESS_TOKEN environment variable is required")
+	}
+	if cfg.PhoneNumberID == "" {
+		return nil, fmt.Errorf("WHATSAPP_PHONE_NUMBER_ID environment variable is required")
+	}
+	if cfg.AppSecret == "" {
+		return nil, fmt.Errorf("WHATSAPP_APP_SECRET environment variable is required")
+	}
+	if cfg.VerifyToken == "" {
+		return nil, fmt.Errorf("WHATSAPP_VERIFY_TOKEN environment variable is required")
+	}
+
+	return cfg, nil
+}
+
+// LoadConfigFromMap loads configuration from a map
+func LoadConfigFromMap(configMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{
+		MaxFileSize:  defaultMaxFileSize,
+		AllowedUsers: make([]string, 0),
+	}
+
+	if accessToken, ok := configMap["access_token"].(string); ok {
+		cfg.AccessToken = accessToken
+	} else {
+		return nil, fmt.Errorf("access_token is required in config")
+	}
+
+	if phoneNumberID, ok := configMap["phone_number_id"].(string); ok {
+		cfg.PhoneNumberID = phoneNumberID
+	} else {
+		return nil, fmt.Errorf("phone_number_id is required in config")
+	}
+
+	if appSecret, ok := configMap["app_secret"].(string); ok {
+		cfg.AppSecret = appSecret
+	} else {
+		return nil, fmt.Errorf("app_secret is required in config")
+	}
+
+	if verifyToken, ok := configMap["verify_token"].(string); ok {
+		cfg.VerifyToken = verifyToken
+	} else {
+		return nil, fmt.Errorf("verify_token is required in config")
+	}
+
+	if apiURL, ok := configMap["api_url"].(string); ok {
+		cfg.APIURL = apiURL
+	}
+
+	if webhookPath, ok := configMap["webhook_path"].(string); ok {
+		cfg.WebhookPath = webhookPath
+	}
+
+	if template, ok := configMap["template"].(string); ok {
+		cfg.Template = template
+	}
+
+	if templateLanguage, ok := configMap["template_language"].(string); ok {
+		cfg.TemplateLanguage = templateLanguage
+	}
+
+	if allowedUsers, ok := configMap["allowed_users"].([]interface{}); ok {
+		for _, uid := range allowedUsers {
+			if id, ok := uid.(string); ok {
+				cfg.AllowedUsers = append(cfg.AllowedUsers, id)
+			}
+		}
+	}
+
+	if maxFileSize, ok := configMap["max_file_size"].(float64); ok {
+		cfg.MaxFileSize = int64(maxFileSize)
+	}
+
+	if debug, ok := configMap["debug"].(bool); ok {
+		cfg.Debug = debug
+	}
+
+	return cfg, nil
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	if c.AccessToken == "" {
+		return fmt.Errorf("access_token is required")
+	}
+
+	if c.PhoneNumberID == "" {
+		return fmt.Errorf("phone_number_id is required")
+	}
+
+	if c.AppSecret == "" {
+		return fmt.Errorf("app_secret is required")
+	}
+
+	if c.VerifyToken == "" {
+		return fmt.Errorf("verify_token is required")
+	}
+
+	if c.WebhookPath != "" && !strings.HasPrefix(c.WebhookPath, "/") {
+		return fmt.Errorf("webhook_path must start with /, not %q", c.WebhookPath)
+	}
+
+	if c.APIURL != "" {
+		if u, err := url.Parse(c.APIURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("api_url must be an absolute URL, not %q", c.APIURL)
+		}
+	}
+
+	return nil
+}
+
+// apiURL returns the base URL of the Graph API, without a trailing slash
+func (c *Config) apiURL() string {
+	if c.APIURL == "" {
+		return defaultAPIURL
+	}
+	return strings.TrimRight(c.APIURL, "/")
+}
+
+// templateLanguage returns the language code of the template
+func (c *Config) templateLanguage() string {
+	if c.TemplateLanguage == "" {
+		return defaultTemplateLanguage
+	}
+	return c.TemplateLanguage
+}
+
+// maxFileSize returns the largest file downloaded, in bytes
+func (c *Config) maxFileSize() int64 {
+	if c.MaxFileSize <= 0 {
+		return defaultMaxFileSize
+	}
+	return c.MaxFileSize
+}
@@ -0,0 +1,139 @@
+package whatsapp
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxMessageLength is the longest text WhatsApp accepts in a message
+const maxMessageLength = 4096
+
+var (
+	headingPattern = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*$`)
+	bulletPattern  = regexp.MustCompile(`^(\s*)[*+]\s+`)
+	linkPattern    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldPattern    = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	italicPattern  = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*`)
+	strikePattern  = regexp.MustCompile(`~~(.+?)~~`)
+)
+
+// formatMessage converts model Markdown to WhatsApp formatting: *bold*,
+// _italic_, ~strike~, monospace code and plain links. Headings become
+// bold lines; code blocks lose their language, which WhatsApp would show.
+func formatMessage(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			lines[i] = "```"
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			lines[i] = "*" + renderInline(strings.Trim(m[1], "*")) + "*"
+			continue
+		}
+		// Bullets of * would read as bold
+		line = bulletPattern.ReplaceAllString(line, "$1- ")
+		lines[i] = renderInline(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderInline converts the emphasis and links of a line, outside its
+// code spans
+func renderInline(line string) string {
+	segments := strings.Split(line, "`")
+	for i, segment := range segments {
+		// Odd segments are code spans, closed when followed by another
+		if i%2 == 1 && i < len(segments)-1 {
+			continue
+		}
+		segment = linkPattern.ReplaceAllStringFunc(segment, func(s string) string {
+			m := linkPattern.FindStringSubmatch(s)
+			if m[1] == m[2] {
+				return m[2]
+			}
+			return m[1] + " (" + m[2] + ")"
+		})
+		segment = boldPattern.ReplaceAllStringFunc(segment, func(s string) string {
+			return "\x00" + s[2:len(s)-2] + "\x00"
+		})
+		segment = italicPattern.ReplaceAllString(segment, "${1}_${2}_")
+		segment = strikePattern.ReplaceAllString(segment, "~$1~")
+		segments[i] = strings.ReplaceAll(segment, "\x00", "*")
+	}
+	return strings.Join(segments, "`")
+}
+
+// codeFence opens and closes code blocks
+const codeFence = "```"
+
+// splitMessage splits formatted text into messages of at most max
+// characters, between lines where possible. Code blocks split across
+// messages are closed and opened again.
+func splitMessage(text string, max int) []string {
+	if runeLen(text) <= max {
+		return []string{text}
+	}
+
+	// Lines are cut to fit in a message with both fences around them
+	limit := max - 2*(len(codeFence)+1)
+
+	var parts []string
+	var lines []string
+	length := 0
+	inCode := false
+	flush := func() {
+		part := strings.Join(lines, "\n")
+		if inCode {
+			part += "\n" + codeFence
+		}
+		if strings.TrimSpace(part) != "" {
+			parts = append(parts, part)
+		}
+		lines, length = nil, 0
+		if inCode {
+			lines, length = []string{codeFence}, len(codeFence)
+		}
+	}
+	add := func(line string) {
+		// Room is kept to close a code block
+		if len(lines) > 0 && length+1+runeLen(line)+len(codeFence)+1 > max {
+			flush()
+		}
+		if len(lines) > 0 {
+			length++
+		}
+		lines = append(lines, line)
+		length += runeLen(line)
+		if line == codeFence {
+			inCode = !inCode
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		for runeLen(line) > limit {
+			runes := []rune(line)
+			cut := limit
+			if i := strings.LastIndexByte(string(runes[:limit]), ' '); !inCode && i > 0 {
+				cut = runeLen(string(runes[:limit])[:i+1])
+			}
+			add(string(runes[:cut]))
+			line = string(runes[cut:])
+		}
+		add(line)
+	}
+	inCode = false
+	flush()
+	return parts
+}
+
+// runeLen returns the number of characters of s
+func runeLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,235 @@
+package whatsapp
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+// message is a message of a user in a notification
+type message struct {
+	From      string    `json:"from"` // WhatsApp ID of the user
+	ID        string    `json:"id"`
+	Timestamp string    `json:"timestamp"` // Unix seconds
+	Type      string    `json:"type"`      // text, image, audio, ...
+	Text      *text     `json:"text,omitempty"`
+	Image     *media    `json:"image,omitempty"`
+	Audio     *media    `json:"audio,omitempty"`
+	Context   *replyRef `json:"context,omitempty"`
+}
+
+// text is the body of a text message
+type text struct {
+	Body string `json:"body"`
+}
+
+// media is the image or audio of a message, downloaded by its ID
+type media struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	Caption  string `json:"caption,omitempty"`
+	Voice    bool   `json:"voice,omitempty"` // recorded in WhatsApp
+}
+
+// replyRef is the message a message replies to
+type replyRef struct {
+	From string `json:"from"`
+	ID   string `json:"id"`
+}
+
+// time returns when the message was sent
+func (m *message) time() time.Time {
+	seconds, err := strconv.ParseInt(m.Timestamp, 10, 64)
+	if err != nil {
+		return time.Now()
+	}
+	return time.Unix(seconds, 0)
+}
+
+// incomingMessage is a message waiting to be answered
+type incomingMessage struct {
+	message *message
+	name    string // profile name of the sender
+}
+
+// Handler handles incoming WhatsApp messages
+type Handler struct {
+	bot      *Bot
+	incoming chan *incomingMessage
+}
+
+// NewHandler creates a new message handler
+func NewHandler(bot *Bot) *Handler {
+	return &Handler{
+		bot:      bot,
+		incoming: make(chan *incomingMessage, 100),
+	}
+}
+
+// Start starts the message handler
+func (h *Handler) Start(ctx context.Context) {
+	h.bot.wg.Add(1)
+	go func() {
+		defer h.bot.wg.Done()
+		h.processMessages(ctx)
+	}()
+}
+
+// HandleMessage queues a message of a notification. Any message of a
+// user opens the customer service window; only text, image and audio
+// messages are answered.
+func (h *Handler) HandleMessage(msg *message, name string) {
+	h.bot.windows.touch(msg.From, msg.time())
+
+	switch msg.Type {
+	case "text", "image", "audio":
+	default:
+		if h.bot.config.Debug {
+			log.Printf("ℹ️  Received WhatsApp message type: %s", msg.Type)
+		}
+		return
+	}
+
+	select {
+	case h.incoming <- &incomingMessage{message: msg, name: name}:
+	default:
+		log.Printf("⚠️  Incoming message queue full, dropping message from %s", msg.From)
+	}
+}
+
+// processMessages answers queued messages in order
+func (h *Handler) processMessages(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case in := <-h.incoming:
+			if err := h.handleMessage(ctx, in); err != nil {
+				log.Printf("❌ Error handling message: %v", err)
+			}
+		}
+	}
+}
+
+// handleMessage passes a message to the message handler
+func (h *Handler) handleMessage(ctx context.Context, in *incomingMessage) error {
+	msg := in.message
+
+	// Check if user is allowed
+	if !isAllowed(h.bot.config.AllowedUsers, msg.From) {
+		if h.bot.config.Debug {
+			log.Printf("🚫 Message from user %s (not in allowed list)", msg.From)
+		}
+		return nil
+	}
+
+	// Blue ticks show the message is being answered
+	if err := h.bot.api.markRead(ctx, h.bot.config.PhoneNumberID, msg.ID); err != nil && h.bot.config.Debug {
+		log.Printf("⚠️  Failed to mark message %s as read: %v", msg.ID, err)
+	}
+
+	// Images are attached, audio messages transcribed
+	content := ""
+	var attachments []*channels.Attachment
+	switch msg.Type {
+	case "text":
+		if msg.Text != nil {
+			content = msg.Text.Body
+		}
+	case "image", "audio":
+		m := msg.Image
+		if msg.Type == "audio" {
+			m = msg.Audio
+		}
+		if m == nil {
+			return nil
+		}
+		attachment, transcript, err := h.readMedia(ctx, msg.Type, m)
+		if err != nil {
+			log.Printf("⚠️  Failed to read %s from %s: %v", msg.Type, msg.From, err)
+			_, err = h.bot.SendMessage(ctx, msg.From, msg.ID, h.mediaError(msg.Type, err))
+			return err
+		}
+		if attachment != nil {
+			attachments = append(attachments, attachment)
+		}
+		content = strings.TrimSpace(m.Caption + "\n\n" + transcript)
+	}
+
+	channelMsg := h.toChannelMessage(in, content, attachments)
+	if strings.TrimSpace(channelMsg.Content) == "" && len(attachments) == 0 {
+		return nil
+	}
+
+	// Call registered message handler
+	handler := h.bot.GetMessageHandler()
+	if handler != nil {
+		if err := handler(ctx, channelMsg); err != nil {
+			log.Printf("❌ Error in message handler: %v", err)
+			// Send error message to user
+			_, _ = h.bot.SendMessage(ctx, msg.From, "", "Sorry, I encountered an error processing your message.")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toChannelMessage converts a message to a channel message. Each user
+// has one conversation, keyed by their WhatsApp ID.
+func (h *Handler) toChannelMessage(in *incomingMessage, content string, attachments []*channels.Attachment) *channels.Message {
+	msg := in.message
+
+	msgType := channels.MessageTypeText
+	switch msg.Type {
+	case "image":
+		msgType = channels.MessageTypeImage
+	case "audio":
+		msgType = channels.MessageTypeAudio
+	}
+
+	replyTo := ""
+	if msg.Context != nil {
+		replyTo = msg.Context.ID
+	}
+
+	fromName := in.name
+	if fromName == "" {
+		fromName = "+" + msg.From
+	}
+
+	metadata := make(map[string]interface{})
+	metadata["whatsapp_message_id"] = msg.ID
+	metadata["whatsapp_phone_number_id"] = h.bot.config.PhoneNumberID
+
+	return &channels.Message{
+		ID:          msg.ID,
+		Channel:     h.bot.Name(),
+		From:        msg.From,
+		FromName:    fromName,
+		To:          msg.From,
+		Content:     content,
+		Type:        msgType,
+		Timestamp:   msg.time(),
+		ReplyTo:     replyTo,
+		Metadata:    metadata,
+		Attachments: attachments,
+	}
+}
+
+// isAllowed reports whether id is in an allow list, empty to allow all
+func isAllowed(allowed []string, id string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.TrimPrefix(a, "+") == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+)
+
+var (
+	// errFileTooLarge is returned for files over Config.MaxFileSize
+	errFileTooLarge = errors.New("file is too large")
+
+	// errNoTranscript is returned for audio messages without a transcript
+	errNoTranscript = errors.New("audio message could not be transcribed")
+)
+
+// readMedia downloads the media of a message: images become attachments,
+// audio messages their transcript
+func (h *Handler) readMedia(ctx context.Context, msgType string, m *media) (*channels.Attachment, string, error) {
+	transcriber := h.bot.config.Transcriber
+	if _, nop := transcriber.(channels.NopTranscriber); msgType == "audio" && (nop || transcriber == nil) {
+		return nil, "", errNoTranscript
+	}
+
+	data, mimeType, err := h.bot.downloadMedia(ctx, m.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if m.MimeType != "" {
+		mimeType = m.MimeType
+	}
+
+	if msgType == "audio" {
+		text, err := transcriber.Transcribe(ctx, data, mimeType)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to transcribe audio message: %w", err)
+		}
+		if text == "" {
+			return nil, "", errNoTranscript
+		}
+		return nil, text, nil
+	}
+
+	name := "image"
+	if _, ext, ok := strings.Cut(mimeType, "/"); ok {
+		name += "." + strings.TrimPrefix(ext, "x-")
+	}
+	return &channels.Attachment{
+		Type:     channels.MessageTypeImage,
+		Name:     name,
+		MimeType: mimeType,
+		Data:     data,
+	}, "", nil
+}
+
+// mediaError returns what the user is told when their media cannot be read
+func (h *Handler) mediaError(msgType string, err error) string {
+	kind := msgType
+	if msgType == "audio" {
+		kind = "audio message"
+	}
+	switch {
+	case errors.Is(err, errFileTooLarge):
+		maxSize := h.bot.config.maxFileSize()
+		limit := fmt.Sprintf("%d MB", maxSize>>20)
+		if maxSize < 1<<20 {
+			limit = fmt.Sprintf("%d KB", maxSize>>10)
+		}
+		return fmt.Sprintf("⚠️ That %s is too large, I can read files up to %s.", kind, limit)
+	case errors.Is(err, errNoTranscript):
+		return "⚠️ I can't listen to voice messages, please send text."
+	default:
+		return fmt.Sprintf("⚠️ Sorry, I couldn't read that %s.", kind)
+	}
+}
+
+// downloadMedia downloads a media file of a message and returns it with
+// its MIME type. Its URL is looked up first, and expires in minutes.
+func (b *Bot) downloadMedia(ctx context.Context, mediaID string) ([]byte, string, error) {
+	maxSize := b.config.maxFileSize()
+
+	info, err := b.api.media(ctx, mediaID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get media: %w", err)
+	}
+	if info.FileSize > maxSize {
+		return nil, "", errFileTooLarge
+	}
+
+	data, err := b.api.download(ctx, info.URL, maxSize)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, info.MimeType, nil
+}
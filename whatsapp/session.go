@@ -0,0 +1,70 @@
+package whatsapp
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// sessionWindow is how long after a message of a user free-form
+	// replies may be sent to them, the customer service window
+	sessionWindow = 24 * time.Hour
+
+	// maxTemplateParam is the longest text put in a template variable,
+	// in characters, below the limit of the template body
+	maxTemplateParam = 1000
+)
+
+// sessionWindows tracks when each user last wrote, which opens the
+// customer service window for free-form replies
+type sessionWindows struct {
+	mu   sync.Mutex
+	last map[string]time.Time // by WhatsApp ID
+}
+
+// touch records a message of a user sent at the given time
+func (s *sessionWindows) touch(waID string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last == nil {
+		s.last = make(map[string]time.Time)
+	}
+	if at.After(s.last[waID]) {
+		s.last[waID] = at
+	}
+}
+
+// isOpen reports whether free-form replies may be sent to a user at now
+func (s *sessionWindows) isOpen(waID string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, ok := s.last[waID]
+	if !ok {
+		return false
+	}
+	if now.Sub(last) >= sessionWindow {
+		delete(s.last, waID)
+		return false
+	}
+	return true
+}
+
+// close forgets the window of a user, when WhatsApp says it has closed
+func (s *sessionWindows) close(waID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.last, waID)
+}
+
+// templateParam returns a reply as the value of a template variable,
+// which may hold neither line breaks nor tabs, shortened when too long
+func templateParam(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxTemplateParam {
+		text = string(runes[:maxTemplateParam-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,184 @@
+package whatsapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/openclaw/go-openclaw/pkg/channels"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// signatureHeader holds the signature of a notification
+	signatureHeader = "X-Hub-Signature-256"
+
+	// signaturePrefix precedes the hex signature in the header
+	signaturePrefix = "sha256="
+
+	// maxWebhookBody is the largest notification accepted, in bytes
+	maxWebhookBody = 1 << 20
+)
+
+// notification is a webhook notification of the Cloud API
+type notification struct {
+	Object string `json:"object"` // whatsapp_business_account
+	Entry  []struct {
+		ID      string   `json:"id"`
+		Changes []change `json:"changes"`
+	} `json:"entry"`
+}
+
+// change is a change of a WhatsApp Business account; messages come in
+// changes of the messages field
+type change struct {
+	Field string `json:"field"`
+	Value struct {
+		Metadata struct {
+			DisplayPhoneNumber string `json:"display_phone_number"`
+			PhoneNumberID      string `json:"phone_number_id"`
+		} `json:"metadata"`
+		Contacts []contact `json:"contacts,omitempty"`
+		Messages []message `json:"messages,omitempty"`
+	} `json:"value"`
+}
+
+// contact is the WhatsApp profile of the sender of a message
+type contact struct {
+	WaID    string `json:"wa_id"`
+	Profile struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+}
+
+// WebhookPath returns the path of the webhook callback URL on the gateway
+// HTTP server
+func (b *Bot) WebhookPath() string {
+	if b.config.WebhookPath != "" {
+		return b.config.WebhookPath
+	}
+	return defaultWebhookPath
+}
+
+// ServeWebhook handles a request of the Cloud API webhook: the check of
+// the callback URL, answered with its challenge when the verify token
+// matches, and notifications, handled when signed with the app secret.
+// Notifications are acknowledged before they are answered, as Meta
+// retries the ones not acknowledged quickly.
+func (b *Bot) ServeWebhook(ctx *fasthttp.RequestCtx) {
+	switch {
+	case ctx.IsGet():
+		b.serveVerification(ctx)
+	case ctx.IsPost():
+		b.serveNotification(ctx)
+	default:
+		ctx.Error("method not allowed", fasthttp.StatusMethodNotAllowed)
+	}
+}
+
+// serveVerification echoes the challenge of a callback URL check
+func (b *Bot) serveVerification(ctx *fasthttp.RequestCtx) {
+	args := ctx.QueryArgs()
+	mode := string(args.Peek("hub.mode"))
+	token := args.Peek("hub.verify_token")
+	if mode != "subscribe" || !hmac.Equal(token, []byte(b.config.VerifyToken)) {
+		if b.config.Debug {
+			log.Printf("🚫 WhatsApp webhook check from %s rejected: verify token mismatch", ctx.RemoteIP())
+		}
+		ctx.Error("forbidden", fasthttp.StatusForbidden)
+		return
+	}
+
+	ctx.SetContentType("text/plain")
+	ctx.SetBody(args.Peek("hub.challenge"))
+}
+
+// serveNotification handles the messages of a notification
+func (b *Bot) serveNotification(ctx *fasthttp.RequestCtx) {
+	body := ctx.PostBody()
+	if ctx.Request.Header.ContentLength() > maxWebhookBody || len(body) > maxWebhookBody {
+		ctx.Error("request too large", fasthttp.StatusRequestEntityTooLarge)
+		return
+	}
+
+	signature := string(ctx.Request.Header.Peek(signatureHeader))
+	if err := verifySignature(b.config.AppSecret, signature, body); err != nil {
+		if b.config.Debug {
+			log.Printf("🚫 WhatsApp notification from %s rejected: %v", ctx.RemoteIP(), err)
+		}
+		ctx.Error("unauthorized", fasthttp.StatusUnauthorized)
+		return
+	}
+
+	var n notification
+	if err := json.Unmarshal(body, &n); err != nil {
+		ctx.Error(fmt.Sprintf("invalid notification: %v", err), fasthttp.StatusBadRequest)
+		return
+	}
+
+	if !b.IsRunning() {
+		// Meta retries until the bot is back
+		ctx.Error("bot is not running", fasthttp.StatusServiceUnavailable)
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	for _, entry := range n.Entry {
+		for _, c := range entry.Changes {
+			// Statuses of sent messages come in the same field
+			if c.Field != "messages" {
+				continue
+			}
+			// An app may serve several phone numbers
+			if c.Value.Metadata.PhoneNumberID != b.config.PhoneNumberID {
+				if b.config.Debug {
+					log.Printf("ℹ️  Dropped notification for phone number %s", c.Value.Metadata.PhoneNumberID)
+				}
+				continue
+			}
+			for i := range c.Value.Messages {
+				msg := &c.Value.Messages[i]
+				if !b.recent.Add(msg.ID) {
+					if b.config.Debug {
+						log.Printf("ℹ️  Dropped message %s, delivered already", msg.ID)
+					}
+					continue
+				}
+				b.handler.HandleMessage(msg, contactName(c.Value.Contacts, msg.From))
+			}
+		}
+	}
+}
+
+// verifySignature checks the signature of a notification, made with the
+// app secret over the body
+func verifySignature(secret, signature string, body []byte) error {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return fmt.Errorf("missing signature")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(strings.ToLower(signature[len(signaturePrefix):])), []byte(expected)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// contactName returns the profile name of the sender of a message, empty
+// if not given
+func contactName(contacts []contact, waID string) string {
+	for _, c := range contacts {
+		if c.WaID == waID {
+			return c.Profile.Name
+		}
+	}
+	return ""
+}
+
+var _ channels.Webhook = (*Bot)(nil)
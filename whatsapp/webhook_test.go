@@ -0,0 +1,109 @@
+package whatsapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// sign signs a body the way Meta does
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newTestBot returns a bot of the test app, on the Graph API at apiURL
+func newTestBot(t *testing.T, apiURL string) *Bot {
+	t.Helper()
+	b, err := NewBot(&Config{
+		AccessToken:   "token",
+		PhoneNumberID: "100",
+		AppSecret:     "secret",
+		VerifyToken:   "verify",
+		APIURL:        apiURL,
+		Template:      "reply",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"object":"whatsapp_business_account"}`)
+	for _, tc := range []struct {
+		name      string
+		signature string
+		body      []byte
+		ok        bool
+	}{
+		{"valid", sign("secret", body), body, true},
+		{"upper case hex", signaturePrefix + strings.ToUpper(strings.TrimPrefix(sign("secret", body), signaturePrefix)), body, true},
+		{"other secret", sign("other", body), body, false},
+		{"changed body", sign("secret", body), []byte(`{}`), false},
+		{"without prefix", strings.TrimPrefix(sign("secret", body), signaturePrefix), body, false},
+		{"sha1", "sha1=" + strings.TrimPrefix(sign("secret", body), signaturePrefix), body, false},
+		{"missing", "", body, false},
+	} {
+		err := verifySignature("secret", tc.signature, tc.body)
+		if tc.ok && err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("%s: accepted", tc.name)
+		}
+	}
+}
+
+func TestServeVerification(t *testing.T) {
+	b := newTestBot(t, "")
+	for _, tc := range []struct {
+		query  string
+		status int
+		body   string
+	}{
+		{"hub.mode=subscribe&hub.verify_token=verify&hub.challenge=42", fasthttp.StatusOK, "42"},
+		{"hub.mode=subscribe&hub.verify_token=wrong&hub.challenge=42", fasthttp.StatusForbidden, ""},
+		{"hub.mode=subscribe&hub.challenge=42", fasthttp.StatusForbidden, ""},
+		{"hub.mode=unsubscribe&hub.verify_token=verify&hub.challenge=42", fasthttp.StatusForbidden, ""},
+	} {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.SetRequestURI("/whatsapp/webhook?" + tc.query)
+		b.ServeWebhook(&ctx)
+		if code := ctx.Response.StatusCode(); code != tc.status {
+			t.Errorf("%s: status %d, want %d", tc.query, code, tc.status)
+		}
+		if tc.body != "" && string(ctx.Response.Body()) != tc.body {
+			t.Errorf("%s: body %q, want the challenge", tc.query, ctx.Response.Body())
+		}
+	}
+}
+
+func TestServeNotificationSignature(t *testing.T) {
+	b := newTestBot(t, "")
+	body := []byte(`{"object":"whatsapp_business_account","entry":[]}`)
+
+	for _, tc := range []struct {
+		signature string
+		status    int
+	}{
+		{"", fasthttp.StatusUnauthorized},
+		{sign("other", body), fasthttp.StatusUnauthorized},
+		// Signed notifications wait for the bot to run, so Meta retries them
+		{sign("secret", body), fasthttp.StatusServiceUnavailable},
+	} {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+		ctx.Request.Header.Set(signatureHeader, tc.signature)
+		ctx.Request.SetBody(body)
+		b.ServeWebhook(&ctx)
+		if code := ctx.Response.StatusCode(); code != tc.status {
+			t.Errorf("signature %q: status %d, want %d", tc.signature, code, tc.status)
+		}
+	}
+}